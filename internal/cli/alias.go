@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// annotationAlias marks the commands registered for aliases; its value is
+// the command the alias runs
+const annotationAlias = "alias"
+
+// maxAliasDepth bounds how many aliases may expand into one another
+const maxAliasDepth = 10
+
+// aliasCmds creates a command for each alias in the config, so that aliases
+// show up in help and shell completion. Aliases never replace built-in
+// commands; one named like a command is ignored with a warning.
+func (c *CLI) aliasCmds(root *cobra.Command) []*cobra.Command {
+	if c.config == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(c.config.Aliases))
+	for name := range c.config.Aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var cmds []*cobra.Command
+	for _, name := range names {
+		command := c.config.Aliases[name]
+		if builtin(root, name) {
+			c.logger.Warn("Ignoring alias named like a command", "alias", name)
+			continue
+		}
+		cmds = append(cmds, &cobra.Command{
+			Use:                name + " [arguments]",
+			Short:              fmt.Sprintf("Alias for %q", command),
+			Long:               fmt.Sprintf("Runs \"task %s\", followed by any arguments given to the alias.", command),
+			Annotations:        map[string]string{annotationAlias: command},
+			DisableFlagParsing: true,
+			// Execute expands aliases before cobra sees them
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return fmt.Errorf("alias %s was not expanded", name)
+			},
+		})
+	}
+	return cmds
+}
+
+// builtin reports whether name is a command or command alias of root
+func builtin(root *cobra.Command, name string) bool {
+	for _, cmd := range root.Commands() {
+		if _, ok := cmd.Annotations[annotationAlias]; ok {
+			continue
+		}
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help" || name == "completion"
+}
+
+// expandAliases replaces the alias that args run, if any, with its command,
+// keeping the arguments before and after it: "task --no-color urgent -c work"
+// runs "task --no-color list ... -c work". Aliases may expand to other
+// aliases. Shell completion requests are expanded too.
+func (c *CLI) expandAliases(root *cobra.Command, args []string) ([]string, error) {
+	prefix := 0
+	if len(args) > 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd) {
+		prefix = 1
+	}
+
+	var seen []string
+	for {
+		cmd, _, err := root.Find(args[prefix:])
+		if err != nil || cmd.Parent() != root {
+			return args, nil
+		}
+		command, ok := cmd.Annotations[annotationAlias]
+		if !ok {
+			return args, nil
+		}
+		if slices.Contains(seen, cmd.Name()) || len(seen) == maxAliasDepth {
+			return nil, &configError{fmt.Errorf("alias %s expands to itself (%s)", seen[0], strings.Join(append(seen, cmd.Name()), " → "))}
+		}
+		seen = append(seen, cmd.Name())
+
+		expansion, err := splitArgs(command)
+		if err != nil {
+			return nil, &configError{fmt.Errorf("invalid alias %s: %w", cmd.Name(), err)}
+		}
+		i := prefix + slices.Index(args[prefix:], cmd.Name())
+		args = slices.Concat(args[:i], expansion, args[i+1:])
+	}
+}
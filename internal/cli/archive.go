@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/encryption"
+	"github.com/edson-mazvila/task-manager/internal/repository"
+	"github.com/edson-mazvila/task-manager/internal/service"
+	"github.com/edson-mazvila/task-manager/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// archiveCmd creates the archive command
+func (c *CLI) archiveCmd() *cobra.Command {
+	var olderThan string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Move old completed tasks into the archive database",
+		Long: `Move tasks completed before --older-than into a separate archive database
+(database.archive in config, by default "<name>.archive.db" next to the main
+database). Archived tasks no longer slow down everyday queries; see them with
+"task list --archived".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("archiving is only supported for SQLite storage")
+			}
+
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return err
+			}
+			cutoff := time.Now().Add(-age)
+			path := c.archivePath()
+			times, err := c.timeFormat()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Archive tasks completed before %s\n  into: %s\n", times.Date(cutoff), path)
+			if c.dryRun {
+				return nil
+			}
+			if !yes && !c.confirm("Continue?") {
+				fmt.Println("Aborted.")
+				return nil
+			}
+
+			n, err := c.storage.Archive(cmd.Context(), path, cutoff)
+			if err != nil {
+				return fmt.Errorf("failed to archive tasks: %w", err)
+			}
+
+			fmt.Println(c.t("✓ Archived %d task(s)", n))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "30d", "Archive tasks completed longer ago than this (e.g. 90d, 8w, 720h)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// archivePath returns the archive database for the current storage
+func (c *CLI) archivePath() string {
+	db := config.DatabaseConfig{Path: c.storage.Path()}
+	if c.config != nil {
+		db.Archive = c.config.Database.Archive
+	}
+	return db.ArchivePath()
+}
+
+// openArchive returns a task service over the archive database and a function
+// closing it, or a nil service when nothing has been archived yet
+func (c *CLI) openArchive(ctx context.Context) (*service.TaskService, func(), error) {
+	if c.storage == nil {
+		return nil, nil, fmt.Errorf("archiving is only supported for SQLite storage")
+	}
+
+	path := c.archivePath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, func() {}, nil
+	}
+
+	archive, err := storage.NewSQLiteStorage(ctx, path, c.logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	var passphrase string
+	if c.config != nil {
+		passphrase = c.config.Database.Passphrase
+	}
+	cipher, err := encryption.Open(ctx, archive.DB(), passphrase)
+	if err != nil {
+		archive.Close()
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	var repo domain.TaskRepository = repository.NewSQLiteTaskRepository(archive.DB(), c.logger)
+	if cipher != nil {
+		repo = repository.NewEncryptedTaskRepository(repo, cipher)
+	}
+	return service.NewTaskService(repo, c.logger), func() { archive.Close() }, nil
+}
+
+// parseAge parses a duration that also accepts days (d) and weeks (w)
+func parseAge(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	if unit != 0 {
+		n, err := strconv.Atoi(strings.TrimSpace(s[:len(s)-1]))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age: %s (use e.g. 30d, 8w, or 720h)", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age: %s (use e.g. 30d, 8w, or 720h)", s)
+	}
+	return d, nil
+}
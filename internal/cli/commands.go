@@ -5,68 +5,451 @@
 package cli
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"text/tabwriter"
+	"os/signal"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/crash"
 	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/i18n"
+	"github.com/edson-mazvila/task-manager/internal/repository"
 	"github.com/edson-mazvila/task-manager/internal/service"
+	"github.com/edson-mazvila/task-manager/internal/state"
+	"github.com/edson-mazvila/task-manager/internal/storage"
+	"github.com/edson-mazvila/task-manager/internal/suggest"
+	"github.com/edson-mazvila/task-manager/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+// Version is the application version, set at build time with
+// -ldflags "-X github.com/edson-mazvila/task-manager/internal/cli.Version=v1.2.3".
+var Version = "dev"
+
+// annotationPerRequestTimeout marks long-running commands, such as servers,
+// whose --timeout bounds each request rather than the whole command. Set to
+// "true", or to the name of a boolean flag that makes the command long-running.
+const annotationPerRequestTimeout = "per-request-timeout"
+
+// perRequestTimeout reports whether cmd, as invoked, applies --timeout to
+// each request (see annotationPerRequestTimeout)
+func perRequestTimeout(cmd *cobra.Command) bool {
+	value := cmd.Annotations[annotationPerRequestTimeout]
+	if value == "" || value == "true" {
+		return value == "true"
+	}
+	on, _ := cmd.Flags().GetBool(value)
+	return on
+}
+
 // CLI holds the CLI configuration and dependencies.
 // It follows dependency injection principles, receiving the service layer
 // and logger through the constructor to maintain loose coupling.
 type CLI struct {
-	service *service.TaskService
-	logger  *slog.Logger
+	service  *service.TaskService
+	users    *service.UserService
+	logger   *slog.Logger
+	state    *state.Store
+	suggest  *suggest.Store
+	config   *config.Config
+	storage  *storage.SQLiteStorage
+	noColor  bool
+	noPager  bool
+	allUsers bool
+	actor    *domain.User // set once roles are enforced
+	dryRun   bool
+	timeout  time.Duration
+	cancel   context.CancelFunc
+	stdin    *bufio.Reader
+	pager    *pager
+	messages *i18n.Printer
+	// fingerprint of the database before the command ran, see RootCmd
+	fingerprint string
+}
+
+// Option configures optional CLI dependencies
+type Option func(*CLI)
+
+// WithStateStore sets the store used to persist CLI state such as the active context
+func WithStateStore(store *state.Store) Option {
+	return func(c *CLI) {
+		c.state = store
+	}
+}
+
+// WithSuggestionStore sets the cache used to answer shell completion without querying the database
+func WithSuggestionStore(store *suggest.Store) Option {
+	return func(c *CLI) {
+		c.suggest = store
+	}
+}
+
+// WithConfig gives commands access to user configuration such as display defaults
+func WithConfig(cfg *config.Config) Option {
+	return func(c *CLI) {
+		c.config = cfg
+	}
+}
+
+// WithStorage gives database maintenance commands access to the underlying storage
+func WithStorage(store *storage.SQLiteStorage) Option {
+	return func(c *CLI) {
+		c.storage = store
+	}
+}
+
+// WithUserService enables the user commands for assigning tasks in a shared database
+func WithUserService(users *service.UserService) Option {
+	return func(c *CLI) {
+		c.users = users
+	}
 }
 
 // NewCLI creates a new CLI instance
-func NewCLI(service *service.TaskService, logger *slog.Logger) *CLI {
-	return &CLI{
+func NewCLI(service *service.TaskService, logger *slog.Logger, opts ...Option) *CLI {
+	c := &CLI{
 		service: service,
 		logger:  logger,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // RootCmd returns the root command with all subcommands attached.
-// Subcommands include: add, list, get, update, complete, delete.
+// Subcommands include: add, list, mine, delegated, get, update, modify, complete, delete, archive, stats, context, user, export, sync, db, encrypt, decrypt, config, logs, git, import.
 // Each command has its own flags and validation logic.
 func (c *CLI) RootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "task",
-		Short: "A production-grade CLI task manager",
+		Short: c.t("A production-grade CLI task manager"),
 		Long:  `Task Manager is a CLI application for managing your tasks efficiently.`,
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&c.noColor, "no-color", false, "Disable colored output (also honors NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&c.noPager, "no-pager", false, "Do not pipe long output through a pager")
+	rootCmd.PersistentFlags().BoolVar(&c.dryRun, "dry-run", false, "Show what would change without writing anything")
+	rootCmd.PersistentFlags().BoolVar(&c.allUsers, "all-users", false, "Admins: work on every user's tasks, not just your own")
+	rootCmd.PersistentFlags().String("output", "text", "Format for errors (text, json)")
+	rootCmd.PersistentFlags().DurationVar(&c.timeout, "timeout", 0, "Give up after this long, e.g. 30s (default from behavior.timeout; 0 waits forever)")
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		c.legacyHint(cmd)
+
+		if c.storage != nil {
+			c.fingerprint, _ = suggest.Fingerprint(c.storage.Path())
+		}
+
+		if c.dryRun {
+			c.service = c.service.DryRun(func(repo domain.TaskRepository) domain.TaskRepository {
+				return repository.NewDryRunTaskRepository(repo, c.logger)
+			})
+			fmt.Fprintln(os.Stderr, "Dry run: no changes will be written.")
+		}
+
+		if !cmd.Flags().Changed("timeout") && c.config != nil {
+			c.timeout = c.config.Behavior.Timeout
+		}
+		// Long-running commands apply the timeout to each request instead
+		if c.timeout > 0 && !perRequestTimeout(cmd) {
+			ctx, cancel := context.WithTimeout(cmd.Context(), c.timeout)
+			cmd.SetContext(ctx)
+			c.cancel = cancel
+		}
+
+		if err := c.enforceRoles(cmd.Context()); err != nil {
+			return err
+		}
+
+		return c.startPager(cmd)
+	}
+
+	// Keep the completion cache current after commands that wrote to the
+	// database, which changes its fingerprint. Commands that only read leave
+	// the cache alone, even if another process made it stale.
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		if c.storage == nil || c.dryRun {
+			return
+		}
+		if fingerprint, err := suggest.Fingerprint(c.storage.Path()); err != nil || fingerprint == c.fingerprint {
+			return
+		}
+		if _, err := c.suggestions(cmd.Context()); err != nil {
+			c.logger.Debug("Failed to refresh completion cache", "error", err)
+		}
+	}
+
 	rootCmd.AddCommand(
 		c.addCmd(),
 		c.listCmd(),
+		c.mineCmd(),
+		c.delegatedCmd(),
+		c.delegateCmd(),
 		c.completeCmd(),
+		c.snoozeCmd(),
+		c.pinCmd(true),
+		c.pinCmd(false),
+		c.linkCmd(),
+		c.unlinkCmd(),
+		c.linksCmd(),
+		c.commentCmd(),
 		c.deleteCmd(),
+		c.archiveCmd(),
+		c.statsCmd(),
+		c.burndownCmd(),
+		c.velocityCmd(),
 		c.updateCmd(),
+		c.modifyCmd(),
 		c.getCmd(),
+		c.contextCmd(),
+		c.userCmd(),
+		c.exportCmd(),
+		c.syncCmd(),
+		c.dbCmd(),
+		c.doctorCmd(),
+		c.maintenanceCmd(),
+		c.encryptCmd(),
+		c.decryptCmd(),
+		c.configCmd(),
+		c.logsCmd(),
+		c.gitCmd(),
+		c.importCmd(),
+		c.digestCmd(),
+		c.standupCmd(),
+		c.promptCmd(),
+		c.overdueCmd(),
+		c.staleCmd(),
+		c.planCmd(),
+		c.nextCmd(),
+		c.escalationsCmd(),
+		c.reportCmd(),
+		c.timelineCmd(),
+		c.mcpCmd(),
+		c.pluginsCmd(),
 	)
+	rootCmd.AddCommand(c.aliasCmds(rootCmd)...)
 
 	return rootCmd
 }
 
+// Execute runs the root command with panic recovery. If a command panics,
+// a crash report is written to the data directory and its location is
+// printed instead of a raw stack trace. Errors are reported on stderr, as
+// JSON when the command was run with --output json; use ExitCode to map
+// the returned error to a process exit code.
+func (c *CLI) Execute() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = c.handlePanic(r, debug.Stack())
+		}
+	}()
+
+	rootCmd := c.RootCmd()
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	// A bare "task" runs the configured default command instead of help
+	args := os.Args[1:]
+	if len(args) == 0 {
+		if args, err = c.defaultArgs(); err != nil {
+			writeError(os.Stderr, rootCmd, err)
+			return err
+		}
+	}
+	if args, err = c.expandAliases(rootCmd, args); err != nil {
+		writeError(os.Stderr, rootCmd, err)
+		return err
+	}
+	rootCmd.SetArgs(args)
+
+	// Ctrl-C cancels the running command's context so in-flight queries stop.
+	// If the command does not return shortly after (e.g. it is waiting for a
+	// prompt), the process exits anyway.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		stop()
+	}()
+	go func() {
+		<-ctx.Done()
+		select {
+		case <-done:
+			return
+		default:
+		}
+		stop()
+		select {
+		case <-done:
+		case <-time.After(interruptGrace):
+			fmt.Fprintln(os.Stderr, "Interrupted")
+			os.Exit(ExitInterrupted)
+		}
+	}()
+
+	// Anything that is not a command may be a plugin on PATH
+	if name, path := findPlugin(rootCmd, args); path != "" {
+		err = c.runPlugin(ctx, name, path, args[1:])
+		var pluginErr *pluginError
+		if err != nil && !errors.As(err, &pluginErr) {
+			writeError(os.Stderr, rootCmd, err)
+		}
+		return err
+	}
+
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	c.stopPager()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s (raise --timeout or behavior.timeout): %w", c.timeout, err)
+	}
+	if err != nil {
+		writeError(os.Stderr, cmd, err)
+		if !jsonOutput(cmd) {
+			fmt.Fprintln(os.Stderr, cmd.UsageString())
+		}
+	}
+	return err
+}
+
+// interruptGrace is how long a command may take to stop after Ctrl-C
+const interruptGrace = 2 * time.Second
+
+// defaultArgs returns the arguments of display.default_command, or nil if
+// none is configured. A leading "task" is accepted and dropped.
+func (c *CLI) defaultArgs() ([]string, error) {
+	if c.config == nil || strings.TrimSpace(c.config.Display.DefaultCommand) == "" {
+		return nil, nil
+	}
+
+	args, err := splitArgs(c.config.Display.DefaultCommand)
+	if err != nil {
+		return nil, &configError{fmt.Errorf("invalid display.default_command: %w", err)}
+	}
+	if len(args) > 0 && args[0] == "task" {
+		args = args[1:]
+	}
+
+	return args, nil
+}
+
+// splitArgs splits a command line into arguments, honoring single and double quotes
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// handlePanic records a crash report for a recovered panic and returns an error describing it
+func (c *CLI) handlePanic(recovered interface{}, stack []byte) error {
+	c.logger.Error("Command panicked", "panic", recovered)
+
+	command := strings.Join(os.Args, " ")
+	report := crash.NewReport(Version, command, recovered, stack, c.config)
+
+	dir := os.TempDir()
+	if c.config != nil {
+		dir = c.config.DataDir()
+	}
+
+	path, err := report.Write(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "task crashed unexpectedly: %v\n%s\n", recovered, stack)
+		return fmt.Errorf("unexpected internal error (failed to save crash report: %w)", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "task crashed unexpectedly. A crash report was saved to:\n  %s\n", path)
+	fmt.Fprintf(os.Stderr, "Please include this file when reporting the issue.\n")
+	return fmt.Errorf("unexpected internal error: %v", recovered)
+}
+
 // addCmd creates the add command
 func (c *CLI) addCmd() *cobra.Command {
 	var priority string
 	var description string
+	var taskContext string
+	var assignee string
+	var location string
+	var estimate string
+	var points int
+	var due string
+	var sets []string
+	var fromStdin bool
+	var id, key string
 
 	cmd := &cobra.Command{
 		Use:   "add [title]",
-		Short: "Add a new task",
-		Long:  `Add a new task with the specified title, priority, and optional description.`,
-		Args:  cobra.ExactArgs(1),
+		Short: c.t("Add a new task"),
+		Long: `Add a new task with the specified title, priority, and optional description.
+Use "-" as the title to read it from stdin, or --stdin to create one task per
+input line. Batch lines have the form "title", "title | priority", or
+"title | priority | due", where due takes the values of --due and either may be
+left empty; blank lines and lines starting with "#" are ignored. Batches are
+created atomically.
+
+Scripts can retry safely by passing --key (any string, e.g. a request ID) or
+--id (a UUID): when a task with that ID already exists, it is shown instead of
+creating another one.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if fromStdin {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			title := args[0]
+			// A project file supplies defaults for flags that were not given
+			if !cmd.Flags().Changed("priority") && c.config != nil && c.config.Project.Priority != "" {
+				priority = c.config.Project.Priority
+			}
+			if !cmd.Flags().Changed("context") {
+				taskContext = c.projectContext()
+			}
 
 			// Parse priority
 			taskPriority := domain.TaskPriority(priority)
@@ -76,20 +459,93 @@ func (c *CLI) addCmd() *cobra.Command {
 				return fmt.Errorf("invalid priority: %s (must be low, medium, or high)", priority)
 			}
 
+			opts := c.creatorOptions()
+			if taskContext != "" {
+				opts = append(opts, domain.WithTaskContext(taskContext))
+			}
+			if assignee != "" {
+				opts = append(opts, domain.WithAssignee(assignee))
+			}
+			if location != "" {
+				opts = append(opts, domain.WithLocation(location))
+			}
+			effort, err := estimateOptions(cmd, estimate, points)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, effort...)
+			dueDate, err := dueOptions(cmd, due)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, dueDate...)
+			fields, err := c.fieldOptions(sets)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, fields...)
+
+			if id != "" || key != "" {
+				if id != "" && key != "" {
+					return fmt.Errorf("give either --id or --key, not both")
+				}
+				if fromStdin {
+					return fmt.Errorf("--id and --key create a single task and cannot be combined with --stdin")
+				}
+				if id != "" {
+					opts = append(opts, domain.WithID(id))
+				} else {
+					opts = append(opts, domain.WithIdempotencyKey(key))
+				}
+			}
+
+			if fromStdin {
+				return c.addBatch(cmd.Context(), os.Stdin, description, taskPriority, opts)
+			}
+
+			title := args[0]
+			if title == "-" {
+				line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil && line == "" {
+					return fmt.Errorf("failed to read title from stdin: %w", err)
+				}
+				title = strings.TrimSpace(line)
+			}
+
 			// Create task
-			ctx := context.Background()
-			task, err := c.service.CreateTask(ctx, title, description, taskPriority)
+			ctx := cmd.Context()
+			start := time.Now()
+			task, err := c.service.CreateTask(ctx, title, description, taskPriority, opts...)
 			if err != nil {
 				return fmt.Errorf("failed to create task: %w", err)
 			}
 
-			fmt.Printf("✓ Task created successfully\n")
-			fmt.Printf("  ID:       %s\n", task.ID)
-			fmt.Printf("  Title:    %s\n", task.Title)
-			fmt.Printf("  Priority: %s\n", task.Priority)
+			if task.CreatedAt.Before(start) {
+				fmt.Println(c.t("✓ Task already exists"))
+			} else {
+				fmt.Println(c.t("✓ Task created successfully"))
+			}
+			details := c.taskSummary(task)
+			if task.Assignee != "" {
+				details = append(details, detail{c.t("Assignee:"), task.Assignee})
+			}
+			if task.Location != "" {
+				details = append(details, detail{c.t("Location:"), task.Location})
+			}
+			if effort := ui.Effort(task.Estimate, int64(task.Points)); effort != "" {
+				details = append(details, detail{c.t("Estimate:"), effort})
+			}
+			if day, ok := task.DueDate(); ok {
+				times, err := c.timeFormat()
+				if err != nil {
+					return err
+				}
+				details = append(details, detail{c.t("Due:"), times.Day(day)})
+			}
 			if task.Description != "" {
-				fmt.Printf("  Description: %s\n", task.Description)
+				details = append(details, detail{c.t("Description:"), task.Description})
 			}
+			printDetails(details)
 
 			return nil
 		},
@@ -97,203 +553,382 @@ func (c *CLI) addCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&priority, "priority", "p", "medium", "Task priority (low, medium, high)")
 	cmd.Flags().StringVarP(&description, "description", "d", "", "Task description")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Task context (e.g. home, office, errands)")
+	cmd.Flags().StringVar(&assignee, "assignee", "", "Assign the task to a registered user")
+	cmd.Flags().StringVar(&location, "location", "", "Only list the task at this location (e.g. office)")
+	addEstimateFlags(cmd, &estimate, &points)
+	addDueFlag(cmd, &due)
+	addSetFlag(cmd, &sets)
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+	_ = cmd.RegisterFlagCompletionFunc("assignee", c.assigneeCompletion)
+	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Create one task per line read from stdin")
+	cmd.Flags().StringVar(&id, "id", "", "Create the task with this ID (a UUID); shows the task if it exists")
+	cmd.Flags().StringVar(&key, "key", "", "Idempotency key; adding again with the same key shows the first task")
+	_ = cmd.RegisterFlagCompletionFunc("priority", fixedCompletion(priorityValues...))
 
 	return cmd
 }
 
-// listCmd creates the list command
-func (c *CLI) listCmd() *cobra.Command {
-	var status string
-	var priority string
-	var fromDate string
-	var toDate string
-
-	cmd := &cobra.Command{
-		Use:   "list",
-		Short: "List tasks",
-		Long:  `List all tasks with optional filtering by status, priority, and date range.`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			filter := domain.TaskFilter{}
+// addBatch creates one task per non-empty input line in a single transaction.
+// Flag values act as defaults for every line.
+func (c *CLI) addBatch(ctx context.Context, in io.Reader, description string, priority domain.TaskPriority, opts []domain.TaskOption) error {
+	var drafts []service.NewTaskDraft
+	var problems []string
+
+	scanner := bufio.NewScanner(in)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		draft, err := parseBatchLine(line, description, priority, opts)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("  line %d: %v", lineNo, err))
+			continue
+		}
+		drafts = append(drafts, draft)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
 
-			// Parse status filter
-			if status != "" {
-				taskStatus := domain.TaskStatus(status)
-				if taskStatus != domain.TaskStatusPending && taskStatus != domain.TaskStatusCompleted {
-					return fmt.Errorf("invalid status: %s (must be pending or completed)", status)
-				}
-				filter.Status = &taskStatus
-			}
+	if len(problems) > 0 {
+		fmt.Fprintln(os.Stderr, "Invalid input, no tasks were created:")
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+		}
+		return fmt.Errorf("%d invalid line(s)", len(problems))
+	}
 
-			// Parse priority filter
-			if priority != "" {
-				taskPriority := domain.TaskPriority(priority)
-				if taskPriority != domain.TaskPriorityLow &&
-					taskPriority != domain.TaskPriorityMedium &&
-					taskPriority != domain.TaskPriorityHigh {
-					return fmt.Errorf("invalid priority: %s (must be low, medium, or high)", priority)
-				}
-				filter.Priority = &taskPriority
-			}
+	if len(drafts) == 0 {
+		fmt.Println(c.t("No tasks to create."))
+		return nil
+	}
 
-			// Parse date filters
-			if fromDate != "" {
-				t, err := time.Parse("2006-01-02", fromDate)
-				if err != nil {
-					return fmt.Errorf("invalid from-date format (use YYYY-MM-DD): %w", err)
-				}
-				filter.FromDate = &t
-			}
+	tasks, err := c.service.CreateTasks(ctx, drafts)
+	if err != nil {
+		return fmt.Errorf("failed to create tasks: %w", err)
+	}
 
-			if toDate != "" {
-				t, err := time.Parse("2006-01-02", toDate)
-				if err != nil {
-					return fmt.Errorf("invalid to-date format (use YYYY-MM-DD): %w", err)
-				}
-				filter.ToDate = &t
-			}
+	fmt.Println(c.t("✓ Created %d task(s)", len(tasks)))
+	for _, task := range tasks {
+		fmt.Printf("  %s  %s (%s)\n", task.ID[:8], task.Title, task.Priority)
+	}
 
-			// List tasks
-			ctx := context.Background()
-			tasks, err := c.service.ListTasks(ctx, filter)
-			if err != nil {
-				return fmt.Errorf("failed to list tasks: %w", err)
-			}
+	return nil
+}
 
-			if len(tasks) == 0 {
-				fmt.Println("No tasks found.")
-				return nil
-			}
+// parseBatchLine parses a "title", "title | priority", or
+// "title | priority | due" batch input line
+func parseBatchLine(line, description string, priority domain.TaskPriority, opts []domain.TaskOption) (service.NewTaskDraft, error) {
+	fields := strings.Split(line, "|")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
 
-			// Display tasks in table format
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tPRIORITY\tCREATED")
-			fmt.Fprintln(w, "--\t-----\t------\t--------\t-------")
+	draft := service.NewTaskDraft{
+		Title:       fields[0],
+		Description: description,
+		Priority:    priority,
+		Options:     opts,
+	}
 
-			for _, task := range tasks {
-				createdAt := task.CreatedAt.Format("2006-01-02 15:04")
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-					task.ID[:8], task.Title, task.Status, task.Priority, createdAt)
-			}
+	if draft.Title == "" {
+		return draft, fmt.Errorf("title cannot be empty")
+	}
 
-			w.Flush()
-			fmt.Printf("\nTotal: %d task(s)\n", len(tasks))
+	if len(fields) > 1 && fields[1] != "" {
+		p := domain.TaskPriority(strings.ToLower(fields[1]))
+		if p != domain.TaskPriorityLow && p != domain.TaskPriorityMedium && p != domain.TaskPriorityHigh {
+			return draft, fmt.Errorf("invalid priority: %s (must be low, medium, or high)", fields[1])
+		}
+		draft.Priority = p
+	}
 
-			return nil
-		},
+	if len(fields) > 3 {
+		return draft, fmt.Errorf("too many fields (expected \"title | priority | due\")")
 	}
 
-	cmd.Flags().StringVarP(&status, "status", "s", "", "Filter by status (pending, completed)")
-	cmd.Flags().StringVarP(&priority, "priority", "p", "", "Filter by priority (low, medium, high)")
-	cmd.Flags().StringVar(&fromDate, "from", "", "Filter by from date (YYYY-MM-DD)")
-	cmd.Flags().StringVar(&toDate, "to", "", "Filter by to date (YYYY-MM-DD)")
+	if len(fields) > 2 && fields[2] != "" {
+		due, err := parseDueDate(fields[2], time.Now())
+		if err != nil {
+			return draft, err
+		}
+		draft.Options = append(slices.Clip(opts), domain.WithDueDate(due))
+	}
 
-	return cmd
+	return draft, nil
 }
 
 // getCmd creates the get command
 func (c *CLI) getCmd() *cobra.Command {
+	var absolute bool
+
 	cmd := &cobra.Command{
 		Use:   "get [task-id]",
-		Short: "Get task details",
-		Long:  `Get detailed information about a specific task.`,
-		Args:  cobra.ExactArgs(1),
+		Short: c.t("Get task details"),
+		Long: `Get detailed information about a specific task, given by its full or short ID.
+Times are shown with how long ago they were, and due dates relative to today,
+unless --absolute is given.`,
+		Annotations:       map[string]string{annotationPager: "true"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: c.taskIDCompletion(nil),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			taskID := args[0]
+			ctx := cmd.Context()
+			taskID, err := c.resolveTaskID(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get task: %w", err)
+			}
 
-			ctx := context.Background()
 			task, err := c.service.GetTask(ctx, taskID)
 			if err != nil {
 				return fmt.Errorf("failed to get task: %w", err)
 			}
 
-			fmt.Printf("Task Details:\n")
-			fmt.Printf("  ID:          %s\n", task.ID)
-			fmt.Printf("  Title:       %s\n", task.Title)
-			fmt.Printf("  Description: %s\n", task.Description)
-			fmt.Printf("  Status:      %s\n", task.Status)
-			fmt.Printf("  Priority:    %s\n", task.Priority)
-			fmt.Printf("  Created:     %s\n", task.CreatedAt.Format("2006-01-02 15:04:05"))
-			fmt.Printf("  Updated:     %s\n", task.UpdatedAt.Format("2006-01-02 15:04:05"))
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+			times, err := c.timeFormat()
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			when := func(t time.Time) string {
+				if absolute {
+					return times.DateTime(t)
+				}
+				return ui.RelativeTime(t, now) + " (" + times.DateTime(t) + ")"
+			}
+
+			status := string(task.Status)
+			if task.Status == domain.TaskStatusCompleted {
+				status = painter.Paint(ui.RoleSuccess, status)
+			}
+
+			fmt.Printf("%s\n", painter.Paint(ui.RoleHeader, c.t("Task Details:")))
+			fmt.Printf("  %-13s%s\n", c.t("ID:"), painter.Paint(ui.RoleID, task.ID))
+			fmt.Printf("  %-13s%s\n", c.t("Title:"), task.Title)
+			fmt.Printf("  %-13s%s\n", c.t("Description:"), task.Description)
+			fmt.Printf("  %-13s%s\n", c.t("Status:"), status)
+			fmt.Printf("  %-13s%s\n", c.t("Priority:"), painter.Paint(ui.PriorityRole(task.Priority), string(task.Priority)))
+			if task.Pinned {
+				fmt.Printf("  %-13s%s %s\n", c.t("Pinned:"), ui.PinMarker, c.t("yes"))
+			}
+			if task.Context != "" {
+				fmt.Printf("  %-13s@%s\n", c.t("Context:"), task.Context)
+			}
+			if task.Assignee != "" {
+				fmt.Printf("  %-13s%s\n", c.t("Assignee:"), task.Assignee)
+			}
+			if task.Location != "" {
+				fmt.Printf("  %-13s%s\n", c.t("Location:"), task.Location)
+			}
+			if effort := ui.Effort(task.Estimate, int64(task.Points)); effort != "" {
+				fmt.Printf("  %-13s%s\n", c.t("Estimate:"), effort)
+			}
+			printFields(task)
+			if due, ok := task.DueDate(); ok {
+				text := times.Day(due)
+				if !absolute && task.Status == domain.TaskStatusPending {
+					text = ui.RelativeDue(due, now) + " (" + text + ")"
+					if ui.DaysUntil(due, now) < 0 {
+						text = painter.Paint(ui.RoleOverdue, text)
+					}
+				}
+				fmt.Printf("  %-13s%s\n", c.t("Due:"), text)
+			}
+			fmt.Printf("  %-13s%s\n", c.t("Created:"), when(task.CreatedAt))
+			fmt.Printf("  %-13s%s\n", c.t("Updated:"), when(task.UpdatedAt))
+			if task.Waiting(now) {
+				fmt.Printf("  %-13s%s\n", c.t("Snoozed:"), c.t("until %s", when(*task.WaitUntil)))
+			}
 
 			if task.CompletedAt != nil {
-				fmt.Printf("  Completed:   %s\n", task.CompletedAt.Format("2006-01-02 15:04:05"))
+				fmt.Printf("  %-13s%s\n", c.t("Completed:"), when(*task.CompletedAt))
+			}
+
+			printGitLinks(task, times)
+
+			if err := c.printLinks(ctx, painter, task); err != nil {
+				return fmt.Errorf("failed to get task links: %w", err)
+			}
+
+			if err := c.printComments(ctx, painter, task, when); err != nil {
+				return fmt.Errorf("failed to get task comments: %w", err)
 			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&absolute, "absolute", false, "Show dates and times without how long ago or until")
+
 	return cmd
 }
 
 // completeCmd creates the complete command
 func (c *CLI) completeCmd() *cobra.Command {
+	pending := domain.TaskStatusPending
+	var followUp bool
+
 	cmd := &cobra.Command{
 		Use:   "complete [task-id]",
-		Short: "Mark a task as completed",
-		Long:  `Mark the specified task as completed.`,
-		Args:  cobra.ExactArgs(1),
+		Short: c.t("Mark a task as completed"),
+		Long: `Mark the specified task as completed.
+With --follow-up (or behavior.follow_up_prompt in config), you are asked whether
+to create a follow-up task that inherits the priority and context of the completed one.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: c.taskIDCompletion(&pending),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			taskID := args[0]
 
-			ctx := context.Background()
+			ctx := cmd.Context()
 			task, err := c.service.CompleteTask(ctx, taskID)
 			if err != nil {
 				return fmt.Errorf("failed to complete task: %w", err)
 			}
 
-			fmt.Printf("✓ Task marked as completed\n")
-			fmt.Printf("  ID:    %s\n", task.ID)
-			fmt.Printf("  Title: %s\n", task.Title)
+			fmt.Println(c.t("✓ Task marked as completed"))
+			printDetails([]detail{{c.t("ID:"), task.ID}, {c.t("Title:"), task.Title}})
+
+			if !cmd.Flags().Changed("follow-up") {
+				followUp = c.config != nil && c.config.Behavior.FollowUpPrompt && ui.IsTerminal(os.Stdin)
+			}
+			if followUp {
+				return c.promptFollowUp(ctx, task)
+			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&followUp, "follow-up", false, "Offer to create a follow-up task")
+
 	return cmd
 }
 
+// promptFollowUp asks whether to create a follow-up to a completed task
+// and creates it with the same priority and context.
+func (c *CLI) promptFollowUp(ctx context.Context, completed *domain.Task) error {
+	if !c.confirm(c.t("Create follow-up task?")) {
+		return nil
+	}
+
+	title, err := c.ask(c.t("Title"))
+	if err != nil || title == "" {
+		fmt.Println(c.t("No title given, skipping follow-up."))
+		return nil
+	}
+
+	opts := append(c.creatorOptions(), domain.WithTaskContext(completed.Context))
+	task, err := c.service.CreateTask(ctx, title, "", completed.Priority, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create follow-up task: %w", err)
+	}
+
+	fmt.Println(c.t("✓ Follow-up task created"))
+	printDetails(c.taskSummary(task))
+
+	return nil
+}
+
 // deleteCmd creates the delete command
 func (c *CLI) deleteCmd() *cobra.Command {
+	var yes bool
+
 	cmd := &cobra.Command{
 		Use:   "delete [task-id]",
-		Short: "Delete a task",
-		Long:  `Delete the specified task permanently.`,
-		Args:  cobra.ExactArgs(1),
+		Short: c.t("Delete a task"),
+		Long: `Delete the specified task permanently.
+You are asked to confirm unless --yes is given or behavior.confirm_delete is
+disabled in config.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: c.taskIDCompletion(nil),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			taskID := args[0]
+			ctx := cmd.Context()
+
+			if !yes && c.confirmDeletes() {
+				task, err := c.service.GetTask(ctx, taskID)
+				if err != nil {
+					return fmt.Errorf("failed to delete task: %w", err)
+				}
+
+				if !ui.IsTerminal(os.Stdin) {
+					return fmt.Errorf("refusing to delete without confirmation (use --yes)")
+				}
+				if !c.confirm(c.t("Delete task '%s'?", task.Title)) {
+					fmt.Println(c.t("Aborted."))
+					return nil
+				}
+			}
 
-			ctx := context.Background()
 			if err := c.service.DeleteTask(ctx, taskID); err != nil {
 				return fmt.Errorf("failed to delete task: %w", err)
 			}
 
-			fmt.Printf("✓ Task deleted successfully (ID: %s)\n", taskID)
+			fmt.Println(c.t("✓ Task deleted successfully (ID: %s)", taskID))
 
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+	cmd.Flags().BoolVar(&yes, "force", false, "Alias for --yes")
+
 	return cmd
 }
 
+// confirmDeletes reports whether destructive commands should ask for confirmation
+func (c *CLI) confirmDeletes() bool {
+	return c.config == nil || c.config.Behavior.ConfirmDelete
+}
+
 // updateCmd creates the update command
 func (c *CLI) updateCmd() *cobra.Command {
 	var title string
 	var description string
 	var priority string
+	var taskContext string
+	var assignee string
+	var location string
+	var estimate string
+	var points int
+	var due string
+	var sets []string
 
 	cmd := &cobra.Command{
-		Use:   "update [task-id]",
-		Short: "Update a task",
-		Long:  `Update the specified task's title, description, or priority.`,
-		Args:  cobra.ExactArgs(1),
+		Use:               "update [task-id]",
+		Short:             c.t("Update a task"),
+		Long:              `Update the specified task's title, description, priority, context, assignee, location, estimate, due date, or user-defined fields.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: c.taskIDCompletion(nil),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			taskID := args[0]
 
+			contextChanged := cmd.Flags().Changed("context")
+			assigneeChanged := cmd.Flags().Changed("assignee")
+			locationChanged := cmd.Flags().Changed("location")
+			effort, err := estimateOptions(cmd, estimate, points)
+			if err != nil {
+				return err
+			}
+			dueDate, err := dueOptions(cmd, due)
+			if err != nil {
+				return err
+			}
+			fields, err := c.fieldOptions(sets)
+			if err != nil {
+				return err
+			}
+
 			// At least one field must be provided
-			if title == "" && description == "" && priority == "" {
-				return fmt.Errorf("at least one field must be provided (--title, --description, or --priority)")
+			if title == "" && description == "" && priority == "" && !contextChanged && !assigneeChanged && !locationChanged && len(effort) == 0 && len(dueDate) == 0 && len(fields) == 0 {
+				return fmt.Errorf("at least one field must be provided (--title, --description, --priority, --context, --assignee, --location, --estimate, --points, --due, or --set)")
 			}
 
 			// Parse priority if provided
@@ -307,17 +942,26 @@ func (c *CLI) updateCmd() *cobra.Command {
 				}
 			}
 
+			opts := append(append(effort, dueDate...), fields...)
+			if contextChanged {
+				opts = append(opts, domain.WithTaskContext(taskContext))
+			}
+			if assigneeChanged {
+				opts = append(opts, domain.WithAssignee(assignee))
+			}
+			if locationChanged {
+				opts = append(opts, domain.WithLocation(location))
+			}
+
 			// Update task
-			ctx := context.Background()
-			task, err := c.service.UpdateTask(ctx, taskID, title, description, taskPriority)
+			ctx := cmd.Context()
+			task, err := c.service.UpdateTask(ctx, taskID, title, description, taskPriority, opts...)
 			if err != nil {
 				return fmt.Errorf("failed to update task: %w", err)
 			}
 
-			fmt.Printf("✓ Task updated successfully\n")
-			fmt.Printf("  ID:       %s\n", task.ID)
-			fmt.Printf("  Title:    %s\n", task.Title)
-			fmt.Printf("  Priority: %s\n", task.Priority)
+			fmt.Println(c.t("✓ Task updated successfully"))
+			printDetails(c.taskSummary(task)[:3])
 
 			return nil
 		},
@@ -326,6 +970,43 @@ func (c *CLI) updateCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&title, "title", "t", "", "New task title")
 	cmd.Flags().StringVarP(&description, "description", "d", "", "New task description")
 	cmd.Flags().StringVarP(&priority, "priority", "p", "", "New task priority (low, medium, high)")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "New task context (empty to clear)")
+	cmd.Flags().StringVar(&assignee, "assignee", "", "New assignee (empty to unassign)")
+	cmd.Flags().StringVar(&location, "location", "", "New location (empty for anywhere)")
+	addEstimateFlags(cmd, &estimate, &points)
+	addDueFlag(cmd, &due)
+	addSetFlag(cmd, &sets)
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+	_ = cmd.RegisterFlagCompletionFunc("assignee", c.assigneeCompletion)
+	_ = cmd.RegisterFlagCompletionFunc("priority", fixedCompletion(priorityValues...))
 
 	return cmd
 }
+
+// timeFormat returns how dates and times are displayed, from the display
+// section of the config file
+func (c *CLI) timeFormat() (ui.TimeFormat, error) {
+	if c.config == nil {
+		return ui.TimeFormat{}, nil
+	}
+	display := c.config.Display
+	times, err := ui.NewTimeFormat(display.DateFormat, display.TimeFormat, display.Timezone)
+	if err != nil {
+		return times, &configError{fmt.Errorf("invalid display.timezone: %w", err)}
+	}
+	return times, nil
+}
+
+// painter returns a color painter honoring --no-color, NO_COLOR, and the configured theme
+func (c *CLI) painter() (*ui.Painter, error) {
+	var theme map[string]string
+	if c.config != nil {
+		theme = c.config.Display.Theme
+	}
+
+	painter, err := ui.NewPainter(ui.ColorEnabled(c.noColor, c.terminal()), theme)
+	if err != nil {
+		return nil, fmt.Errorf("invalid display theme: %w", err)
+	}
+	return painter, nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// commentCmd creates the comment command
+func (c *CLI) commentCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "comment [task-id] [text]",
+		Short: "Comment on a task",
+		Long: `Add a comment to a task, attributed to the current user (user.name in config
+or TASK_USER). Unlike the description, comments form a thread that everyone
+sharing the database sees in "task get". Viewers cannot comment.`,
+		Example:           `  task comment 3f2a1b9c "Blocked on the API review"`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: c.taskIDCompletion(nil),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			author, err := c.currentUser()
+			if err != nil {
+				return err
+			}
+
+			taskID, err := c.resolveTaskID(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to comment on task: %w", err)
+			}
+
+			comment := &domain.Comment{TaskID: taskID, Author: author, Body: strings.TrimSpace(args[1])}
+			if err := c.service.AddComment(ctx, comment); err != nil {
+				return fmt.Errorf("failed to comment on task: %w", err)
+			}
+			if c.dryRun {
+				fmt.Printf("Would comment on task %s as %s\n", taskID[:8], author)
+				return nil
+			}
+
+			fmt.Println(c.t("✓ Commented on task %s as %s", taskID[:8], author))
+			return nil
+		},
+	}
+}
+
+// printComments prints the comment thread of a task for get, oldest first,
+// with when formatting the time of each comment
+func (c *CLI) printComments(ctx context.Context, painter *ui.Painter, task *domain.Task, when func(time.Time) string) error {
+	if c.storage == nil {
+		return nil
+	}
+	comments, err := c.service.ListComments(ctx, task.ID)
+	if err != nil || len(comments) == 0 {
+		return err
+	}
+
+	fmt.Printf("  %s\n", c.t("Comments:"))
+	for _, comment := range comments {
+		fmt.Printf("    %s, %s\n", painter.Paint(ui.RoleHeader, comment.Author), when(comment.CreatedAt))
+		for _, line := range strings.Split(comment.Body, "\n") {
+			fmt.Printf("      %s\n", line)
+		}
+	}
+	return nil
+}
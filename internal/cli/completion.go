@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/encryption"
+	"github.com/edson-mazvila/task-manager/internal/suggest"
+	"github.com/spf13/cobra"
+)
+
+// taskIDCompletion returns a completion function suggesting task IDs with their
+// titles as descriptions. If status is non-nil, only tasks with that status are offered.
+func (c *CLI) taskIDCompletion(status *domain.TaskStatus) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		cache, err := c.suggestions(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var completions []string
+		for _, task := range cache.Tasks {
+			if status != nil && task.Status != *status {
+				continue
+			}
+			if !strings.HasPrefix(task.ID, toComplete) {
+				continue
+			}
+			completions = append(completions, task.ID+"\t"+task.Title)
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// contextCompletion suggests the contexts already used by tasks
+func (c *CLI) contextCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cache, err := c.suggestions(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return cache.Contexts, cobra.ShellCompDirectiveNoFileComp
+}
+
+// assigneeCompletion suggests the users tasks are already assigned to
+func (c *CLI) assigneeCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cache, err := c.suggestions(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return cache.Assignees, cobra.ShellCompDirectiveNoFileComp
+}
+
+// suggestionStore returns the configured completion cache, falling back to the default location
+func (c *CLI) suggestionStore() (*suggest.Store, error) {
+	if c.suggest != nil {
+		return c.suggest, nil
+	}
+
+	path, err := suggest.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	c.suggest = suggest.NewStore(path)
+	return c.suggest, nil
+}
+
+// suggestions returns completion data, from the cache when it still matches
+// the database file and rebuilt from the database otherwise. Without SQLite
+// storage, or if the cache cannot be used, the database is queried directly.
+func (c *CLI) suggestions(ctx context.Context) (*suggest.Cache, error) {
+	if c.storage == nil {
+		return c.buildSuggestions(ctx, "")
+	}
+
+	// Never write the titles of an encrypted database to the cache file
+	if encrypted, err := encryption.Enabled(ctx, c.storage.DB()); err != nil || encrypted {
+		return c.buildSuggestions(ctx, "")
+	}
+
+	fingerprint, err := suggest.Fingerprint(c.storage.Path())
+	if err != nil {
+		return c.buildSuggestions(ctx, "")
+	}
+
+	store, err := c.suggestionStore()
+	if err != nil {
+		return c.buildSuggestions(ctx, "")
+	}
+
+	if cache, err := store.Load(); err == nil && cache.Fingerprint == fingerprint {
+		return cache, nil
+	}
+
+	cache, err := c.buildSuggestions(ctx, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Save(cache); err != nil {
+		c.logger.Debug("Failed to save completion cache", "error", err)
+	}
+
+	return cache, nil
+}
+
+// buildSuggestions reads completion data from the database
+func (c *CLI) buildSuggestions(ctx context.Context, fingerprint string) (*suggest.Cache, error) {
+	tasks, err := c.service.ListTasks(ctx, domain.TaskFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return suggest.Build(fingerprint, tasks), nil
+}
+
+// fixedCompletion returns a completion function offering a fixed set of values
+func fixedCompletion(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// priorityValues are the completions offered for --priority flags
+var priorityValues = []string{
+	string(domain.TaskPriorityLow),
+	string(domain.TaskPriorityMedium),
+	string(domain.TaskPriorityHigh),
+}
+
+// statusValues are the completions offered for --status flags
+var statusValues = []string{
+	string(domain.TaskStatusPending),
+	string(domain.TaskStatusCompleted),
+}
@@ -0,0 +1,540 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/secrets"
+	"github.com/edson-mazvila/task-manager/internal/state"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// configBundleVersion is the current configuration bundle format version
+const configBundleVersion = 1
+
+// configBundle is a portable snapshot of user configuration and CLI state
+// used to set up another machine. Secrets are never included.
+type configBundle struct {
+	Version    int            `yaml:"version"`
+	ExportedAt time.Time      `yaml:"exported_at"`
+	Config     *config.Config `yaml:"config"`
+	State      *state.State   `yaml:"state,omitempty"`
+}
+
+// configCmd creates the config command grouping configuration subcommands
+func (c *CLI) configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage configuration",
+		Long:  `Manage Task Manager configuration.`,
+	}
+
+	cmd.AddCommand(
+		c.configInitCmd(),
+		c.configShowCmd(),
+		c.configSetCmd(),
+		c.configExportCmd(),
+		c.configImportCmd(),
+		c.configSetSecretCmd(),
+		c.configTrustCmd(),
+		c.configUntrustCmd(),
+	)
+
+	return cmd
+}
+
+// configInitCmd creates the config init command
+func (c *CLI) configInitCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a commented default config file",
+		Long: `Write a config file listing every setting with its default value and the
+environment variable that overrides it. The file goes to CONFIG_FILE when set,
+otherwise to $XDG_CONFIG_HOME/task-manager/config.yaml (~/.config when unset).
+An existing file is only replaced with --force.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := os.Getenv("CONFIG_FILE")
+			if path == "" {
+				var err error
+				if path, err = config.UserFilePath(); err != nil {
+					return err
+				}
+			}
+
+			if c.dryRun {
+				fmt.Printf("Would write default configuration to %s\n", path)
+				return nil
+			}
+
+			if err := config.WriteDefaultFile(path, force); err != nil {
+				if !force {
+					if _, statErr := os.Stat(path); statErr == nil {
+						return fmt.Errorf("%w (use --force to replace it)", err)
+					}
+				}
+				return err
+			}
+
+			fmt.Println(c.t("✓ Configuration written to %s", path))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing config file")
+
+	return cmd
+}
+
+// configShowCmd creates the config show command
+func (c *CLI) configShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration",
+		Long: `Show the configuration in effect: defaults, merged with the config file, merged
+with the project file (.task.yaml) if any, merged with environment variables. Secrets are masked.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.config == nil {
+				return fmt.Errorf("configuration is not available")
+			}
+
+			data, err := yaml.Marshal(c.config.Redacted())
+			if err != nil {
+				return fmt.Errorf("failed to encode config: %w", err)
+			}
+
+			path := config.FilePath()
+			if _, err := os.Stat(path); err != nil {
+				path += " (not found, using defaults)"
+			}
+
+			fmt.Printf("# Config file: %s\n", path)
+			if project := c.config.ProjectFile(); project != "" {
+				fmt.Printf("# Project file: %s\n", project)
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+}
+
+// configSetCmd creates the config set command
+func (c *CLI) configSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting in the config file",
+		Long: `Change a setting in the config file, keeping its comments and other settings.
+Keys are dotted paths such as database.path or behavior.confirm_delete. The
+change is rejected if the resulting configuration does not load.`,
+		Example: `  task config set database.path ~/Dropbox/tasks.db
+  task config set logging.level debug
+  task config set behavior.timeout 30s`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return config.Keys(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			if !config.IsKey(key) {
+				return fmt.Errorf("unknown config key: %s (see \"task config show\")", key)
+			}
+
+			path := config.FilePath()
+			if c.dryRun {
+				fmt.Printf("Would set %s in %s\n", key, path)
+				return nil
+			}
+
+			previous, err := os.ReadFile(path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to read config file: %w", err)
+			}
+
+			// The file is checked on its own so environment overrides cannot hide
+			// a bad value, unless it already relies on the environment to be valid
+			check := config.CheckFile
+			if previous != nil && config.CheckFile(path) != nil {
+				check = func(string) error {
+					_, err := config.Load()
+					return err
+				}
+			}
+
+			if err := config.SetFileValue(path, key, value); err != nil {
+				return err
+			}
+
+			// Keep the old file if the new value makes the configuration invalid
+			if err := check(path); err != nil {
+				if previous != nil {
+					os.WriteFile(path, previous, 0600)
+				} else {
+					os.Remove(path)
+				}
+				return &configError{fmt.Errorf("%s not changed: %w", key, err)}
+			}
+
+			fmt.Println(c.t("✓ Set %s in %s", key, path))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// configExportCmd creates the config export command
+func (c *CLI) configExportCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export configuration to a portable bundle",
+		Long: `Export the effective configuration and CLI state (such as the active context)
+to a YAML bundle that can be imported on another machine. Secrets like the
+database password are excluded.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.config == nil {
+				return fmt.Errorf("configuration is not available")
+			}
+
+			exported := c.config.WithoutSecrets()
+
+			// Machine-specific default paths are left for the target machine to resolve
+			if path, err := config.DefaultDatabasePath(); err == nil && exported.Database.Path == path {
+				exported.Database.Path = ""
+			}
+
+			bundle := configBundle{
+				Version:    configBundleVersion,
+				ExportedAt: time.Now().UTC(),
+				Config:     exported,
+			}
+
+			if store, err := c.stateStore(); err == nil {
+				if st, err := store.Load(); err == nil && *st != (state.State{}) {
+					bundle.State = st
+				}
+			}
+
+			data, err := yaml.Marshal(bundle)
+			if err != nil {
+				return fmt.Errorf("failed to encode bundle: %w", err)
+			}
+
+			if file == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+
+			if err := os.WriteFile(file, data, 0600); err != nil {
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+
+			fmt.Println(c.t("✓ Configuration exported to %s", file))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write the bundle to a file instead of stdout")
+
+	return cmd
+}
+
+// configImportCmd creates the config import command
+func (c *CLI) configImportCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "import [bundle-file|-]",
+		Short: "Import configuration from a bundle",
+		Long: `Import a bundle created by "task config export", writing the config file
+and CLI state. Use "-" to read the bundle from stdin. An existing config file
+is only replaced with --force.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var data []byte
+			var err error
+			if args[0] == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read bundle: %w", err)
+			}
+
+			var bundle configBundle
+			if err := yaml.Unmarshal(data, &bundle); err != nil {
+				return fmt.Errorf("failed to parse bundle: %w", err)
+			}
+
+			switch {
+			case bundle.Version == 0 || bundle.Config == nil:
+				return fmt.Errorf("not a configuration bundle")
+			case bundle.Version > configBundleVersion:
+				return fmt.Errorf("unsupported bundle version %d (newest supported is %d)", bundle.Version, configBundleVersion)
+			}
+
+			// Validate a copy so defaults filled in by Validate are not persisted
+			check := *bundle.Config
+			if err := check.Validate(); err != nil {
+				return fmt.Errorf("bundle contains invalid configuration: %w", err)
+			}
+
+			configPath := config.FilePath()
+			if _, err := os.Stat(configPath); err == nil && !force {
+				return fmt.Errorf("%s already exists (use --force to replace it)", configPath)
+			} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to check config file: %w", err)
+			}
+
+			if c.dryRun {
+				fmt.Printf("Would write configuration to %s\n", configPath)
+				return nil
+			}
+
+			out, err := yaml.Marshal(bundle.Config)
+			if err != nil {
+				return fmt.Errorf("failed to encode config: %w", err)
+			}
+			if err := os.WriteFile(configPath, out, 0600); err != nil {
+				return fmt.Errorf("failed to write config file: %w", err)
+			}
+			fmt.Println(c.t("✓ Configuration written to %s", configPath))
+
+			if bundle.State != nil {
+				store, err := c.stateStore()
+				if err != nil {
+					return err
+				}
+				if err := store.Save(bundle.State); err != nil {
+					return err
+				}
+				fmt.Println(c.t("✓ State written to %s", store.Path()))
+			}
+
+			if bundle.Config.Database.Password == config.KeyringValue {
+				fmt.Printf("! Store the database password with \"task config set-secret %s\".\n", secrets.DBPassword)
+			} else if bundle.Config.Database.Type == "postgres" {
+				fmt.Println("! Secrets are not exported; set DB_PASSWORD or database.password on this machine.")
+			}
+			if bundle.Config.Database.Passphrase == config.KeyringValue {
+				fmt.Printf("! Store the database passphrase with \"task config set-secret %s\".\n", secrets.DBPassphrase)
+			}
+			if bundle.Config.Email.Password == config.KeyringValue {
+				fmt.Printf("! Store the SMTP password with \"task config set-secret %s\".\n", secrets.SMTPPassword)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing config file")
+
+	return cmd
+}
+
+// projectFileArg returns the project file named in args, or else the one
+// that applies in the working directory
+func projectFileArg(args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	path, err := config.FindProjectFile(wd)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("no %s in this directory or its parents", strings.Join(config.ProjectFileNames, " or "))
+	}
+	return path, nil
+}
+
+// configTrustCmd creates the config trust command
+func (c *CLI) configTrustCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "trust [project-file]",
+		Short: "Allow a project file to set any setting",
+		Long: `Allow a project file (.task.yaml or .taskrc) to set any setting, like
+"direnv allow". Until then a project file may only set the project section,
+database.path, database.archive, and the display date, time, timezone, and
+language settings, so that a cloned repository cannot send tasks to its
+webhooks or change where logs and email go. Review the file first. Editing
+it revokes the trust.
+
+A project file with other settings stops task from loading, so trust it with
+TASK_NO_PROJECT=1 set:
+
+  TASK_NO_PROJECT=1 task config trust`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := projectFileArg(args)
+			if err != nil {
+				return err
+			}
+			if c.dryRun {
+				fmt.Printf("Would trust %s\n", path)
+				return nil
+			}
+			if err := config.TrustProjectFile(path); err != nil {
+				return err
+			}
+			fmt.Println(c.t("✓ Trusted %s until it changes", path))
+			return nil
+		},
+	}
+}
+
+// configUntrustCmd creates the config untrust command
+func (c *CLI) configUntrustCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "untrust [project-file]",
+		Short: "Restrict a project file to the settings allowed without trust",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := projectFileArg(args)
+			if err != nil {
+				return err
+			}
+			if c.dryRun {
+				fmt.Printf("Would stop trusting %s\n", path)
+				return nil
+			}
+			if err := config.UntrustProjectFile(path); err != nil {
+				return err
+			}
+			fmt.Println(c.t("✓ No longer trusting %s", path))
+			return nil
+		},
+	}
+}
+
+// configSetSecretCmd creates the config set-secret command
+func (c *CLI) configSetSecretCmd() *cobra.Command {
+	var remove bool
+
+	cmd := &cobra.Command{
+		Use:   "set-secret <name>",
+		Short: "Store a secret in the OS keyring",
+		Long: `Store a secret in the OS keyring (macOS Keychain, Secret Service on Linux,
+Windows Credential Manager) instead of the config file or environment.
+The value is read from the terminal without echo, or from stdin when piped.
+
+Secrets:
+  db-password     PostgreSQL password, used when database.password is "keyring"
+  db-passphrase   encrypted database passphrase, used when database.passphrase is "keyring"
+  github-token    GitHub token for "task sync github" when --token and GITHUB_TOKEN are unset
+  todoist-token   Todoist API token for "task import --format todoist api"
+  smtp-password   SMTP password for "task digest", used when email.password is "keyring"`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: secrets.Names(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if remove {
+				if c.dryRun {
+					fmt.Printf("Would remove %s from the keyring\n", name)
+					return nil
+				}
+				if err := secrets.Delete(name); err != nil {
+					return err
+				}
+				fmt.Println(c.t("✓ Removed %s from the keyring", name))
+				return nil
+			}
+
+			value, err := c.readSecret(name)
+			if err != nil {
+				return err
+			}
+
+			if c.dryRun {
+				fmt.Printf("Would store %s in the keyring\n", name)
+				return nil
+			}
+			if err := secrets.Set(name, value); err != nil {
+				return err
+			}
+
+			fmt.Println(c.t("✓ Stored %s in the keyring", name))
+			if key := secretSetting(name); key != "" {
+				fmt.Printf("  Set %s: %s in %s to use it.\n", key, config.KeyringValue, config.FilePath())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&remove, "delete", false, "Remove the secret from the keyring instead")
+
+	return cmd
+}
+
+// readSecret reads a secret value from the terminal without echo, or from
+// stdin when it is not a terminal
+func (c *CLI) readSecret(name string) (string, error) {
+	if !ui.IsTerminal(os.Stdin) {
+		data, err := io.ReadAll(c.input())
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	fmt.Fprintf(os.Stdout, "Value for %s: ", name)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stdout)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	return string(data), nil
+}
+
+// apiToken returns the API token given with a --token flag, or else the one
+// in the env variable, or else the one stored in the keyring as secret
+func apiToken(flag, env, secret string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	if token := os.Getenv(env); token != "" {
+		return token, nil
+	}
+
+	token, err := secrets.Get(secret)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return "", &configError{fmt.Errorf("no API token (use --token, %s, or task config set-secret %s)", env, secret)}
+	}
+	return token, err
+}
+
+// secretSetting returns the config key that can refer to the secret called name
+func secretSetting(name string) string {
+	switch name {
+	case secrets.DBPassword:
+		return "database.password"
+	case secrets.DBPassphrase:
+		return "database.passphrase"
+	case secrets.SMTPPassword:
+		return "email.password"
+	default:
+		return ""
+	}
+}
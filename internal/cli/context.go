@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// contextCmd creates the context command for managing the active GTD context
+func (c *CLI) contextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage the active context",
+		Long: `Manage the active GTD context (e.g. home, office, errands).
+While a context is active, list only shows tasks in that context. A
+project file (.task.yaml) with project.context pins the context for its
+directory tree.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			active, err := c.activeContext()
+			if err != nil {
+				return err
+			}
+
+			if active == "" {
+				fmt.Println("No active context.")
+				return nil
+			}
+
+			if c.projectContext() != "" {
+				fmt.Printf("Active context: @%s (from %s)\n", active, c.config.ProjectFile())
+				return nil
+			}
+
+			fmt.Printf("Active context: @%s\n", active)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:               "set [context]",
+			Short:             "Set the active context",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: c.contextCompletion,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				name := domain.NormalizeContext(args[0])
+				if name == "" {
+					return fmt.Errorf("context name cannot be empty")
+				}
+
+				if err := c.saveActiveContext(name); err != nil {
+					return err
+				}
+
+				fmt.Println(c.t("✓ Active context set to @%s", name))
+				if project := c.projectContext(); project != "" {
+					fmt.Printf("Note: %s keeps @%s active in this directory\n", c.config.ProjectFile(), project)
+				}
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "clear",
+			Short: "Clear the active context",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if err := c.saveActiveContext(""); err != nil {
+					return err
+				}
+
+				fmt.Println(c.t("✓ Active context cleared"))
+				if project := c.projectContext(); project != "" {
+					fmt.Printf("Note: %s keeps @%s active in this directory\n", c.config.ProjectFile(), project)
+				}
+				return nil
+			},
+		},
+	)
+
+	return cmd
+}
+
+// stateStore returns the configured state store, falling back to the default location
+func (c *CLI) stateStore() (*state.Store, error) {
+	if c.state != nil {
+		return c.state, nil
+	}
+
+	path, err := state.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	c.state = state.NewStore(path)
+	return c.state, nil
+}
+
+// activeContext returns the context of the current project directory, or the
+// persisted active context, or an empty string if neither is set
+func (c *CLI) activeContext() (string, error) {
+	if name := c.projectContext(); name != "" {
+		return name, nil
+	}
+
+	store, err := c.stateStore()
+	if err != nil {
+		return "", err
+	}
+
+	st, err := store.Load()
+	if err != nil {
+		return "", err
+	}
+
+	return st.ActiveContext, nil
+}
+
+// projectContext returns the context set by a project file, if any
+func (c *CLI) projectContext() string {
+	if c.config == nil {
+		return ""
+	}
+	return domain.NormalizeContext(c.config.Project.Context)
+}
+
+// saveActiveContext persists the active context
+func (c *CLI) saveActiveContext(name string) error {
+	if c.dryRun {
+		return nil
+	}
+
+	store, err := c.stateStore()
+	if err != nil {
+		return err
+	}
+
+	st, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	st.ActiveContext = name
+	return store.Save(st)
+}
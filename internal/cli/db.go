@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/edson-mazvila/task-manager/internal/xdg"
+	"github.com/spf13/cobra"
+)
+
+// dbCmd creates the db command grouping database maintenance subcommands
+func (c *CLI) dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Database maintenance",
+		Long:  `Database maintenance commands.`,
+	}
+
+	cmd.AddCommand(c.dbRelocateCmd(), c.dbOptimizeCmd(), c.dbMigrateXDGCmd())
+
+	return cmd
+}
+
+// dbRelocateCmd creates the db relocate command
+func (c *CLI) dbRelocateCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "relocate [new-path]",
+		Short: "Move the SQLite database to a new location",
+		Long: `Move the SQLite database to a new location (e.g. a Dropbox or iCloud folder).
+The database is copied as a consistent snapshot, verified with an integrity check
+and row counts, and only then removed from the old location. The config file is
+updated to point at the new path.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("database relocation is only supported for SQLite storage")
+			}
+
+			src := c.storage.Path()
+			dst, err := filepath.Abs(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+
+			// Relocating into a directory keeps the current file name
+			if info, err := os.Stat(dst); err == nil && info.IsDir() {
+				dst = filepath.Join(dst, filepath.Base(src))
+			}
+
+			if absSrc, err := filepath.Abs(src); err == nil && absSrc == dst {
+				return fmt.Errorf("database is already at %s", dst)
+			}
+
+			fmt.Printf("Move database\n  from: %s\n  to:   %s\n", src, dst)
+			if c.dryRun {
+				return nil
+			}
+			if !yes && !c.confirm("Continue?") {
+				fmt.Println("Aborted.")
+				return nil
+			}
+
+			ctx := cmd.Context()
+			if err := c.storage.Relocate(ctx, dst); err != nil {
+				return fmt.Errorf("failed to relocate database: %w", err)
+			}
+			// Relocate closed the storage; nothing else may use it in this run
+			c.storage = nil
+
+			fmt.Println(c.t("✓ Database moved and verified"))
+
+			configPath := config.FilePath()
+			if err := config.SetFileValue(configPath, "database.path", dst); err != nil {
+				fmt.Printf("! Could not update %s: %v\n", configPath, err)
+				fmt.Printf("  Set database.path to %s manually.\n", dst)
+			} else {
+				fmt.Println(c.t("✓ Updated database.path in %s", configPath))
+			}
+
+			if os.Getenv("DB_PATH") != "" {
+				fmt.Printf("! DB_PATH is set in your environment and overrides the config file; update it to %s\n", dst)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// dbOptimizeCmd creates the db optimize command
+func (c *CLI) dbOptimizeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "optimize",
+		Short: "Refresh query statistics",
+		Long: `Run ANALYZE and PRAGMA optimize so SQLite keeps choosing good query plans
+as the database grows.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("database optimization is only supported for SQLite storage")
+			}
+
+			if c.dryRun {
+				fmt.Println("Would run ANALYZE and PRAGMA optimize")
+				return nil
+			}
+
+			elapsed, err := c.storage.Optimize(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to optimize database: %w", err)
+			}
+
+			fmt.Println(c.t("✓ Database optimized in %s", elapsed.Round(time.Millisecond)))
+			return nil
+		},
+	}
+}
+
+// dbMigrateXDGCmd creates the db migrate-xdg command
+func (c *CLI) dbMigrateXDGCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-xdg",
+		Short: "Move files from ~/.task-manager to the XDG directories",
+		Long: `Move files kept in ~/.task-manager by older versions to the XDG base
+directories: the database and archive to $XDG_DATA_HOME/task-manager, CLI state
+to $XDG_STATE_HOME/task-manager, and anything else (such as crash reports) to
+the data directory. The completion cache is dropped and rebuilt on demand.
+The database is moved with the same verified copy as "task db relocate".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			legacy, err := xdg.LegacyDir()
+			if err != nil {
+				return err
+			}
+			entries, err := os.ReadDir(legacy)
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Printf("Nothing to migrate: %s does not exist\n", legacy)
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", legacy, err)
+			}
+
+			dataDir, err := xdg.DataDir()
+			if err != nil {
+				return err
+			}
+			stateDir, err := xdg.StateDir()
+			if err != nil {
+				return err
+			}
+
+			// The open database is moved by the storage, everything else by rename
+			var dbPath string
+			if c.storage != nil {
+				if abs, err := filepath.Abs(c.storage.Path()); err == nil && filepath.Dir(abs) == legacy {
+					dbPath = abs
+				}
+			}
+
+			type move struct{ from, to string }
+			var moves []move
+			for _, entry := range entries {
+				from := filepath.Join(legacy, entry.Name())
+				switch {
+				case from == dbPath || isSidecar(dbPath, from):
+					continue
+				case entry.Name() == "completion.json":
+					moves = append(moves, move{from, ""})
+				case entry.Name() == "state.json":
+					moves = append(moves, move{from, filepath.Join(stateDir, entry.Name())})
+				default:
+					moves = append(moves, move{from, filepath.Join(dataDir, entry.Name())})
+				}
+			}
+
+			fmt.Printf("Migrate %s\n", legacy)
+			if dbPath != "" {
+				fmt.Printf("  %s -> %s\n", filepath.Base(dbPath), filepath.Join(dataDir, filepath.Base(dbPath)))
+			}
+			for _, m := range moves {
+				if m.to == "" {
+					fmt.Printf("  %s (removed, rebuilt on demand)\n", filepath.Base(m.from))
+				} else {
+					fmt.Printf("  %s -> %s\n", filepath.Base(m.from), m.to)
+				}
+			}
+			if c.dryRun {
+				return nil
+			}
+			if !yes && !c.confirm("Continue?") {
+				fmt.Println("Aborted.")
+				return nil
+			}
+
+			if dbPath != "" {
+				dst := filepath.Join(dataDir, filepath.Base(dbPath))
+				if err := c.storage.Relocate(cmd.Context(), dst); err != nil {
+					return fmt.Errorf("failed to move database: %w", err)
+				}
+				c.storage = nil
+				fmt.Println(c.t("✓ Database moved and verified"))
+				c.updateDatabasePath(dbPath, dst)
+			}
+
+			for _, m := range moves {
+				if m.to == "" {
+					err = os.RemoveAll(m.from)
+				} else {
+					err = moveFile(m.from, m.to)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to migrate %s: %w", filepath.Base(m.from), err)
+				}
+			}
+
+			if err := os.Remove(legacy); err != nil {
+				fmt.Printf("! %s was not removed: %v\n", legacy, err)
+			} else {
+				fmt.Println(c.t("✓ Removed %s", legacy))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// updateDatabasePath points database.path in the config file at dst if it
+// named the old location explicitly; a default path needs no change
+func (c *CLI) updateDatabasePath(src, dst string) {
+	configPath := config.FilePath()
+	value, err := config.FileValue(configPath, "database.path")
+	if err != nil {
+		fmt.Printf("! Could not read %s: %v\n", configPath, err)
+		return
+	}
+
+	if abs, err := filepath.Abs(value); value != "" && err == nil && abs == src {
+		if err := config.SetFileValue(configPath, "database.path", dst); err != nil {
+			fmt.Printf("! Could not update %s: %v\n", configPath, err)
+			fmt.Printf("  Set database.path to %s manually.\n", dst)
+		} else {
+			fmt.Println(c.t("✓ Updated database.path in %s", configPath))
+		}
+	}
+
+	if os.Getenv("DB_PATH") != "" {
+		fmt.Printf("! DB_PATH is set in your environment and overrides the config file; update it to %s\n", dst)
+	}
+}
+
+// legacyHint tells the user about an unmigrated ~/.task-manager directory.
+// It is only shown on an interactive terminal so scripts see clean output.
+func (c *CLI) legacyHint(cmd *cobra.Command) {
+	if cmd.Name() == "migrate-xdg" || strings.HasPrefix(cmd.Name(), cobra.ShellCompRequestCmd) || !ui.IsTerminal(os.Stderr) {
+		return
+	}
+	if legacy, err := xdg.LegacyDir(); err == nil {
+		if _, err := os.Stat(legacy); err == nil {
+			fmt.Fprintf(os.Stderr, "! Found %s from an older version; run \"task db migrate-xdg\" to move it to the XDG directories.\n", legacy)
+		}
+	}
+}
+
+// moveFile renames from to to, creating the destination directory and
+// copying across file systems when a rename is not possible
+func moveFile(from, to string) error {
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("%s already exists", to)
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(to, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+// isSidecar reports whether path is a SQLite sidecar file (-wal, -shm,
+// -journal) of the database at dbPath
+func isSidecar(dbPath, path string) bool {
+	return dbPath != "" && strings.HasPrefix(path, dbPath+"-")
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// delegateCmd creates the delegate command
+func (c *CLI) delegateCmd() *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "delegate [task-id] --to [user]",
+		Short: "Hand a task over to another user",
+		Long: `Assign a task to another registered user on behalf of the current user
+(user.name in config or TASK_USER). The hand-over is recorded in the comment
+thread of the task, and a task.delegated event naming who delegated it is
+published, so webhooks can notify the new assignee.`,
+		Example:           `  task delegate 3f2a1b9c --to bob`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: c.taskIDCompletion(nil),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			taskID, err := c.resolveTaskID(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to delegate task: %w", err)
+			}
+			me, _ := c.currentUser()
+
+			var task *domain.Task
+			err = c.service.WithTx(ctx, func(ctx context.Context) error {
+				before, err := c.service.GetTask(ctx, taskID)
+				if err != nil {
+					return err
+				}
+				if task, err = c.service.DelegateTask(ctx, taskID, to, me); err != nil {
+					return err
+				}
+				return c.recordDelegation(ctx, task, before.Assignee, me)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to delegate task: %w", err)
+			}
+
+			if c.dryRun {
+				fmt.Printf("Would delegate task %s to %s\n", task.ID[:8], task.Assignee)
+				return nil
+			}
+			fmt.Println(c.t("✓ Task %s delegated to %s", task.ID[:8], task.Assignee))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "User to hand the task over to")
+	_ = cmd.MarkFlagRequired("to")
+	_ = cmd.RegisterFlagCompletionFunc("to", c.assigneeCompletion)
+
+	return cmd
+}
+
+// recordDelegation adds a comment by the delegating user noting who a task
+// was handed over to. Nothing is recorded without a current user to
+// attribute it to, or in a dry run.
+func (c *CLI) recordDelegation(ctx context.Context, task *domain.Task, from, by string) error {
+	if by == "" || c.dryRun || c.storage == nil {
+		return nil
+	}
+
+	body := "Delegated to " + task.Assignee
+	if from != "" {
+		body += " (was " + from + ")"
+	}
+	return c.service.AddComment(ctx, &domain.Comment{TaskID: task.ID, Author: by, Body: body})
+}
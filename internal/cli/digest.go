@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/digest"
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// digestCmd creates the digest command
+func (c *CLI) digestCmd() *cobra.Command {
+	var email, taskContext string
+	var since time.Duration
+	var skipEmpty, html bool
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Summarize overdue, due-today, and recently completed tasks",
+		Long: `Summarize pending tasks that are overdue or due today, and tasks completed in
+the last --since (24h by default). Due dates are set with --due, or come from
+integrations such as todoist.due or google.due in the task metadata.
+
+With --email (or email.to in the config file) the digest is sent as an email
+with a plain-text and an HTML body, through the SMTP server set under email in
+the config file. Otherwise it is printed. Designed to be run from cron, e.g.
+
+  0 7 * * *  task digest --email me@example.com --skip-empty
+
+Use --dry-run to print the email instead of sending it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			filter := domain.TaskFilter{}
+			if taskContext != "" {
+				name := domain.NormalizeContext(taskContext)
+				filter.Context = &name
+			}
+
+			d, err := digest.Build(ctx, c.service, filter, time.Now(), since)
+			if err != nil {
+				return err
+			}
+			if skipEmpty && d.Empty() {
+				return nil
+			}
+
+			if email == "" && c.config != nil {
+				email = c.config.Email.To
+			}
+			if email == "" {
+				if html {
+					out, err := d.HTML()
+					if err != nil {
+						return err
+					}
+					fmt.Print(out)
+					return nil
+				}
+				fmt.Print(d.Text())
+				return nil
+			}
+
+			mailer, err := c.mailer()
+			if err != nil {
+				return err
+			}
+			to, err := digest.ParseAddresses(email)
+			if err != nil {
+				return err
+			}
+			msg, err := d.Message(mailer.From, to)
+			if err != nil {
+				return err
+			}
+
+			if c.dryRun {
+				fmt.Printf("Would send via %s:%d:\n\n%s", mailer.Host, mailer.Port, msg)
+				return nil
+			}
+			if err := mailer.Send(ctx, to, msg); err != nil {
+				return fmt.Errorf("failed to send digest: %w", err)
+			}
+
+			fmt.Println(c.t("✓ Digest sent to %s", email))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "email", "", "Send the digest to these addresses, comma separated (default email.to)")
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "Include tasks completed within this long")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only include tasks in this context")
+	cmd.Flags().BoolVar(&skipEmpty, "skip-empty", false, "Print or send nothing when there is nothing to report")
+	cmd.Flags().BoolVar(&html, "html", false, "Print the HTML body instead of plain text")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+
+	return cmd
+}
+
+// mailer returns a mailer for the SMTP server in the config file
+func (c *CLI) mailer() (*digest.Mailer, error) {
+	if c.config == nil || c.config.Email.Host == "" {
+		return nil, &configError{fmt.Errorf("no SMTP server configured (set email.host in the config file)")}
+	}
+
+	settings := c.config.Email
+	from := settings.From
+	if from == "" {
+		from = settings.Username
+	}
+	if from == "" {
+		return nil, &configError{fmt.Errorf("no sender address configured (set email.from in the config file)")}
+	}
+
+	return &digest.Mailer{
+		Host:     settings.Host,
+		Port:     settings.Port,
+		Username: settings.Username,
+		Password: settings.Password,
+		From:     from,
+	}, nil
+}
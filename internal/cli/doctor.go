@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd creates the doctor command
+func (c *CLI) doctorCmd() *cobra.Command {
+	var full bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the configuration and database for problems",
+		Long: `Check that the configuration is valid, the database can be reached, its
+schema is up to date and its contents are intact, the database files are
+writable and private, and there is enough free disk space. Problems come
+with a suggested fix.
+
+The integrity check uses SQLite's quick_check; --full runs the slower
+integrity_check, which also verifies the indexes. The exit status is non-zero
+if any check fails; warnings do not affect it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checks := doctor.Run(cmd.Context(), doctor.Options{
+				ConfigFile: config.FilePath(),
+				Config:     c.config,
+				Storage:    c.storage,
+				Full:       full,
+			})
+
+			width := 0
+			for _, check := range checks {
+				width = max(width, len(check.Name))
+			}
+
+			failed := 0
+			for _, check := range checks {
+				mark := "✓"
+				switch check.Status {
+				case doctor.Warn:
+					mark = "!"
+				case doctor.Fail:
+					mark = "✗"
+					failed++
+				}
+				fmt.Printf("%s %-*s  %s\n", mark, width, check.Name, check.Detail)
+				if check.Fix != "" {
+					fmt.Printf("  %-*s  → %s\n", width, "", check.Fix)
+				}
+			}
+
+			if c.storage == nil {
+				fmt.Println("\nDatabase checks are only supported for SQLite storage.")
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d check(s) failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&full, "full", false, "Run the full integrity check, including indexes")
+
+	return cmd
+}
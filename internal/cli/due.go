@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// addDueFlag registers --due
+func addDueFlag(cmd *cobra.Command, due *string) {
+	cmd.Flags().StringVar(due, "due", "", "Day the task is due: YYYY-MM-DD, today, tomorrow, or e.g. 3d or 2w from today (empty to clear)")
+}
+
+// dueOptions returns the task option for --due, if it was given on the
+// command line
+func dueOptions(cmd *cobra.Command, due string) ([]domain.TaskOption, error) {
+	if !cmd.Flags().Changed("due") {
+		return nil, nil
+	}
+	day, err := parseDueDate(due, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return []domain.TaskOption{domain.WithDueDate(day)}, nil
+}
+
+// parseDueDate parses a due date: a YYYY-MM-DD date, "today", "tomorrow",
+// or a number of days or weeks from today such as 3d or +2w. An empty
+// string gives nil, which clears the due date.
+func parseDueDate(s string, now time.Time) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if len(s) > 1 && s[0] == '+' {
+		s = s[1:]
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	days := -1
+	switch strings.ToLower(s) {
+	case "today":
+		days = 0
+	case "tomorrow":
+		days = 1
+	default:
+		if day, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+			return &day, nil
+		}
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+			switch s[len(s)-1] {
+			case 'd':
+				days = n
+			case 'w':
+				days = 7 * n
+			}
+		}
+	}
+	if days < 0 {
+		return nil, fmt.Errorf("invalid due date: %s (use e.g. 2026-11-01, today, tomorrow, 3d, or 2w)", s)
+	}
+
+	due := today.AddDate(0, 0, days)
+	return &due, nil
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edson-mazvila/task-manager/internal/encryption"
+	"github.com/spf13/cobra"
+)
+
+// encryptCmd creates the encrypt command
+func (c *CLI) encryptCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt task titles, descriptions, and comments in the database",
+		Long: `Encrypt the title and description of every task, and the comments on it,
+with a key derived from the passphrase in database.passphrase or
+TASK_PASSPHRASE (which may be "keyring", see "task config set-secret"). Once
+encrypted, the same passphrase is required to open the database; there is no
+way to recover the tasks without it.
+
+Filters and queries on title or description do not match encrypted tasks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("encryption is only supported for SQLite storage")
+			}
+
+			passphrase, err := c.passphrase()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Encrypt all tasks in %s\n", c.storage.Path())
+			if c.dryRun {
+				return nil
+			}
+			if !yes && !c.confirm("Continue? Keep the passphrase safe, it cannot be recovered") {
+				fmt.Println("Aborted.")
+				return nil
+			}
+
+			n, err := encryption.EncryptDatabase(cmd.Context(), c.storage.DB(), passphrase)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt database: %w", err)
+			}
+
+			// The completion cache holds task titles in plain text
+			if store, err := c.suggestionStore(); err == nil {
+				if err := store.Clear(); err != nil {
+					c.logger.Debug("Failed to clear completion cache", "error", err)
+				}
+			}
+
+			fmt.Println(c.t("✓ Encrypted %d task(s)", n))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// decryptCmd creates the decrypt command
+func (c *CLI) decryptCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "decrypt",
+		Short: "Decrypt the database back to plain text",
+		Long: `Decrypt every task with the passphrase in database.passphrase or
+TASK_PASSPHRASE and remove the encryption settings, leaving a plain database
+that opens without a passphrase.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("encryption is only supported for SQLite storage")
+			}
+
+			passphrase, err := c.passphrase()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Decrypt all tasks in %s\n", c.storage.Path())
+			if c.dryRun {
+				return nil
+			}
+			if !yes && !c.confirm("Continue?") {
+				fmt.Println("Aborted.")
+				return nil
+			}
+
+			n, err := encryption.DecryptDatabase(cmd.Context(), c.storage.DB(), passphrase)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt database: %w", err)
+			}
+
+			fmt.Println(c.t("✓ Decrypted %d task(s)", n))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// passphrase returns the configured database passphrase
+func (c *CLI) passphrase() (string, error) {
+	if c.config == nil || c.config.Database.Passphrase == "" {
+		return "", &configError{errors.New("no passphrase configured (set database.passphrase in config or TASK_PASSPHRASE; use \"keyring\" with task config set-secret db-passphrase)")}
+	}
+	return c.config.Database.Passphrase, nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/encryption"
+	"github.com/edson-mazvila/task-manager/internal/integrations/tasksync"
+	"github.com/edson-mazvila/task-manager/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Process exit codes, so that scripts can tell failures apart
+const (
+	ExitOK          = 0
+	ExitError       = 1 // any other failure, including invalid usage
+	ExitNotFound    = 2
+	ExitValidation  = 3
+	ExitConfig      = 4
+	ExitStorage     = 5
+	ExitTimeout     = 6
+	ExitForbidden   = 7   // the user's role does not allow the change
+	ExitInterrupted = 130 // 128 + SIGINT, as shells report Ctrl-C
+)
+
+// configError marks failures caused by missing or invalid configuration
+type configError struct {
+	err error
+}
+
+// Error implements the error interface
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *configError) Unwrap() error {
+	return e.err
+}
+
+// errorReport is the JSON form of a command error written with --output json
+type errorReport struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// classify returns the exit code and machine-readable category of err
+func classify(err error) (int, string) {
+	var cfgErr *configError
+	var pluginErr *pluginError
+
+	switch {
+	case err == nil:
+		return ExitOK, ""
+	case errors.As(err, &pluginErr):
+		return pluginErr.code, "plugin"
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout, "timeout"
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted, "interrupted"
+	case errors.Is(err, domain.ErrTaskNotFound),
+		errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrExternalRefNotFound),
+		errors.Is(err, domain.ErrLinkNotFound):
+		return ExitNotFound, "not_found"
+	case errors.Is(err, domain.ErrValidation),
+		errors.Is(err, domain.ErrInvalidTaskID),
+		errors.Is(err, domain.ErrDuplicateUser):
+		return ExitValidation, "validation"
+	case errors.Is(err, domain.ErrForbidden):
+		return ExitForbidden, "forbidden"
+	case errors.As(err, &cfgErr),
+		errors.Is(err, encryption.ErrPassphraseRequired),
+		errors.Is(err, encryption.ErrWrongPassphrase),
+		errors.Is(err, tasksync.ErrNotAuthorized):
+		return ExitConfig, "config"
+	case storage.IsDatabaseError(err):
+		return ExitStorage, "storage"
+	default:
+		return ExitError, "error"
+	}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	code, _ := classify(err)
+	return code
+}
+
+// jsonOutput reports whether cmd was invoked with --output json
+func jsonOutput(cmd *cobra.Command) bool {
+	if cmd == nil {
+		return false
+	}
+	flag := cmd.Flags().Lookup("output")
+	return flag != nil && flag.Value.String() == "json"
+}
+
+// writeError reports a command error on w, as JSON when requested
+func writeError(w io.Writer, cmd *cobra.Command, err error) {
+	if !jsonOutput(cmd) {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	_, category := classify(err)
+	report := errorReport{
+		Code:    category,
+		Message: err.Error(),
+	}
+
+	var violation *domain.RuleViolation
+	if errors.As(err, &violation) {
+		report.Details = map[string]string{"rule": violation.Rule}
+	}
+
+	data, _ := json.Marshal(report)
+	fmt.Fprintln(w, string(data))
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/escalation"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// escalationsCmd creates the escalations command
+func (c *CLI) escalationsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "escalations",
+		Short: "Evaluate the escalations of the config file",
+		Long: `Escalations, declared under escalations in the config file, say who to tell
+about pending tasks that stay overdue, e.g. high-priority tasks more than 48
+hours past their due day. Due dates are set with --due, or come from
+integrations such as todoist.due in the task metadata; snoozed tasks are
+left out.
+
+"task escalations test" shows what would be sent; "task escalations run"
+sends it. Every run that matches notifies again, so run it from cron as often
+as people should be reminded, e.g.
+
+  0 9 * * 1-5  task escalations run`,
+	}
+
+	cmd.AddCommand(c.escalationsTestCmd(), c.escalationsRunCmd())
+
+	return cmd
+}
+
+// escalationsTestCmd creates the escalations test command
+func (c *CLI) escalationsTestCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "test",
+		Short: "Show the tasks each escalation applies to, without notifying anyone",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			matches, err := c.evaluateEscalations(cmd.Context(), time.Now())
+			if err != nil || len(matches) == 0 {
+				return err
+			}
+
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+			for i, m := range matches {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("%s → %s\n", painter.Paint(ui.RoleHeader, m.Rule.Name), recipients(m.Rule))
+				table := ui.NewTable(painter, "ID", "DUE", "PRIORITY", "ASSIGNEE", "TITLE")
+				for _, task := range m.Tasks {
+					due, _ := task.DueDate()
+					table.AddRow("",
+						ui.Cell{Text: task.ID[:8], Role: ui.RoleID},
+						ui.Cell{Text: due.Format(time.DateOnly), Role: ui.RoleOverdue},
+						ui.Cell{Text: string(task.Priority), Role: ui.PriorityRole(task.Priority)},
+						ui.Cell{Text: task.Assignee},
+						ui.Cell{Text: task.Title},
+					)
+				}
+				if err := table.Render(os.Stdout); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// escalationsRunCmd creates the escalations run command
+func (c *CLI) escalationsRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Notify the recipients of every escalation that applies",
+		Long: `Send each escalation that applies to some tasks to its webhook and email
+recipients. Webhooks receive a JSON POST whose "text" chat services such as
+Slack show; emails go through the SMTP server set under email in the config
+file. Escalations with desktop set are also shown as set by
+notifications.backend. With --dry-run the messages are printed instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			matches, err := c.evaluateEscalations(ctx, time.Now())
+			if err != nil || len(matches) == 0 {
+				return err
+			}
+
+			notifier := &escalation.Notifier{}
+			for _, m := range matches {
+				if len(m.Rule.Email) > 0 && notifier.Mailer == nil {
+					if notifier.Mailer, err = c.mailer(); err != nil {
+						return err
+					}
+				}
+				if m.Rule.Desktop && notifier.Desktop == nil {
+					if notifier.Desktop, err = c.notifier(); err != nil {
+						return err
+					}
+				}
+			}
+
+			for _, m := range matches {
+				if c.dryRun {
+					fmt.Printf("Would notify %s:\n%s\n", recipients(m.Rule), m.Text())
+					continue
+				}
+				if err := notifier.Notify(ctx, m); err != nil {
+					return err
+				}
+				fmt.Println(c.t("✓ %s: notified %s of %d task(s)", m.Rule.Name, recipients(m.Rule), len(m.Tasks)))
+			}
+			return nil
+		},
+	}
+}
+
+// evaluateEscalations matches the escalations of the config file against
+// the pending tasks that are not snoozed, reporting when there is nothing
+// to escalate
+func (c *CLI) evaluateEscalations(ctx context.Context, now time.Time) ([]escalation.Match, error) {
+	if c.config == nil || len(c.config.Escalations) == 0 {
+		return nil, &configError{fmt.Errorf("no escalations configured (add them under escalations in the config file)")}
+	}
+	rules, err := escalation.FromConfig(c.config.Escalations)
+	if err != nil {
+		return nil, &configError{err}
+	}
+
+	pending := domain.TaskStatusPending
+	tasks, err := c.service.ListTasks(ctx, domain.TaskFilter{Status: &pending, AwakeAt: &now})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	matches := escalation.Evaluate(rules, tasks, now)
+	if len(matches) == 0 {
+		fmt.Println("No tasks to escalate")
+	}
+	return matches, nil
+}
+
+// recipients describes where an escalation is sent
+func recipients(rule escalation.Rule) string {
+	var to []string
+	if rule.Webhook != "" {
+		to = append(to, rule.Webhook)
+	}
+	if len(rule.Email) > 0 {
+		to = append(to, strings.Join(rule.Email, ", "))
+	}
+	if rule.Desktop {
+		to = append(to, "the desktop")
+	}
+	return strings.Join(to, " and ")
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// addEstimateFlags registers --estimate and --points
+func addEstimateFlags(cmd *cobra.Command, estimate *string, points *int) {
+	cmd.Flags().StringVar(estimate, "estimate", "", "Expected effort as a duration, e.g. 90m, 2h, 1d (0 to clear)")
+	cmd.Flags().IntVar(points, "points", 0, "Expected effort in points (0 to clear)")
+}
+
+// estimateOptions returns the task options for the estimate flags that were
+// given on the command line
+func estimateOptions(cmd *cobra.Command, estimate string, points int) ([]domain.TaskOption, error) {
+	var opts []domain.TaskOption
+	if cmd.Flags().Changed("estimate") {
+		d, err := parseAge(estimate)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid estimate: %s (use e.g. 90m, 2h, 1d)", estimate)
+		}
+		opts = append(opts, domain.WithEstimate(d))
+	}
+	if cmd.Flags().Changed("points") {
+		if points < 0 {
+			return nil, fmt.Errorf("invalid points: %d (cannot be negative)", points)
+		}
+		opts = append(opts, domain.WithPoints(points))
+	}
+	return opts, nil
+}
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/export"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd creates the export command grouping task export formats
+func (c *CLI) exportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export tasks",
+		Long: `Export all tasks for backup or verification, or to plain-text formats for
+long-term archives.`,
+	}
+
+	cmd.AddCommand(
+		c.exportCanonicalCmd(),
+		c.exportTextCmd("org", "Export tasks as an Org-mode file",
+			`Export every task as an Org-mode heading: TODO or DONE, a priority cookie
+([#A] high, [#B] medium, [#C] low), the context as a tag, the completion time
+as CLOSED, and the task ID and creation time as properties. The description is
+the heading body. "task import --format org" reads the file back.`,
+			export.WriteOrgTask),
+		c.exportTextCmd("todotxt", "Export tasks as a todo.txt file",
+			`Export every task as a todo.txt line: "x" and the completion date for
+completed tasks, the priority ((A) high, (B) medium, (C) low), the creation
+date, the context as @context, and the task ID as an id: tag. Descriptions are
+not exported. "task import --format todotxt" reads the file back.`,
+			export.WriteTodoTxtTask),
+		c.exportObsidianCmd(),
+		c.exportSchemaCmd(),
+	)
+
+	return cmd
+}
+
+// exportCanonicalCmd creates the export canonical command
+func (c *CLI) exportCanonicalCmd() *cobra.Command {
+	var file string
+	var hash bool
+
+	cmd := &cobra.Command{
+		Use:   "canonical",
+		Short: "Export tasks in a deterministic form for diffing",
+		Long: `Export every task as JSON Lines sorted by ID, with stable key order and UTC
+timestamps. Two databases holding the same tasks produce byte-identical output,
+so exports can be diffed or hashed to verify a sync or restore.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if hash {
+				h := sha256.New()
+				n, err := c.writeCanonical(ctx, h)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("sha256:%s  %d task(s)\n", hex.EncodeToString(h.Sum(nil)), n)
+				return nil
+			}
+
+			return c.writeExport(file, func(w io.Writer) (int, error) {
+				return c.writeCanonical(ctx, w)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write the export to a file instead of stdout")
+	cmd.Flags().BoolVar(&hash, "hash", false, "Print only the SHA-256 digest of the export")
+
+	return cmd
+}
+
+// exportSchemaCmd creates the export schema command
+func (c *CLI) exportSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of canonical exports",
+		Long: fmt.Sprintf(`Print the JSON Schema that every line of "task export canonical" follows,
+for tools that read exports. Lines carry the schema_version they follow,
+currently %d; "task import --format canonical" checks files against it.`, export.SchemaVersion),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := os.Stdout.Write(export.Schema)
+			return err
+		},
+	}
+}
+
+// exportTextCmd creates an export command for a plain-text format that
+// writes one task at a time, oldest first
+func (c *CLI) exportTextCmd(format, short, long string, writeTask func(io.Writer, *domain.Task) error) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   format,
+		Short: short,
+		Long:  long,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			return c.writeExport(file, func(w io.Writer) (int, error) {
+				tasks, err := c.service.ListTasks(ctx, domain.TaskFilter{})
+				if err != nil {
+					return 0, err
+				}
+				sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) })
+
+				for _, task := range tasks {
+					if err := writeTask(w, task); err != nil {
+						return 0, err
+					}
+				}
+				return len(tasks), nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write the export to a file instead of stdout")
+
+	return cmd
+}
+
+// exportObsidianCmd creates the export obsidian command
+func (c *CLI) exportObsidianCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "obsidian",
+		Short: "Export tasks as Markdown notes for an Obsidian vault",
+		Long: `Write one Markdown note per task into a directory, such as a folder of an
+Obsidian vault. Each note is named after the task title and starts with YAML
+front matter for Dataview queries:
+
+  task_id, status, priority, context, assignee, created, completed, due, tags
+
+The due date and tags come from the task metadata (e.g. due, todoist.due,
+trello.labels); the context is also a tag. Run the export again to update the notes: only
+changed notes are rewritten (edits made in the vault are overwritten), and notes
+of deleted or renamed tasks are removed. Other files in the directory are left
+alone.
+
+Example Dataview query:
+
+  TABLE priority, due FROM "tasks" WHERE status = "pending" SORT due`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tasks, err := c.service.ListTasks(cmd.Context(), domain.TaskFilter{})
+			if err != nil {
+				return err
+			}
+
+			result, err := export.WriteObsidianVault(dir, tasks)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(c.t("✓ Exported %d task(s) to %s (%d written, %d unchanged, %d removed)",
+				len(tasks), dir, result.Written, result.Unchanged, result.Removed))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "", "Directory to write the notes to, e.g. ~/vault/tasks")
+	_ = cmd.MarkFlagRequired("dir")
+	_ = cmd.MarkFlagDirname("dir")
+
+	return cmd
+}
+
+// writeExport runs write against stdout, or against file when set. Files are
+// written next to the destination and renamed, so a failed export never
+// leaves a truncated file behind.
+func (c *CLI) writeExport(file string, write func(io.Writer) (int, error)) error {
+	if file == "" {
+		out := bufio.NewWriter(os.Stdout)
+		if _, err := write(out); err != nil {
+			return err
+		}
+		return out.Flush()
+	}
+
+	tmp := file + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	out := bufio.NewWriter(f)
+	n, err := write(out)
+	if err == nil {
+		err = out.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	fmt.Println(c.t("✓ Exported %d task(s) to %s", n, file))
+	return nil
+}
+
+// writeCanonical streams every task to w in canonical form and returns how many were written
+func (c *CLI) writeCanonical(ctx context.Context, w io.Writer) (int, error) {
+	n := 0
+	err := c.service.StreamTasks(ctx, domain.TaskFilter{}, func(task *domain.Task) error {
+		n++
+		return export.WriteCanonicalTask(w, task)
+	})
+	return n, err
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// fieldDefs returns the user-defined fields declared in the configuration
+func (c *CLI) fieldDefs() []domain.FieldDef {
+	if c.config == nil {
+		return nil
+	}
+	return c.config.FieldDefs()
+}
+
+// fieldDef returns the declared user-defined field with the given name
+func (c *CLI) fieldDef(name string) (domain.FieldDef, bool) {
+	for _, def := range c.fieldDefs() {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return domain.FieldDef{}, false
+}
+
+// fieldNames returns the names of the declared user-defined fields
+func (c *CLI) fieldNames() []string {
+	var names []string
+	for _, def := range c.fieldDefs() {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+// addSetFlag registers --set for user-defined fields
+func addSetFlag(cmd *cobra.Command, sets *[]string) {
+	cmd.Flags().StringArrayVar(sets, "set", nil, "User-defined field as name=value, empty to clear (repeatable)")
+}
+
+// fieldOptions returns the task options setting the user-defined fields given with --set
+func (c *CLI) fieldOptions(sets []string) ([]domain.TaskOption, error) {
+	var opts []domain.TaskOption
+	for _, expr := range sets {
+		name, value, ok := strings.Cut(expr, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --set %q (expected field=value)", expr)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		def, ok := c.fieldDef(name)
+		if !ok {
+			if names := c.fieldNames(); len(names) > 0 {
+				return nil, fmt.Errorf("unknown field %q (declared fields: %s)", name, strings.Join(names, ", "))
+			}
+			return nil, fmt.Errorf("unknown field %q (declare user-defined fields under fields in config.yaml)", name)
+		}
+		value, err := def.Normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, domain.WithField(name, value))
+	}
+	return opts, nil
+}
+
+// printFields prints the user-defined fields of a task for get, sorted by name
+func printFields(task *domain.Task) {
+	names := make([]string, 0, len(task.Fields))
+	for name := range task.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("  %-12s %s\n", name+":", task.Fields[name])
+	}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+)
+
+// templateFuncs are the helper functions available to --format templates
+var templateFuncs = template.FuncMap{
+	"short": func(id string) string {
+		if len(id) > 8 {
+			return id[:8]
+		}
+		return id
+	},
+	"date": func(layout string, t interface{}) string {
+		switch v := t.(type) {
+		case time.Time:
+			return v.Format(layout)
+		case *time.Time:
+			if v == nil {
+				return ""
+			}
+			return v.Format(layout)
+		default:
+			return ""
+		}
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// renderTemplate executes a user-supplied Go template once per task,
+// writing each result on its own line.
+func renderTemplate(w io.Writer, format string, tasks []*domain.Task) error {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid format template: %w", err)
+	}
+
+	for _, task := range tasks {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, task); err != nil {
+			return fmt.Errorf("failed to render task %s: %w", task.ID, err)
+		}
+
+		line := strings.TrimRight(sb.String(), "\n")
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// csvHeader lists the columns written by renderCSV
+var csvHeader = []string{"id", "title", "description", "status", "priority", "context", "created", "updated", "completed", "due"}
+
+// renderCSV writes tasks as RFC 4180 CSV with a header row.
+// Timestamps use RFC 3339; an empty completed column means the task is still open.
+// The due column is a YYYY-MM-DD date, empty when the task has no due date.
+func renderCSV(w io.Writer, tasks []*domain.Task) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, task := range tasks {
+		completed := ""
+		if task.CompletedAt != nil {
+			completed = task.CompletedAt.Format(time.RFC3339)
+		}
+		due := ""
+		if day, ok := task.DueDate(); ok {
+			due = day.Format(time.DateOnly)
+		}
+
+		record := []string{
+			task.ID,
+			task.Title,
+			task.Description,
+			string(task.Status),
+			string(task.Priority),
+			task.Context,
+			task.CreatedAt.Format(time.RFC3339),
+			task.UpdatedAt.Format(time.RFC3339),
+			completed,
+			due,
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// taskJSON is the JSON form of a task written by renderJSON
+type taskJSON struct {
+	ID          string            `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Status      string            `json:"status"`
+	Priority    string            `json:"priority"`
+	Context     string            `json:"context,omitempty"`
+	Assignee    string            `json:"assignee,omitempty"`
+	CreatedBy   string            `json:"created_by,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	WaitUntil   *time.Time        `json:"wait_until,omitempty"`
+	Pinned      bool              `json:"pinned,omitempty"`
+	Estimate    string            `json:"estimate,omitempty"`
+	Points      int               `json:"points,omitempty"`
+	Location    string            `json:"location,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"`
+	Metadata    domain.Metadata   `json:"metadata,omitempty"`
+}
+
+// renderJSON writes tasks as an indented JSON array
+func renderJSON(w io.Writer, tasks []*domain.Task) error {
+	out := make([]taskJSON, 0, len(tasks))
+	for _, task := range tasks {
+		out = append(out, taskJSON{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      string(task.Status),
+			Priority:    string(task.Priority),
+			Context:     task.Context,
+			Assignee:    task.Assignee,
+			CreatedBy:   task.CreatedBy,
+			CreatedAt:   task.CreatedAt,
+			UpdatedAt:   task.UpdatedAt,
+			CompletedAt: task.CompletedAt,
+			WaitUntil:   task.WaitUntil,
+			Pinned:      task.Pinned,
+			Estimate:    ui.Duration(task.Estimate),
+			Points:      task.Points,
+			Location:    task.Location,
+			Fields:      task.Fields,
+			Metadata:    task.Metadata,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/integrations/git"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// gitCmd creates the git command grouping the git integration subcommands
+func (c *CLI) gitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git",
+		Short: "Link tasks to git branches and commits",
+		Long: `Link tasks to the git repository in the working directory. "task git branch"
+creates a branch for a task; commits whose message ends with a trailer such as
+
+    task: 1a2b3c4d
+
+are linked to that task by "task git scan", which the hook installed by
+"task git hook" runs after every commit. "task get" lists the linked branch
+and commits.`,
+	}
+
+	cmd.AddCommand(
+		c.gitBranchCmd(),
+		c.gitScanCmd(),
+		c.gitHookCmd(),
+	)
+
+	return cmd
+}
+
+// gitBranchCmd creates the git branch command
+func (c *CLI) gitBranchCmd() *cobra.Command {
+	var noCheckout bool
+
+	cmd := &cobra.Command{
+		Use:   "branch [task-id]",
+		Short: "Create or switch to the branch for a task",
+		Long: `Create a branch named after the task (e.g. task/1a2b3c4d-fix-login-redirect)
+at HEAD and switch to it. If the task is already linked to a branch, switch to
+that branch instead, creating it again if it was deleted.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: c.taskIDCompletion(nil),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			repo, err := c.gitRepository(ctx)
+			if err != nil {
+				return err
+			}
+
+			task, err := c.service.GetTask(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get task: %w", err)
+			}
+
+			name, ok := task.Metadata.GetString(git.MetadataBranch)
+			if !ok {
+				name = git.BranchName(task.ID, task.Title)
+			}
+
+			exists, err := repo.BranchExists(ctx, name)
+			if err != nil {
+				return err
+			}
+
+			if c.dryRun {
+				if !exists {
+					fmt.Printf("  would create branch %s\n", name)
+				}
+				if !noCheckout {
+					fmt.Printf("  would switch to branch %s\n", name)
+				}
+				return nil
+			}
+
+			if !exists {
+				if err := repo.CreateBranch(ctx, name); err != nil {
+					return fmt.Errorf("failed to create branch: %w", err)
+				}
+				fmt.Println(c.t("✓ Created branch %s", name))
+			}
+			if !ok {
+				if _, err := c.service.SetTaskMetadata(ctx, task.ID, git.MetadataBranch, name); err != nil {
+					return fmt.Errorf("failed to link branch: %w", err)
+				}
+			}
+
+			if !noCheckout {
+				if err := repo.Checkout(ctx, name); err != nil {
+					return fmt.Errorf("failed to switch branch: %w", err)
+				}
+				fmt.Println(c.t("✓ Switched to branch %s", name))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&noCheckout, "no-checkout", false, "Create and link the branch without switching to it")
+
+	return cmd
+}
+
+// gitScanCmd creates the git scan command
+func (c *CLI) gitScanCmd() *cobra.Command {
+	var rev string
+	var max int
+
+	cmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Link commits to the tasks named in their trailers",
+		Long: `Read the commits reachable from --rev and link each one whose message ends
+with a "task: <id>" trailer to that task. The ID may be shortened to its first
+8 characters, and one trailer may name several tasks separated by commas.
+Commits that are already linked are skipped, so scanning again is safe.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if max < 0 {
+				return fmt.Errorf("--max cannot be negative")
+			}
+
+			ctx := cmd.Context()
+			repo, err := c.gitRepository(ctx)
+			if err != nil {
+				return err
+			}
+
+			commits, err := repo.Commits(ctx, rev, max)
+			if err != nil {
+				return fmt.Errorf("failed to read commits: %w", err)
+			}
+
+			index, err := c.taskIDIndex(ctx)
+			if err != nil {
+				return err
+			}
+
+			// Group new links by task so each task is written once
+			links := make(map[string][]git.CommitLink)
+			var order []string
+			for _, commit := range commits {
+				for _, ref := range commit.TaskIDs {
+					id, err := index.resolve(ref)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: commit %s: %v\n", commit.Hash[:8], err)
+						continue
+					}
+					if _, seen := links[id]; !seen {
+						order = append(order, id)
+					}
+					links[id] = append(links[id], commit.Link())
+				}
+			}
+
+			var linked, tasks int
+			for _, id := range order {
+				n, err := c.linkCommits(ctx, id, links[id])
+				if err != nil {
+					return err
+				}
+				if n > 0 {
+					linked += n
+					tasks++
+				}
+			}
+
+			if linked == 0 {
+				fmt.Println(c.t("✓ No new commits to link"))
+				return nil
+			}
+			if c.dryRun {
+				fmt.Printf("Would link %d commit(s) to %d task(s)\n", linked, tasks)
+				return nil
+			}
+			fmt.Println(c.t("✓ Linked %d commit(s) to %d task(s)", linked, tasks))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rev, "rev", "HEAD", "Revision (or range, e.g. main..HEAD) to read commits from")
+	cmd.Flags().IntVarP(&max, "max", "n", 500, "Maximum number of commits to read (0 reads all)")
+
+	return cmd
+}
+
+// gitHookCmd creates the git hook command
+func (c *CLI) gitHookCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Install a post-commit hook that links new commits",
+		Long: `Install a post-commit hook in the repository that runs "task git scan" for
+each new commit, so commits with a task trailer are linked as they are made.
+The hook never makes a commit fail.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			repo, err := c.gitRepository(ctx)
+			if err != nil {
+				return err
+			}
+
+			if c.dryRun {
+				fmt.Printf("  would install a post-commit hook in %s\n", repo.Dir())
+				return nil
+			}
+
+			path, err := repo.InstallHook(ctx, "task git scan --rev HEAD --max 1", force)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(c.t("✓ Installed %s", path))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing post-commit hook")
+
+	return cmd
+}
+
+// linkCommits appends the commits not yet linked to the task's metadata and
+// returns how many were new
+func (c *CLI) linkCommits(ctx context.Context, id string, commits []git.CommitLink) (int, error) {
+	task, err := c.service.GetTask(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	var existing []git.CommitLink
+	if _, err := task.Metadata.Get(git.MetadataCommits, &existing); err != nil {
+		return 0, err
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, link := range existing {
+		known[link.Hash] = true
+	}
+
+	added := 0
+	for _, link := range commits {
+		if known[link.Hash] {
+			continue
+		}
+		known[link.Hash] = true
+		existing = append(existing, link)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	if _, err := c.service.SetTaskMetadata(ctx, id, git.MetadataCommits, existing); err != nil {
+		return 0, fmt.Errorf("failed to link commits: %w", err)
+	}
+	return added, nil
+}
+
+// gitRepository returns the git repository containing the working directory
+func (c *CLI) gitRepository(ctx context.Context) (*git.Repository, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return git.Open(ctx, wd)
+}
+
+// taskIDIndex resolves full or shortened task IDs
+type taskIDIndex []string
+
+// taskIDIndex returns an index of every task ID
+func (c *CLI) taskIDIndex(ctx context.Context) (taskIDIndex, error) {
+	var ids taskIDIndex
+	err := c.service.StreamTasks(ctx, domain.TaskFilter{}, func(task *domain.Task) error {
+		ids = append(ids, task.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return ids, nil
+}
+
+// resolve returns the single task ID starting with ref
+func (idx taskIDIndex) resolve(ref string) (string, error) {
+	if len(ref) < 8 {
+		return "", fmt.Errorf("task ID %q is too short (use at least 8 characters)", ref)
+	}
+
+	var match string
+	for _, id := range idx {
+		if !strings.HasPrefix(id, ref) {
+			continue
+		}
+		if match != "" {
+			return "", fmt.Errorf("task ID %q is ambiguous", ref)
+		}
+		match = id
+	}
+	if match == "" {
+		return "", fmt.Errorf("task %s: %w", ref, domain.ErrTaskNotFound)
+	}
+	return match, nil
+}
+
+// printGitLinks prints the branch and commits linked to a task by the git integration
+func printGitLinks(task *domain.Task, times ui.TimeFormat) {
+	if branch, ok := task.Metadata.GetString(git.MetadataBranch); ok {
+		fmt.Printf("  Branch:      %s\n", branch)
+	}
+
+	var commits []git.CommitLink
+	if ok, err := task.Metadata.Get(git.MetadataCommits, &commits); !ok || err != nil || len(commits) == 0 {
+		return
+	}
+	fmt.Printf("  Commits:\n")
+	for _, commit := range commits {
+		fmt.Printf("    %s  %s  %s\n", commit.Hash[:min(len(commit.Hash), 8)], times.Date(commit.Date), commit.Subject)
+	}
+}
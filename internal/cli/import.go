@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/importer"
+	"github.com/edson-mazvila/task-manager/internal/integrations/linking"
+	"github.com/edson-mazvila/task-manager/internal/repository"
+	"github.com/edson-mazvila/task-manager/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// importFormats lists the formats accepted by "task import --format"
+var importFormats = []string{"todoist", "trello", "org", "todotxt", "canonical"}
+
+// importCmd creates the import command
+func (c *CLI) importCmd() *cobra.Command {
+	var format, project, token, apiURL, lists string
+
+	cmd := &cobra.Command{
+		Use:   "import <file|api>",
+		Short: "Import tasks from another task manager",
+		Long: `Import tasks from another task manager. All tasks are created at once, or none
+if any is invalid; use --dry-run to preview them first.
+
+Formats:
+  todoist   a project exported as CSV (the project name is taken from the file
+            name unless --project is given), or "api" to read every active task
+            with an API token from --token, TODOIST_TOKEN, or the keyring entry
+            todoist-token. Priorities p1, p2, p3/p4 become high, medium, low;
+            projects become contexts; labels and due dates are kept in the
+            task metadata. Tasks read from the API are not imported twice.
+  trello    a board exported as JSON. With --lists context (the default) each
+            list becomes a context; with --lists status the board becomes the
+            context and cards in a Done list are imported as completed.
+            Checklists are added to the description, a high/medium/low label
+            sets the priority, and labels and due dates are kept in the task
+            metadata. Cards already imported are skipped.
+  org       an Org-mode file. Headings with a TODO keyword become tasks (DONE
+            and CANCELED ones completed); [#A]/[#B]/[#C] set the priority, the
+            first tag the context, and CLOSED and a CREATED property the dates.
+            Other tags, DEADLINE, and SCHEDULED are kept in the task metadata.
+  todotxt   a todo.txt file. Lines starting with "x" are completed; (A), (B),
+            (C) set the priority, the first @context the context, and the
+            leading dates the completion and creation dates. +projects and
+            key:value tags such as due: are kept in the task metadata.
+  canonical a file written by "task export canonical". Every line is checked
+            against the export schema ("task export schema") first, and
+            tasks keep their IDs, dates, and other attributes.
+
+Org headings with an ID property and todo.txt lines with an id: tag (as
+written by "task export") are not imported twice. With --project, tasks
+without a context get that context.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("import is only supported for SQLite storage")
+			}
+
+			ctx := cmd.Context()
+			var items []importer.Item
+			var provider string
+			var err error
+
+			switch format {
+			case "todoist":
+				provider = importer.TodoistProvider
+				items, err = c.readTodoist(ctx, args[0], project, token, apiURL)
+			case "trello":
+				provider = importer.TrelloProvider
+				items, err = readTrello(args[0], importer.TrelloLists(lists), project)
+			case "org":
+				provider = importer.OrgProvider
+				items, err = readFile(args[0], project, importer.ParseOrg)
+			case "todotxt":
+				provider = importer.TodoTxtProvider
+				items, err = readFile(args[0], project, importer.ParseTodoTxt)
+			case "canonical":
+				provider = importer.CanonicalProvider
+				items, err = readFile(args[0], project, importer.ParseCanonical)
+			default:
+				return fmt.Errorf("unknown format %q (must be %s)", format, strings.Join(importFormats, ", "))
+			}
+			if err != nil {
+				return err
+			}
+
+			linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(c.storage.DB(), c.logger), c.logger)
+			result, err := importer.NewImporter(c.service, linker, c.logger).Import(ctx, provider, items, c.dryRun)
+			if err != nil {
+				return fmt.Errorf("import failed: %w", err)
+			}
+
+			if c.dryRun {
+				for n, task := range result.Created {
+					fmt.Printf("  would create %s\n", describeImport(task.Title, string(task.Priority), task.Context, result.Items[n]))
+				}
+				fmt.Printf("Would import %d task(s), %d already imported\n", len(result.Created), result.Skipped)
+				return nil
+			}
+
+			if result.Skipped > 0 {
+				fmt.Println(c.t("✓ Imported %d task(s), skipped %d already imported", len(result.Created), result.Skipped))
+			} else {
+				fmt.Println(c.t("✓ Imported %d task(s)", len(result.Created)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Source format: "+strings.Join(importFormats, ", "))
+	cmd.Flags().StringVar(&project, "project", "", "Context for the imported tasks (default from the file or board name)")
+	cmd.Flags().StringVar(&lists, "lists", string(importer.TrelloListsContext), "What Trello lists become: context or status")
+	cmd.Flags().StringVar(&token, "token", "", "API token when importing from an API")
+	cmd.Flags().StringVar(&apiURL, "api-url", "", "API URL, to use a proxy or test server")
+	_ = cmd.MarkFlagRequired("format")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(importFormats, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// readTodoist reads Todoist items from a CSV export, or from the API when source is "api"
+func (c *CLI) readTodoist(ctx context.Context, source, project, token, apiURL string) ([]importer.Item, error) {
+	if source == "api" {
+		token, err := apiToken(token, "TODOIST_TOKEN", secrets.TodoistToken)
+		if err != nil {
+			return nil, err
+		}
+		items, err := importer.NewTodoistClient(token, apiURL).Items(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Todoist tasks: %w", err)
+		}
+		return items, nil
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer f.Close()
+
+	if project == "" {
+		project = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	}
+	items, err := importer.ParseTodoistCSV(f, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	return items, nil
+}
+
+// readTrello reads Trello items from a board JSON export
+func readTrello(path string, lists importer.TrelloLists, project string) ([]importer.Item, error) {
+	if lists != importer.TrelloListsContext && lists != importer.TrelloListsStatus {
+		return nil, fmt.Errorf("invalid --lists %q (must be context or status)", lists)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer f.Close()
+
+	items, err := importer.ParseTrelloJSON(f, lists, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return items, nil
+}
+
+// readFile reads items from a file with parse
+func readFile(path, project string, parse func(io.Reader, string) ([]importer.Item, error)) ([]importer.Item, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer f.Close()
+
+	items, err := parse(f, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return items, nil
+}
+
+// describeImport renders a task to be imported for the dry-run preview
+func describeImport(title, priority, taskContext string, item importer.Item) string {
+	parts := []string{fmt.Sprintf("%q", title), priority}
+	if taskContext != "" {
+		parts = append(parts, "@"+taskContext)
+	}
+
+	keys := make([]string, 0, len(item.Metadata))
+	for key := range item.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, item.Metadata[key]))
+	}
+	if item.Completed {
+		parts = append(parts, "completed")
+	}
+	return strings.Join(parts, " ")
+}
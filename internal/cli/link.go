@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/repository"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// linkKindValues are the values accepted by --kind
+var linkKindValues = []string{string(domain.LinkRelates), string(domain.LinkDuplicates), string(domain.LinkSeeAlso)}
+
+// linkRepository returns the repository storing task links, for reading
+// them; links are changed through the service, which checks the user's role
+func (c *CLI) linkRepository() (domain.LinkRepository, error) {
+	if c.storage == nil {
+		return nil, fmt.Errorf("task links are only supported for SQLite storage")
+	}
+	return repository.NewSQLiteLinkRepository(c.storage.DB(), c.logger), nil
+}
+
+// resolveTaskID returns the ID of the task ref refers to: a full ID, or a
+// prefix of at least 8 characters such as the short IDs list shows
+func (c *CLI) resolveTaskID(ctx context.Context, ref string) (string, error) {
+	if len(ref) < 8 {
+		return "", fmt.Errorf("task ID %q is too short (use at least 8 characters)", ref)
+	}
+
+	prefix := &domain.QueryCond{Field: domain.QueryFieldID, Op: domain.QueryMatch, Value: ref}
+	tasks, err := c.service.ListTasks(ctx, domain.TaskFilter{Query: prefix})
+	if err != nil {
+		return "", err
+	}
+	switch len(tasks) {
+	case 0:
+		return "", fmt.Errorf("task %s: %w", ref, domain.ErrTaskNotFound)
+	case 1:
+		return tasks[0].ID, nil
+	}
+	return "", fmt.Errorf("task ID %q is ambiguous", ref)
+}
+
+// linkTarget resolves the target argument of link and unlink: a URL as
+// given, or the ID of a task
+func (c *CLI) linkTarget(ctx context.Context, arg string) (string, error) {
+	if domain.IsLinkURL(arg) {
+		return arg, nil
+	}
+	return c.resolveTaskID(ctx, arg)
+}
+
+// linkCmd creates the link command
+func (c *CLI) linkCmd() *cobra.Command {
+	var kind string
+
+	cmd := &cobra.Command{
+		Use:   "link [task-id] [other-task-id|url]",
+		Short: "Link a task to another task or a URL",
+		Long: `Record that a task relates to, duplicates, or should be read together with
+("see also") another task or a URL. Links to tasks show up in "task get" on
+both tasks; "task links" follows them further. Tasks can be given by their
+short IDs as shown by list.
+
+The kind defaults to relates for tasks and see-also for URLs.`,
+		Example: `  task link 3f2a1b9c 5d1e2f3a
+  task link 3f2a1b9c 5d1e2f3a --kind duplicates
+  task link 3f2a1b9c https://example.com/design-doc`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: c.taskIDCompletion(nil),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			taskID, err := c.resolveTaskID(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to link task: %w", err)
+			}
+			target, err := c.linkTarget(ctx, args[1])
+			if err != nil {
+				return fmt.Errorf("failed to link task: %w", err)
+			}
+
+			if kind == "" {
+				kind = string(domain.LinkRelates)
+				if domain.IsLinkURL(target) {
+					kind = string(domain.LinkSeeAlso)
+				}
+			}
+			linkKind, err := domain.ParseLinkKind(kind)
+			if err != nil {
+				return err
+			}
+
+			link := &domain.Link{TaskID: taskID, Kind: linkKind, Target: target, CreatedAt: time.Now()}
+			if err := c.service.LinkTask(ctx, link); err != nil {
+				return fmt.Errorf("failed to link task: %w", err)
+			}
+			if c.dryRun {
+				fmt.Printf("Would link %s %s %s\n", taskID[:8], linkKind.Label(), shortTarget(target))
+				return nil
+			}
+
+			fmt.Println(c.t("✓ Task %s %s %s", taskID[:8], c.t(linkKind.Label()), shortTarget(target)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&kind, "kind", "k", "", "Relationship: relates, duplicates, or see-also")
+	_ = cmd.RegisterFlagCompletionFunc("kind", fixedCompletion(linkKindValues...))
+
+	return cmd
+}
+
+// unlinkCmd creates the unlink command
+func (c *CLI) unlinkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unlink [task-id] [other-task-id|url]",
+		Short:             "Remove the links between a task and another task or a URL",
+		Long:              `Remove every link between two tasks, in either direction, or from a task to a URL.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: c.taskIDCompletion(nil),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			taskID, err := c.resolveTaskID(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to unlink task: %w", err)
+			}
+			target, err := c.linkTarget(ctx, args[1])
+			if err != nil {
+				return fmt.Errorf("failed to unlink task: %w", err)
+			}
+
+			if err := c.service.UnlinkTask(ctx, taskID, target); err != nil {
+				return fmt.Errorf("failed to unlink task: %w", err)
+			}
+			if c.dryRun {
+				fmt.Printf("Would unlink %s and %s\n", taskID[:8], shortTarget(target))
+				return nil
+			}
+
+			fmt.Println(c.t("✓ Task %s unlinked from %s", taskID[:8], shortTarget(target)))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// linksCmd creates the links command
+func (c *CLI) linksCmd() *cobra.Command {
+	var depth int
+
+	cmd := &cobra.Command{
+		Use:   "links [task-id]",
+		Short: "Show the tasks and URLs linked to a task",
+		Long: `Show the links of a task as a tree, following links to other tasks up to
+--depth levels (0 follows them all). Each task appears once; later mentions
+are marked "(above)".`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: c.taskIDCompletion(nil),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if depth < 0 {
+				return fmt.Errorf("invalid depth: %d (cannot be negative)", depth)
+			}
+
+			ctx := cmd.Context()
+			links, err := c.linkRepository()
+			if err != nil {
+				return err
+			}
+			taskID, err := c.resolveTaskID(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get task: %w", err)
+			}
+			task, err := c.service.GetTask(ctx, taskID)
+			if err != nil {
+				return fmt.Errorf("failed to get task: %w", err)
+			}
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s %s\n", painter.Paint(ui.RoleID, task.ID[:8]), task.Title)
+			tree := &linkTree{cli: c, links: links, painter: painter, depth: depth, seen: map[string]bool{task.ID: true}}
+			return tree.print(ctx, task.ID, "", "", 1)
+		},
+	}
+
+	cmd.Flags().IntVar(&depth, "depth", 1, "Levels of linked tasks to follow (0 for all)")
+
+	return cmd
+}
+
+// linkTree prints the links reachable from a task
+type linkTree struct {
+	cli     *CLI
+	links   domain.LinkRepository
+	painter *ui.Painter
+	depth   int
+	seen    map[string]bool
+}
+
+// print prints the links of taskID at the given level, indented by prefix,
+// except the link back to the parent task it was reached from
+func (t *linkTree) print(ctx context.Context, taskID, parent, prefix string, level int) error {
+	all, err := t.links.ListByTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	var links []*domain.Link
+	for _, link := range all {
+		if _, other := linkEnd(link, taskID); other != parent {
+			links = append(links, link)
+		}
+	}
+
+	for i, link := range links {
+		branch, indent := "├── ", "│   "
+		if i == len(links)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		label, other := linkEnd(link, taskID)
+		if domain.IsLinkURL(other) {
+			fmt.Printf("%s%s%s %s\n", prefix, branch, label, other)
+			continue
+		}
+
+		text, task := t.cli.describeLinkedTask(ctx, t.painter, other)
+		if t.seen[other] {
+			fmt.Printf("%s%s%s %s (above)\n", prefix, branch, label, text)
+			continue
+		}
+		fmt.Printf("%s%s%s %s\n", prefix, branch, label, text)
+		t.seen[other] = true
+
+		if task != nil && (t.depth == 0 || level < t.depth) {
+			if err := t.print(ctx, other, taskID, prefix+indent, level+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// linkEnd returns how link reads from taskID and the other end of the link
+func linkEnd(link *domain.Link, taskID string) (string, string) {
+	if link.TaskID == taskID {
+		return link.Kind.Label(), link.Target
+	}
+	return link.Kind.InverseLabel(), link.TaskID
+}
+
+// describeLinkedTask returns the short ID and title of a linked task, and
+// the task if it still exists
+func (c *CLI) describeLinkedTask(ctx context.Context, painter *ui.Painter, id string) (string, *domain.Task) {
+	task, err := c.service.GetTask(ctx, id)
+	if err != nil {
+		return shortTarget(id) + " (not found)", nil
+	}
+
+	text := painter.Paint(ui.RoleID, task.ID[:8]) + " " + task.Title
+	if task.Status == domain.TaskStatusCompleted {
+		text += painter.Paint(ui.RoleCompleted, " (completed)")
+	}
+	return text, task
+}
+
+// printLinks prints the links of a task for get
+func (c *CLI) printLinks(ctx context.Context, painter *ui.Painter, task *domain.Task) error {
+	if c.storage == nil {
+		return nil
+	}
+	links, err := repository.NewSQLiteLinkRepository(c.storage.DB(), c.logger).ListByTask(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	width := 0
+	for _, link := range links {
+		label, _ := linkEnd(link, task.ID)
+		width = max(width, len(label))
+	}
+
+	for i, link := range links {
+		heading := "Links:"
+		if i > 0 {
+			heading = ""
+		}
+		label, other := linkEnd(link, task.ID)
+		text := other
+		if !domain.IsLinkURL(other) {
+			text, _ = c.describeLinkedTask(ctx, painter, other)
+		}
+		fmt.Printf("  %-12s %-*s %s\n", heading, width, label, text)
+	}
+	return nil
+}
+
+// shortTarget shortens a task ID link target to the form list shows
+func shortTarget(target string) string {
+	if domain.IsLinkURL(target) || len(target) <= 8 {
+		return target
+	}
+	return target[:8]
+}
@@ -0,0 +1,494 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/query"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// listOptions holds the flags shared by list and list-like commands
+type listOptions struct {
+	status      string
+	priority    string
+	fromDate    string
+	toDate      string
+	taskContext string
+	assignee    string
+	location    string
+	query       string
+	pinned      bool
+	all         bool
+	format      string
+	output      string
+	absolute    bool
+	archived    bool
+	groupBy     string
+	wide        bool
+	oneline     bool
+	watch       bool
+	interval    time.Duration
+}
+
+// listCmd creates the list command
+func (c *CLI) listCmd() *cobra.Command {
+	opts := &listOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: c.t("List tasks"),
+		Long: `List all tasks with optional filtering by status, priority, context, assignee, and date range.
+When an active context is set (see "task context set"), only tasks in that context
+are shown unless --all or --context is given. Snoozed tasks (see "task snooze")
+are hidden until they wake unless --all is given. So are tasks restricted to
+another location (see "task add --location"): the location is user.location in
+config or TASK_LOCATION, or else the host name; --location picks another one.
+Use --archived to search the archive database instead (see "task archive"). In
+a terminal, long titles and descriptions are cut to fit its width.
+
+--query/-q takes a filter expression combining field comparisons with AND, OR,
+NOT and parentheses, e.g.
+  task list -q 'status:pending AND (priority>=medium OR context:office) AND created<2025-01-01'
+Fields: id, title, description, status, priority, context, assignee, created_by,
+created, updated, completed. Operators: ":" (equals; substring for title and
+description, prefix for id), =, !=, and <, <=, >, >= for priority and dates.
+
+--watch clears the screen and lists tasks again whenever the database changes,
+for a live task panel in a terminal or tmux pane. Press Ctrl-C to stop.`,
+		Annotations: map[string]string{annotationPerRequestTimeout: "watch", annotationPager: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.watch {
+				return c.watchList(cmd.Context(), opts)
+			}
+			return c.runList(cmd.Context(), opts)
+		},
+	}
+
+	c.addListFlags(cmd, opts)
+	cmd.Flags().StringVar(&opts.assignee, "assignee", "", "Filter by assignee")
+	_ = cmd.RegisterFlagCompletionFunc("assignee", c.assigneeCompletion)
+	cmd.Flags().StringVar(&opts.location, "location", "", "List the tasks that can be done at this location instead of here")
+	cmd.Flags().BoolVar(&opts.archived, "archived", false, "List archived tasks instead")
+	cmd.Flags().BoolVarP(&opts.watch, "watch", "w", false, "Keep listing tasks as they change")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 5*time.Second, "How often --watch checks for changes")
+
+	return cmd
+}
+
+// mineCmd creates the mine command listing tasks assigned to the current user
+func (c *CLI) mineCmd() *cobra.Command {
+	opts := &listOptions{status: string(domain.TaskStatusPending)}
+
+	cmd := &cobra.Command{
+		Use:   "mine",
+		Short: "List pending tasks assigned to you",
+		Long: `List tasks assigned to the current user (user.name in config or TASK_USER).
+Accepts the same flags as list; only pending tasks are shown unless --status is given.`,
+		Annotations: map[string]string{annotationPager: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			me, err := c.currentUser()
+			if err != nil {
+				return err
+			}
+			opts.assignee = me
+			return c.runList(cmd.Context(), opts)
+		},
+	}
+
+	c.addListFlags(cmd, opts)
+
+	return cmd
+}
+
+// delegatedCmd creates the delegated command listing tasks the current user
+// created and assigned to someone else
+func (c *CLI) delegatedCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "delegated",
+		Short: "List tasks you delegated to others",
+		Long: `List tasks you created (user.name in config or TASK_USER) that are assigned
+to someone else, grouped by assignee with their last activity. Only pending
+tasks are shown unless --all is given.`,
+		Annotations: map[string]string{annotationPager: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			me, err := c.currentUser()
+			if err != nil {
+				return err
+			}
+
+			filter := domain.TaskFilter{CreatedBy: &me}
+			if !all {
+				pending := domain.TaskStatusPending
+				filter.Status = &pending
+			}
+
+			tasks, err := c.service.ListTasks(cmd.Context(), filter)
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+
+			groups := make(map[string][]*domain.Task)
+			var assignees []string
+			for _, task := range tasks {
+				if task.Assignee == "" || task.Assignee == me {
+					continue
+				}
+				if _, ok := groups[task.Assignee]; !ok {
+					assignees = append(assignees, task.Assignee)
+				}
+				groups[task.Assignee] = append(groups[task.Assignee], task)
+			}
+
+			if len(assignees) == 0 {
+				fmt.Println("No delegated tasks.")
+				return nil
+			}
+			sort.Strings(assignees)
+
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			for i, assignee := range assignees {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Println(painter.Paint(ui.RoleHeader, fmt.Sprintf("%s (%d)", assignee, len(groups[assignee]))))
+
+				table := ui.NewTable(painter, "ID", "TITLE", "STATUS", "PRIORITY", "LAST ACTIVITY")
+				for _, task := range groups[assignee] {
+					rowRole := ""
+					if task.Status == domain.TaskStatusCompleted {
+						rowRole = ui.RoleCompleted
+					}
+					table.AddRow(rowRole,
+						ui.Cell{Text: task.ID[:8], Role: ui.RoleID},
+						ui.Cell{Text: task.Title},
+						ui.Cell{Text: string(task.Status)},
+						ui.Cell{Text: string(task.Priority), Role: ui.PriorityRole(task.Priority)},
+						ui.Cell{Text: ui.RelativeTime(task.UpdatedAt, now)},
+					)
+				}
+				if err := table.Render(os.Stdout); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Include completed tasks")
+
+	return cmd
+}
+
+// addListFlags registers the filtering and output flags shared by list-like commands
+func (c *CLI) addListFlags(cmd *cobra.Command, opts *listOptions) {
+	c.addFilterFlags(cmd, opts)
+	cmd.Flags().StringVarP(&opts.format, "format", "f", "", "Go template applied to each task, e.g. '{{.ID}} {{.Title}}'")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "table", "Output format (table, csv, json)")
+	cmd.Flags().BoolVar(&opts.absolute, "absolute", false, "Show absolute timestamps instead of relative ages")
+	cmd.Flags().StringVarP(&opts.groupBy, "group-by", "g", "", "Show tasks in sections by status, priority, context (or project), or due")
+	cmd.Flags().BoolVar(&opts.wide, "wide", false, "Add context, due date, and description columns")
+	cmd.Flags().BoolVar(&opts.oneline, "oneline", false, "Show each task on one compact line")
+	_ = cmd.RegisterFlagCompletionFunc("output", fixedCompletion("table", "csv", "json"))
+	_ = cmd.RegisterFlagCompletionFunc("group-by", fixedCompletion(ui.GroupByValues...))
+}
+
+// addFilterFlags registers the flags selecting tasks, shared by list and modify
+func (c *CLI) addFilterFlags(cmd *cobra.Command, opts *listOptions) {
+	cmd.Flags().StringVarP(&opts.status, "status", "s", opts.status, "Filter by status (pending, completed)")
+	cmd.Flags().StringVarP(&opts.priority, "priority", "p", "", "Filter by priority (low, medium, high)")
+	cmd.Flags().StringVar(&opts.fromDate, "from", "", "Filter by from date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.toDate, "to", "", "Filter by to date (YYYY-MM-DD)")
+	cmd.Flags().StringVarP(&opts.taskContext, "context", "c", "", "Filter by context (overrides the active context)")
+	cmd.Flags().StringVarP(&opts.query, "query", "q", "", "Filter expression, e.g. 'status:pending AND (priority:high OR context:office)'")
+	cmd.Flags().BoolVar(&opts.pinned, "pinned", false, "Only pinned tasks")
+	cmd.Flags().BoolVarP(&opts.all, "all", "a", false, "Ignore the active context")
+	_ = cmd.RegisterFlagCompletionFunc("status", fixedCompletion(statusValues...))
+	_ = cmd.RegisterFlagCompletionFunc("priority", fixedCompletion(priorityValues...))
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+}
+
+// buildFilter converts list flags into a task filter, applying the active
+// context unless a context is given explicitly or --all is set
+func (c *CLI) buildFilter(opts *listOptions) (domain.TaskFilter, string, error) {
+	filter := domain.TaskFilter{}
+
+	// Parse status filter
+	if opts.status != "" {
+		taskStatus := domain.TaskStatus(opts.status)
+		if taskStatus != domain.TaskStatusPending && taskStatus != domain.TaskStatusCompleted {
+			return filter, "", fmt.Errorf("invalid status: %s (must be pending or completed)", opts.status)
+		}
+		filter.Status = &taskStatus
+	}
+
+	// Parse priority filter
+	if opts.priority != "" {
+		taskPriority := domain.TaskPriority(opts.priority)
+		if taskPriority != domain.TaskPriorityLow &&
+			taskPriority != domain.TaskPriorityMedium &&
+			taskPriority != domain.TaskPriorityHigh {
+			return filter, "", fmt.Errorf("invalid priority: %s (must be low, medium, or high)", opts.priority)
+		}
+		filter.Priority = &taskPriority
+	}
+
+	// Parse date filters
+	if opts.fromDate != "" {
+		t, err := time.Parse("2006-01-02", opts.fromDate)
+		if err != nil {
+			return filter, "", fmt.Errorf("invalid from-date format (use YYYY-MM-DD): %w", err)
+		}
+		filter.FromDate = &t
+	}
+
+	if opts.toDate != "" {
+		t, err := time.Parse("2006-01-02", opts.toDate)
+		if err != nil {
+			return filter, "", fmt.Errorf("invalid to-date format (use YYYY-MM-DD): %w", err)
+		}
+		filter.ToDate = &t
+	}
+
+	// Parse query expression
+	if opts.query != "" {
+		expr, err := query.Parse(opts.query, c.fieldDefs()...)
+		if err != nil {
+			return filter, "", fmt.Errorf("invalid query: %w", err)
+		}
+		filter.Query = expr
+	}
+
+	if opts.pinned {
+		pinned := true
+		filter.Pinned = &pinned
+	}
+
+	// Parse assignee filter
+	if opts.assignee != "" {
+		name := domain.NormalizeUserName(opts.assignee)
+		filter.Assignee = &name
+	}
+
+	// Parse context filter, falling back to the active context
+	var active string
+	if opts.taskContext != "" {
+		name := domain.NormalizeContext(opts.taskContext)
+		filter.Context = &name
+	} else if !opts.all {
+		var err error
+		active, err = c.activeContext()
+		if err != nil {
+			return filter, "", err
+		}
+		if active != "" {
+			filter.Context = &active
+		}
+	}
+
+	return filter, active, nil
+}
+
+// runList lists tasks matching opts in the requested output format
+func (c *CLI) runList(ctx context.Context, opts *listOptions) error {
+	if opts.output != "table" && opts.output != "csv" && opts.output != "json" {
+		return fmt.Errorf("invalid output: %s (must be table, csv, or json)", opts.output)
+	}
+	if opts.output != "table" && opts.format != "" {
+		return fmt.Errorf("--format cannot be combined with --output %s", opts.output)
+	}
+	if opts.groupBy != "" && (opts.output != "table" || opts.format != "") {
+		return fmt.Errorf("--group-by only applies to table output")
+	}
+	if (opts.wide || opts.oneline) && (opts.output != "table" || opts.format != "") {
+		return fmt.Errorf("--wide and --oneline only apply to table output")
+	}
+	if opts.oneline && (opts.wide || opts.groupBy != "") {
+		return fmt.Errorf("--oneline cannot be combined with --wide or --group-by")
+	}
+	if opts.groupBy == "project" {
+		opts.groupBy = string(ui.GroupByContext)
+	}
+	if opts.groupBy != "" && !slices.Contains(ui.GroupByValues, opts.groupBy) {
+		return fmt.Errorf("invalid group: %s (must be status, priority, context, project, or due)", opts.groupBy)
+	}
+
+	filter, active, err := c.buildFilter(opts)
+	if err != nil {
+		return err
+	}
+
+	// Snoozed tasks stay hidden until they wake, unless --all is given
+	now := time.Now()
+	if !opts.all && !opts.archived {
+		filter.AwakeAt = &now
+	}
+
+	// So do tasks restricted to another location
+	if opts.location != "" {
+		location := domain.NormalizeLocation(opts.location)
+		filter.Location = &location
+	} else if location := c.currentLocation(); location != "" && !opts.all && !opts.archived {
+		filter.Location = &location
+	}
+
+	if active != "" && opts.output == "table" && opts.format == "" {
+		fmt.Printf("%s\n\n", c.t("Context: @%s (use --all to show every task)", active))
+	}
+
+	// List tasks, from the archive database when asked
+	svc := c.service
+	if opts.archived {
+		archive, closeArchive, err := c.openArchive(ctx)
+		if err != nil {
+			return err
+		}
+		defer closeArchive()
+		svc = archive
+	}
+
+	var tasks []*domain.Task
+	if svc != nil {
+		tasks, err = svc.ListTasks(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list tasks: %w", err)
+		}
+	}
+
+	switch opts.output {
+	case "csv":
+		return renderCSV(os.Stdout, tasks)
+	case "json":
+		return renderJSON(os.Stdout, tasks)
+	}
+
+	format := opts.format
+	if format == "" && c.config != nil {
+		format = c.config.Display.ListFormat
+	}
+	if format != "" {
+		return renderTemplate(os.Stdout, format, tasks)
+	}
+
+	if len(tasks) == 0 {
+		fmt.Println(c.t("No tasks found."))
+		return nil
+	}
+
+	painter, err := c.painter()
+	if err != nil {
+		return err
+	}
+
+	times, err := c.timeFormat()
+	if err != nil {
+		return err
+	}
+	tableOpts := ui.TaskTableOptions{
+		Now:      now,
+		Absolute: opts.absolute,
+		Times:    times,
+		Width:    ui.TerminalWidth(c.terminal()),
+		Wide:     opts.wide,
+	}
+	switch {
+	case opts.oneline:
+		err = ui.RenderTaskLines(os.Stdout, painter, tasks, tableOpts)
+	case opts.groupBy != "":
+		var groups []ui.TaskGroup
+		groups, err = ui.GroupTasks(tasks, ui.GroupBy(opts.groupBy), now)
+		if err == nil {
+			err = ui.RenderGroups(os.Stdout, painter, groups, tableOpts)
+		}
+	default:
+		err = ui.RenderTasks(os.Stdout, painter, tasks, tableOpts)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.printStaleCount(tasks, now)
+	return nil
+}
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watchList lists tasks like runList, then again whenever the database
+// changes, until ctx is cancelled. --timeout applies to each listing.
+func (c *CLI) watchList(ctx context.Context, opts *listOptions) error {
+	if opts.interval <= 0 {
+		return fmt.Errorf("invalid interval: %s (must be positive)", opts.interval)
+	}
+	if opts.archived {
+		return fmt.Errorf("--watch cannot be combined with --archived")
+	}
+
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	last := ""
+	for first := true; ; first = false {
+		// Relative ages and snoozed tasks change with time too, so list
+		// again at least once a minute
+		stamp := c.databaseStamp() + time.Now().Truncate(time.Minute).String()
+		if first || stamp != last {
+			last = stamp
+			fmt.Print(clearScreen)
+			if err := c.watchListOnce(ctx, opts); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			fmt.Printf("\nEvery %s: %s\n", opts.interval, time.Now().Format("15:04:05"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchListOnce runs one listing of watchList under --timeout
+func (c *CLI) watchListOnce(ctx context.Context, opts *listOptions) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return c.runList(ctx, opts)
+}
+
+// databaseStamp identifies the current state of the database files by
+// their sizes and modification times; writes from any process change it
+func (c *CLI) databaseStamp() string {
+	if c.storage == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, path := range []string{c.storage.Path(), c.storage.Path() + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%d@%d;", info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return b.String()
+}
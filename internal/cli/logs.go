@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// logsFollowInterval is how often "logs tail --follow" checks for new records
+const logsFollowInterval = 500 * time.Millisecond
+
+// logsCmd creates the logs command grouping log file subcommands
+func (c *CLI) logsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "View the log file",
+		Long: `View the log file written when logging.output is "file" (by default
+$XDG_STATE_HOME/task-manager/task.log).`,
+	}
+
+	cmd.AddCommand(c.logsTailCmd())
+
+	return cmd
+}
+
+// logsTailCmd creates the logs tail command
+func (c *CLI) logsTailCmd() *cobra.Command {
+	var lines int
+	var follow bool
+
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Print the last lines of the log file",
+		Long: `Print the last lines of the log file. With --follow, keep printing new
+records as they are written, across rotations, until interrupted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if lines < 0 {
+				return fmt.Errorf("--lines cannot be negative")
+			}
+
+			var logging config.LoggingConfig
+			if c.config != nil {
+				logging = c.config.Logging
+			}
+			path := logging.FilePath()
+
+			f, err := os.Open(path)
+			if errors.Is(err, os.ErrNotExist) && !follow {
+				return fmt.Errorf("no log file at %s (set logging.output to \"file\" to write one)", path)
+			}
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to open log file: %w", err)
+			}
+
+			var offset int64
+			if f != nil {
+				offset, err = tailLines(f, lines, os.Stdout)
+				if err != nil {
+					f.Close()
+					return fmt.Errorf("failed to read log file: %w", err)
+				}
+			}
+
+			if !follow {
+				f.Close()
+				return nil
+			}
+			return followLog(cmd.Context(), path, f, offset, os.Stdout)
+		},
+	}
+
+	cmd.Flags().IntVarP(&lines, "lines", "n", 20, "Number of lines to print")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new records until interrupted")
+
+	return cmd
+}
+
+// tailLines writes the last n lines of f to w and returns the end offset
+func tailLines(f *os.File, n int, w io.Writer) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+
+	// Read backwards in blocks until enough line breaks have been seen
+	const block = 32 * 1024
+	var buf []byte
+	start := size
+	for start > 0 && bytes.Count(buf, []byte("\n")) <= n {
+		step := int64(block)
+		if start < step {
+			step = start
+		}
+		start -= step
+
+		chunk := make([]byte, step)
+		if _, err := f.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		buf = append(chunk, buf...)
+	}
+
+	lines := bytes.SplitAfter(buf, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	for _, line := range lines {
+		if _, err := w.Write(line); err != nil {
+			return 0, err
+		}
+	}
+	return size, nil
+}
+
+// followLog copies records appended to the log file at path to w until ctx
+// is done. f is the open log file (or nil if it does not exist yet) and
+// offset is where to continue reading. A rotated or truncated file is
+// reopened from the start.
+func followLog(ctx context.Context, path string, f *os.File, offset int64, w io.Writer) error {
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(logsFollowInterval)
+	defer ticker.Stop()
+
+	for {
+		if info, err := os.Stat(path); err == nil {
+			current := f != nil
+			if current {
+				if open, err := f.Stat(); err != nil || !os.SameFile(open, info) || info.Size() < offset {
+					current = false
+				}
+			}
+
+			if !current {
+				// Finish what was written to the old file before switching
+				if f != nil {
+					copyFrom(f, offset, w)
+					f.Close()
+				}
+				f, _ = os.Open(path)
+				offset = 0
+			}
+
+			if f != nil {
+				n, err := copyFrom(f, offset, w)
+				if err != nil {
+					return fmt.Errorf("failed to read log file: %w", err)
+				}
+				offset += n
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// copyFrom copies f from offset to its current end into w
+func copyFrom(f *os.File, offset int64, w io.Writer) (int64, error) {
+	return io.Copy(w, io.NewSectionReader(f, offset, 1<<62))
+}
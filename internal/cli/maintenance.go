@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maintenanceCmd creates the maintenance command
+func (c *CLI) maintenanceCmd() *cobra.Command {
+	var tombstones string
+
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Prune old records and compact the database",
+		Long: `Prune deletion records older than database.retention.tombstone_days (or
+--tombstones), then compact the database file (VACUUM), rebuild its indexes
+(REINDEX), and refresh the query planner statistics (ANALYZE). Reports the
+size of the database before and after.
+
+Deletion records let "task sync" remove deleted tasks from the other
+database; one not synced since a record was pruned brings the task back.
+By default they are kept for ever.`,
+		Example: `  task maintenance
+  task maintenance --tombstones 180d
+  task maintenance --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("database maintenance is only supported for SQLite storage")
+			}
+
+			var retention time.Duration
+			if tombstones != "" {
+				age, err := parseAge(tombstones)
+				if err != nil {
+					return err
+				}
+				retention = age
+			} else if c.config != nil {
+				retention = time.Duration(c.config.Database.Retention.TombstoneDays) * 24 * time.Hour
+			}
+			var cutoff time.Time
+			if retention > 0 {
+				cutoff = time.Now().Add(-retention)
+			}
+
+			times, err := c.timeFormat()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			if c.dryRun {
+				if !cutoff.IsZero() {
+					n, err := c.storage.CountTombstones(ctx, cutoff)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Would prune %d deletion record(s) from before %s\n", n, times.Date(cutoff))
+				}
+				fmt.Println("Would run VACUUM, REINDEX, ANALYZE and PRAGMA optimize")
+				return nil
+			}
+
+			report, err := c.storage.Maintain(ctx, cutoff)
+			if err != nil {
+				return fmt.Errorf("failed to maintain database: %w", err)
+			}
+
+			if !cutoff.IsZero() {
+				fmt.Println(c.t("✓ Pruned %d deletion record(s) from before %s", report.TombstonesPruned, times.Date(cutoff)))
+			}
+			fmt.Println(c.t("✓ Database compacted, reindexed and analyzed in %s", report.Elapsed.Round(time.Millisecond)))
+			fmt.Printf("  Size: %s → %s\n", ui.Bytes(report.SizeBefore), ui.Bytes(report.SizeAfter))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&tombstones, "tombstones", "", "Prune deletion records older than this (e.g. 180d, 26w), overriding the config")
+
+	return cmd
+}
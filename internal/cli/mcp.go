@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/edson-mazvila/task-manager/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+// mcpCmd creates the mcp command
+func (c *CLI) mcpCmd() *cobra.Command {
+	var readOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve tasks to AI assistants over the Model Context Protocol",
+		Long: `Run a Model Context Protocol (MCP) server on stdin and stdout, so AI assistants
+can list, search, create, update, complete, and delete tasks. Register it with
+your assistant as a stdio server running "task mcp", e.g.
+
+  {"mcpServers": {"tasks": {"command": "task", "args": ["mcp", "--read-only"]}}}
+
+With --read-only only list_tasks, search_tasks, and get_task are offered. With
+--dry-run changes are validated but not saved. --timeout (or behavior.timeout)
+bounds each tool call. Logs go to stderr or the log file, never stdout.`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{annotationPerRequestTimeout: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := []mcp.Option{
+				mcp.WithVersion(Version),
+				mcp.WithLogger(c.logger),
+				mcp.WithCreateOptions(c.creatorOptions()...),
+				mcp.WithCallTimeout(c.timeout),
+				mcp.WithFields(c.fieldDefs()),
+			}
+			if readOnly {
+				opts = append(opts, mcp.WithReadOnly())
+			}
+
+			c.logger.Info("MCP server started", "read_only", readOnly)
+			return mcp.NewServer(c.service, opts...).Serve(cmd.Context(), os.Stdin, os.Stdout)
+		},
+	}
+
+	cmd.Flags().BoolVar(&readOnly, "read-only", false, "Only offer tools that read tasks")
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/i18n"
+)
+
+// t translates a user-facing message into the language of display.language,
+// or of the locale (LC_ALL, LC_MESSAGES, LANG), and formats it with args
+func (c *CLI) t(format string, args ...any) string {
+	if c.messages == nil {
+		configured := ""
+		if c.config != nil {
+			configured = c.config.Display.Language
+		}
+		messages, err := i18n.New(i18n.Detect(configured))
+		if err != nil {
+			c.logger.Debug("Failed to load messages", "error", err)
+		}
+		c.messages = messages
+	}
+	return c.messages.Sprintf(format, args...)
+}
+
+// detail is a labelled line of task details
+type detail struct {
+	label string
+	value string
+}
+
+// printDetails prints details as indented "Label: value" lines, with the
+// values aligned whatever the length of the translated labels
+func printDetails(details []detail) {
+	width := 0
+	for _, d := range details {
+		width = max(width, utf8.RuneCountInString(d.label))
+	}
+	for _, d := range details {
+		fmt.Printf("  %-*s %s\n", width, d.label, d.value)
+	}
+}
+
+// taskSummary returns the ID, title, priority, and context of a task as
+// details, the context only when set
+func (c *CLI) taskSummary(task *domain.Task) []detail {
+	details := []detail{
+		{c.t("ID:"), task.ID},
+		{c.t("Title:"), task.Title},
+		{c.t("Priority:"), string(task.Priority)},
+	}
+	if task.Context != "" {
+		details = append(details, detail{c.t("Context:"), "@" + task.Context})
+	}
+	return details
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// modifyCmd creates the modify command for bulk updates
+func (c *CLI) modifyCmd() *cobra.Command {
+	opts := &listOptions{}
+	var sets []string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "modify",
+		Short: "Update every task matching a filter",
+		Long: `Apply field changes to every task selected by the same filters as list.
+Changes are given as --set field=value and applied atomically: either every
+matching task is updated or none is. Settable fields are priority, status,
+context, assignee, description, due (as for --due), and the user-defined
+fields declared in config.yaml; an empty value clears context, assignee,
+description, due, or a user-defined field. You are asked to confirm unless --yes is given.`,
+		Example: `  task modify --status pending --context someday --set priority=low
+  task modify --assignee alice --set assignee=bob --yes
+  task modify --context someday --set due=+7d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			patch, err := c.parseSets(sets)
+			if err != nil {
+				return err
+			}
+
+			filter, _, err := c.buildFilter(opts)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			tasks, err := c.service.ListTasks(ctx, filter)
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+			if len(tasks) == 0 {
+				fmt.Println("No tasks match.")
+				return nil
+			}
+
+			if !yes {
+				if !ui.IsTerminal(os.Stdin) {
+					return fmt.Errorf("refusing to modify %d task(s) without confirmation (use --yes)", len(tasks))
+				}
+				if !c.confirm(fmt.Sprintf("Modify %d task(s) (%s)?", len(tasks), strings.Join(sets, ", "))) {
+					fmt.Println("Aborted.")
+					return nil
+				}
+			}
+
+			n, err := c.service.UpdateTasks(ctx, filter, patch)
+			if err != nil {
+				return fmt.Errorf("failed to modify tasks: %w", err)
+			}
+
+			fmt.Println(c.t("✓ Modified %d task(s)", n))
+			return nil
+		},
+	}
+
+	c.addFilterFlags(cmd, opts)
+	cmd.Flags().StringVar(&opts.assignee, "assignee", "", "Filter by assignee")
+	_ = cmd.RegisterFlagCompletionFunc("assignee", c.assigneeCompletion)
+	cmd.Flags().StringArrayVar(&sets, "set", nil, "Field change as field=value (repeatable)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+	_ = cmd.MarkFlagRequired("set")
+
+	return cmd
+}
+
+// parseSets parses --set field=value expressions into a task patch
+func (c *CLI) parseSets(sets []string) (domain.TaskPatch, error) {
+	var patch domain.TaskPatch
+
+	for _, expr := range sets {
+		field, value, ok := strings.Cut(expr, "=")
+		if !ok {
+			return patch, fmt.Errorf("invalid --set %q (expected field=value)", expr)
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "priority":
+			p := domain.TaskPriority(strings.ToLower(value))
+			if p != domain.TaskPriorityLow && p != domain.TaskPriorityMedium && p != domain.TaskPriorityHigh {
+				return patch, fmt.Errorf("invalid priority: %s (must be low, medium, or high)", value)
+			}
+			patch.Priority = &p
+		case "status":
+			st := domain.TaskStatus(strings.ToLower(value))
+			if st != domain.TaskStatusPending && st != domain.TaskStatusCompleted {
+				return patch, fmt.Errorf("invalid status: %s (must be pending or completed)", value)
+			}
+			patch.Status = &st
+		case "context":
+			name := domain.NormalizeContext(value)
+			patch.Context = &name
+		case "assignee":
+			name := domain.NormalizeUserName(value)
+			patch.Assignee = &name
+		case "description":
+			patch.Description = &value
+		case "due":
+			day, err := parseDueDate(value, time.Now())
+			if err != nil {
+				return patch, err
+			}
+			due := ""
+			if day != nil {
+				due = day.Format(time.DateOnly)
+			}
+			patch.Due = &due
+		default:
+			def, ok := c.fieldDef(field)
+			if !ok {
+				settable := append([]string{"priority", "status", "context", "assignee", "description", "due"}, c.fieldNames()...)
+				return patch, fmt.Errorf("unknown field %q (settable: %s)", field, strings.Join(settable, ", "))
+			}
+			normalized, err := def.Normalize(value)
+			if err != nil {
+				return patch, err
+			}
+			if patch.Fields == nil {
+				patch.Fields = make(map[string]string)
+			}
+			patch.Fields[field] = normalized
+		}
+	}
+
+	return patch, nil
+}
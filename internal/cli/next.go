@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/plan"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// nextCmd creates the next command
+func (c *CLI) nextCmd() *cobra.Command {
+	var taskContext string
+	var all bool
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "next",
+		Short: "Show the tasks to work on next",
+		Long: `Show the next actions: the pending tasks that are not snoozed, due soonest
+first, then pinned tasks, then by priority, then oldest first, in the same
+order as "task plan". Like list, next only shows the active context (see
+"task context set") unless --context or --all is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit <= 0 {
+				return fmt.Errorf("invalid limit: %d (must be positive)", limit)
+			}
+
+			now := time.Now()
+			pending := domain.TaskStatusPending
+			filter := domain.TaskFilter{Status: &pending, AwakeAt: &now}
+			var active string
+			if taskContext != "" {
+				name := domain.NormalizeContext(taskContext)
+				filter.Context = &name
+			} else if !all {
+				var err error
+				active, err = c.activeContext()
+				if err != nil {
+					return err
+				}
+				if active != "" {
+					filter.Context = &active
+				}
+			}
+
+			tasks, err := c.service.ListTasks(cmd.Context(), filter)
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+			if active != "" {
+				fmt.Println(c.t("Context: @%s (use --all to show every task)", active))
+			}
+			if len(tasks) == 0 {
+				fmt.Println(c.t("No tasks found."))
+				return nil
+			}
+			plan.Order(tasks)
+			more := len(tasks) - limit
+			tasks = tasks[:min(limit, len(tasks))]
+
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+			times, err := c.timeFormat()
+			if err != nil {
+				return err
+			}
+			table := ui.TaskTable(painter, tasks, ui.TaskTableOptions{
+				Now:   now,
+				Times: times,
+				Width: ui.TerminalWidth(c.terminal()),
+			})
+			if err := table.Render(os.Stdout); err != nil {
+				return err
+			}
+
+			if more > 0 {
+				fmt.Printf("\n%d more pending task(s) (see \"task list\")\n", more)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only include tasks in this context")
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Ignore the active context")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 5, "Number of tasks to show")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+
+	return cmd
+}
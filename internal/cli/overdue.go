@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/notify"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// overdueCmd creates the overdue command
+func (c *CLI) overdueCmd() *cobra.Command {
+	var taskContext string
+	var notifyDesktop bool
+
+	cmd := &cobra.Command{
+		Use:   "overdue",
+		Short: "List overdue tasks and publish task.overdue events",
+		Long: `List the pending tasks past their due date and publish a task.overdue event
+for each, so that subscribers such as the webhooks in the config file hear
+about them. Due dates are set with --due, or come from integrations such as
+todoist.due or google.due in the task metadata; snoozed tasks are left out.
+Run it from cron to be reminded daily, e.g.
+
+  0 9 * * *  task overdue
+
+With --notify a notification also lists them on the desktop (see
+notifications.backend in the config file). With --dry-run no events are
+published and nothing is shown.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := domain.TaskFilter{}
+			if taskContext != "" {
+				name := domain.NormalizeContext(taskContext)
+				filter.Context = &name
+			}
+
+			now := time.Now()
+			tasks, err := c.service.PublishOverdue(cmd.Context(), filter, now)
+			if err != nil {
+				return err
+			}
+			if len(tasks) == 0 {
+				fmt.Println("No overdue tasks")
+				return nil
+			}
+
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+			table := ui.NewTable(painter, "ID", "DUE", "PRIORITY", "TITLE")
+			for _, task := range tasks {
+				due, _ := task.DueDate()
+				table.AddRow("",
+					ui.Cell{Text: task.ID[:8], Role: ui.RoleID},
+					ui.Cell{Text: due.Format(time.DateOnly), Role: ui.RoleOverdue},
+					ui.Cell{Text: string(task.Priority), Role: ui.PriorityRole(task.Priority)},
+					ui.Cell{Text: task.Title},
+				)
+			}
+			if err := table.Render(os.Stdout); err != nil {
+				return err
+			}
+
+			fmt.Printf("\n%d overdue task(s)\n", len(tasks))
+
+			if notifyDesktop && !c.dryRun {
+				notifier, err := c.notifier()
+				if err != nil {
+					return err
+				}
+				titles := make([]string, len(tasks))
+				for i, task := range tasks {
+					titles[i] = task.Title
+				}
+				n := notify.Notification{Title: fmt.Sprintf("%d overdue task(s)", len(tasks)), Body: strings.Join(titles, "\n")}
+				if err := notifier.Notify(cmd.Context(), n); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only include tasks in this context")
+	cmd.Flags().BoolVar(&notifyDesktop, "notify", false, "Also show a notification listing the overdue tasks")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+
+	return cmd
+}
+
+// notifier returns the notifier selected by notifications.backend
+func (c *CLI) notifier() (notify.Notifier, error) {
+	backend := ""
+	if c.config != nil {
+		backend = c.config.Notify.Backend
+	}
+	notifier, err := notify.New(backend, c.logger)
+	if err != nil {
+		return nil, &configError{err}
+	}
+	return notifier, nil
+}
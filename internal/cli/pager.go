@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// annotationPager marks commands whose output is shown through a pager
+const annotationPager = "pager"
+
+// defaultPager is used when neither display.pager nor PAGER is set
+const defaultPager = "less"
+
+// pager is a running pager process reading what the command writes to stdout
+type pager struct {
+	cmd    *exec.Cmd
+	w      *os.File
+	stdout *os.File // the terminal, restored as os.Stdout by stop
+}
+
+// pagerCommand returns the pager to use, from TASK_PAGER, display.pager, or
+// PAGER, in that order. As with git, "cat" means no pager. display.pager
+// comes from the user's own config file, as project files may not set it.
+func (c *CLI) pagerCommand() string {
+	command := os.Getenv("TASK_PAGER")
+	if command == "" && c.config != nil {
+		command = c.config.Display.Pager
+	}
+	if command == "" {
+		command = os.Getenv("PAGER")
+	}
+	if command == "" {
+		command = defaultPager
+	}
+	return command
+}
+
+// startPager starts the pager for cmd and redirects os.Stdout to it, when
+// cmd is marked with annotationPager, stdout is a terminal, and paging is not
+// disabled by --no-pager or a pager of "cat". less quits at once when the
+// output fits on one screen (LESS defaults to FRX, as in git), so short
+// output appears as if no pager were used.
+func (c *CLI) startPager(cmd *cobra.Command) error {
+	if c.noPager || cmd.Annotations[annotationPager] == "" || !ui.IsTerminal(os.Stdout) {
+		return nil
+	}
+	// A live listing redraws the screen itself
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		return nil
+	}
+
+	args, err := splitArgs(c.pagerCommand())
+	if err != nil {
+		return &configError{fmt.Errorf("invalid pager: %w", err)}
+	}
+	if len(args) == 0 || args[0] == "cat" {
+		return nil
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to start pager: %w", err)
+	}
+	process := exec.Command(args[0], args[1:]...)
+	process.Stdin = r
+	process.Stdout = os.Stdout
+	process.Stderr = os.Stderr
+	process.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		process.Env = append(process.Env, "LESS=FRX")
+	}
+	if err := process.Start(); err != nil {
+		r.Close()
+		w.Close()
+		// Without a working pager, print directly
+		c.logger.Debug("Failed to start pager", "pager", args[0], "error", err)
+		return nil
+	}
+	r.Close()
+
+	c.pager = &pager{cmd: process, w: w, stdout: os.Stdout}
+	os.Stdout = w
+	return nil
+}
+
+// stopPager waits for the pager to show everything written and for the user
+// to quit it, then restores os.Stdout
+func (c *CLI) stopPager() {
+	if c.pager == nil {
+		return
+	}
+	os.Stdout = c.pager.stdout
+	c.pager.w.Close()
+	if err := c.pager.cmd.Wait(); err != nil {
+		c.logger.Debug("Pager exited with an error", "error", err)
+	}
+	c.pager = nil
+}
+
+// terminal returns the file where output finally appears: the terminal
+// while paging, otherwise stdout
+func (c *CLI) terminal() *os.File {
+	if c.pager != nil {
+		return c.pager.stdout
+	}
+	return os.Stdout
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// pinCmd creates the pin command, or the unpin command when pin is false
+func (c *CLI) pinCmd(pin bool) *cobra.Command {
+	use, short, done := "pin", "Pin a task to the top of list", "✓ Task %s pinned"
+	if !pin {
+		use, short, done = "unpin", "Unpin a task", "✓ Task %s unpinned"
+	}
+
+	cmd := &cobra.Command{
+		Use:   use + " [task-id]",
+		Short: short,
+		Long: `Pinned tasks always sort to the top of list, marked with ` + ui.PinMarker + `, ahead of the
+usual newest-first order. Use "task list --pinned" to show only pinned tasks.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: c.taskIDCompletion(nil),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			task, err := c.service.UpdateTask(cmd.Context(), args[0], "", "", "", domain.WithPinned(pin))
+			if err != nil {
+				return fmt.Errorf("failed to %s task: %w", use, err)
+			}
+
+			fmt.Println(c.t(done, task.ID[:8]))
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/plan"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// planCmd creates the plan command
+func (c *CLI) planCmd() *cobra.Command {
+	var hoursPerDay float64
+	var days int
+	var weekends bool
+	var taskContext string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Propose a schedule for the coming week from estimates",
+		Long: `Lay the pending tasks out over the coming days, today first, so that each
+day holds at most --hours-per-day of estimated work (see "task add
+--estimate"). Tasks due soonest come first, then pinned tasks, then by
+priority. A task is never planned after its due date: when the days before
+are full, its due day goes over capacity and is flagged. Tasks longer than a
+day are split, snoozed tasks wait until they wake, and work that does not
+fit is listed at the end. Weekends are skipped unless --weekends is given.
+
+Nothing is changed; the plan is a proposal. --output ics writes it as an
+iCalendar file of all-day events, e.g.
+
+  task plan --output ics > plan.ics`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "ics" {
+				return fmt.Errorf("invalid output: %s (must be table or ics)", output)
+			}
+			if hoursPerDay <= 0 || hoursPerDay > 24 {
+				return fmt.Errorf("invalid hours per day: %g (must be more than 0 and at most 24)", hoursPerDay)
+			}
+			if days <= 0 {
+				return fmt.Errorf("invalid days: %d (must be positive)", days)
+			}
+
+			pending := domain.TaskStatusPending
+			filter := domain.TaskFilter{Status: &pending}
+			if taskContext != "" {
+				name := domain.NormalizeContext(taskContext)
+				filter.Context = &name
+			}
+			tasks, err := c.service.ListTasks(cmd.Context(), filter)
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+
+			now := time.Now()
+			p := plan.Schedule(tasks, plan.Options{
+				Start:    now,
+				Days:     days,
+				Capacity: time.Duration(hoursPerDay * float64(time.Hour)),
+				Weekends: weekends,
+			})
+
+			if output == "ics" {
+				return plan.WriteICS(os.Stdout, p, now)
+			}
+			return c.printPlan(p)
+		},
+	}
+
+	cmd.Flags().Float64Var(&hoursPerDay, "hours-per-day", 6, "Hours of work available each day")
+	cmd.Flags().IntVar(&days, "days", 7, "Number of days to plan, starting today")
+	cmd.Flags().BoolVar(&weekends, "weekends", false, "Plan Saturdays and Sundays too")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only plan tasks in this context")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, ics)")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+	_ = cmd.RegisterFlagCompletionFunc("output", fixedCompletion("table", "ics"))
+
+	return cmd
+}
+
+// printPlan prints a plan as a table with one section per day, followed by
+// the work that did not fit
+func (c *CLI) printPlan(p *plan.Plan) error {
+	painter, err := c.painter()
+	if err != nil {
+		return err
+	}
+	times, err := c.timeFormat()
+	if err != nil {
+		return err
+	}
+
+	capacity := ui.Duration(p.Capacity)
+	table := ui.NewTable(painter, "DAY", "LOAD", "ID", "TITLE", "TIME")
+	var over []string
+	for _, day := range p.Days {
+		name := day.Date.Format("Mon") + " " + times.Day(day.Date)
+		load := ui.Cell{Text: orZero(ui.Duration(day.Load)) + "/" + capacity}
+		if p.Over(day) {
+			load.Role = ui.RoleOverdue
+			load.Text += " over"
+			over = append(over, name)
+		}
+
+		if len(day.Entries) == 0 {
+			table.AddRow("", ui.Cell{Text: name}, load, ui.Cell{Text: "-"}, ui.Cell{}, ui.Cell{})
+			continue
+		}
+		for i, entry := range day.Entries {
+			first := []ui.Cell{{Text: name}, load}
+			if i > 0 {
+				first = []ui.Cell{{}, {}}
+			}
+			title := ui.Cell{Text: entry.Task.Title}
+			if entry.Due {
+				title.Text += " (due)"
+			}
+			table.AddRow("", append(first,
+				ui.Cell{Text: entry.Task.ID[:8], Role: ui.RoleID},
+				title,
+				ui.Cell{Text: ui.Duration(entry.Duration)},
+			)...)
+		}
+	}
+	if err := table.Render(os.Stdout); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nPlanned %s over %d day(s)\n", orZero(ui.Duration(p.Load())), len(p.Days))
+	if len(over) > 0 {
+		fmt.Println(painter.Paint(ui.RoleOverdue, fmt.Sprintf("Over capacity: %s", strings.Join(over, ", "))))
+	}
+
+	if len(p.Unplanned) > 0 {
+		var rest time.Duration
+		for _, entry := range p.Unplanned {
+			rest += entry.Duration
+		}
+		fmt.Printf("\nDoes not fit (%s):\n", ui.Duration(rest))
+		for _, entry := range p.Unplanned {
+			fmt.Printf("  %s  %s (%s)\n", painter.Paint(ui.RoleID, entry.Task.ID[:8]), entry.Task.Title, ui.Duration(entry.Duration))
+		}
+	}
+	if n := len(p.Unestimated); n > 0 {
+		fmt.Printf("\n%d task(s) without an estimate left out (set one with \"task update --estimate\")\n", n)
+	}
+	return nil
+}
+
+// orZero returns text, or "0h" when it is empty
+func orZero(text string) string {
+	if text == "" {
+		return "0h"
+	}
+	return text
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/edson-mazvila/task-manager/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+// pluginError reports a plugin that exited with a non-zero status; task
+// exits with the same status
+type pluginError struct {
+	name string
+	code int
+}
+
+// Error implements the error interface
+func (e *pluginError) Error() string {
+	return fmt.Sprintf("plugin %s exited with status %d", e.name, e.code)
+}
+
+// findPlugin returns the name and executable of the plugin args run, git
+// style: "task foo ..." runs task-foo from PATH when foo is not a command.
+// Flags before the plugin name are not supported.
+func findPlugin(root *cobra.Command, args []string) (string, string) {
+	if len(args) == 0 {
+		return "", ""
+	}
+	name := args[0]
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "__") ||
+		strings.ContainsAny(name, `/\`) || builtin(root, name) {
+		return "", ""
+	}
+
+	path, err := exec.LookPath(plugin.Prefix + name)
+	if err != nil {
+		return "", ""
+	}
+	return name, path
+}
+
+// runPlugin runs a plugin executable with args, attached to the terminal,
+// and the environment described in package plugin
+func (c *CLI) runPlugin(ctx context.Context, name, path string, args []string) error {
+	env := plugin.Env{
+		Plugin:     name,
+		ConfigFile: config.FilePath(),
+	}
+	if self, err := os.Executable(); err == nil {
+		env.Binary = self
+	}
+	if c.config != nil {
+		env.DatabaseType = c.config.Database.Type
+		if c.config.Database.Type == "sqlite" {
+			env.DatabasePath = c.config.Database.Path
+		}
+	}
+	if active, err := c.activeContext(); err == nil {
+		env.Context = active
+	}
+
+	c.logger.Debug("Running plugin", "plugin", name, "path", path)
+
+	// Not bound to ctx: the plugin gets Ctrl-C from the terminal itself
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), env.Vars()...)
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &pluginError{name: name, code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", name, err)
+	}
+	return nil
+}
+
+// pluginsCmd creates the plugins command
+func (c *CLI) pluginsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "plugins",
+		Short: "List the plugins found on PATH",
+		Long: `List the task-* executables on PATH. Running "task foo" runs task-foo
+when foo is not a built-in command, passing the remaining arguments, with
+TASK_BIN, TASK_CONFIG_FILE, TASK_DB_TYPE, TASK_DB_PATH, and TASK_CONTEXT
+set (see package pkg/plugin). The first executable of a name on PATH wins.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			root := cmd.Root()
+			table := ui.NewTable(painter, "PLUGIN", "PATH")
+			var seen []string
+			for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+				matches, _ := filepath.Glob(filepath.Join(dir, plugin.Prefix+"*"))
+				for _, path := range matches {
+					name := strings.TrimPrefix(filepath.Base(path), plugin.Prefix)
+					if slices.Contains(seen, name) || builtin(root, name) {
+						continue
+					}
+					if _, err := exec.LookPath(path); err != nil {
+						continue
+					}
+					seen = append(seen, name)
+					table.AddRow("", ui.Cell{Text: name}, ui.Cell{Text: path})
+				}
+			}
+
+			if len(seen) == 0 {
+				fmt.Println("No plugins found on PATH.")
+				return nil
+			}
+			return table.Render(os.Stdout)
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// input returns the shared buffered reader over stdin. A single reader is
+// reused so that consecutive prompts do not lose buffered input.
+func (c *CLI) input() *bufio.Reader {
+	if c.stdin == nil {
+		c.stdin = bufio.NewReader(os.Stdin)
+	}
+	return c.stdin
+}
+
+// ask prints question and returns the trimmed line typed by the user
+func (c *CLI) ask(question string) (string, error) {
+	fmt.Fprintf(os.Stdout, "%s: ", question)
+
+	answer, err := c.input().ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(os.Stdout)
+		return "", err
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
+// confirm asks a yes/no question on stdin and reports whether the user answered yes.
+// Anything other than "y" or "yes", or their translations, (including EOF) counts as no.
+func (c *CLI) confirm(question string) bool {
+	answer, err := c.ask(question + " " + c.t("[y/N]"))
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes", c.t("y"), c.t("yes"):
+		return true
+	default:
+		return false
+	}
+}
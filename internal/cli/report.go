@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// reportCmd creates the report command grouping the summary reports
+func (c *CLI) reportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summary reports for dashboards",
+		Long:  `Summary reports of tasks, as a table or as CSV or JSON for dashboards.`,
+	}
+
+	cmd.AddCommand(c.reportProjectsCmd())
+
+	return cmd
+}
+
+// reportProjectsCmd creates the report projects command
+func (c *CLI) reportProjectsCmd() *cobra.Command {
+	opts := &listOptions{}
+	var output string
+
+	cmd := &cobra.Command{
+		Use:     "projects",
+		Aliases: []string{"contexts"},
+		Short:   "Show open, completed and overdue tasks and estimates per project",
+		Long: `Show for each project (the context of its tasks, as set by a project's
+.task.yaml or with --context) how many tasks are open, completed and overdue,
+and the estimates and points remaining and completed. Accepts the same filters
+as list, except that the active context is ignored.
+
+Overdue tasks are open tasks past their due date, set with --due or
+imported from another task manager.`,
+		Example: `  task report projects
+  task report projects --from 2026-01-01 --output csv
+  task report projects --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "csv" && output != "json" {
+				return fmt.Errorf("invalid output format: %s (must be table, csv, or json)", output)
+			}
+
+			opts.all = true
+			filter, _, err := c.buildFilter(opts)
+			if err != nil {
+				return err
+			}
+
+			reports, err := c.service.ReportByContext(cmd.Context(), filter, time.Now())
+			if err != nil {
+				return err
+			}
+
+			switch output {
+			case "csv":
+				return renderReportCSV(os.Stdout, reports)
+			case "json":
+				return renderReportJSON(os.Stdout, reports)
+			}
+
+			if len(reports) == 0 {
+				fmt.Println("No tasks found.")
+				return nil
+			}
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			var total domain.ContextReport
+			table := ui.NewTable(painter, "PROJECT", "OPEN", "OVERDUE", "COMPLETED", "REMAINING", "DONE")
+			for _, r := range reports {
+				overdue := ui.Cell{Text: strconv.FormatInt(r.Overdue, 10)}
+				if r.Overdue > 0 {
+					overdue.Role = ui.RoleOverdue
+				}
+				table.AddRow("",
+					ui.Cell{Text: projectLabel(r.Context)},
+					ui.Cell{Text: strconv.FormatInt(r.Open.Tasks, 10)},
+					overdue,
+					ui.Cell{Text: strconv.FormatInt(r.Completed.Tasks, 10), Role: ui.RoleCompleted},
+					ui.Cell{Text: ui.Effort(r.Open.Estimate, r.Open.Points)},
+					ui.Cell{Text: ui.Effort(r.Completed.Estimate, r.Completed.Points), Role: ui.RoleCompleted},
+				)
+				total.Open.Tasks += r.Open.Tasks
+				total.Completed.Tasks += r.Completed.Tasks
+				total.Overdue += r.Overdue
+			}
+			if err := table.Render(os.Stdout); err != nil {
+				return err
+			}
+
+			fmt.Printf("\nTotal: %d open (%d overdue), %d completed\n", total.Open.Tasks, total.Overdue, total.Completed.Tasks)
+			return nil
+		},
+	}
+
+	c.addFilterFlags(cmd, opts)
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, csv, json)")
+	_ = cmd.RegisterFlagCompletionFunc("output", fixedCompletion("table", "csv", "json"))
+
+	return cmd
+}
+
+// projectLabel shows a context as a project name
+func projectLabel(name string) string {
+	if name == "" {
+		return "(none)"
+	}
+	return "@" + name
+}
+
+// reportRecord is the JSON form of a domain.ContextReport; estimates are
+// formatted like those of list
+type reportRecord struct {
+	Project           string `json:"project"`
+	Open              int64  `json:"open"`
+	Overdue           int64  `json:"overdue"`
+	Completed         int64  `json:"completed"`
+	OpenEstimate      string `json:"open_estimate,omitempty"`
+	OpenPoints        int64  `json:"open_points,omitempty"`
+	CompletedEstimate string `json:"completed_estimate,omitempty"`
+	CompletedPoints   int64  `json:"completed_points,omitempty"`
+}
+
+// reportRecords converts reports to their JSON and CSV form
+func reportRecords(reports []*domain.ContextReport) []reportRecord {
+	records := make([]reportRecord, 0, len(reports))
+	for _, r := range reports {
+		records = append(records, reportRecord{
+			Project:           r.Context,
+			Open:              r.Open.Tasks,
+			Overdue:           r.Overdue,
+			Completed:         r.Completed.Tasks,
+			OpenEstimate:      ui.Duration(r.Open.Estimate),
+			OpenPoints:        r.Open.Points,
+			CompletedEstimate: ui.Duration(r.Completed.Estimate),
+			CompletedPoints:   r.Completed.Points,
+		})
+	}
+	return records
+}
+
+// renderReportJSON writes reports as an indented JSON array
+func renderReportJSON(w io.Writer, reports []*domain.ContextReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reportRecords(reports))
+}
+
+// renderReportCSV writes reports as CSV with a header row
+func renderReportCSV(w io.Writer, reports []*domain.ContextReport) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"project", "open", "overdue", "completed", "open_estimate", "open_points", "completed_estimate", "completed_points"}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, r := range reportRecords(reports) {
+		record := []string{
+			r.Project,
+			strconv.FormatInt(r.Open, 10),
+			strconv.FormatInt(r.Overdue, 10),
+			strconv.FormatInt(r.Completed, 10),
+			r.OpenEstimate,
+			strconv.FormatInt(r.OpenPoints, 10),
+			r.CompletedEstimate,
+			strconv.FormatInt(r.CompletedPoints, 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
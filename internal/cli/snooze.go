@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// snoozeCmd creates the snooze command
+func (c *CLI) snoozeCmd() *cobra.Command {
+	pending := domain.TaskStatusPending
+	var wake bool
+
+	cmd := &cobra.Command{
+		Use:   "snooze [task-id] [duration|date]",
+		Short: "Hide a task from list until later",
+		Long: `Snooze a pending task so that list hides it until the given time, like a GTD
+tickler file. The time is a duration from now (30m, 4h, 3d, 2w), a date
+(YYYY-MM-DD, meaning the start of that day), or "tomorrow". Once the time
+passes the task shows up again by itself. "task list --all" shows snoozed
+tasks too, and --wake un-snoozes a task right away.`,
+		Example: `  task snooze 3f2a 3d
+  task snooze 3f2a 2026-11-01
+  task snooze 3f2a --wake`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: c.taskIDCompletion(&pending),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if wake == (len(args) == 2) {
+				return fmt.Errorf("give either a duration or date, or --wake")
+			}
+
+			var until *time.Time
+			if !wake {
+				t, err := parseWakeTime(args[1], time.Now())
+				if err != nil {
+					return err
+				}
+				until = &t
+			}
+
+			ctx := cmd.Context()
+			task, err := c.service.GetTask(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to snooze task: %w", err)
+			}
+			if task.Status != domain.TaskStatusPending && until != nil {
+				return fmt.Errorf("task %s is %s; only pending tasks can be snoozed", task.ID[:8], task.Status)
+			}
+
+			task, err = c.service.UpdateTask(ctx, task.ID, "", "", "", domain.WithWaitUntil(until))
+			if err != nil {
+				return fmt.Errorf("failed to snooze task: %w", err)
+			}
+
+			if until == nil {
+				fmt.Println(c.t("✓ Task %s is awake", task.ID[:8]))
+				return nil
+			}
+			times, err := c.timeFormat()
+			if err != nil {
+				return err
+			}
+			fmt.Println(c.t("✓ Task %s snoozed until %s", task.ID[:8], times.In(*until).Format("Mon ")+times.DateTime(*until)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&wake, "wake", false, "Un-snooze the task now")
+
+	return cmd
+}
+
+// parseWakeTime parses when a snoozed task wakes: a duration from now
+// (accepting days and weeks), a YYYY-MM-DD date, or "tomorrow"
+func parseWakeTime(s string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if strings.EqualFold(s, "tomorrow") {
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if day, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		if !day.After(now) {
+			return time.Time{}, fmt.Errorf("invalid date: %s is not in the future", s)
+		}
+		return day, nil
+	}
+
+	d, err := parseAge(s)
+	if err != nil || d == 0 {
+		return time.Time{}, fmt.Errorf("invalid snooze time: %s (use e.g. 4h, 3d, 2w, 2026-11-01, or tomorrow)", s)
+	}
+	return now.Add(d), nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// staleCmd creates the stale command
+func (c *CLI) staleCmd() *cobra.Command {
+	var days int
+	var taskContext string
+
+	cmd := &cobra.Command{
+		Use:   "stale",
+		Short: "List pending tasks without recent activity",
+		Long: `List the pending tasks that nobody has touched for --days days, oldest
+activity first. Activity is any change to a task, and comments and links
+added to it; snoozed tasks are left out. Close, update, or snooze them to
+keep the backlog honest.
+
+--days defaults to display.stale_days in the config file, or 30. Setting
+display.stale_days also makes "task list" count the stale tasks it shows.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("days") {
+				days = c.staleDays()
+			}
+			if days <= 0 {
+				return fmt.Errorf("invalid days: %d (must be positive)", days)
+			}
+
+			now := time.Now()
+			idleSince := now.AddDate(0, 0, -days)
+			pending := domain.TaskStatusPending
+			filter := domain.TaskFilter{Status: &pending, AwakeAt: &now, IdleSince: &idleSince}
+			if taskContext != "" {
+				name := domain.NormalizeContext(taskContext)
+				filter.Context = &name
+			}
+
+			tasks, err := c.service.ListTasks(cmd.Context(), filter)
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+			if len(tasks) == 0 {
+				fmt.Printf("No tasks idle for %d days\n", days)
+				return nil
+			}
+			sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].LastActivityAt.Before(tasks[j].LastActivityAt) })
+
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+			table := ui.NewTable(painter, "ID", "LAST ACTIVITY", "PRIORITY", "TITLE")
+			for _, task := range tasks {
+				table.AddRow("",
+					ui.Cell{Text: task.ID[:8], Role: ui.RoleID},
+					ui.Cell{Text: ui.RelativeTime(task.LastActivityAt, now), Role: ui.RoleOverdue},
+					ui.Cell{Text: string(task.Priority), Role: ui.PriorityRole(task.Priority)},
+					ui.Cell{Text: task.Title},
+				)
+			}
+			if err := table.Render(os.Stdout); err != nil {
+				return err
+			}
+
+			fmt.Printf("\n%d stale task(s)\n", len(tasks))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", domain.DefaultStaleDays, "Days without activity that make a task stale")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only include tasks in this context")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+
+	return cmd
+}
+
+// staleDays returns display.stale_days, or DefaultStaleDays when unset
+func (c *CLI) staleDays() int {
+	if c.config != nil && c.config.Display.StaleDays > 0 {
+		return c.config.Display.StaleDays
+	}
+	return domain.DefaultStaleDays
+}
+
+// printStaleCount prints how many of the listed tasks are stale, under a
+// task list, when display.stale_days is set
+func (c *CLI) printStaleCount(tasks []*domain.Task, now time.Time) {
+	if c.config == nil || c.config.Display.StaleDays <= 0 {
+		return
+	}
+
+	stale := 0
+	for _, task := range tasks {
+		if task.Stale(now, c.config.Display.StaleDays) {
+			stale++
+		}
+	}
+	if stale > 0 {
+		fmt.Println(c.t("%d task(s) are stale (see \"task stale\")", stale))
+	}
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/digest"
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// standupCmd creates the standup command
+func (c *CLI) standupCmd() *cobra.Command {
+	var format, taskContext string
+	var since time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "standup",
+		Short: "Print a standup update ready to paste",
+		Long: `Print the tasks completed in the last --since (24h by default) under
+"Yesterday", and the pending tasks that are overdue, due today, or pinned under
+"Today", in Markdown or in Slack's message format. Due dates are set with
+--due, or come from integrations such as todoist.due in the task metadata.`,
+		Example: `  task standup
+  task standup --since 72h --format slack
+  task standup --context work | pbcopy`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := domain.TaskFilter{}
+			if taskContext != "" {
+				name := domain.NormalizeContext(taskContext)
+				filter.Context = &name
+			}
+
+			d, err := digest.Build(cmd.Context(), c.service, filter, time.Now(), since)
+			if err != nil {
+				return err
+			}
+			out, err := d.Standup(digest.StandupFormat(format))
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", string(digest.StandupMarkdown), "Output format: markdown or slack")
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "Include tasks completed within this long")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only include tasks in this context")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{string(digest.StandupMarkdown), string(digest.StandupSlack)}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+
+	return cmd
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd creates the stats command summarizing task counts
+func (c *CLI) statsCmd() *cobra.Command {
+	opts := &listOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show task counts by status and priority",
+		Long: `Show how many tasks there are by status and by priority. Accepts the same
+filters as list, including the active context; counting is done in the database
+without loading the tasks.
+
+When tasks have estimates, the remaining and completed estimates and points are
+also summed per context.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, active, err := c.buildFilter(opts)
+			if err != nil {
+				return err
+			}
+			if active != "" {
+				fmt.Printf("Context: @%s (use --all to count every task)\n\n", active)
+			}
+
+			ctx := cmd.Context()
+			byStatus, err := c.service.CountTasksByStatus(ctx, filter)
+			if err != nil {
+				return err
+			}
+			byPriority, err := c.service.CountTasksByPriority(ctx, filter)
+			if err != nil {
+				return err
+			}
+
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			var total int64
+			table := ui.NewTable(painter, "STATUS", "TASKS")
+			for _, status := range []domain.TaskStatus{domain.TaskStatusPending, domain.TaskStatusCompleted} {
+				total += byStatus[status]
+				table.AddRow("", ui.Cell{Text: string(status)}, ui.Cell{Text: strconv.FormatInt(byStatus[status], 10)})
+			}
+			if err := table.Render(os.Stdout); err != nil {
+				return err
+			}
+
+			fmt.Println()
+			table = ui.NewTable(painter, "PRIORITY", "TASKS")
+			for _, priority := range []domain.TaskPriority{domain.TaskPriorityHigh, domain.TaskPriorityMedium, domain.TaskPriorityLow} {
+				table.AddRow("",
+					ui.Cell{Text: string(priority), Role: ui.PriorityRole(priority)},
+					ui.Cell{Text: strconv.FormatInt(byPriority[priority], 10)},
+				)
+			}
+			if err := table.Render(os.Stdout); err != nil {
+				return err
+			}
+
+			fmt.Printf("\nTotal: %d task(s)\n", total)
+
+			return c.printEffort(cmd.Context(), painter, filter)
+		},
+	}
+
+	c.addFilterFlags(cmd, opts)
+
+	return cmd
+}
+
+// printEffort prints the estimates and points of the pending and completed
+// tasks matching filter per context, if any task has an estimate
+func (c *CLI) printEffort(ctx context.Context, painter *ui.Painter, filter domain.TaskFilter) error {
+	efforts := make(map[domain.TaskStatus]map[string]domain.Effort)
+	for _, status := range []domain.TaskStatus{domain.TaskStatusPending, domain.TaskStatusCompleted} {
+		if filter.Status != nil && *filter.Status != status {
+			continue
+		}
+		f := filter
+		f.Status = &status
+		effort, err := c.service.EffortByContext(ctx, f)
+		if err != nil {
+			return err
+		}
+		efforts[status] = effort
+	}
+
+	var names []string
+	var totals [2]domain.Effort
+	for i, status := range []domain.TaskStatus{domain.TaskStatusPending, domain.TaskStatusCompleted} {
+		for name, effort := range efforts[status] {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+			totals[i].Tasks += effort.Tasks
+			totals[i].Estimate += effort.Estimate
+			totals[i].Points += effort.Points
+		}
+	}
+	if totals[0].Estimate == 0 && totals[0].Points == 0 && totals[1].Estimate == 0 && totals[1].Points == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	fmt.Println()
+	table := ui.NewTable(painter, "CONTEXT", "PENDING", "REMAINING", "COMPLETED")
+	for _, name := range names {
+		pending := efforts[domain.TaskStatusPending][name]
+		completed := efforts[domain.TaskStatusCompleted][name]
+		label := "(none)"
+		if name != "" {
+			label = "@" + name
+		}
+		table.AddRow("",
+			ui.Cell{Text: label},
+			ui.Cell{Text: strconv.FormatInt(pending.Tasks, 10)},
+			ui.Cell{Text: ui.Effort(pending.Estimate, pending.Points)},
+			ui.Cell{Text: ui.Effort(completed.Estimate, completed.Points), Role: ui.RoleCompleted},
+		)
+	}
+	if err := table.Render(os.Stdout); err != nil {
+		return err
+	}
+
+	remaining := ui.Effort(totals[0].Estimate, totals[0].Points)
+	done := ui.Effort(totals[1].Estimate, totals[1].Points)
+	fmt.Printf("\nEstimate: %s remaining, %s completed\n", cmp.Or(remaining, "none"), cmp.Or(done, "none"))
+	return nil
+}
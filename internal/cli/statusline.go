@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// defaultPromptFormat prints e.g. "3! 5": overdue, then due today, leaving
+// out zero counts
+const defaultPromptFormat = `{{if .Overdue}}{{.Overdue}}!{{end}}{{if and .Overdue .Today}} {{end}}{{if .Today}}{{.Today}}{{end}}`
+
+// promptCounts are the values available to prompt --format templates
+type promptCounts struct {
+	Overdue int64 // pending tasks due before today
+	Today   int64 // pending tasks due today
+	Pending int64 // all pending tasks
+}
+
+// promptCmd creates the prompt command
+func (c *CLI) promptCmd() *cobra.Command {
+	var format, taskContext string
+
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Print a one-line task summary for shell prompts and status bars",
+		Long: `Print a compact summary of pending tasks on one line, e.g. "3! 5" for 3
+overdue and 5 due today, or nothing when neither. Only counts are queried, so
+it is fast enough to run on every shell prompt or status bar refresh. Snoozed
+tasks are left out; due dates are set with --due, or come from integrations
+such as todoist.due in the task metadata.
+
+--format takes a Go template with the fields .Overdue, .Today, and .Pending.`,
+		Example: `  PS1='$(task prompt) \$ '
+  set -g status-right '#(task prompt --format "{{.Overdue}}!/{{.Pending}}")'
+  task prompt --context work`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tmpl, err := template.New("prompt").Parse(format)
+			if err != nil {
+				return fmt.Errorf("invalid format template: %w", err)
+			}
+
+			now := time.Now()
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+			tomorrow := today.AddDate(0, 0, 1)
+			pending := domain.TaskStatusPending
+			filter := domain.TaskFilter{Status: &pending, AwakeAt: &now}
+			if taskContext != "" {
+				name := domain.NormalizeContext(taskContext)
+				filter.Context = &name
+			}
+
+			var counts promptCounts
+			if counts.Pending, err = c.service.CountTasks(cmd.Context(), filter); err != nil {
+				return err
+			}
+			filter.DueBefore = &today
+			if counts.Overdue, err = c.service.CountTasks(cmd.Context(), filter); err != nil {
+				return err
+			}
+			filter.DueBefore = &tomorrow
+			dueByTomorrow, err := c.service.CountTasks(cmd.Context(), filter)
+			if err != nil {
+				return err
+			}
+			counts.Today = dueByTomorrow - counts.Overdue
+
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, counts); err != nil {
+				return fmt.Errorf("failed to render prompt: %w", err)
+			}
+			fmt.Println(strings.TrimRight(sb.String(), "\n"))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", defaultPromptFormat, "Go template for the summary")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only count tasks in this context")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+
+	return cmd
+}
@@ -0,0 +1,396 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/dbsync"
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/encryption"
+	"github.com/edson-mazvila/task-manager/internal/integrations/github"
+	"github.com/edson-mazvila/task-manager/internal/integrations/google"
+	"github.com/edson-mazvila/task-manager/internal/integrations/linking"
+	"github.com/edson-mazvila/task-manager/internal/integrations/tasksync"
+	"github.com/edson-mazvila/task-manager/internal/repository"
+	"github.com/edson-mazvila/task-manager/internal/secrets"
+	"github.com/edson-mazvila/task-manager/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd creates the sync command
+func (c *CLI) syncCmd() *cobra.Command {
+	var remotePath string
+	var initRemote bool
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync tasks with another database",
+		Long: `Reconcile this database with another task database, e.g. a copy on another
+machine reached through a shared folder. Tasks are copied in both directions;
+when a task was edited on both sides the most recent edit wins, and deletions
+are propagated so deleted tasks do not come back.
+
+An encrypted database only syncs with one encrypted under the same key: a
+remote created with --init gets this database's key, and two databases
+encrypted separately, even with the same passphrase, are refused.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("sync is only supported for SQLite storage")
+			}
+			if strings.Contains(remotePath, "://") {
+				return fmt.Errorf("remote URLs are not supported yet; pass the path to a SQLite database")
+			}
+
+			path, err := filepath.Abs(remotePath)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+			if local, err := filepath.Abs(c.storage.Path()); err == nil && local == path {
+				return fmt.Errorf("remote is the local database")
+			}
+			_, err = os.Stat(path)
+			created := os.IsNotExist(err)
+			if created && !initRemote {
+				return fmt.Errorf("remote database not found: %s (use --init to create it)", path)
+			}
+
+			ctx := cmd.Context()
+			remote, err := storage.NewSQLiteStorage(ctx, path, c.logger)
+			if err != nil {
+				return fmt.Errorf("failed to open remote database: %w", err)
+			}
+			defer remote.Close()
+
+			// Rows are copied as they are, encrypted or not, so both sides
+			// need the same key; a new remote takes this database's
+			if created {
+				if err := encryption.CopyKey(ctx, c.storage.DB(), remote.DB()); err != nil {
+					return err
+				}
+			}
+			if err := encryption.CheckSameKey(ctx, c.storage.DB(), remote.DB()); err != nil {
+				return fmt.Errorf("cannot sync with %s: %w (encrypt or decrypt one of them to match the other)", path, err)
+			}
+
+			syncer := dbsync.NewSyncer(
+				dbsync.NewSQLiteReplica(c.storage.DB(), c.logger),
+				dbsync.NewSQLiteReplica(remote.DB(), c.logger),
+				c.logger,
+			)
+
+			plan, err := syncer.Plan(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to compare databases: %w", err)
+			}
+
+			if plan.Empty() {
+				fmt.Println(c.t("✓ Already in sync"))
+				return nil
+			}
+
+			if c.dryRun {
+				for _, a := range plan.Actions {
+					fmt.Printf("  would %s\n", describeAction(a))
+				}
+				return nil
+			}
+
+			if err := syncer.Apply(ctx, plan); err != nil {
+				return fmt.Errorf("sync failed: %w", err)
+			}
+
+			fmt.Println(c.t("✓ Synced with %s", path))
+			fmt.Printf("  pulled: %d new, %d updated, %d deleted\n",
+				plan.Count(dbsync.PullCreate), plan.Count(dbsync.PullUpdate), plan.Count(dbsync.DeleteLocal))
+			fmt.Printf("  pushed: %d new, %d updated, %d deleted\n",
+				plan.Count(dbsync.PushCreate), plan.Count(dbsync.PushUpdate), plan.Count(dbsync.DeleteRemote))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&remotePath, "remote", "r", "", "Path to the other SQLite database")
+	cmd.Flags().BoolVar(&initRemote, "init", false, "Create the remote database if it does not exist")
+	_ = cmd.MarkFlagRequired("remote")
+
+	cmd.AddCommand(
+		c.syncGitHubCmd(),
+		c.syncGoogleCmd(),
+	)
+
+	return cmd
+}
+
+// syncGitHubCmd creates the sync github command
+func (c *CLI) syncGitHubCmd() *cobra.Command {
+	var repo, token, apiURL string
+
+	cmd := &cobra.Command{
+		Use:   "github",
+		Short: "Sync tasks with GitHub issues",
+		Long: `Import the open issues of a GitHub repository as tasks and close the issue of
+every imported task that was completed. Issues closed on GitHub complete their
+task, and issues edited since the last sync refresh it. Each issue is linked to
+its task, so syncing again never imports an issue twice.
+
+Priority labels ("high", "priority: low", ...) set the task priority and the
+milestone becomes the task context; all labels are kept in the task metadata.
+
+The token comes from --token, GITHUB_TOKEN, or the keyring entry github-token
+(see "task config set-secret"); it needs read and write access to issues.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("GitHub sync is only supported for SQLite storage")
+			}
+			if err := github.ValidateRepo(repo); err != nil {
+				return err
+			}
+
+			token, err := apiToken(token, "GITHUB_TOKEN", secrets.GitHubToken)
+			if err != nil {
+				return err
+			}
+
+			linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(c.storage.DB(), c.logger), c.logger)
+			client := github.NewClient(token, github.WithBaseURL(apiURL))
+			syncer := github.NewSyncer(client, c.service, linker, c.logger)
+
+			result, err := syncer.Sync(cmd.Context(), repo, c.dryRun)
+			if err != nil {
+				return fmt.Errorf("GitHub sync failed: %w", err)
+			}
+
+			if result.Empty() {
+				fmt.Println(c.t("✓ %s is already in sync", repo))
+				return nil
+			}
+
+			verb := ""
+			if c.dryRun {
+				verb = "would "
+			}
+			for _, item := range result.Imported {
+				fmt.Printf("  %simport #%d %s\n", verb, item.Number, item.Title)
+			}
+			for _, item := range result.Updated {
+				fmt.Printf("  %supdate #%d %s\n", verb, item.Number, item.Title)
+			}
+			for _, item := range result.Closed {
+				fmt.Printf("  %sclose #%d %s\n", verb, item.Number, item.Title)
+			}
+			for _, item := range result.Completed {
+				fmt.Printf("  %scomplete #%d %s\n", verb, item.Number, item.Title)
+			}
+			if c.dryRun {
+				return nil
+			}
+
+			fmt.Println(c.t("✓ Synced with %s: %d imported, %d updated, %d closed on GitHub, %d completed locally", repo,
+				len(result.Imported), len(result.Updated), len(result.Closed), len(result.Completed)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository as owner/name")
+	cmd.Flags().StringVar(&token, "token", "", "GitHub token (default GITHUB_TOKEN or the keyring)")
+	cmd.Flags().StringVar(&apiURL, "api-url", github.DefaultBaseURL, "GitHub API URL, for GitHub Enterprise")
+	_ = cmd.MarkFlagRequired("repo")
+
+	return cmd
+}
+
+// syncGoogleCmd creates the sync google command
+func (c *CLI) syncGoogleCmd() *cobra.Command {
+	var list, taskContext string
+
+	cmd := &cobra.Command{
+		Use:   "google",
+		Short: "Two-way sync with Google Tasks",
+		Long: `Sync tasks with a Google Tasks list in both directions: title, notes (the
+description), due date, and completion. Tasks new on either side are created on
+the other; when a task was edited on both sides since the last sync, the most
+recent edit wins. Completed Google tasks are only imported once linked.
+
+With --context, new Google tasks get that context and only local tasks in it
+are pushed; otherwise every pending local task is pushed. Due dates are kept
+in the task metadata (google.due). A task deleted in Google Tasks is unlinked
+and not pushed again; a task deleted locally is imported again unless it is
+also deleted in Google Tasks.
+
+Run "task sync google login" once first.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.storage == nil {
+				return fmt.Errorf("Google Tasks sync is only supported for SQLite storage")
+			}
+			ctx := cmd.Context()
+
+			credentialsPath, err := google.CredentialsPath()
+			if err != nil {
+				return err
+			}
+			oauthConfig, err := google.LoadCredentials(credentialsPath)
+			if err != nil {
+				return err
+			}
+			tokenPath, err := google.TokenPath()
+			if err != nil {
+				return err
+			}
+			token, err := google.LoadToken(tokenPath)
+			if err != nil {
+				return err
+			}
+
+			provider := google.NewProvider(ctx, oauthConfig, token, tokenPath, list)
+			linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(c.storage.DB(), c.logger), c.logger)
+			syncer := tasksync.NewSyncer(provider, c.service, linker, c.logger)
+
+			result, err := syncer.Sync(ctx, domain.NormalizeContext(taskContext), c.dryRun)
+			if err != nil {
+				return fmt.Errorf("Google Tasks sync failed: %w", err)
+			}
+
+			if result.Empty() {
+				fmt.Println(c.t("✓ Already in sync with Google Tasks"))
+				return nil
+			}
+
+			verb := ""
+			if c.dryRun {
+				verb = "would "
+			}
+			for _, group := range []struct {
+				action string
+				titles []string
+			}{
+				{"pull", result.Pulled},
+				{"update locally", result.UpdatedLocal},
+				{"push", result.Pushed},
+				{"update in Google Tasks", result.UpdatedRemote},
+				{"unlink", result.Unlinked},
+			} {
+				for _, title := range group.titles {
+					fmt.Printf("  %s%s %s\n", verb, group.action, title)
+				}
+			}
+			if c.dryRun {
+				return nil
+			}
+
+			fmt.Println(c.t("✓ Synced with Google Tasks"))
+			fmt.Printf("  pulled: %d new, %d updated\n", len(result.Pulled), len(result.UpdatedLocal))
+			fmt.Printf("  pushed: %d new, %d updated\n", len(result.Pushed), len(result.UpdatedRemote))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&list, "list", "", "Google Tasks list title (default: the default list)")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only sync local tasks in this context, and give it to pulled tasks")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+
+	cmd.AddCommand(c.syncGoogleLoginCmd(), c.syncGoogleLogoutCmd())
+
+	return cmd
+}
+
+// syncGoogleLoginCmd creates the sync google login command
+func (c *CLI) syncGoogleLoginCmd() *cobra.Command {
+	var credentials string
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Authorize access to Google Tasks",
+		Long: `Authorize Task Manager to read and write your Google Tasks. Create an OAuth
+client of type "Desktop app" in the Google Cloud console (with the Google Tasks
+API enabled), download its JSON, and pass it with --credentials the first time;
+it is copied to the config directory. Open the printed URL in a browser on this
+machine and grant access; the token is cached in the data directory.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			credentialsPath, err := google.CredentialsPath()
+			if err != nil {
+				return err
+			}
+
+			if credentials != "" {
+				data, err := os.ReadFile(credentials)
+				if err != nil {
+					return fmt.Errorf("failed to read credentials: %w", err)
+				}
+				if _, err := google.ParseCredentials(data); err != nil {
+					return err
+				}
+				if err := os.MkdirAll(filepath.Dir(credentialsPath), 0700); err != nil {
+					return fmt.Errorf("failed to create config directory: %w", err)
+				}
+				if err := os.WriteFile(credentialsPath, data, 0600); err != nil {
+					return fmt.Errorf("failed to save credentials: %w", err)
+				}
+			}
+
+			oauthConfig, err := google.LoadCredentials(credentialsPath)
+			if err != nil {
+				return err
+			}
+
+			token, err := google.Login(cmd.Context(), oauthConfig, func(url string) {
+				fmt.Printf("Open this URL in your browser to authorize Task Manager:\n\n  %s\n\nWaiting for authorization...\n", url)
+			})
+			if err != nil {
+				return fmt.Errorf("Google login failed: %w", err)
+			}
+
+			tokenPath, err := google.TokenPath()
+			if err != nil {
+				return err
+			}
+			if err := google.SaveToken(tokenPath, token); err != nil {
+				return err
+			}
+
+			fmt.Println(c.t("✓ Logged in to Google Tasks (token saved to %s)", tokenPath))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&credentials, "credentials", "", "OAuth client JSON downloaded from the Google Cloud console")
+
+	return cmd
+}
+
+// syncGoogleLogoutCmd creates the sync google logout command
+func (c *CLI) syncGoogleLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the cached Google token",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokenPath, err := google.TokenPath()
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(tokenPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove token: %w", err)
+			}
+			fmt.Println(c.t("✓ Logged out of Google Tasks"))
+			return nil
+		},
+	}
+}
+
+// describeAction renders a sync action for dry-run output
+func describeAction(a dbsync.Action) string {
+	switch {
+	case a.User != nil:
+		return fmt.Sprintf("%s %s", a.Kind, a.User.Name)
+	case a.Task != nil:
+		return fmt.Sprintf("%s %s %s", a.Kind, a.Task.ID[:8], a.Task.Title)
+	default:
+		return fmt.Sprintf("%s %s (tombstone only)", a.Kind, a.Tombstone.TaskID[:8])
+	}
+}
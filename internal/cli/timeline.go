@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/repository"
+	"github.com/edson-mazvila/task-manager/internal/timeline"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// timelineMarks are the symbols and color roles of each kind of event
+var timelineMarks = map[timeline.Kind]struct {
+	symbol, role string
+}{
+	timeline.Created:   {"+", ui.RoleSuccess},
+	timeline.Updated:   {"~", ""},
+	timeline.Completed: {"✓", ui.RoleCompleted},
+	timeline.Deleted:   {"✗", ui.RoleOverdue},
+}
+
+// timelineCmd creates the timeline command
+func (c *CLI) timelineCmd() *cobra.Command {
+	var days int
+	var taskContext string
+
+	cmd := &cobra.Command{
+		Use:   "timeline",
+		Short: "Show recent task activity day by day",
+		Long: `Show the tasks created, updated, completed, and deleted over the last
+--days days, oldest first, grouped by day; handy for writing a standup update.
+
+Activity is read from the tasks themselves, so only the latest update of each
+task shows, and deleted tasks are shown by ID. Deletions are left out when
+filtering by --context.`,
+		Annotations: map[string]string{annotationPager: "true"},
+		Example: `  task timeline
+  task timeline --days 1
+  task timeline --context work`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days < 1 {
+				return fmt.Errorf("invalid days: %d (must be at least 1)", days)
+			}
+
+			var filter domain.TaskFilter
+			if taskContext != "" {
+				filter.Context = &taskContext
+			}
+			var tombstones timeline.Tombstones
+			if c.storage != nil {
+				tombstones = repository.NewSQLiteTaskRepository(c.storage.DB(), c.logger)
+			}
+
+			now := time.Now()
+			since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, time.Local)
+			events, err := timeline.Build(cmd.Context(), c.service, tombstones, filter, since)
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				fmt.Printf("No activity in the last %d day(s).\n", days)
+				return nil
+			}
+
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			for i, day := range timeline.ByDay(events) {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Println(painter.Paint(ui.RoleHeader, day.Date.Format("Mon 2 Jan")))
+				for _, event := range day.Events {
+					mark := timelineMarks[event.Kind]
+					title := ""
+					if event.Task != nil {
+						title = event.Task.Title
+					}
+					fmt.Printf("  %s  %s  %s  %s\n",
+						event.At.Local().Format("15:04"),
+						painter.Paint(mark.role, fmt.Sprintf("%s %-9s", mark.symbol, event.Kind)),
+						painter.Paint(ui.RoleID, event.TaskID[:8]),
+						title,
+					)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&days, "days", "d", 14, "Days of activity to show, including today")
+	cmd.Flags().StringVarP(&taskContext, "context", "c", "", "Only tasks in this context")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+
+	return cmd
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/trend"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// chartWidth is the width of the burndown plot area and velocity bars
+const chartWidth = 60
+
+// addTrendFlags registers the flags selecting tasks for burndown and
+// velocity. Projects are contexts, so --project is an alias of --context.
+func (c *CLI) addTrendFlags(cmd *cobra.Command, opts *listOptions) {
+	cmd.Flags().StringVarP(&opts.taskContext, "context", "c", "", "Only include tasks in this context (overrides the active context)")
+	cmd.Flags().StringVar(&opts.taskContext, "project", "", "Same as --context")
+	cmd.Flags().StringVarP(&opts.priority, "priority", "p", "", "Only include tasks with this priority")
+	cmd.Flags().StringVar(&opts.assignee, "assignee", "", "Only include tasks assigned to this user")
+	cmd.Flags().StringVarP(&opts.query, "query", "q", "", "Filter expression, as for list")
+	cmd.Flags().BoolVarP(&opts.all, "all", "a", false, "Ignore the active context")
+	_ = cmd.RegisterFlagCompletionFunc("context", c.contextCompletion)
+	_ = cmd.RegisterFlagCompletionFunc("project", c.contextCompletion)
+	_ = cmd.RegisterFlagCompletionFunc("priority", fixedCompletion(priorityValues...))
+	_ = cmd.RegisterFlagCompletionFunc("assignee", c.assigneeCompletion)
+}
+
+// trendTasks loads every task matching opts, printing the active context
+func (c *CLI) trendTasks(cmd *cobra.Command, opts *listOptions) ([]*domain.Task, error) {
+	filter, active, err := c.buildFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	if active != "" {
+		fmt.Printf("Context: @%s (use --all to include every task)\n\n", active)
+	}
+	return c.service.ListTasks(cmd.Context(), filter)
+}
+
+// burndownCmd creates the burndown command
+func (c *CLI) burndownCmd() *cobra.Command {
+	opts := &listOptions{}
+	var weeks, height int
+	var ascii bool
+
+	cmd := &cobra.Command{
+		Use:   "burndown",
+		Short: "Chart open and completed tasks over time",
+		Long: `Chart how many tasks were open and how many were completed at the end of each
+day over the last --weeks weeks. The history is derived from when tasks were
+created and completed, so deleted and archived tasks are not counted.
+
+The chart is drawn with braille characters; use --ascii for terminals or fonts
+without them.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if weeks < 1 {
+				return fmt.Errorf("invalid weeks: %d (must be at least 1)", weeks)
+			}
+			if height < 3 {
+				return fmt.Errorf("invalid height: %d (must be at least 3)", height)
+			}
+
+			tasks, err := c.trendTasks(cmd, opts)
+			if err != nil {
+				return err
+			}
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			days := trend.Burndown(tasks, now.AddDate(0, 0, -7*weeks), now)
+			first, last := days[0], days[len(days)-1]
+
+			open := make([]int, len(days))
+			completed := make([]int, len(days))
+			for i, day := range days {
+				open[i] = day.Open
+				completed[i] = day.Completed
+			}
+
+			chart := &ui.LineChart{
+				Series: []ui.Series{
+					{Name: "open", Role: ui.RolePriorityMedium, Mark: 'o', Values: open},
+					{Name: "completed", Role: ui.RoleSuccess, Mark: '+', Values: completed},
+				},
+				Height:  height,
+				Width:   chartWidth,
+				Braille: !ascii,
+				Start:   first.Date.Format("Mon 2 Jan"),
+				End:     last.Date.Format("Mon 2 Jan"),
+			}
+			if err := chart.Render(os.Stdout, painter); err != nil {
+				return err
+			}
+
+			fmt.Printf("\nOpen: %d (%+d since %s)   Completed: %d in %d week(s)\n",
+				last.Open, last.Open-first.Open, first.Date.Format("Mon 2 Jan"),
+				last.Completed-first.Completed, weeks)
+			return nil
+		},
+	}
+
+	c.addTrendFlags(cmd, opts)
+	cmd.Flags().IntVar(&weeks, "weeks", 4, "Number of weeks to chart")
+	cmd.Flags().IntVar(&height, "height", 12, "Chart height in lines")
+	cmd.Flags().BoolVar(&ascii, "ascii", false, "Draw with ASCII characters instead of braille")
+
+	return cmd
+}
+
+// velocityCmd creates the velocity command
+func (c *CLI) velocityCmd() *cobra.Command {
+	opts := &listOptions{}
+	var weeks int
+	var ascii bool
+
+	cmd := &cobra.Command{
+		Use:   "velocity",
+		Short: "Show tasks completed per week",
+		Long: `Show how many tasks were created and completed in each of the last --weeks
+weeks (Monday to Sunday), with a bar per week and the average completions.
+Like burndown, it is derived from task timestamps.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if weeks < 1 {
+				return fmt.Errorf("invalid weeks: %d (must be at least 1)", weeks)
+			}
+
+			tasks, err := c.trendTasks(cmd, opts)
+			if err != nil {
+				return err
+			}
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			history := trend.Velocity(tasks, weeks, time.Now())
+			most, total := 0, 0
+			for _, week := range history {
+				most = max(most, week.Completed)
+				total += week.Completed
+			}
+
+			table := ui.NewTable(painter, "WEEK OF", "CREATED", "COMPLETED", "")
+			for _, week := range history {
+				table.AddRow("",
+					ui.Cell{Text: week.Start.Format("Mon 2 Jan")},
+					ui.Cell{Text: strconv.Itoa(week.Created)},
+					ui.Cell{Text: strconv.Itoa(week.Completed)},
+					ui.Cell{Text: ui.Bar(week.Completed, most, chartWidth/2, ascii), Role: ui.RoleSuccess},
+				)
+			}
+			if err := table.Render(os.Stdout); err != nil {
+				return err
+			}
+
+			// The current week is still running, so it is left out of the average
+			fmt.Printf("\nCompleted: %d in %d week(s)", total, weeks)
+			if weeks > 1 {
+				finished := total - history[len(history)-1].Completed
+				fmt.Printf(", %.1f per finished week", float64(finished)/float64(weeks-1))
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+
+	c.addTrendFlags(cmd, opts)
+	cmd.Flags().IntVar(&weeks, "weeks", 8, "Number of weeks to show")
+	cmd.Flags().BoolVar(&ascii, "ascii", false, "Draw bars with ASCII characters")
+
+	return cmd
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// userCmd creates the user command for managing the users tasks can be assigned to
+func (c *CLI) userCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users",
+		Long: `Manage the users tasks can be assigned to in a shared database.
+Set user.name in config (or TASK_USER) to use "task mine".
+
+Once a user is an admin ("task user role alice admin"), every change is
+checked against the role of the current user: viewers can only read,
+members can create tasks and change those they created or are assigned,
+and admins can change anything and manage users. An unset or unregistered
+current user is then a viewer.`,
+	}
+
+	cmd.AddCommand(c.userAddCmd(), c.userListCmd(), c.userRoleCmd())
+
+	return cmd
+}
+
+// userAddCmd creates the user add command
+func (c *CLI) userAddCmd() *cobra.Command {
+	var email, role string
+
+	cmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Register a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.users == nil {
+				return fmt.Errorf("user management is not available")
+			}
+
+			parsed, err := domain.ParseRole(role)
+			if err != nil {
+				return err
+			}
+
+			if c.dryRun {
+				fmt.Printf("Would register user %s as %s\n", domain.NormalizeUserName(args[0]), parsed)
+				return nil
+			}
+
+			user, err := c.users.CreateUser(cmd.Context(), args[0], email)
+			if err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+			if parsed != user.Role {
+				if user, err = c.users.SetRole(cmd.Context(), user.Name, parsed); err != nil {
+					return fmt.Errorf("failed to set role: %w", err)
+				}
+			}
+
+			fmt.Println(c.t("✓ User %s registered as %s", user.Name, user.Role))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&email, "email", "e", "", "User email address")
+	cmd.Flags().StringVar(&role, "role", string(domain.RoleMember), "Role: admin, member, or viewer")
+	_ = cmd.RegisterFlagCompletionFunc("role", fixedCompletion("admin", "member", "viewer"))
+
+	return cmd
+}
+
+// userListCmd creates the user list command
+func (c *CLI) userListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List registered users",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.users == nil {
+				return fmt.Errorf("user management is not available")
+			}
+
+			users, err := c.users.ListUsers(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if len(users) == 0 {
+				fmt.Println("No users registered.")
+				return nil
+			}
+
+			painter, err := c.painter()
+			if err != nil {
+				return err
+			}
+
+			times, err := c.timeFormat()
+			if err != nil {
+				return err
+			}
+
+			table := ui.NewTable(painter, "NAME", "EMAIL", "ROLE", "CREATED")
+			for _, user := range users {
+				table.AddRow("",
+					ui.Cell{Text: user.Name},
+					ui.Cell{Text: user.Email},
+					ui.Cell{Text: string(user.Role)},
+					ui.Cell{Text: times.Date(user.CreatedAt)},
+				)
+			}
+
+			return table.Render(os.Stdout)
+		},
+	}
+}
+
+// userRoleCmd creates the user role command
+func (c *CLI) userRoleCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "role <name> <admin|member|viewer>",
+		Short: "Change the role of a user",
+		Long: `Change the role of a user. Making the first admin turns role checks on for
+everyone; from then on only admins can change roles or register users.`,
+		Example: `  task user role alice admin
+  task user role bob viewer`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return []cobra.Completion{"admin", "member", "viewer"}, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.users == nil {
+				return fmt.Errorf("user management is not available")
+			}
+
+			role, err := domain.ParseRole(args[1])
+			if err != nil {
+				return err
+			}
+
+			if c.dryRun {
+				fmt.Printf("Would make %s %s\n", domain.NormalizeUserName(args[0]), role)
+				return nil
+			}
+
+			user, err := c.users.SetRole(cmd.Context(), args[0], role)
+			if err != nil {
+				return fmt.Errorf("failed to set role: %w", err)
+			}
+
+			fmt.Println(c.t("✓ %s is now %s", user.Name, user.Role))
+			return nil
+		},
+	}
+}
+
+// enforceRoles makes the task and user services act for the current user
+// once roles are enforced (see service.UserService.RolesEnforced), and
+// limits tasks to those the user created or is assigned unless an admin
+// passed --all-users. An unset or unregistered current user is then a
+// viewer who sees no tasks.
+func (c *CLI) enforceRoles(ctx context.Context) error {
+	if c.users == nil {
+		return nil
+	}
+
+	enforced, err := c.users.RolesEnforced(ctx)
+	if err != nil || !enforced {
+		return err
+	}
+
+	actor := &domain.User{Role: domain.RoleViewer}
+	if me, err := c.currentUser(); err == nil {
+		actor.Name = me
+		user, err := c.users.GetUser(ctx, me)
+		switch {
+		case err == nil:
+			actor = user
+		case !errors.Is(err, domain.ErrUserNotFound):
+			return err
+		}
+	}
+
+	c.logger.Debug("Enforcing roles", "user", actor.Name, "role", actor.Role, "all_users", c.allUsers)
+	c.actor = actor
+	c.service = c.service.As(actor)
+	c.users = c.users.As(actor)
+
+	if !c.allUsers {
+		c.service = c.service.ScopedTo(actor)
+	} else if actor.Role != domain.RoleAdmin {
+		return fmt.Errorf("only admins can use --all-users: %w", domain.ErrForbidden)
+	}
+	return nil
+}
+
+// creatorOptions returns the task options recording the current user as
+// creator, or none when no user is configured
+func (c *CLI) creatorOptions() []domain.TaskOption {
+	me, err := c.currentUser()
+	if err != nil {
+		return nil
+	}
+	return []domain.TaskOption{domain.WithCreatedBy(me)}
+}
+
+// currentUser returns the configured default user for "task mine"
+func (c *CLI) currentUser() (string, error) {
+	if c.config == nil || c.config.User.Name == "" {
+		return "", &configError{fmt.Errorf("no current user configured (set user.name in config or TASK_USER)")}
+	}
+	return domain.NormalizeUserName(c.config.User.Name), nil
+}
+
+// currentLocation returns where task runs, for hiding tasks restricted to
+// other locations: user.location in config (or TASK_LOCATION), or else the
+// host name without its domain. It is empty when neither is known.
+func (c *CLI) currentLocation() string {
+	if c.config != nil && c.config.User.Location != "" {
+		return domain.NormalizeLocation(c.config.User.Location)
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	host, _, _ = strings.Cut(host, ".")
+	return domain.NormalizeLocation(host)
+}
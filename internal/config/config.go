@@ -3,58 +3,250 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/mail"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/events"
+	"github.com/edson-mazvila/task-manager/internal/i18n"
+	"github.com/edson-mazvila/task-manager/internal/notify"
+	"github.com/edson-mazvila/task-manager/internal/secrets"
+	"github.com/edson-mazvila/task-manager/internal/xdg"
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	Logging  LoggingConfig  `yaml:"logging"`
+	Database    DatabaseConfig     `yaml:"database"`
+	Logging     LoggingConfig      `yaml:"logging"`
+	Display     DisplayConfig      `yaml:"display"`
+	Behavior    BehaviorConfig     `yaml:"behavior"`
+	Validation  ValidationConfig   `yaml:"validation"`
+	User        UserConfig         `yaml:"user"`
+	Tracing     TracingConfig      `yaml:"tracing"`
+	Email       EmailConfig        `yaml:"email,omitempty"`
+	Notify      NotifyConfig       `yaml:"notifications,omitempty"`
+	Rules       []RuleConfig       `yaml:"rules,omitempty"`
+	Fields      []FieldConfig      `yaml:"fields,omitempty"`
+	Webhooks    []WebhookConfig    `yaml:"webhooks,omitempty"`
+	Escalations []EscalationConfig `yaml:"escalations,omitempty"`
+	Project     ProjectConfig      `yaml:"project,omitempty"`
+	Aliases     map[string]string  `yaml:"aliases,omitempty"` // command name -> the command it runs, e.g. urgent: "list --priority high"
+
+	// keyring records the secrets that were read from the OS keyring
+	keyring map[string]bool
+
+	// projectFile is the per-directory config file merged over the config file
+	projectFile string
 }
 
+// KeyringValue, used as the value of a secret setting (database.password,
+// database.passphrase, or email.password), reads the secret from the OS keyring instead
+const KeyringValue = "keyring"
+
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
-	Type     string `yaml:"type"`     // sqlite or postgres
-	Path     string `yaml:"path"`     // for SQLite
-	Host     string `yaml:"host"`     // for PostgreSQL
-	Port     int    `yaml:"port"`     // for PostgreSQL
-	Name     string `yaml:"name"`     // for PostgreSQL
-	User     string `yaml:"user"`     // for PostgreSQL
-	Password string `yaml:"password"` // for PostgreSQL
-	SSLMode  string `yaml:"ssl_mode"` // for PostgreSQL
+	Type     string `yaml:"type"`               // sqlite or postgres
+	Path     string `yaml:"path"`               // for SQLite
+	Archive  string `yaml:"archive,omitempty"`  // for SQLite, archived tasks; defaults next to Path
+	Host     string `yaml:"host"`               // for PostgreSQL
+	Port     int    `yaml:"port"`               // for PostgreSQL
+	Name     string `yaml:"name"`               // for PostgreSQL
+	User     string `yaml:"user"`               // for PostgreSQL
+	Password string `yaml:"password,omitempty"` // for PostgreSQL; "keyring" reads it from the OS keyring
+	SSLMode  string `yaml:"ssl_mode"`           // for PostgreSQL
+
+	Passphrase string `yaml:"passphrase,omitempty"` // for SQLite, unlocks a database encrypted with "task encrypt"; "keyring" reads it from the OS keyring
+
+	BusyTimeout time.Duration   `yaml:"busy_timeout,omitempty"` // for SQLite, how long to wait for a lock held by another process
+	WriteLock   bool            `yaml:"write_lock,omitempty"`   // for SQLite, take the write lock when a transaction begins
+	Retry       RetryConfig     `yaml:"retry"`                  // retries for writes that fail because the database is busy
+	Cache       CacheConfig     `yaml:"cache"`                  // in-memory cache of task reads
+	Retention   RetentionConfig `yaml:"retention"`              // what "task maintenance" prunes
+}
+
+// RetentionConfig controls how long "task maintenance" keeps records that
+// only matter for a while
+type RetentionConfig struct {
+	// TombstoneDays is how long deletion records are kept for "task sync",
+	// 0 for ever. A database not synced within this time brings back the
+	// tasks deleted elsewhere.
+	TombstoneDays int `yaml:"tombstone_days"`
+}
+
+// CacheConfig controls the in-memory cache of recently read tasks and
+// listings. Writes by this process invalidate it; writes by other processes
+// show once the entries expire.
+type CacheConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Size    int           `yaml:"size"` // tasks, and separately listings, kept at most
+	TTL     time.Duration `yaml:"ttl"`  // how long an entry is used before it is read again
+}
+
+// RetryConfig controls how writes are retried when the database is busy or
+// locked by another process. The delay starts at Backoff and doubles after
+// each failed attempt.
+type RetryConfig struct {
+	Attempts int           `yaml:"attempts"` // total attempts, 1 disables retries
+	Backoff  time.Duration `yaml:"backoff"`  // delay before the first retry
 }
 
 // LoggingConfig holds logging-related configuration
 type LoggingConfig struct {
-	Level  string `yaml:"level"`  // debug, info, warn, error
-	Format string `yaml:"format"` // json or text
+	Level  string   `yaml:"level"`           // debug, info, warn, error
+	Format string   `yaml:"format"`          // json or text
+	Debug  []string `yaml:"debug,omitempty"` // subsystems logged at debug level regardless of Level
+
+	Output     string        `yaml:"output,omitempty"`      // stderr (default) or file
+	File       string        `yaml:"file,omitempty"`        // log file when Output is file; defaults to the XDG state directory
+	MaxSizeMB  int           `yaml:"max_size_mb,omitempty"` // rotate the log file when it would grow past this size
+	MaxBackups int           `yaml:"max_backups,omitempty"` // rotated files kept, 0 keeps all
+	MaxAge     time.Duration `yaml:"max_age,omitempty"`     // rotated files older than this are removed, 0 keeps them
+}
+
+// Log outputs accepted by logging.output
+const (
+	LogOutputStderr = "stderr"
+	LogOutputFile   = "file"
+)
+
+// DisplayConfig holds output-related configuration
+type DisplayConfig struct {
+	ListFormat     string            `yaml:"list_format,omitempty"`     // Go template applied to each task by list
+	Theme          map[string]string `yaml:"theme,omitempty"`           // theme role -> color names, e.g. priority.high: "bold red"
+	DefaultCommand string            `yaml:"default_command,omitempty"` // command run by a bare "task", e.g. "list --status pending"
+	DateFormat     string            `yaml:"date_format,omitempty"`     // Go layout of dates, e.g. "02/01/2006"; default 2006-01-02
+	TimeFormat     string            `yaml:"time_format,omitempty"`     // Go layout of times of day, e.g. "3:04PM"; default 15:04
+	Timezone       string            `yaml:"timezone,omitempty"`        // IANA time zone of displayed times, e.g. "Europe/Lisbon"; default local
+	Pager          string            `yaml:"pager,omitempty"`           // pager for long output of list and get, overriding PAGER; "cat" disables paging
+	Language       string            `yaml:"language,omitempty"`        // language of messages, e.g. "pt"; default from LC_ALL, LC_MESSAGES, or LANG
+	StaleDays      int               `yaml:"stale_days,omitempty"`      // days without activity after which list counts pending tasks as stale; 0 for no count
+}
+
+// BehaviorConfig holds interactive behavior settings
+type BehaviorConfig struct {
+	FollowUpPrompt bool          `yaml:"follow_up_prompt"`  // offer to create a follow-up task after complete
+	ConfirmDelete  bool          `yaml:"confirm_delete"`    // ask before deleting tasks unless --yes is given
+	Timeout        time.Duration `yaml:"timeout,omitempty"` // default --timeout for each command, 0 waits forever
+}
+
+// ValidationConfig holds the limits checked when tasks are created or updated
+type ValidationConfig struct {
+	MaxTitleLength       int      `yaml:"max_title_length"`              // in characters, 0 for no limit
+	MaxDescriptionLength int      `yaml:"max_description_length"`        // in characters, 0 for no limit
+	RequireDescription   []string `yaml:"require_description,omitempty"` // priorities whose tasks need a description
+	BannedCharacters     string   `yaml:"banned_characters,omitempty"`   // characters rejected in titles and descriptions
+	TrimWhitespace       bool     `yaml:"trim_whitespace"`               // trim titles and descriptions, and collapse whitespace in titles
+}
+
+// Rules returns the validation rules the service enforces
+func (v ValidationConfig) Rules() domain.ValidationRules {
+	rules := domain.ValidationRules{
+		MaxTitleLength:       v.MaxTitleLength,
+		MaxDescriptionLength: v.MaxDescriptionLength,
+		BannedCharacters:     v.BannedCharacters,
+		TrimWhitespace:       v.TrimWhitespace,
+	}
+	for _, priority := range v.RequireDescription {
+		rules.RequireDescription = append(rules.RequireDescription, domain.TaskPriority(strings.ToLower(priority)))
+	}
+	return rules
+}
+
+// UserConfig identifies the current user in a shared database
+type UserConfig struct {
+	Name     string `yaml:"name,omitempty"`     // default user for "task mine"
+	Location string `yaml:"location,omitempty"` // where task runs, for tasks restricted to a location; the host name when empty
+}
+
+// TracingConfig holds OpenTelemetry tracing settings
+type TracingConfig struct {
+	Enabled  bool   `yaml:"enabled"`            // export spans for service and repository calls
+	Endpoint string `yaml:"endpoint,omitempty"` // OTLP/HTTP endpoint URL, e.g. http://localhost:4318
+}
+
+// NotifyConfig selects where notifications, such as those of
+// "task overdue --notify", are shown
+type NotifyConfig struct {
+	Backend string `yaml:"backend,omitempty"` // auto (default: the desktop, or the log where there is none), desktop, log, or none
+}
+
+// EmailConfig holds the SMTP settings used by "task digest"
+type EmailConfig struct {
+	Host     string `yaml:"host,omitempty"`     // SMTP server; digests are only sent when set
+	Port     int    `yaml:"port,omitempty"`     // 587 (STARTTLS) by default; 465 uses implicit TLS
+	Username string `yaml:"username,omitempty"` // login, if the server requires one
+	Password string `yaml:"password,omitempty"` // "keyring" reads it from the OS keyring
+	From     string `yaml:"from,omitempty"`     // sender address; defaults to username
+	To       string `yaml:"to,omitempty"`       // default recipients, comma separated
+}
+
+// RuleConfig declares a team convention enforced when tasks are created or updated
+type RuleConfig struct {
+	Name    string            `yaml:"name"`
+	When    map[string]string `yaml:"when,omitempty"`    // field -> value; the rule applies when all match
+	Require []string          `yaml:"require"`           // fields that must be non-empty
+	Message string            `yaml:"message,omitempty"` // shown instead of the generated message
+}
+
+// FieldConfig declares a user-defined task field
+type FieldConfig struct {
+	Name   string   `yaml:"name"`
+	Type   string   `yaml:"type"`             // string, number, date, or enum
+	Values []string `yaml:"values,omitempty"` // allowed values of an enum
+}
+
+// WebhookConfig subscribes a URL to task events, which are POSTed to it as JSON
+type WebhookConfig struct {
+	URL    string   `yaml:"url"`
+	Events []string `yaml:"events,omitempty"` // e.g. task.created; every event when empty
+}
+
+// EscalationConfig declares who to tell about tasks that stay overdue, e.g.
+// high-priority tasks overdue for more than 48 hours. "task escalations run"
+// evaluates the escalations, typically from cron.
+type EscalationConfig struct {
+	Name     string        `yaml:"name"`
+	Priority string        `yaml:"priority,omitempty"` // only tasks of this priority; any when empty
+	Context  string        `yaml:"context,omitempty"`  // only tasks in this context; any when empty
+	Overdue  time.Duration `yaml:"overdue,omitempty"`  // how long after the end of its due day a task escalates, e.g. 48h
+	Webhook  string        `yaml:"webhook,omitempty"`  // URL POSTed a JSON message with a Slack-compatible "text"
+	Email    string        `yaml:"email,omitempty"`    // recipients, comma separated, sent through the email settings
+	Desktop  bool          `yaml:"desktop,omitempty"`  // also notify through notifications.backend where it runs
+}
+
+// EventTypes returns the events the webhook subscribes to, none meaning all
+func (w WebhookConfig) EventTypes() []events.Type {
+	types := make([]events.Type, 0, len(w.Events))
+	for _, name := range w.Events {
+		t, _ := events.ParseType(name)
+		types = append(types, t)
+	}
+	return types
+}
+
+// FieldDefs returns the declared user-defined fields
+func (c *Config) FieldDefs() []domain.FieldDef {
+	defs := make([]domain.FieldDef, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		defs = append(defs, domain.FieldDef{Name: f.Name, Type: domain.FieldType(f.Type), Values: f.Values})
+	}
+	return defs
 }
 
 // Load loads configuration from environment variables and config file
 func Load() (*Config, error) {
-	cfg := &Config{
-		Database: DatabaseConfig{
-			Type:     getEnvOrDefault("DB_TYPE", "sqlite"),
-			Path:     getEnvOrDefault("DB_PATH", ""),
-			Host:     getEnvOrDefault("DB_HOST", "localhost"),
-			Port:     getEnvIntOrDefault("DB_PORT", 5432),
-			Name:     getEnvOrDefault("DB_NAME", "taskmanager"),
-			User:     getEnvOrDefault("DB_USER", ""),
-			Password: getEnvOrDefault("DB_PASSWORD", ""),
-			SSLMode:  getEnvOrDefault("DB_SSL_MODE", "disable"),
-		},
-		Logging: LoggingConfig{
-			Level:  getEnvOrDefault("LOG_LEVEL", "info"),
-			Format: getEnvOrDefault("LOG_FORMAT", "text"),
-		},
-	}
+	cfg := defaultConfig()
 
 	// Store env var overrides before loading config file
 	envOverrides := make(map[string]string)
-	envVars := []string{"DB_TYPE", "DB_PATH", "DB_HOST", "DB_PORT", "DB_NAME", "DB_USER", "DB_PASSWORD", "DB_SSL_MODE", "LOG_LEVEL", "LOG_FORMAT"}
+	envVars := []string{"DB_TYPE", "DB_PATH", "DB_HOST", "DB_PORT", "DB_NAME", "DB_USER", "DB_PASSWORD", "DB_SSL_MODE", "TASK_PASSPHRASE", "LOG_LEVEL", "LOG_FORMAT", "LOG_DEBUG", "LOG_OUTPUT", "LOG_FILE", "TASK_USER", "TASK_LOCATION", "SMTP_PASSWORD"}
 	for _, key := range envVars {
 		if val := os.Getenv(key); val != "" {
 			envOverrides[key] = val
@@ -62,7 +254,7 @@ func Load() (*Config, error) {
 	}
 
 	// Try to load from config file if it exists
-	configPath := getEnvOrDefault("CONFIG_FILE", "config.yaml")
+	configPath := FilePath()
 	configExplicit := os.Getenv("CONFIG_FILE") != ""
 
 	if configPath != "" {
@@ -74,6 +266,22 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// A project file in the working directory or above overrides the config
+	// file for that directory tree; environment variables still win
+	if os.Getenv("TASK_NO_PROJECT") == "" {
+		if wd, err := os.Getwd(); err == nil {
+			projectPath, err := FindProjectFile(wd)
+			if err != nil {
+				return nil, err
+			}
+			if projectPath != "" && projectPath != configPath {
+				if err := loadProjectFile(projectPath, cfg); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
 	// Reapply environment variable overrides
 	if _, ok := envOverrides["DB_TYPE"]; ok {
 		cfg.Database.Type = envOverrides["DB_TYPE"]
@@ -99,12 +307,37 @@ func Load() (*Config, error) {
 	if _, ok := envOverrides["DB_SSL_MODE"]; ok {
 		cfg.Database.SSLMode = envOverrides["DB_SSL_MODE"]
 	}
+	if _, ok := envOverrides["TASK_PASSPHRASE"]; ok {
+		cfg.Database.Passphrase = envOverrides["TASK_PASSPHRASE"]
+	}
 	if _, ok := envOverrides["LOG_LEVEL"]; ok {
 		cfg.Logging.Level = envOverrides["LOG_LEVEL"]
 	}
 	if _, ok := envOverrides["LOG_FORMAT"]; ok {
 		cfg.Logging.Format = envOverrides["LOG_FORMAT"]
 	}
+	if _, ok := envOverrides["LOG_DEBUG"]; ok {
+		cfg.Logging.Debug = splitList(envOverrides["LOG_DEBUG"])
+	}
+	if _, ok := envOverrides["LOG_OUTPUT"]; ok {
+		cfg.Logging.Output = envOverrides["LOG_OUTPUT"]
+	}
+	if _, ok := envOverrides["LOG_FILE"]; ok {
+		cfg.Logging.File = envOverrides["LOG_FILE"]
+	}
+	if _, ok := envOverrides["TASK_USER"]; ok {
+		cfg.User.Name = envOverrides["TASK_USER"]
+	}
+	if _, ok := envOverrides["TASK_LOCATION"]; ok {
+		cfg.User.Location = envOverrides["TASK_LOCATION"]
+	}
+	if _, ok := envOverrides["SMTP_PASSWORD"]; ok {
+		cfg.Email.Password = envOverrides["SMTP_PASSWORD"]
+	}
+
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
+	}
 
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -113,6 +346,88 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// resolveSecrets replaces secret settings set to KeyringValue with the
+// values stored in the OS keyring
+func (c *Config) resolveSecrets() error {
+	for name, field := range c.secretFields() {
+		if *field != KeyringValue {
+			continue
+		}
+
+		value, err := secrets.Get(name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("%s is not in the keyring (store it with \"task config set-secret %s\")", name, name)
+		}
+		if err != nil {
+			return err
+		}
+
+		*field = value
+		if c.keyring == nil {
+			c.keyring = make(map[string]bool)
+		}
+		c.keyring[name] = true
+	}
+
+	return nil
+}
+
+// FromKeyring reports whether the secret called name was read from the OS keyring
+func (c *Config) FromKeyring(name string) bool {
+	return c.keyring[name]
+}
+
+// defaultConfig returns the settings used when neither the config file nor
+// the environment sets them
+func defaultConfig() *Config {
+	return &Config{
+		Database: DatabaseConfig{
+			Type:    "sqlite",
+			Host:    "localhost",
+			Port:    5432,
+			Name:    "taskmanager",
+			SSLMode: "disable",
+			Retry: RetryConfig{
+				Attempts: 3,
+				Backoff:  100 * time.Millisecond,
+			},
+			Cache: CacheConfig{
+				Size: 1000,
+				TTL:  30 * time.Second,
+			},
+		},
+		Logging: LoggingConfig{
+			Level:      "info",
+			Format:     "text",
+			Output:     LogOutputStderr,
+			MaxSizeMB:  10,
+			MaxBackups: 5,
+			MaxAge:     30 * 24 * time.Hour,
+		},
+		Behavior: BehaviorConfig{
+			ConfirmDelete: true,
+		},
+		Validation: ValidationConfig{
+			MaxTitleLength:       domain.DefaultValidationRules.MaxTitleLength,
+			MaxDescriptionLength: domain.DefaultValidationRules.MaxDescriptionLength,
+			TrimWhitespace:       domain.DefaultValidationRules.TrimWhitespace,
+		},
+		Email: EmailConfig{
+			Port: 587,
+		},
+	}
+}
+
+// CheckFile reports whether the config file at path loads and validates on
+// its own, without environment overrides or keyring lookups
+func CheckFile(path string) error {
+	cfg := defaultConfig()
+	if err := loadFromFile(path, cfg, true); err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
 // loadFromFile loads configuration from a YAML file
 func loadFromFile(path string, cfg *Config, explicit bool) error {
 	if path == "" {
@@ -148,12 +463,44 @@ func (c *Config) Validate() error {
 
 	if c.Database.Type == "sqlite" {
 		if c.Database.Path == "" {
-			// Set default path in user's home directory
-			homeDir, err := os.UserHomeDir()
+			path, err := DefaultDatabasePath()
 			if err != nil {
-				return fmt.Errorf("failed to get user home directory: %w", err)
+				return err
 			}
-			c.Database.Path = filepath.Join(homeDir, ".task-manager", "tasks.db")
+			c.Database.Path = path
+		}
+	}
+
+	if c.Database.BusyTimeout < 0 {
+		return errors.New("database.busy_timeout cannot be negative")
+	}
+	if c.Database.Retry.Attempts < 1 {
+		return errors.New("database.retry.attempts must be at least 1")
+	}
+	if c.Database.Retry.Backoff < 0 {
+		return errors.New("database.retry.backoff cannot be negative")
+	}
+	if c.Database.Retention.TombstoneDays < 0 {
+		return errors.New("database.retention.tombstone_days cannot be negative")
+	}
+	if c.Database.Cache.Enabled && c.Database.Cache.Size < 1 {
+		return errors.New("database.cache.size must be at least 1")
+	}
+	if c.Database.Cache.Enabled && c.Database.Cache.TTL <= 0 {
+		return errors.New("database.cache.ttl must be positive")
+	}
+	if c.Behavior.Timeout < 0 {
+		return errors.New("behavior.timeout cannot be negative")
+	}
+	if c.Display.StaleDays < 0 {
+		return errors.New("display.stale_days cannot be negative")
+	}
+	if c.Display.Language != "" && !i18n.Supported(i18n.Detect(c.Display.Language)) {
+		return fmt.Errorf("display.language: unsupported language %q (supported: %s)", c.Display.Language, strings.Join(i18n.Languages(), ", "))
+	}
+	if c.Display.Timezone != "" {
+		if _, err := time.LoadLocation(c.Display.Timezone); err != nil {
+			return fmt.Errorf("display.timezone: unknown time zone %q", c.Display.Timezone)
 		}
 	}
 
@@ -179,9 +526,205 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid log format: %s (must be json or text)", c.Logging.Format)
 	}
 
+	switch c.Project.Priority {
+	case "", "low", "medium", "high":
+	default:
+		return fmt.Errorf("invalid project priority: %s (must be low, medium, or high)", c.Project.Priority)
+	}
+
+	switch c.Logging.Output {
+	case "", LogOutputStderr, LogOutputFile:
+	default:
+		return fmt.Errorf("invalid log output: %s (must be stderr or file)", c.Logging.Output)
+	}
+	if c.Logging.MaxSizeMB < 0 || c.Logging.MaxBackups < 0 || c.Logging.MaxAge < 0 {
+		return errors.New("logging.max_size_mb, max_backups and max_age cannot be negative")
+	}
+
+	if c.Email.Port < 1 || c.Email.Port > 65535 {
+		return fmt.Errorf("invalid email port: %d", c.Email.Port)
+	}
+
+	if c.Validation.MaxTitleLength < 0 || c.Validation.MaxDescriptionLength < 0 {
+		return errors.New("validation.max_title_length and max_description_length cannot be negative")
+	}
+	for _, priority := range c.Validation.RequireDescription {
+		switch strings.ToLower(priority) {
+		case "low", "medium", "high":
+		default:
+			return fmt.Errorf("invalid validation.require_description priority: %s (must be low, medium, or high)", priority)
+		}
+	}
+
+	for _, name := range c.Logging.Debug {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("logging.debug entries cannot be empty")
+		}
+	}
+
+	for _, hook := range c.Webhooks {
+		if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url: %q (must be an http or https URL)", hook.URL)
+		}
+		for _, name := range hook.Events {
+			if _, err := events.ParseType(name); err != nil {
+				return fmt.Errorf("invalid webhook %s: %w", hook.URL, err)
+			}
+		}
+	}
+
+	if c.Notify.Backend != "" && !slices.Contains(notify.Backends, strings.ToLower(c.Notify.Backend)) {
+		return fmt.Errorf("invalid notifications.backend: %s (must be auto, desktop, log, or none)", c.Notify.Backend)
+	}
+
+	names := make(map[string]bool, len(c.Escalations))
+	for _, esc := range c.Escalations {
+		if esc.Name == "" {
+			return errors.New("escalations need a name")
+		}
+		if names[esc.Name] {
+			return fmt.Errorf("invalid escalations: %q is declared twice", esc.Name)
+		}
+		names[esc.Name] = true
+		switch strings.ToLower(esc.Priority) {
+		case "", "low", "medium", "high":
+		default:
+			return fmt.Errorf("invalid escalation %s priority: %s (must be low, medium, or high)", esc.Name, esc.Priority)
+		}
+		if esc.Overdue < 0 {
+			return fmt.Errorf("escalation %s: overdue cannot be negative", esc.Name)
+		}
+		if esc.Webhook == "" && esc.Email == "" && !esc.Desktop {
+			return fmt.Errorf("escalation %s needs a webhook, an email, or desktop", esc.Name)
+		}
+		if esc.Webhook != "" {
+			if u, err := url.Parse(esc.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid escalation %s webhook: %q (must be an http or https URL)", esc.Name, esc.Webhook)
+			}
+		}
+		if esc.Email != "" {
+			if _, err := mail.ParseAddressList(esc.Email); err != nil {
+				return fmt.Errorf("invalid escalation %s email: %w", esc.Name, err)
+			}
+		}
+	}
+
+	for name, command := range c.Aliases {
+		if name == "" || strings.HasPrefix(name, "-") || strings.ContainsFunc(name, unicode.IsSpace) {
+			return fmt.Errorf("invalid alias name: %q (must be a single word not starting with -)", name)
+		}
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("alias %s has no command", name)
+		}
+	}
+
+	seen := make(map[string]bool, len(c.Fields))
+	for _, def := range c.FieldDefs() {
+		if err := def.Validate(); err != nil {
+			return fmt.Errorf("invalid fields: %w", err)
+		}
+		if seen[def.Name] {
+			return fmt.Errorf("invalid fields: %q is declared twice", def.Name)
+		}
+		seen[def.Name] = true
+	}
+
 	return nil
 }
 
+// DefaultDatabasePath returns the default SQLite database location,
+// $XDG_DATA_HOME/task-manager/tasks.db, or ~/.task-manager/tasks.db while an
+// existing database there has not been migrated
+func DefaultDatabasePath() (string, error) {
+	return xdg.Path(xdg.DataDir, "tasks.db")
+}
+
+// FilePath returns the log file: the configured file, or task.log in the
+// XDG state directory
+func (l LoggingConfig) FilePath() string {
+	if l.File != "" {
+		return l.File
+	}
+	dir, err := xdg.StateDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "task.log")
+}
+
+// ArchivePath returns the SQLite file holding archived tasks: the configured
+// archive path, or "<name>.archive.db" next to the main database
+func (d DatabaseConfig) ArchivePath() string {
+	if d.Archive != "" {
+		return d.Archive
+	}
+	return strings.TrimSuffix(d.Path, filepath.Ext(d.Path)) + ".archive.db"
+}
+
+// Redacted returns a copy of the configuration with secrets masked,
+// suitable for display or inclusion in diagnostics.
+func (c *Config) Redacted() *Config {
+	return c.replaceSecrets(c.maskSecret)
+}
+
+// WithoutSecrets returns a copy of the configuration with secrets removed,
+// suitable for exporting. Secrets read from the keyring are kept as
+// KeyringValue, which carries no secret and tells the reader what to store.
+func (c *Config) WithoutSecrets() *Config {
+	return c.replaceSecrets(func(name, _ string) string {
+		if c.FromKeyring(name) {
+			return KeyringValue
+		}
+		return ""
+	})
+}
+
+// secretFields returns the secret settings of c keyed by their keyring name.
+// Every secret setting must be listed here so it is resolved, masked and
+// left out of exports.
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		secrets.DBPassword:   &c.Database.Password,
+		secrets.DBPassphrase: &c.Database.Passphrase,
+		secrets.SMTPPassword: &c.Email.Password,
+	}
+}
+
+// replaceSecrets returns a copy of c with each secret setting replaced by
+// replace(name, value)
+func (c *Config) replaceSecrets(replace func(name, value string) string) *Config {
+	copied := *c
+	for name, field := range copied.secretFields() {
+		*field = replace(name, *field)
+	}
+	return &copied
+}
+
+// maskSecret returns the masked form of a secret setting. Secrets read from
+// the keyring are shown as KeyringValue, which is how they are configured.
+func (c *Config) maskSecret(name, value string) string {
+	switch {
+	case value == "":
+		return ""
+	case c.FromKeyring(name):
+		return KeyringValue
+	default:
+		return "********"
+	}
+}
+
+// DataDir returns the directory holding the SQLite database and related files
+func (c *Config) DataDir() string {
+	if c.Database.Path != "" {
+		return filepath.Dir(c.Database.Path)
+	}
+	dir, err := xdg.DataDir()
+	if err != nil {
+		return os.TempDir()
+	}
+	return dir
+}
+
 // getEnvOrDefault returns the value of an environment variable or a default value
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -200,3 +743,14 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// splitList splits a comma-separated value into trimmed, non-empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
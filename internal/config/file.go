@@ -0,0 +1,235 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// FilePath returns the configuration file location used by Load: CONFIG_FILE
+// when set, config.yaml in the working directory if it exists, and the user
+// config file (see UserFilePath) otherwise
+func FilePath() string {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return path
+	}
+	if _, err := os.Stat("config.yaml"); err == nil {
+		return "config.yaml"
+	}
+	if path, err := UserFilePath(); err == nil {
+		return path
+	}
+	return "config.yaml"
+}
+
+// UserFilePath returns the per-user config file,
+// $XDG_CONFIG_HOME/task-manager/config.yaml (~/.config when unset)
+func UserFilePath() (string, error) {
+	dir, err := xdg.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// WriteDefaultFile writes a commented config file with the default settings
+// to path, creating its directory. An existing file is only replaced when
+// overwrite is set.
+func WriteDefaultFile(path string, overwrite bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+
+	f, err := os.OpenFile(path, flags, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+
+	if _, err := f.WriteString(defaultFile); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return f.Close()
+}
+
+// Keys returns the dotted names of the scalar settings that SetFileValue can
+// change, e.g. "database.path", in declaration order. Lists and maps such
+// as rules and display.theme are edited in the file directly.
+func Keys() []string {
+	return appendKeys(nil, "", reflect.TypeOf(Config{}))
+}
+
+// appendKeys appends the scalar setting names of struct type t under prefix
+func appendKeys(keys []string, prefix string, t reflect.Type) []string {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = appendKeys(keys, prefix+name+".", field.Type)
+		case reflect.Slice, reflect.Map:
+		default:
+			keys = append(keys, prefix+name)
+		}
+	}
+	return keys
+}
+
+// IsKey reports whether key is one of Keys
+func IsKey(key string) bool {
+	for _, k := range Keys() {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// SetFileValue sets a dotted key (e.g. "database.path") in the YAML file at path,
+// creating the file and any intermediate mappings if needed. Existing comments
+// and unrelated keys are preserved.
+func SetFileValue(path, key, value string) error {
+	if key == "" {
+		return errors.New("config key cannot be empty")
+	}
+
+	doc := &yaml.Node{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return fmt.Errorf("failed to parse config file: %w", err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	node := doc.Content[0]
+	if node.Kind != yaml.MappingNode {
+		return errors.New("config file root must be a mapping")
+	}
+
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		child := mappingValue(node, part)
+		last := i == len(parts)-1
+
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if last {
+				child = &yaml.Node{Kind: yaml.ScalarNode}
+			}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: part},
+				child,
+			)
+		}
+
+		if last {
+			if child.Kind != yaml.ScalarNode {
+				return fmt.Errorf("config key %s is not a scalar value", key)
+			}
+			child.Value = value
+			child.Tag = ""
+			child.Style = 0
+			break
+		}
+
+		if child.Kind != yaml.MappingNode {
+			return fmt.Errorf("config key %s is not a section", strings.Join(parts[:i+1], "."))
+		}
+		node = child
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	out := buf.Bytes()
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, out, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// FileValue returns the value of a dotted key in the YAML file at path, or
+// an empty string when the file or key does not exist
+func FileValue(path, key string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return "", fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return "", nil
+	}
+
+	node := doc.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return "", nil
+		}
+		if node = mappingValue(node, part); node == nil {
+			return "", nil
+		}
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("config key %s is not a scalar value", key)
+	}
+	return node.Value, nil
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileNames are the per-directory config files looked for, in order,
+// in the working directory and each of its parents
+var ProjectFileNames = []string{".task.yaml", ".taskrc"}
+
+// ProjectConfig holds defaults for tasks created and listed in a project
+// directory, usually set in a .task.yaml at the root of a code repository
+type ProjectConfig struct {
+	Context  string `yaml:"context,omitempty"`  // context for new tasks and list filtering, in place of the active context
+	Priority string `yaml:"priority,omitempty"` // priority for new tasks when --priority is not given
+}
+
+// FindProjectFile returns the nearest project config file in dir or one of
+// its parents, or an empty string if there is none
+func FindProjectFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	for {
+		for _, name := range ProjectFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("failed to check %s: %w", path, err)
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// projectSettings is what an untrusted project config file may set: the
+// project defaults, where the database is, and how dates and messages are
+// shown. Anything that runs commands, makes network calls, or writes
+// elsewhere needs the file to be trusted first (see TrustProjectFile).
+type projectSettings struct {
+	Project  ProjectConfig `yaml:"project"`
+	Database struct {
+		Path    string `yaml:"path"`
+		Archive string `yaml:"archive"`
+	} `yaml:"database"`
+	Display struct {
+		DateFormat string `yaml:"date_format"`
+		TimeFormat string `yaml:"time_format"`
+		Timezone   string `yaml:"timezone"`
+		Language   string `yaml:"language"`
+	} `yaml:"display"`
+}
+
+// loadProjectFile merges the project config file at path over cfg. File
+// paths in it are relative to the directory holding the file, so a project
+// can keep its database next to its code. Unless the file is trusted, only
+// the settings of projectSettings are allowed and any other key is an error.
+func loadProjectFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read project config %s: %w", path, err)
+	}
+
+	keys, err := fileKeys(data)
+	if err != nil {
+		return fmt.Errorf("failed to load project config %s: %w", path, err)
+	}
+	// The pager runs on every long listing, so only the user may choose it
+	if slices.Contains(keys, "display.pager") {
+		return fmt.Errorf("project config %s cannot set display.pager, even when trusted; "+
+			"set it in your own config file or TASK_PAGER instead", path)
+	}
+
+	trusted, err := projectTrusted(path, data)
+	if err != nil {
+		return err
+	}
+
+	paths := []*string{&cfg.Database.Path, &cfg.Database.Archive, &cfg.Logging.File}
+	before := make([]string, len(paths))
+	for i, p := range paths {
+		before[i] = *p
+	}
+
+	if trusted {
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("failed to load project config %s: %w", path, err)
+		}
+	} else {
+		var denied []string
+		for _, key := range keys {
+			if !slices.Contains(projectKeys, key) {
+				denied = append(denied, key)
+			}
+		}
+		if len(denied) > 0 {
+			return fmt.Errorf("project config %s is not trusted, so it cannot set %s; review it, then run "+
+				"\"TASK_NO_PROJECT=1 task config trust %s\" to allow it", path, strings.Join(denied, ", "), path)
+		}
+		var project projectSettings
+		if err := yaml.Unmarshal(data, &project); err != nil {
+			return fmt.Errorf("failed to load project config %s: %w", path, err)
+		}
+		project.apply(cfg)
+	}
+
+	for i, p := range paths {
+		if *p != before[i] && *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(filepath.Dir(path), *p)
+		}
+	}
+
+	cfg.projectFile = path
+	return nil
+}
+
+// projectKeys are the settings of projectSettings, as dotted keys
+var projectKeys = []string{
+	"project.context", "project.priority",
+	"database.path", "database.archive",
+	"display.date_format", "display.time_format", "display.timezone", "display.language",
+}
+
+// fileKeys returns the keys set in a config file, as dotted keys down to
+// the second level
+func fileKeys(data []byte) ([]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var keys []string
+	top := doc.Content[0].Content
+	for i := 0; i+1 < len(top); i += 2 {
+		section, value := top[i].Value, top[i+1]
+		if value.Kind != yaml.MappingNode {
+			keys = append(keys, section)
+			continue
+		}
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			keys = append(keys, section+"."+value.Content[j].Value)
+		}
+	}
+	return keys, nil
+}
+
+// apply merges the settings given in the project file over cfg
+func (p *projectSettings) apply(cfg *Config) {
+	set := func(dst *string, value string) {
+		if value != "" {
+			*dst = value
+		}
+	}
+	set(&cfg.Project.Context, p.Project.Context)
+	set(&cfg.Project.Priority, p.Project.Priority)
+	set(&cfg.Database.Path, p.Database.Path)
+	set(&cfg.Database.Archive, p.Database.Archive)
+	set(&cfg.Display.DateFormat, p.Display.DateFormat)
+	set(&cfg.Display.TimeFormat, p.Display.TimeFormat)
+	set(&cfg.Display.Timezone, p.Display.Timezone)
+	set(&cfg.Display.Language, p.Display.Language)
+}
+
+// trustFile is where TrustProjectFile records trusted project files,
+// $XDG_STATE_HOME/task-manager/trusted-projects.json
+func trustFile() (string, error) {
+	return xdg.Path(xdg.StateDir, "trusted-projects.json")
+}
+
+// readTrusted returns the trusted project files: the SHA-256 of the
+// content trusted, by absolute path
+func readTrusted() (map[string]string, error) {
+	path, err := trustFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted projects: %w", err)
+	}
+	trusted := map[string]string{}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("failed to parse trusted projects %s: %w", path, err)
+	}
+	return trusted, nil
+}
+
+// projectTrusted reports whether the project file at path was trusted with
+// its current content; any change to the file revokes the trust
+func projectTrusted(path string, data []byte) (bool, error) {
+	trusted, err := readTrusted()
+	if err != nil {
+		return false, err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return trusted[abs] == hex.EncodeToString(sum[:]), nil
+}
+
+// TrustProjectFile allows the project file at path, as it is now, to set
+// any setting, like "direnv allow". Editing the file revokes the trust.
+func TrustProjectFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read project config: %w", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	trusted, err := readTrusted()
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	trusted[abs] = hex.EncodeToString(sum[:])
+	return writeTrusted(trusted)
+}
+
+// UntrustProjectFile revokes the trust given to the project file at path
+func UntrustProjectFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	trusted, err := readTrusted()
+	if err != nil {
+		return err
+	}
+	delete(trusted, abs)
+	return writeTrusted(trusted)
+}
+
+// writeTrusted saves the trusted project files, readable by the user only
+func writeTrusted(trusted map[string]string) error {
+	path, err := trustFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trusted projects: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write trusted projects: %w", err)
+	}
+	return nil
+}
+
+// ProjectFile returns the project config file merged into the configuration,
+// or an empty string if none was found
+func (c *Config) ProjectFile() string {
+	return c.projectFile
+}
@@ -0,0 +1,145 @@
+package config
+
+// defaultFile is the config file written by "task config init". Every
+// setting is shown with its default value; environment variables listed
+// next to a setting override it.
+const defaultFile = `# Task Manager configuration
+#
+# Settings left commented out use the default shown. Change values here or
+# with "task config set <key> <value>", and check the result with
+# "task config show".
+
+database:
+  type: sqlite                 # sqlite or postgres (DB_TYPE)
+  # path: ""                   # SQLite file (DB_PATH); defaults to $XDG_DATA_HOME/task-manager/tasks.db
+  # archive: ""                # where "task archive" moves old completed tasks; defaults next to path
+  # passphrase: ""             # unlocks a database encrypted with "task encrypt" (TASK_PASSPHRASE);
+  #                            # "keyring" reads it from the OS keyring, see "task config set-secret"
+
+  # Handling "database is locked" when several processes share the file
+  # busy_timeout: 0s           # how long SQLite waits for another process's lock
+  # write_lock: false          # take the write lock when a transaction begins (BEGIN IMMEDIATE)
+  retry:
+    attempts: 3                # total attempts for a busy write (1 disables retries)
+    backoff: 100ms             # first retry delay, doubled after each attempt
+
+  # Keeping recently read tasks in memory; writes by other processes show
+  # once the cached entries expire
+  cache:
+    enabled: false
+    size: 1000                 # tasks, and separately listings, kept at most
+    ttl: 30s                   # how long a cached entry is used
+
+  # What "task maintenance" prunes
+  retention:
+    tombstone_days: 0          # keep deletion records for "task sync" this long, 0 for ever
+
+  # PostgreSQL
+  # host: localhost            # DB_HOST
+  # port: 5432                 # DB_PORT
+  # name: taskmanager          # DB_NAME
+  # user: ""                   # DB_USER
+  # password: ""               # DB_PASSWORD; "keyring" reads it from the OS keyring
+  # ssl_mode: disable          # DB_SSL_MODE
+
+logging:
+  level: info                  # debug, info, warn, error (LOG_LEVEL)
+  format: text                 # text or json (LOG_FORMAT)
+  # Subsystems logged at debug level regardless of level (LOG_DEBUG):
+  # cli, service, repository, storage, sync, http
+  # debug: [repository]
+  # output: stderr             # stderr or file (LOG_OUTPUT); view the file with "task logs tail"
+  # file: ""                   # log file (LOG_FILE); defaults to $XDG_STATE_HOME/task-manager/task.log
+  # max_size_mb: 10            # rotate when the file would grow past this size
+  # max_backups: 5             # rotated files kept, 0 keeps all
+  # max_age: 720h              # rotated files older than this are removed, 0 keeps them
+
+display:
+  # Command run when "task" is invoked with no arguments
+  # default_command: "list --status pending"
+
+  # Go template applied to each task by "task list" (overridden by --format)
+  # list_format: '{{short .ID}} {{.Title}} ({{.Priority}})'
+
+  # Colors per role: header, id, priority.high, priority.medium, priority.low,
+  # completed, overdue, success
+  # theme:
+  #   priority.high: "bold red"
+
+  # How dates and times are shown, as Go layouts, and in which time zone
+  # date_format: "02/01/2006"
+  # time_format: "3:04PM"
+  # timezone: "Europe/Lisbon"
+
+  # Pager for long list and get output (default $PAGER, or less); "cat" disables it
+  # pager: "less -R"
+
+  # Language of messages (en, pt); default from LC_ALL, LC_MESSAGES, or LANG
+  # language: "pt"
+
+  # Days without activity after which list counts pending tasks as stale
+  # stale_days: 30
+
+behavior:
+  follow_up_prompt: false      # offer a follow-up task after "task complete"
+  confirm_delete: true         # ask before deleting tasks (--yes skips)
+  # timeout: 0s                # give up on any command after this long; 0 waits forever
+
+validation:
+  max_title_length: 200        # in characters, 0 for no limit
+  max_description_length: 10000
+  trim_whitespace: true        # trim titles and descriptions, collapse spaces in titles
+  # require_description: [high] # priorities whose tasks need a description
+  # banned_characters: "<>"    # characters rejected in titles and descriptions
+
+user:
+  # name: ""                   # current user in a shared database (TASK_USER)
+  # location: ""               # this machine's location; the host name when empty (TASK_LOCATION)
+
+tracing:
+  enabled: false               # export OpenTelemetry spans over OTLP/HTTP
+  # endpoint: http://localhost:4318
+
+# Where notifications ("task overdue --notify") are shown
+# notifications:
+#   backend: auto              # the desktop, or the log where there is none; desktop, log, or none
+
+# SMTP server for "task digest"
+# email:
+#   host: smtp.example.com
+#   port: 587                  # STARTTLS; 465 uses implicit TLS
+#   username: me@example.com
+#   password: keyring          # SMTP_PASSWORD; "keyring" reads it from the OS keyring
+#   from: ""                   # sender address; defaults to username
+#   to: me@example.com         # default recipients, comma separated
+
+# Per-project defaults usually go in a .task.yaml at the root of a project
+# instead; it is merged over this file when running inside that directory
+# project:
+#   context: work              # context for new tasks and list, in place of the active context
+#   priority: medium           # priority for new tasks without --priority
+
+# URLs that receive task events (task.created, task.updated, task.completed,
+# task.deleted, task.overdue, task.delegated) as JSON POSTs
+# webhooks:
+#   - url: https://hooks.example.com/tasks
+#     events: [task.created, task.completed]   # every event when omitted
+
+# Who to tell about tasks that stay overdue ("task escalations run")
+# escalations:
+#   - name: urgent-and-late
+#     priority: high
+#     overdue: 48h
+#     webhook: https://hooks.slack.com/services/...
+
+# Team conventions checked on every create and update
+# rules:
+#   - name: office-needs-description
+#     when: {context: office}
+#     require: [description]
+#     message: office tasks need a description
+
+# Custom commands; arguments after the alias are appended
+# aliases:
+#   urgent: list --status pending --priority high --oneline
+`
@@ -0,0 +1,85 @@
+// Package crash records unexpected panics as crash report files so users get
+// a short message and a file to attach to a bug report instead of a raw
+// goroutine dump.
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Report describes a recovered panic
+type Report struct {
+	Time      time.Time
+	Version   string
+	Command   string
+	Panic     interface{}
+	Stack     []byte
+	Config    *config.Config
+	GoVersion string
+	Platform  string
+}
+
+// NewReport creates a report for a recovered panic value and its stack trace
+func NewReport(version, command string, recovered interface{}, stack []byte, cfg *config.Config) *Report {
+	return &Report{
+		Time:      time.Now(),
+		Version:   version,
+		Command:   command,
+		Panic:     recovered,
+		Stack:     stack,
+		Config:    cfg,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+// String formats the report as plain text. Secrets in the configuration are redacted.
+func (r *Report) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Task Manager crash report\n")
+	fmt.Fprintf(&sb, "Time:     %s\n", r.Time.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Version:  %s\n", r.Version)
+	fmt.Fprintf(&sb, "Go:       %s\n", r.GoVersion)
+	fmt.Fprintf(&sb, "Platform: %s\n", r.Platform)
+	if r.Command != "" {
+		fmt.Fprintf(&sb, "Command:  %s\n", r.Command)
+	}
+	fmt.Fprintf(&sb, "\nPanic: %v\n\n", r.Panic)
+	fmt.Fprintf(&sb, "Stack:\n%s\n", r.Stack)
+
+	if r.Config != nil {
+		data, err := yaml.Marshal(r.Config.Redacted())
+		if err != nil {
+			fmt.Fprintf(&sb, "\nConfig: <failed to encode: %v>\n", err)
+		} else {
+			fmt.Fprintf(&sb, "\nConfig:\n%s", data)
+		}
+	}
+
+	return sb.String()
+}
+
+// Write saves the report as a timestamped file in dir and returns its path
+func (r *Report) Write(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+
+	name := fmt.Sprintf("crash-%s.txt", r.Time.UTC().Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, []byte(r.String()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,362 @@
+// Package dbsync reconciles two task databases, such as the copies on a
+// laptop and a desktop. Conflicting edits are resolved by updated_at (the
+// most recent edit wins) and deletions are propagated through tombstones,
+// so a task deleted on one side is not resurrected by the other.
+package dbsync
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/repository"
+)
+
+// Replica is one side of a sync
+type Replica struct {
+	Tasks      domain.TaskRepository
+	Tombstones domain.TombstoneRepository
+	Users      domain.UserRepository
+
+	// Tx, if set, runs the changes a sync makes to the replica in one
+	// transaction
+	Tx domain.TxManager
+}
+
+// NewSQLiteReplica creates a replica backed by a SQLite database
+func NewSQLiteReplica(db *sql.DB, logger *slog.Logger) *Replica {
+	tasks := repository.NewSQLiteTaskRepository(db, logger)
+	return &Replica{
+		Tasks:      tasks,
+		Tombstones: tasks,
+		Users:      repository.NewSQLiteUserRepository(db, logger),
+		Tx:         repository.NewTxManager(db, logger),
+	}
+}
+
+// withTx runs fn in a transaction of the replica, or simply runs it
+// without a TxManager
+func (r *Replica) withTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.Tx == nil {
+		return fn(ctx)
+	}
+	return r.Tx.WithTx(ctx, fn)
+}
+
+// ActionKind describes what a sync action does
+type ActionKind int
+
+const (
+	// PullUser copies a user from the remote to the local database
+	PullUser ActionKind = iota
+	// PushUser copies a user from the local to the remote database
+	PushUser
+	// PullCreate copies a task that only exists remotely
+	PullCreate
+	// PushCreate copies a task that only exists locally
+	PushCreate
+	// PullUpdate overwrites the local task with a newer remote edit
+	PullUpdate
+	// PushUpdate overwrites the remote task with a newer local edit
+	PushUpdate
+	// DeleteLocal applies a remote tombstone to the local database
+	DeleteLocal
+	// DeleteRemote applies a local tombstone to the remote database
+	DeleteRemote
+)
+
+// String returns a human-readable name for the action kind
+func (k ActionKind) String() string {
+	switch k {
+	case PullUser:
+		return "pull user"
+	case PushUser:
+		return "push user"
+	case PullCreate:
+		return "pull new"
+	case PushCreate:
+		return "push new"
+	case PullUpdate:
+		return "pull update"
+	case PushUpdate:
+		return "push update"
+	case DeleteLocal:
+		return "delete local"
+	case DeleteRemote:
+		return "delete remote"
+	default:
+		return "unknown"
+	}
+}
+
+// Action is a single change needed to bring the two databases in line.
+// For deletes, Task is the task being removed, or nil when only the
+// tombstone itself needs to be recorded.
+type Action struct {
+	Kind      ActionKind
+	Task      *domain.Task
+	User      *domain.User
+	Tombstone *domain.Tombstone
+}
+
+// Plan is the ordered list of actions for one sync
+type Plan struct {
+	Actions []Action
+}
+
+// Count returns the number of actions of the given kind. Deletes that only
+// record a tombstone are not counted.
+func (p *Plan) Count(kind ActionKind) int {
+	n := 0
+	for _, a := range p.Actions {
+		if a.Kind == kind && !(isDelete(kind) && a.Task == nil) {
+			n++
+		}
+	}
+	return n
+}
+
+// Empty reports whether the databases are already in sync
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// local reports whether actions of kind change the local replica
+func (k ActionKind) local() bool {
+	return k == PullUser || k == PullCreate || k == PullUpdate || k == DeleteLocal
+}
+
+// isDelete reports whether kind applies a tombstone
+func isDelete(kind ActionKind) bool {
+	return kind == DeleteLocal || kind == DeleteRemote
+}
+
+// Syncer reconciles a local and a remote replica
+type Syncer struct {
+	local  *Replica
+	remote *Replica
+	logger *slog.Logger
+}
+
+// NewSyncer creates a new syncer
+func NewSyncer(local, remote *Replica, logger *slog.Logger) *Syncer {
+	return &Syncer{
+		local:  local,
+		remote: remote,
+		logger: logger,
+	}
+}
+
+// snapshot is the sync-relevant content of one replica
+type snapshot struct {
+	tasks      map[string]*domain.Task
+	tombstones map[string]*domain.Tombstone
+	users      map[string]*domain.User
+}
+
+// load reads the tasks, tombstones, and users of a replica
+func load(ctx context.Context, r *Replica) (*snapshot, error) {
+	tasks, err := r.Tasks.List(ctx, domain.TaskFilter{})
+	if err != nil {
+		return nil, err
+	}
+	tombstones, err := r.Tombstones.ListTombstones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := r.Users.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &snapshot{
+		tasks:      make(map[string]*domain.Task, len(tasks)),
+		tombstones: make(map[string]*domain.Tombstone, len(tombstones)),
+		users:      make(map[string]*domain.User, len(users)),
+	}
+	for _, t := range tasks {
+		s.tasks[t.ID] = t
+	}
+	for _, t := range tombstones {
+		s.tombstones[t.TaskID] = t
+	}
+	for _, u := range users {
+		s.users[u.Name] = u
+	}
+	return s, nil
+}
+
+// Plan compares the two replicas and returns the actions needed to reconcile them
+func (s *Syncer) Plan(ctx context.Context) (*Plan, error) {
+	local, err := load(ctx, s.local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local database: %w", err)
+	}
+	remote, err := load(ctx, s.remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote database: %w", err)
+	}
+
+	plan := &Plan{}
+
+	// Users are only ever added, so assignees exist on both sides
+	for _, name := range sortedKeys(remote.users) {
+		if _, ok := local.users[name]; !ok {
+			plan.Actions = append(plan.Actions, Action{Kind: PullUser, User: remote.users[name]})
+		}
+	}
+	for _, name := range sortedKeys(local.users) {
+		if _, ok := remote.users[name]; !ok {
+			plan.Actions = append(plan.Actions, Action{Kind: PushUser, User: local.users[name]})
+		}
+	}
+
+	// Tasks present on at least one side; the newer edit wins
+	ids := sortedKeys(local.tasks)
+	for _, id := range sortedKeys(remote.tasks) {
+		if _, ok := local.tasks[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		l, r := local.tasks[id], remote.tasks[id]
+		switch {
+		case l != nil && r != nil:
+			if l.UpdatedAt.After(r.UpdatedAt) {
+				plan.Actions = append(plan.Actions, Action{Kind: PushUpdate, Task: l})
+			} else if r.UpdatedAt.After(l.UpdatedAt) {
+				plan.Actions = append(plan.Actions, Action{Kind: PullUpdate, Task: r})
+			}
+		case l != nil:
+			if !deletedAfter(remote.tombstones[id], l) {
+				plan.Actions = append(plan.Actions, Action{Kind: PushCreate, Task: l})
+			}
+		default:
+			if !deletedAfter(local.tombstones[id], r) {
+				plan.Actions = append(plan.Actions, Action{Kind: PullCreate, Task: r})
+			}
+		}
+	}
+
+	// Tombstones are propagated unless the task was edited after the delete
+	for _, id := range sortedKeys(remote.tombstones) {
+		t := remote.tombstones[id]
+		if lt := local.tombstones[id]; lt != nil && !t.DeletedAt.After(lt.DeletedAt) {
+			continue
+		}
+		if l := local.tasks[id]; l == nil || deletedAfter(t, l) {
+			plan.Actions = append(plan.Actions, Action{Kind: DeleteLocal, Task: l, Tombstone: t})
+		}
+	}
+	for _, id := range sortedKeys(local.tombstones) {
+		t := local.tombstones[id]
+		if rt := remote.tombstones[id]; rt != nil && !t.DeletedAt.After(rt.DeletedAt) {
+			continue
+		}
+		if r := remote.tasks[id]; r == nil || deletedAfter(t, r) {
+			plan.Actions = append(plan.Actions, Action{Kind: DeleteRemote, Task: r, Tombstone: t})
+		}
+	}
+
+	return plan, nil
+}
+
+// Apply executes a plan produced by Plan. The changes to each replica are
+// made in one transaction of its Tx, the local replica first, so a failed
+// sync leaves each database either fully updated or as it was. Without a
+// transaction across both, a failure on the remote side keeps the local
+// changes; syncing again completes the remote side.
+func (s *Syncer) Apply(ctx context.Context, plan *Plan) error {
+	for _, local := range []bool{true, false} {
+		side := s.remote
+		if local {
+			side = s.local
+		}
+		err := side.withTx(ctx, func(ctx context.Context) error {
+			for _, a := range plan.Actions {
+				if a.Kind.local() != local {
+					continue
+				}
+				if err := s.apply(ctx, a); err != nil {
+					return fmt.Errorf("failed to %s: %w", a.Kind, err)
+				}
+				s.logger.Debug("Sync action applied", "action", a.Kind.String(), "task_id", taskID(a))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("Sync completed", "actions", len(plan.Actions))
+	return nil
+}
+
+// apply executes a single action
+func (s *Syncer) apply(ctx context.Context, a Action) error {
+	switch a.Kind {
+	case PullUser:
+		return s.local.Users.Create(ctx, a.User)
+	case PushUser:
+		return s.remote.Users.Create(ctx, a.User)
+	case PullCreate:
+		return s.local.Tasks.Create(ctx, a.Task)
+	case PushCreate:
+		return s.remote.Tasks.Create(ctx, a.Task)
+	case PullUpdate:
+		return s.local.Tasks.Update(ctx, a.Task)
+	case PushUpdate:
+		return s.remote.Tasks.Update(ctx, a.Task)
+	case DeleteLocal:
+		return s.local.Tombstones.ApplyTombstone(ctx, a.Tombstone)
+	case DeleteRemote:
+		return s.remote.Tombstones.ApplyTombstone(ctx, a.Tombstone)
+	default:
+		return fmt.Errorf("unknown sync action %d", a.Kind)
+	}
+}
+
+// Sync plans and applies a reconciliation in one step
+func (s *Syncer) Sync(ctx context.Context) (*Plan, error) {
+	plan, err := s.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Apply(ctx, plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// deletedAfter reports whether tombstone t is at least as recent as the last edit of task
+func deletedAfter(t *domain.Tombstone, task *domain.Task) bool {
+	return t != nil && !task.UpdatedAt.After(t.DeletedAt)
+}
+
+// taskID returns the ID of the task an action refers to, if any
+func taskID(a Action) string {
+	switch {
+	case a.Task != nil:
+		return a.Task.ID
+	case a.Tombstone != nil:
+		return a.Tombstone.TaskID
+	default:
+		return ""
+	}
+}
+
+// sortedKeys returns the keys of m in sorted order so plans are deterministic
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,199 @@
+// Package digest builds a summary of overdue, due-today, and recently
+// completed tasks, renders it as plain text and HTML, and sends it by email.
+// It is meant to be run from cron with "task digest".
+package digest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// Tasks is the part of the task service used to build a digest
+type Tasks interface {
+	ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error)
+}
+
+// Entry is a task in a digest
+type Entry struct {
+	Task *domain.Task
+	Due  time.Time // zero for completed tasks without a due date
+}
+
+// Digest is a summary of tasks at a point in time
+type Digest struct {
+	Now       time.Time
+	Since     time.Time // start of the "recently completed" window
+	Overdue   []Entry   // pending tasks due before today
+	DueToday  []Entry   // pending tasks due today
+	Completed []Entry   // tasks completed since Since
+	Pinned    []Entry   // other pending tasks, pinned; only used by Standup
+}
+
+// Build summarizes tasks as of now: pending tasks due before or on today,
+// and tasks completed within the last window. Due dates come from
+// task metadata (see domain.DueDateKeys). filter narrows the tasks
+// considered, e.g. to one context; its status is ignored.
+func Build(ctx context.Context, tasks Tasks, filter domain.TaskFilter, now time.Time, window time.Duration) (*Digest, error) {
+	d := &Digest{Now: now, Since: now.Add(-window)}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// Snoozed tasks are left out until they wake
+	pending := domain.TaskStatusPending
+	filter.Status = &pending
+	filter.AwakeAt = &now
+	open, err := tasks.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending tasks: %w", err)
+	}
+	for _, task := range open {
+		due, ok := task.DueDate()
+		switch {
+		case ok && due.Before(today):
+			d.Overdue = append(d.Overdue, Entry{Task: task, Due: due})
+		case ok && due.Equal(today):
+			d.DueToday = append(d.DueToday, Entry{Task: task, Due: due})
+		case task.Pinned:
+			d.Pinned = append(d.Pinned, Entry{Task: task, Due: due})
+		}
+	}
+
+	completed := domain.TaskStatusCompleted
+	filter.Status = &completed
+	filter.AwakeAt = nil
+	done, err := tasks.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completed tasks: %w", err)
+	}
+	for _, task := range done {
+		if task.CompletedAt != nil && !task.CompletedAt.Before(d.Since) {
+			due, _ := task.DueDate()
+			d.Completed = append(d.Completed, Entry{Task: task, Due: due})
+		}
+	}
+
+	// Oldest due date first, then highest priority
+	byDue := func(entries []Entry) {
+		sort.SliceStable(entries, func(i, j int) bool {
+			if !entries[i].Due.Equal(entries[j].Due) {
+				return entries[i].Due.Before(entries[j].Due)
+			}
+			return priorityRank(entries[i].Task.Priority) < priorityRank(entries[j].Task.Priority)
+		})
+	}
+	byDue(d.Overdue)
+	byDue(d.DueToday)
+	byDue(d.Pinned)
+	sort.SliceStable(d.Completed, func(i, j int) bool {
+		return d.Completed[i].Task.CompletedAt.After(*d.Completed[j].Task.CompletedAt)
+	})
+
+	return d, nil
+}
+
+// Empty reports whether the digest has nothing to report
+func (d *Digest) Empty() bool {
+	return len(d.Overdue)+len(d.DueToday)+len(d.Completed) == 0
+}
+
+// Subject returns an email subject line summarizing the digest
+func (d *Digest) Subject() string {
+	return fmt.Sprintf("Task digest for %s: %d overdue, %d due today, %d completed",
+		d.Now.Format("Mon 2 Jan"), len(d.Overdue), len(d.DueToday), len(d.Completed))
+}
+
+// section is a titled group of entries, shared by both renderings
+type section struct {
+	Title   string
+	Entries []Entry
+	Empty   string
+}
+
+// sections returns the digest grouped for rendering
+func (d *Digest) sections() []section {
+	return []section{
+		{"Overdue", d.Overdue, "Nothing overdue."},
+		{"Due today", d.DueToday, "Nothing due today."},
+		{"Completed since " + d.Since.Format("Mon 2 Jan 15:04"), d.Completed, "Nothing completed."},
+	}
+}
+
+// Text renders the digest as plain text
+func (d *Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task digest for %s\n", d.Now.Format("Monday 2 January 2006"))
+
+	for _, s := range d.sections() {
+		fmt.Fprintf(&b, "\n%s (%d)\n", s.Title, len(s.Entries))
+		if len(s.Entries) == 0 {
+			fmt.Fprintf(&b, "  %s\n", s.Empty)
+		}
+		for _, e := range s.Entries {
+			fmt.Fprintf(&b, "  - %s %s\n", e.Task.Title, details(e))
+		}
+	}
+	return b.String()
+}
+
+// htmlTemplate renders the digest as a simple HTML email
+var htmlTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{"details": details}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #222;">
+<h2>Task digest for {{.Now.Format "Monday 2 January 2006"}}</h2>
+{{range .Sections}}
+<h3>{{.Title}} ({{len .Entries}})</h3>
+{{if .Entries}}<ul>
+{{range .Entries}}<li>{{if eq .Task.Priority "high"}}<strong>{{.Task.Title}}</strong>{{else}}{{.Task.Title}}{{end}}
+<span style="color: #777;">{{details .}}</span></li>
+{{end}}</ul>{{else}}<p style="color: #777;">{{.Empty}}</p>{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the digest as an HTML document
+func (d *Digest) HTML() (string, error) {
+	var b bytes.Buffer
+	data := struct {
+		Now      time.Time
+		Sections []section
+	}{d.Now, d.sections()}
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return b.String(), nil
+}
+
+// details renders the priority, context, and dates of an entry, e.g.
+// "[high] @home (due Mon 12 Oct)"
+func details(e Entry) string {
+	parts := []string{"[" + string(e.Task.Priority) + "]"}
+	if e.Task.Context != "" {
+		parts = append(parts, "@"+e.Task.Context)
+	}
+	if !e.Due.IsZero() && e.Task.Status == domain.TaskStatusPending {
+		parts = append(parts, "(due "+e.Due.Format("Mon 2 Jan")+")")
+	}
+	if e.Task.CompletedAt != nil {
+		parts = append(parts, "(done "+e.Task.CompletedAt.Local().Format("Mon 2 Jan 15:04")+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// priorityRank orders priorities from high to low
+func priorityRank(p domain.TaskPriority) int {
+	switch p {
+	case domain.TaskPriorityHigh:
+		return 0
+	case domain.TaskPriorityMedium:
+		return 1
+	default:
+		return 2
+	}
+}
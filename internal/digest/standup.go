@@ -0,0 +1,59 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// StandupFormat is the markup a standup update is written in
+type StandupFormat string
+
+const (
+	StandupMarkdown StandupFormat = "markdown"
+	StandupSlack    StandupFormat = "slack" // Slack's mrkdwn
+)
+
+// Standup renders the digest as a standup update: the tasks completed since
+// d.Since under "Yesterday", and the overdue, due-today, and pinned tasks
+// under "Today".
+func (d *Digest) Standup(format StandupFormat) (string, error) {
+	var heading func(string) string
+	var bullet string
+	switch format {
+	case StandupMarkdown:
+		heading = func(s string) string { return "**" + s + "**" }
+		bullet = "-"
+	case StandupSlack:
+		heading = func(s string) string { return "*" + s + "*" }
+		bullet = "•"
+	default:
+		return "", fmt.Errorf("invalid standup format: %s (must be markdown or slack)", format)
+	}
+
+	today := make([]Entry, 0, len(d.Overdue)+len(d.DueToday)+len(d.Pinned))
+	today = append(append(append(today, d.Overdue...), d.DueToday...), d.Pinned...)
+
+	var b strings.Builder
+	for i, s := range []section{
+		{"Yesterday", d.Completed, "Nothing completed."},
+		{"Today", today, "Nothing due."},
+	} {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s\n", heading(s.Title))
+		if len(s.Entries) == 0 {
+			fmt.Fprintf(&b, "%s _%s_\n", bullet, s.Empty)
+		}
+		for _, e := range s.Entries {
+			fmt.Fprintf(&b, "%s %s", bullet, e.Task.Title)
+			if !e.Due.IsZero() && e.Task.Status == domain.TaskStatusPending {
+				fmt.Fprintf(&b, " (due %s)", e.Due.Format("Mon 2 Jan"))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), nil
+}
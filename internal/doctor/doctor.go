@@ -0,0 +1,240 @@
+// Package doctor diagnoses an installation: the configuration, the database
+// connection, schema and integrity, and the permissions and free space of
+// the database files. Each check reports its status and, for problems, a
+// suggested fix.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/storage"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	OK   Status = iota
+	Warn        // works, but worth fixing
+	Fail        // broken
+)
+
+// String returns the status name
+func (s Status) String() string {
+	switch s {
+	case Warn:
+		return "warn"
+	case Fail:
+		return "fail"
+	}
+	return "ok"
+}
+
+// Check is the result of one diagnosis
+type Check struct {
+	Name   string
+	Status Status
+	Detail string // what was found
+	Fix    string // suggested fix for Warn and Fail
+}
+
+// Options are what Run examines
+type Options struct {
+	ConfigFile string                 // config file in use; missing is fine
+	Config     *config.Config         // effective configuration
+	Storage    *storage.SQLiteStorage // nil when not using SQLite
+	Full       bool                   // run integrity_check instead of the faster quick_check
+}
+
+// minFreeSpace is the free space below which the disk check warns
+const minFreeSpace = 100 << 20
+
+// Run runs every check, in order. Checks needing the database are skipped
+// when it cannot be reached.
+func Run(ctx context.Context, opts Options) []Check {
+	checks := []Check{checkConfig(opts)}
+	if opts.Storage == nil {
+		return checks
+	}
+
+	connected := checkConnection(ctx, opts.Storage)
+	checks = append(checks, connected)
+	if connected.Status == Fail {
+		return checks
+	}
+
+	return append(checks,
+		checkSchema(ctx, opts.Storage),
+		checkIntegrity(ctx, opts.Storage, opts.Full),
+		checkPermissions(opts.Storage.Path()),
+		checkDiskSpace(opts.Storage.Path()),
+	)
+}
+
+// checkConfig validates the config file on its own and the effective
+// configuration with environment overrides
+func checkConfig(opts Options) Check {
+	check := Check{Name: "Config"}
+
+	if _, err := os.Stat(opts.ConfigFile); errors.Is(err, os.ErrNotExist) {
+		check.Detail = fmt.Sprintf("no config file at %s, using defaults", opts.ConfigFile)
+	} else if err := config.CheckFile(opts.ConfigFile); err != nil {
+		check.Status = Fail
+		check.Detail = fmt.Sprintf("%s: %v", opts.ConfigFile, err)
+		check.Fix = `correct the setting with "task config set", or recreate the file with "task config init --force"`
+		return check
+	} else {
+		check.Detail = opts.ConfigFile + " is valid"
+	}
+
+	if opts.Config != nil {
+		if err := opts.Config.Validate(); err != nil {
+			check.Status = Fail
+			check.Detail = fmt.Sprintf("effective configuration: %v", err)
+			check.Fix = `check the environment variables overriding the config file, and "task config show"`
+		}
+	}
+	return check
+}
+
+// checkConnection queries the database
+func checkConnection(ctx context.Context, st *storage.SQLiteStorage) Check {
+	check := Check{Name: "Database"}
+
+	version, err := st.Ping(ctx)
+	if err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = "check database.path and that no other process holds the database locked"
+		return check
+	}
+
+	check.Detail = fmt.Sprintf("connected to %s (SQLite %s)", st.Path(), version)
+	return check
+}
+
+// checkSchema compares the applied migrations with those of this build
+func checkSchema(ctx context.Context, st *storage.SQLiteStorage) Check {
+	check := Check{Name: "Schema"}
+
+	status, err := st.Migrations(ctx)
+	switch {
+	case err != nil:
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = "restore the database from a backup"
+	case len(status.Pending) > 0:
+		check.Status = Fail
+		check.Detail = fmt.Sprintf("%d migration(s) not applied: %s", len(status.Pending), strings.Join(status.Pending, ", "))
+		check.Fix = "run any task command with write access to the database to apply them"
+	case len(status.Unknown) > 0:
+		check.Status = Warn
+		check.Detail = fmt.Sprintf("%d migration(s) from a newer version: %s", len(status.Unknown), strings.Join(status.Unknown, ", "))
+		check.Fix = "upgrade task to the version that last wrote the database"
+	default:
+		check.Detail = fmt.Sprintf("%d migration(s) applied, none pending", len(status.Applied))
+	}
+	return check
+}
+
+// checkIntegrity runs quick_check, or integrity_check with full
+func checkIntegrity(ctx context.Context, st *storage.SQLiteStorage, full bool) Check {
+	check := Check{Name: "Integrity"}
+
+	pragma, run := "quick_check", st.QuickCheck
+	if full {
+		pragma, run = "integrity_check", st.IntegrityCheck
+	}
+	if err := run(ctx); err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = `export what can still be read with "task export", then restore the database from a backup`
+		return check
+	}
+
+	check.Detail = pragma + " passed"
+	return check
+}
+
+// checkPermissions checks that the database and its directory are writable
+// and that the database is private to its owner
+func checkPermissions(path string) Check {
+	check := Check{Name: "Permissions"}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = "check database.path"
+		return check
+	}
+
+	// SQLite creates journal files next to the database, so the directory
+	// must be writable too
+	dir := filepath.Dir(path)
+	probe, err := os.CreateTemp(dir, ".task-doctor-*")
+	if err != nil {
+		check.Status = Fail
+		check.Detail = fmt.Sprintf("cannot write to %s", dir)
+		check.Fix = "chmod u+w " + dir
+		return check
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		check.Status = Fail
+		check.Detail = fmt.Sprintf("cannot write to %s", path)
+		check.Fix = "chmod u+w " + path
+		return check
+	}
+	file.Close()
+
+	if mode := info.Mode().Perm(); mode&0o077 != 0 {
+		check.Status = Warn
+		check.Detail = fmt.Sprintf("%s is readable by other users (%04o)", path, mode)
+		check.Fix = "chmod 600 " + path
+		return check
+	}
+
+	check.Detail = fmt.Sprintf("%s is writable and private (%04o)", path, info.Mode().Perm())
+	return check
+}
+
+// checkDiskSpace checks the free space where the database lives, which must
+// also hold a copy of it during "task db relocate" and VACUUM
+func checkDiskSpace(path string) Check {
+	check := Check{Name: "Disk space"}
+
+	free, err := freeSpace(filepath.Dir(path))
+	if errors.Is(err, errors.ErrUnsupported) {
+		check.Detail = "not checked on this platform"
+		return check
+	}
+	if err != nil {
+		check.Status = Warn
+		check.Detail = err.Error()
+		return check
+	}
+
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+
+	check.Detail = ui.Bytes(int64(free)) + " free"
+	if free < max(minFreeSpace, 2*uint64(size)) {
+		check.Status = Warn
+		check.Detail += fmt.Sprintf(", database is %s", ui.Bytes(size))
+		check.Fix = `free up space, or move the database with "task db relocate"`
+	}
+	return check
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxCommentLength is the longest comment accepted, in characters
+const MaxCommentLength = 10000
+
+// Comment is a remark left on a task by a user. Unlike the description,
+// which belongs to the task, comments form a thread attributed to their
+// authors, so users of a shared database can discuss a task.
+type Comment struct {
+	ID        string
+	TaskID    string
+	Author    string
+	Body      string
+	CreatedAt time.Time
+}
+
+// Validate validates the comment
+func (c *Comment) Validate() error {
+	if c.TaskID == "" {
+		return ErrInvalidTaskID
+	}
+	if c.Author == "" {
+		return invalid("comment needs an author")
+	}
+	if strings.TrimSpace(c.Body) == "" {
+		return invalid("comment cannot be empty")
+	}
+	if n := utf8.RuneCountInString(c.Body); n > MaxCommentLength {
+		return invalid(fmt.Sprintf("comment is too long (%d characters, at most %d)", n, MaxCommentLength))
+	}
+	return nil
+}
+
+// CommentRepository defines the interface for task comment persistence
+type CommentRepository interface {
+	Add(ctx context.Context, comment *Comment) error
+	ListByTask(ctx context.Context, taskID string) ([]*Comment, error)
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrTaskNotFound is returned when a task is not found
@@ -11,4 +14,59 @@ var (
 
 	// ErrDuplicateTask is returned when trying to create a duplicate task
 	ErrDuplicateTask = errors.New("duplicate task")
+
+	// ErrExternalRefNotFound is returned when no external reference matches
+	ErrExternalRefNotFound = errors.New("external reference not found")
+
+	// ErrLinkNotFound is returned when no link matches
+	ErrLinkNotFound = errors.New("link not found")
+
+	// ErrUserNotFound is returned when a user is not found
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrDuplicateUser is returned when trying to create a user that already exists
+	ErrDuplicateUser = errors.New("user already exists")
+
+	// ErrForbidden is returned when the role of the current user does not allow a change
+	ErrForbidden = errors.New("permission denied")
+
+	// ErrValidation is matched by errors.Is for every task or user validation failure
+	ErrValidation = errors.New("validation failed")
 )
+
+// validationError is an input problem that matches ErrValidation
+type validationError struct {
+	msg string
+}
+
+// invalid creates a validation error with the given message
+func invalid(msg string) error {
+	return &validationError{msg: msg}
+}
+
+// Error implements the error interface
+func (e *validationError) Error() string {
+	return e.msg
+}
+
+// Is reports whether target is ErrValidation
+func (e *validationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// RuleViolation is returned when a task breaks a convention enforced by a task hook
+type RuleViolation struct {
+	Rule    string
+	Message string
+}
+
+// Error implements the error interface
+func (v *RuleViolation) Error() string {
+	return fmt.Sprintf("rule %q violated: %s", v.Rule, v.Message)
+}
+
+// Is reports whether target is ErrValidation, so rule violations are
+// treated like any other validation failure
+func (v *RuleViolation) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ExternalRef links a local task to its counterpart in an external system
+// (e.g. a GitHub issue or a Todoist item) so that sync integrations can
+// recognize previously imported items and skip unchanged ones.
+type ExternalRef struct {
+	Provider        string
+	RemoteID        string
+	TaskID          string
+	ETag            string
+	RemoteUpdatedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// ExternalRefRepository defines the interface for external reference persistence
+type ExternalRefRepository interface {
+	Get(ctx context.Context, provider, remoteID string) (*ExternalRef, error)
+	GetByTask(ctx context.Context, provider, taskID string) (*ExternalRef, error)
+	ListByTask(ctx context.Context, taskID string) ([]*ExternalRef, error)
+	ListByProvider(ctx context.Context, provider string) ([]*ExternalRef, error)
+	Upsert(ctx context.Context, ref *ExternalRef) error
+	Delete(ctx context.Context, provider, remoteID string) error
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of a user-defined field
+type FieldType string
+
+const (
+	FieldTypeString FieldType = "string"
+	FieldTypeNumber FieldType = "number"
+	FieldTypeDate   FieldType = "date"
+	FieldTypeEnum   FieldType = "enum"
+)
+
+// FieldDef declares a user-defined field. Values of every type are stored
+// as text in their normalized form: numbers as the shortest decimal, dates
+// as YYYY-MM-DD, and enum values in lower case.
+type FieldDef struct {
+	Name   string
+	Type   FieldType
+	Values []string // allowed values of an enum
+}
+
+// fieldNamePattern is the form of user-defined field names
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// builtinFieldNames are task fields that user-defined fields cannot shadow
+var builtinFieldNames = []string{
+	"id", "title", "description", "status", "priority", "context", "project", "assignee",
+	"created_by", "created", "updated", "completed", "wait_until", "pinned",
+	"estimate", "points", "metadata", "due", "tags", "task_id",
+}
+
+// Validate checks that the definition has a usable name and type
+func (d FieldDef) Validate() error {
+	if !fieldNamePattern.MatchString(d.Name) {
+		return fmt.Errorf("invalid field name %q (use lower case letters, digits, and _)", d.Name)
+	}
+	if slices.Contains(builtinFieldNames, d.Name) {
+		return fmt.Errorf("field name %q is a built-in task field", d.Name)
+	}
+
+	switch d.Type {
+	case FieldTypeString, FieldTypeNumber, FieldTypeDate:
+		if len(d.Values) > 0 {
+			return fmt.Errorf("field %q: values are only allowed for enum fields", d.Name)
+		}
+	case FieldTypeEnum:
+		if len(d.Values) == 0 {
+			return fmt.Errorf("field %q: enum fields must list their values", d.Name)
+		}
+	default:
+		return fmt.Errorf("field %q: invalid type %q (must be string, number, date, or enum)", d.Name, d.Type)
+	}
+
+	return nil
+}
+
+// Normalize validates value against the field type and returns its stored
+// form. An empty value is returned unchanged and clears the field.
+func (d FieldDef) Normalize(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	switch d.Type {
+	case FieldTypeNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %q is not a number", d.Name, value)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+
+	case FieldTypeDate:
+		day, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %q is not a date (use YYYY-MM-DD)", d.Name, value)
+		}
+		return day.Format(time.DateOnly), nil
+
+	case FieldTypeEnum:
+		for _, allowed := range d.Values {
+			if strings.EqualFold(value, allowed) {
+				return strings.ToLower(allowed), nil
+			}
+		}
+		return "", fmt.Errorf("invalid %s: %q (must be one of %s)", d.Name, value, strings.Join(d.Values, ", "))
+	}
+
+	return value, nil
+}
+
+// WithField sets a user-defined field, which must already be normalized; an
+// empty value removes it
+func WithField(name, value string) TaskOption {
+	return func(t *Task) {
+		if value == "" {
+			delete(t.Fields, name)
+			return
+		}
+		if t.Fields == nil {
+			t.Fields = make(map[string]string)
+		}
+		t.Fields[name] = value
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LinkKind is the relationship a link records
+type LinkKind string
+
+const (
+	LinkRelates    LinkKind = "relates"
+	LinkDuplicates LinkKind = "duplicates"
+	LinkSeeAlso    LinkKind = "see-also"
+)
+
+// LinkKinds lists the link kinds
+var LinkKinds = []LinkKind{LinkRelates, LinkDuplicates, LinkSeeAlso}
+
+// ParseLinkKind parses a link kind, accepting "relates-to" and "see also" too
+func ParseLinkKind(s string) (LinkKind, error) {
+	switch strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(s, "-", " ")), " ")) {
+	case "relates", "relates to":
+		return LinkRelates, nil
+	case "duplicates":
+		return LinkDuplicates, nil
+	case "see also":
+		return LinkSeeAlso, nil
+	}
+	return "", invalid(fmt.Sprintf("invalid link kind: %s (must be relates, duplicates, or see-also)", s))
+}
+
+// Label describes the link as read from its task, e.g. "duplicates"
+func (k LinkKind) Label() string {
+	switch k {
+	case LinkRelates:
+		return "relates to"
+	case LinkSeeAlso:
+		return "see also"
+	}
+	return string(k)
+}
+
+// InverseLabel describes the link as read from its target task, e.g.
+// "duplicated by"
+func (k LinkKind) InverseLabel() string {
+	if k == LinkDuplicates {
+		return "duplicated by"
+	}
+	return k.Label()
+}
+
+// Link records a relationship from a task to another task or to a URL.
+// Links are stored once, on the task they were created from, and listed
+// for both ends.
+type Link struct {
+	TaskID    string
+	Kind      LinkKind
+	Target    string // task ID or URL
+	CreatedAt time.Time
+}
+
+// IsURL reports whether the link points to a URL rather than a task
+func (l *Link) IsURL() bool {
+	return IsLinkURL(l.Target)
+}
+
+// IsLinkURL reports whether a link target is a URL
+func IsLinkURL(target string) bool {
+	return strings.Contains(target, "://")
+}
+
+// Validate validates the link
+func (l *Link) Validate() error {
+	if l.TaskID == "" || l.Target == "" {
+		return invalid("link needs a task and a target")
+	}
+	if l.TaskID == l.Target {
+		return invalid("a task cannot be linked to itself")
+	}
+	if _, err := ParseLinkKind(string(l.Kind)); err != nil {
+		return err
+	}
+	if l.IsURL() {
+		u, err := url.Parse(l.Target)
+		if err != nil || u.Host == "" {
+			return invalid(fmt.Sprintf("invalid link URL: %s", l.Target))
+		}
+	}
+	return nil
+}
+
+// LinkRepository defines the interface for task link persistence
+type LinkRepository interface {
+	Add(ctx context.Context, link *Link) error
+	Remove(ctx context.Context, taskID, target string) error
+	ListByTask(ctx context.Context, taskID string) ([]*Link, error)
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Metadata holds free-form, integration-specific task attributes such as
+// remote IDs or etags. Values are kept as raw JSON so that each integration
+// can decode them into its own types without a schema migration.
+type Metadata map[string]json.RawMessage
+
+// Has reports whether the metadata contains the given key
+func (m Metadata) Has(key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// Get decodes the value stored under key into dest.
+// Returns false if the key is not present.
+func (m Metadata) Get(key string, dest interface{}) (bool, error) {
+	raw, ok := m[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, dest); err != nil {
+		return true, fmt.Errorf("failed to decode metadata key %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// GetString returns the string stored under key
+func (m Metadata) GetString(key string) (string, bool) {
+	var v string
+	ok, err := m.Get(key, &v)
+	if !ok || err != nil {
+		return "", false
+	}
+	return v, true
+}
+
+// GetInt returns the integer stored under key
+func (m Metadata) GetInt(key string) (int64, bool) {
+	var v int64
+	ok, err := m.Get(key, &v)
+	if !ok || err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// GetBool returns the boolean stored under key
+func (m Metadata) GetBool(key string) (bool, bool) {
+	var v bool
+	ok, err := m.Get(key, &v)
+	if !ok || err != nil {
+		return false, false
+	}
+	return v, true
+}
+
+// GetTime returns the timestamp stored under key
+func (m Metadata) GetTime(key string) (time.Time, bool) {
+	var v time.Time
+	ok, err := m.Get(key, &v)
+	if !ok || err != nil {
+		return time.Time{}, false
+	}
+	return v, true
+}
+
+// SetMetadata encodes value as JSON and stores it under key,
+// initializing the task's metadata map if needed.
+func (t *Task) SetMetadata(key string, value interface{}) error {
+	if key == "" {
+		return fmt.Errorf("metadata key cannot be empty")
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata key %q: %w", key, err)
+	}
+
+	if t.Metadata == nil {
+		t.Metadata = make(Metadata)
+	}
+	t.Metadata[key] = raw
+	return nil
+}
+
+// DeleteMetadata removes key from the task's metadata
+func (t *Task) DeleteMetadata(key string) {
+	delete(t.Metadata, key)
+}
+
+// DueKey is the metadata key of a due date set by the user (see WithDueDate)
+const DueKey = "due"
+
+// DueDateKeys are the metadata keys due dates are kept under, in order of
+// preference: the user's own, then those integrations keep. Tasks have no
+// due date field of their own.
+var DueDateKeys = []string{DueKey, "google.due", "todoist.due", "trello.due", "todotxt.due", "org.deadline"}
+
+// DueDate returns the first due date found under DueDateKeys, as a day in
+// local time. Values may be dates, RFC 3339 times, or Org timestamps such
+// as <2026-01-05 Mon>; only the date is used.
+func (t *Task) DueDate() (time.Time, bool) {
+	for _, key := range DueDateKeys {
+		value, ok := t.Metadata.GetString(key)
+		if !ok {
+			continue
+		}
+		value = strings.TrimLeft(value, "<[")
+		if len(value) < len(time.DateOnly) {
+			continue
+		}
+		if due, err := time.ParseInLocation(time.DateOnly, value[:len(time.DateOnly)], time.Local); err == nil {
+			return due, true
+		}
+	}
+	return time.Time{}, false
+}
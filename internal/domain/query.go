@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// QueryField is a task field that can appear in a query expression
+type QueryField string
+
+const (
+	QueryFieldID          QueryField = "id"
+	QueryFieldTitle       QueryField = "title"
+	QueryFieldDescription QueryField = "description"
+	QueryFieldStatus      QueryField = "status"
+	QueryFieldPriority    QueryField = "priority"
+	QueryFieldContext     QueryField = "context"
+	QueryFieldAssignee    QueryField = "assignee"
+	QueryFieldCreatedBy   QueryField = "created_by"
+	QueryFieldCreated     QueryField = "created"
+	QueryFieldUpdated     QueryField = "updated"
+	QueryFieldCompleted   QueryField = "completed"
+	QueryFieldDue         QueryField = "due"   // the day the task is due, see Task.DueDate
+	QueryFieldCustom      QueryField = "field" // a user-defined field, named by QueryCond.Name
+)
+
+// QueryOp is a comparison operator in a query expression
+type QueryOp string
+
+const (
+	// QueryMatch (":") tests equality, except on text fields where it
+	// matches a case-insensitive substring and on id where it matches a prefix
+	QueryMatch        QueryOp = ":"
+	QueryEqual        QueryOp = "="
+	QueryNotEqual     QueryOp = "!="
+	QueryLess         QueryOp = "<"
+	QueryLessEqual    QueryOp = "<="
+	QueryGreater      QueryOp = ">"
+	QueryGreaterEqual QueryOp = ">="
+)
+
+// QueryExpr is a boolean expression over task fields, as parsed from
+// "task list -q". It is one of *QueryAnd, *QueryOr, *QueryNot or *QueryCond.
+type QueryExpr interface {
+	queryExpr()
+}
+
+// QueryAnd matches tasks matching both sides
+type QueryAnd struct {
+	Left, Right QueryExpr
+}
+
+// QueryOr matches tasks matching either side
+type QueryOr struct {
+	Left, Right QueryExpr
+}
+
+// QueryNot matches tasks not matching Expr
+type QueryNot struct {
+	Expr QueryExpr
+}
+
+// QueryCond compares one field with a value. Date fields (created, updated,
+// completed, due) compare whole days and carry the day in Date; other fields
+// carry Value. Conditions on user-defined fields carry the field's Name and
+// Type and a normalized Value.
+type QueryCond struct {
+	Field QueryField
+	Op    QueryOp
+	Value string
+	Date  time.Time
+	Name  string
+	Type  FieldType
+}
+
+func (*QueryAnd) queryExpr()  {}
+func (*QueryOr) queryExpr()   {}
+func (*QueryNot) queryExpr()  {}
+func (*QueryCond) queryExpr() {}
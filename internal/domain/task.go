@@ -2,8 +2,11 @@ package domain
 
 import (
 	"context"
-	"errors"
+	"maps"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // TaskStatus represents the status of a task
@@ -25,39 +28,255 @@ const (
 
 // Task represents a task in the system
 type Task struct {
-	ID          string
-	Title       string
-	Description string
-	Status      TaskStatus
-	Priority    TaskPriority
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	CompletedAt *time.Time
+	ID             string
+	Title          string
+	Description    string
+	Status         TaskStatus
+	Priority       TaskPriority
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	CompletedAt    *time.Time
+	LastActivityAt time.Time // last change to the task, its comments, or its links; set by the repository
+	Context        string
+	Assignee       string
+	CreatedBy      string
+	WaitUntil      *time.Time // snoozed: hidden from default views until then
+	Pinned         bool       // sorted to the top of list
+	Estimate       time.Duration
+	Points         int               // effort in story points, alternatively or in addition to Estimate
+	Location       string            // machine or place the task can be done at; empty for anywhere
+	Fields         map[string]string // user-defined fields by name, see FieldDef
+	Metadata       Metadata
+}
+
+// TaskOption applies an optional attribute to a task during create or update
+type TaskOption func(*Task)
+
+// WithTaskContext sets the GTD context (e.g. "home", "office") of a task
+func WithTaskContext(name string) TaskOption {
+	return func(t *Task) {
+		t.Context = NormalizeContext(name)
+	}
+}
+
+// WithAssignee assigns the task to a user; an empty name unassigns it
+func WithAssignee(name string) TaskOption {
+	return func(t *Task) {
+		t.Assignee = NormalizeUserName(name)
+	}
+}
+
+// WithCreatedBy records the user who created the task; it has no effect on update
+func WithCreatedBy(name string) TaskOption {
+	return func(t *Task) {
+		t.CreatedBy = NormalizeUserName(name)
+	}
+}
+
+// WithWaitUntil snoozes the task until the given time; nil wakes it
+func WithWaitUntil(until *time.Time) TaskOption {
+	return func(t *Task) {
+		t.WaitUntil = until
+	}
+}
+
+// WithDueDate sets the day the task is due under DueKey in its metadata;
+// nil clears it, leaving any due date kept by an integration
+func WithDueDate(due *time.Time) TaskOption {
+	return func(t *Task) {
+		if due == nil {
+			t.DeleteMetadata(DueKey)
+			return
+		}
+		_ = t.SetMetadata(DueKey, due.Format(time.DateOnly))
+	}
+}
+
+// WithPinned pins the task to the top of list, or unpins it
+func WithPinned(pinned bool) TaskOption {
+	return func(t *Task) {
+		t.Pinned = pinned
+	}
+}
+
+// WithEstimate sets how long the task is expected to take; 0 clears it
+func WithEstimate(d time.Duration) TaskOption {
+	return func(t *Task) {
+		t.Estimate = d
+	}
+}
+
+// WithPoints sets the effort of the task in points; 0 clears it
+func WithPoints(points int) TaskOption {
+	return func(t *Task) {
+		t.Points = points
+	}
+}
+
+// WithLocation restricts the task to a machine or place, e.g. "office"; an
+// empty name lets it be done anywhere
+func WithLocation(name string) TaskOption {
+	return func(t *Task) {
+		t.Location = NormalizeLocation(name)
+	}
+}
+
+// idempotencyNamespace is the UUID namespace of IDs derived from idempotency keys
+var idempotencyNamespace = uuid.MustParse("5b0e6a34-2f5c-4f4e-9d67-0c6f2a8e1d3b")
+
+// WithID gives a new task a client-supplied ID, which must be a UUID, instead
+// of a random one. Creating a task whose ID is taken returns the existing
+// task, so a create can be retried safely. Only use it when creating tasks.
+func WithID(id string) TaskOption {
+	return func(t *Task) {
+		t.ID = strings.TrimSpace(id)
+	}
+}
+
+// WithIdempotencyKey gives a new task an ID derived from key, so that
+// creating a task again with the same key returns the first one
+func WithIdempotencyKey(key string) TaskOption {
+	return WithID(uuid.NewSHA1(idempotencyNamespace, []byte(key)).String())
+}
+
+// TaskHook inspects, and may adjust, a task before it is created or updated.
+// Returning an error (typically a *RuleViolation) rejects the change.
+type TaskHook func(ctx context.Context, task *Task) error
+
+// NormalizeContext converts a user-supplied context such as "@Office" to its stored form ("office")
+func NormalizeContext(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+}
+
+// NormalizeLocation converts a user-supplied location or host name such as
+// "Office-PC" to its stored form ("office-pc")
+func NormalizeLocation(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
 }
 
 // TaskFilter contains filter criteria for querying tasks
 type TaskFilter struct {
-	Status   *TaskStatus
-	Priority *TaskPriority
-	FromDate *time.Time
-	ToDate   *time.Time
+	Status    *TaskStatus
+	Priority  *TaskPriority
+	Context   *string
+	Assignee  *string
+	CreatedBy *string
+	Owner     *string // created by or assigned to this user (see User.Owns); "" matches no task
+	Pinned    *bool
+	FromDate  *time.Time
+	ToDate    *time.Time
+	AwakeAt   *time.Time // excludes pending tasks snoozed past this time
+	DueBefore *time.Time // only tasks with a due date (see Task.DueDate) before this day
+	IdleSince *time.Time // only tasks without activity (see Task.LastActivityAt) since this time
+	Location  *string    // tasks that can be done at this location: those without one, and those at it
+	Query     QueryExpr  // combined with the other fields using AND
+}
+
+// TaskPatch lists field changes applied to every task matching a filter.
+// Nil fields are left unchanged.
+type TaskPatch struct {
+	Description *string
+	Status      *TaskStatus
+	Priority    *TaskPriority
+	Context     *string
+	Assignee    *string
+	Due         *string           // due day as YYYY-MM-DD under DueKey; "" clears it
+	Fields      map[string]string // user-defined fields to set; "" removes one
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p TaskPatch) IsEmpty() bool {
+	return p.Description == nil && p.Status == nil && p.Priority == nil && p.Context == nil && p.Assignee == nil &&
+		p.Due == nil && len(p.Fields) == 0
+}
+
+// Apply applies the patch to task in memory, updating CompletedAt on status changes
+func (p TaskPatch) Apply(task *Task, now time.Time) {
+	if p.Description != nil {
+		task.Description = *p.Description
+	}
+	if p.Status != nil && *p.Status != task.Status {
+		task.Status = *p.Status
+		if task.Status == TaskStatusCompleted {
+			task.CompletedAt = &now
+		} else {
+			task.CompletedAt = nil
+		}
+	}
+	if p.Priority != nil {
+		task.Priority = *p.Priority
+	}
+	if p.Context != nil {
+		task.Context = *p.Context
+	}
+	if p.Assignee != nil {
+		task.Assignee = *p.Assignee
+	}
+	if p.Due != nil {
+		if *p.Due == "" {
+			task.DeleteMetadata(DueKey)
+		} else {
+			_ = task.SetMetadata(DueKey, *p.Due)
+		}
+	}
+	for name, value := range p.Fields {
+		WithField(name, value)(task)
+	}
+	task.UpdatedAt = now
 }
 
-// Validate validates the task
+// Validate validates the task with the default validation rules
 func (t *Task) Validate() error {
+	return t.ValidateWith(DefaultValidationRules)
+}
+
+// ValidateWith validates the task against the fixed checks of Validate and
+// the configurable rules
+func (t *Task) ValidateWith(rules ValidationRules) error {
 	if t.Title == "" {
-		return errors.New("task title cannot be empty")
+		return invalid("task title cannot be empty")
 	}
 
 	if t.Status != TaskStatusPending && t.Status != TaskStatusCompleted {
-		return errors.New("invalid task status")
+		return invalid("invalid task status")
 	}
 
 	if t.Priority != TaskPriorityLow && t.Priority != TaskPriorityMedium && t.Priority != TaskPriorityHigh {
-		return errors.New("invalid task priority")
+		return invalid("invalid task priority")
+	}
+
+	if strings.ContainsAny(t.Context, " \t@") {
+		return invalid("task context cannot contain whitespace or '@'")
+	}
+
+	if strings.ContainsAny(t.Assignee, " \t") {
+		return invalid("task assignee cannot contain whitespace")
 	}
 
-	return nil
+	if strings.ContainsAny(t.Location, " \t") {
+		return invalid("task location cannot contain whitespace")
+	}
+
+	if t.Estimate < 0 || t.Points < 0 {
+		return invalid("task estimate and points cannot be negative")
+	}
+
+	return rules.check(t)
+}
+
+// DefaultStaleDays is how many days without activity make a pending task
+// stale, unless configured otherwise
+const DefaultStaleDays = 30
+
+// Stale reports whether the task is pending, not snoozed, and has seen no
+// activity for the given number of days
+func (t *Task) Stale(now time.Time, days int) bool {
+	return t.Status == TaskStatusPending && !t.Waiting(now) && t.LastActivityAt.Before(now.AddDate(0, 0, -days))
+}
+
+// Waiting reports whether the task is pending and snoozed past now
+func (t *Task) Waiting(now time.Time) bool {
+	return t.Status == TaskStatusPending && t.WaitUntil != nil && t.WaitUntil.After(now)
 }
 
 // MarkCompleted marks the task as completed
@@ -68,11 +287,59 @@ func (t *Task) MarkCompleted() {
 	t.UpdatedAt = now
 }
 
+// Clone returns a copy of the task that shares nothing mutable with it
+func (t *Task) Clone() *Task {
+	c := *t
+	if t.CompletedAt != nil {
+		completed := *t.CompletedAt
+		c.CompletedAt = &completed
+	}
+	if t.WaitUntil != nil {
+		wait := *t.WaitUntil
+		c.WaitUntil = &wait
+	}
+	c.Fields = maps.Clone(t.Fields)
+	c.Metadata = maps.Clone(t.Metadata)
+	return &c
+}
+
+// Effort totals the tasks and estimates of a group of tasks
+type Effort struct {
+	Tasks    int64
+	Estimate time.Duration
+	Points   int64
+}
+
+// ContextReport sums up the tasks of one context
+type ContextReport struct {
+	Context   string // "" for tasks without a context
+	Open      Effort
+	Completed Effort
+	Overdue   int64 // open tasks past their due date
+}
+
 // TaskRepository defines the interface for task persistence
 type TaskRepository interface {
 	Create(ctx context.Context, task *Task) error
+	CreateBatch(ctx context.Context, tasks []*Task) error
 	GetByID(ctx context.Context, id string) (*Task, error)
 	List(ctx context.Context, filter TaskFilter) ([]*Task, error)
+	Stream(ctx context.Context, filter TaskFilter, fn func(*Task) error) error
+	Count(ctx context.Context, filter TaskFilter) (int64, error)
+	CountByStatus(ctx context.Context, filter TaskFilter) (map[TaskStatus]int64, error)
+	CountByPriority(ctx context.Context, filter TaskFilter) (map[TaskPriority]int64, error)
+	EffortByContext(ctx context.Context, filter TaskFilter) (map[string]Effort, error)
 	Update(ctx context.Context, task *Task) error
+	UpdateWhere(ctx context.Context, filter TaskFilter, patch TaskPatch, now time.Time) (int64, error)
 	Delete(ctx context.Context, id string) error
 }
+
+// TxManager runs work spanning several repository calls, possibly of
+// different repositories, in one transaction. The calls take part in it by
+// using the context passed to fn.
+type TxManager interface {
+	// WithTx commits what fn wrote if it returns nil and rolls it back
+	// otherwise. Called again from within fn, it joins the running
+	// transaction. It may run fn again when the database was busy.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
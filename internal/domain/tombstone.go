@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Tombstone records that a task was deleted, so the deletion can be
+// propagated when two databases are synced
+type Tombstone struct {
+	TaskID    string
+	DeletedAt time.Time
+}
+
+// TombstoneRepository defines access to the deletion records of a task database
+type TombstoneRepository interface {
+	// ListTombstones returns every recorded deletion
+	ListTombstones(ctx context.Context) ([]*Tombstone, error)
+	// ApplyTombstone deletes the task if it exists and records the tombstone,
+	// keeping the latest deletion time if one is already recorded
+	ApplyTombstone(ctx context.Context, tombstone *Tombstone) error
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// User is a person tasks can be assigned to in a shared database
+type User struct {
+	Name      string
+	Email     string
+	Role      Role
+	CreatedAt time.Time
+}
+
+// Role is what a user may change once roles are enforced, which they are
+// as soon as a user is an admin
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"  // everything, including managing users
+	RoleMember Role = "member" // create tasks, and change the tasks they created or are assigned
+	RoleViewer Role = "viewer" // read only
+)
+
+// Roles lists the valid roles, most privileged first
+var Roles = []Role{RoleAdmin, RoleMember, RoleViewer}
+
+// ParseRole converts a user-supplied role such as "Admin" to a Role
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	switch role {
+	case RoleAdmin, RoleMember, RoleViewer:
+		return role, nil
+	default:
+		return "", invalid(fmt.Sprintf("invalid role: %s (must be admin, member, or viewer)", s))
+	}
+}
+
+// Owns reports whether the user created the task or is assigned to it
+func (u *User) Owns(task *Task) bool {
+	return u.Name != "" && (task.CreatedBy == u.Name || task.Assignee == u.Name)
+}
+
+// NormalizeUserName converts a user-supplied name such as " Alice " to its stored form ("alice")
+func NormalizeUserName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Validate validates the user
+func (u *User) Validate() error {
+	if u.Name == "" {
+		return invalid("user name cannot be empty")
+	}
+
+	if strings.ContainsAny(u.Name, " \t") {
+		return invalid("user name cannot contain whitespace")
+	}
+
+	if u.Email != "" && !strings.Contains(u.Email, "@") {
+		return invalid("invalid user email")
+	}
+
+	if _, err := ParseRole(string(u.Role)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UserRepository defines the interface for user persistence
+type UserRepository interface {
+	Create(ctx context.Context, user *User) error
+	GetByName(ctx context.Context, name string) (*User, error)
+	List(ctx context.Context) ([]*User, error)
+	SetRole(ctx context.Context, name string, role Role) error
+}
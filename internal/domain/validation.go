@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationRules are the configurable checks applied to tasks on top of
+// the fixed ones in Validate
+type ValidationRules struct {
+	MaxTitleLength       int            // in characters, 0 for no limit
+	MaxDescriptionLength int            // in characters, 0 for no limit
+	RequireDescription   []TaskPriority // priorities whose tasks need a description
+	BannedCharacters     string         // characters rejected in titles and descriptions
+	TrimWhitespace       bool           // see Normalize
+}
+
+// DefaultValidationRules are used when the config file does not override them
+var DefaultValidationRules = ValidationRules{
+	MaxTitleLength:       200,
+	MaxDescriptionLength: 10000,
+	TrimWhitespace:       true,
+}
+
+// Normalize tidies the title and description of task in place when
+// TrimWhitespace is set: both are trimmed, and runs of whitespace in the
+// title, including line breaks, become a single space
+func (r ValidationRules) Normalize(task *Task) {
+	task.Title = r.NormalizeTitle(task.Title)
+	task.Description = r.NormalizeDescription(task.Description)
+}
+
+// NormalizeTitle returns title as Normalize stores it
+func (r ValidationRules) NormalizeTitle(title string) string {
+	if !r.TrimWhitespace {
+		return title
+	}
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// NormalizeDescription returns description as Normalize stores it
+func (r ValidationRules) NormalizeDescription(description string) string {
+	if !r.TrimWhitespace {
+		return description
+	}
+	return strings.TrimSpace(description)
+}
+
+// check returns a validation error for the first rule task breaks
+func (r ValidationRules) check(task *Task) error {
+	if n := utf8.RuneCountInString(task.Title); r.MaxTitleLength > 0 && n > r.MaxTitleLength {
+		return invalid(fmt.Sprintf("task title is too long (%d characters, at most %d)", n, r.MaxTitleLength))
+	}
+	if n := utf8.RuneCountInString(task.Description); r.MaxDescriptionLength > 0 && n > r.MaxDescriptionLength {
+		return invalid(fmt.Sprintf("task description is too long (%d characters, at most %d)", n, r.MaxDescriptionLength))
+	}
+
+	if i := strings.IndexAny(task.Title, r.BannedCharacters); i >= 0 {
+		return invalid(fmt.Sprintf("task title cannot contain %q", bannedRune(task.Title[i:])))
+	}
+	if i := strings.IndexAny(task.Description, r.BannedCharacters); i >= 0 {
+		return invalid(fmt.Sprintf("task description cannot contain %q", bannedRune(task.Description[i:])))
+	}
+
+	if strings.TrimSpace(task.Description) == "" && slices.Contains(r.RequireDescription, task.Priority) {
+		return invalid(fmt.Sprintf("%s priority tasks need a description", task.Priority))
+	}
+
+	return nil
+}
+
+// bannedRune returns the first character of s
+func bannedRune(s string) string {
+	_, size := utf8.DecodeRuneInString(s)
+	return s[:size]
+}
@@ -0,0 +1,377 @@
+// Package encryption encrypts task titles, descriptions, and comments at
+// rest. Values are sealed with AES-256-GCM under a key derived from a
+// passphrase with PBKDF2; the salt and a verifier for the passphrase live in
+// the encryption table of the database itself, so a database file carries
+// everything needed to open it except the passphrase.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// prefix marks an encrypted value and the format version it was written with
+const prefix = "enc:v1:"
+
+// verifierText is sealed with the key to check a passphrase without touching any task
+const verifierText = "task-manager"
+
+// iterations is the PBKDF2-HMAC-SHA256 work factor
+const iterations = 600000
+
+var (
+	// ErrPassphraseRequired is returned when the database is encrypted but no passphrase was given
+	ErrPassphraseRequired = errors.New("database is encrypted: set database.passphrase, TASK_PASSPHRASE, or store it with \"task config set-secret db-passphrase\"")
+
+	// ErrWrongPassphrase is returned when the passphrase does not match the database
+	ErrWrongPassphrase = errors.New("wrong passphrase for encrypted database")
+
+	// ErrAlreadyEncrypted is returned when encrypting a database that is already encrypted
+	ErrAlreadyEncrypted = errors.New("database is already encrypted")
+
+	// ErrNotEncrypted is returned when decrypting a database that is not encrypted
+	ErrNotEncrypted = errors.New("database is not encrypted")
+
+	// ErrKeyMismatch is returned when rows cannot be copied between two
+	// databases as they are, because only one is encrypted or they use
+	// different keys
+	ErrKeyMismatch = errors.New("the databases are not encrypted with the same key")
+)
+
+// Cipher encrypts and decrypts individual field values
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher derives a key from passphrase and salt and returns a cipher using it
+func NewCipher(passphrase string, salt []byte) (*Cipher, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt seals plaintext with a random nonce. Empty values stay empty, so an
+// unset description does not grow into ciphertext. Any other value is
+// sealed, even one that looks encrypted, since it may be text a user typed.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value written by Encrypt. Values without the encryption
+// prefix are returned unchanged, so rows written before the database was
+// encrypted (e.g. pulled in by a sync) remain readable.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value has the prefix Encrypt writes
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Enabled reports whether the database holds encrypted tasks
+func Enabled(ctx context.Context, db *sql.DB) (bool, error) {
+	var n int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM encryption").Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to read encryption settings: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Open returns the cipher for an encrypted database after checking the
+// passphrase against the stored verifier. It returns a nil cipher when the
+// database is not encrypted.
+func Open(ctx context.Context, db *sql.DB, passphrase string) (*Cipher, error) {
+	salt, verifier, err := settings(ctx, db)
+	if err != nil || salt == nil {
+		return nil, err
+	}
+
+	if passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+
+	c, err := NewCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if text, err := c.Decrypt(verifier); err != nil || text != verifierText {
+		return nil, ErrWrongPassphrase
+	}
+
+	return c, nil
+}
+
+// settings returns the salt and verifier of an encrypted database, or a nil
+// salt when it is not encrypted
+func settings(ctx context.Context, db *sql.DB) ([]byte, string, error) {
+	var salt []byte
+	var verifier string
+	err := db.QueryRowContext(ctx, "SELECT salt, verifier FROM encryption WHERE id = 1").Scan(&salt, &verifier)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read encryption settings: %w", err)
+	}
+	return salt, verifier, nil
+}
+
+// CheckSameKey returns ErrKeyMismatch unless both databases are plain or
+// both are encrypted under the same key settings, so that rows can be copied
+// from one to the other as they are, e.g. by a sync
+func CheckSameKey(ctx context.Context, a, b *sql.DB) error {
+	aSalt, aVerifier, err := settings(ctx, a)
+	if err != nil {
+		return err
+	}
+	bSalt, bVerifier, err := settings(ctx, b)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(aSalt, bSalt) || aVerifier != bVerifier {
+		return ErrKeyMismatch
+	}
+	return nil
+}
+
+// CopyKey copies the key settings of src, if it is encrypted, into dst, a
+// new database that can then hold rows copied from src as they are. It
+// returns ErrAlreadyEncrypted if dst is encrypted.
+func CopyKey(ctx context.Context, src, dst *sql.DB) error {
+	salt, verifier, err := settings(ctx, src)
+	if err != nil || salt == nil {
+		return err
+	}
+
+	enabled, err := Enabled(ctx, dst)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		return ErrAlreadyEncrypted
+	}
+
+	if _, err := dst.ExecContext(ctx, "INSERT INTO encryption (id, salt, verifier, created_at) VALUES (1, ?, ?, ?)",
+		salt, verifier, time.Now()); err != nil {
+		return fmt.Errorf("failed to copy encryption settings: %w", err)
+	}
+	return nil
+}
+
+// EncryptDatabase encrypts the title and description of every task, and the
+// body of every comment, under a new key derived from passphrase and records
+// the key's salt and verifier.
+// All rows are rewritten in one transaction. It returns the number of tasks
+// encrypted.
+func EncryptDatabase(ctx context.Context, db *sql.DB, passphrase string) (int64, error) {
+	if passphrase == "" {
+		return 0, fmt.Errorf("a passphrase is required to encrypt the database")
+	}
+
+	enabled, err := Enabled(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	if enabled {
+		return 0, ErrAlreadyEncrypted
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return 0, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	c, err := NewCipher(passphrase, salt)
+	if err != nil {
+		return 0, err
+	}
+
+	verifier, err := c.Encrypt(verifierText)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rows already sealed, e.g. pulled in by a sync before the key check,
+	// are left as they are rather than sealed twice
+	encrypt := func(value string) (string, error) {
+		if IsEncrypted(value) {
+			return value, nil
+		}
+		return c.Encrypt(value)
+	}
+
+	return rewrite(ctx, db, encrypt, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO encryption (id, salt, verifier, created_at) VALUES (1, ?, ?, ?)",
+			salt, verifier, time.Now())
+		return err
+	})
+}
+
+// DecryptDatabase decrypts every task and comment with the key derived from
+// passphrase and removes the encryption settings, leaving a plain database. All rows are
+// rewritten in one transaction. It returns the number of tasks decrypted.
+func DecryptDatabase(ctx context.Context, db *sql.DB, passphrase string) (int64, error) {
+	c, err := Open(ctx, db, passphrase)
+	if err != nil {
+		return 0, err
+	}
+	if c == nil {
+		return 0, ErrNotEncrypted
+	}
+
+	return rewrite(ctx, db, c.Decrypt, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM encryption")
+		return err
+	})
+}
+
+// rewrite applies transform to the title and description of every task and
+// the body of every comment, and runs finish, all in one transaction
+func rewrite(ctx context.Context, db *sql.DB, transform func(string) (string, error), finish func(*sql.Tx) error) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	type row struct{ id, title, description string }
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, title, COALESCE(description, '') FROM tasks")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	var tasks []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.title, &r.description); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, "UPDATE tasks SET title = ?, description = ? WHERE id = ?")
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare update: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range tasks {
+		title, err := transform(r.title)
+		if err != nil {
+			return 0, fmt.Errorf("task %s: %w", r.id, err)
+		}
+		description, err := transform(r.description)
+		if err != nil {
+			return 0, fmt.Errorf("task %s: %w", r.id, err)
+		}
+		if _, err := stmt.ExecContext(ctx, title, description, r.id); err != nil {
+			return 0, fmt.Errorf("failed to update task %s: %w", r.id, err)
+		}
+	}
+
+	if err := rewriteComments(ctx, tx, transform); err != nil {
+		return 0, err
+	}
+
+	if err := finish(tx); err != nil {
+		return 0, fmt.Errorf("failed to update encryption settings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int64(len(tasks)), nil
+}
+
+// rewriteComments applies transform to the body of every comment in tx
+func rewriteComments(ctx context.Context, tx *sql.Tx, transform func(string) (string, error)) error {
+	type row struct{ id, body string }
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, body FROM task_comments")
+	if err != nil {
+		return fmt.Errorf("failed to read comments: %w", err)
+	}
+
+	var comments []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.body); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read comments: %w", err)
+	}
+
+	for _, r := range comments {
+		body, err := transform(r.body)
+		if err != nil {
+			return fmt.Errorf("comment %s: %w", r.id, err)
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE task_comments SET body = ? WHERE id = ?", body, r.id); err != nil {
+			return fmt.Errorf("failed to update comment %s: %w", r.id, err)
+		}
+	}
+	return nil
+}
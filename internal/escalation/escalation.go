@@ -0,0 +1,235 @@
+// Package escalation evaluates the escalations declared in config.yaml,
+// such as "tell the team channel about high-priority tasks overdue for
+// more than 48 hours", against pending tasks, and delivers a message for
+// each escalation that matches to its webhook and email recipients.
+// Nothing remembers what was sent: every evaluation that matches notifies
+// again, so how often it runs is how often people are reminded.
+package escalation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/digest"
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/notify"
+)
+
+// webhookTimeout bounds each webhook request of a Notifier without a client
+const webhookTimeout = 10 * time.Second
+
+// Rule is an escalation: the overdue tasks it covers and who to tell
+type Rule struct {
+	Name     string
+	Priority domain.TaskPriority // empty for any priority
+	Context  string              // empty for any context
+	Overdue  time.Duration       // how long after the end of its due day a task escalates
+	Webhook  string
+	Email    []string
+	Desktop  bool // notify through the Desktop notifier of the Notifier
+}
+
+// FromConfig converts escalation declarations, validated with the config,
+// into rules
+func FromConfig(escalations []config.EscalationConfig) ([]Rule, error) {
+	rules := make([]Rule, 0, len(escalations))
+	for _, esc := range escalations {
+		rule := Rule{
+			Name:     esc.Name,
+			Priority: domain.TaskPriority(strings.ToLower(esc.Priority)),
+			Context:  domain.NormalizeContext(esc.Context),
+			Overdue:  esc.Overdue,
+			Webhook:  esc.Webhook,
+			Desktop:  esc.Desktop,
+		}
+		if esc.Email != "" {
+			to, err := digest.ParseAddresses(esc.Email)
+			if err != nil {
+				return nil, fmt.Errorf("escalation %s: %w", esc.Name, err)
+			}
+			rule.Email = to
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Applies reports whether task escalates under the rule as of now: it is
+// pending, matches the priority and context of the rule, and its due day
+// ended more than Overdue ago. Due dates come from task metadata (see
+// domain.DueDateKeys).
+func (r Rule) Applies(task *domain.Task, now time.Time) bool {
+	if task.Status != domain.TaskStatusPending {
+		return false
+	}
+	if (r.Priority != "" && task.Priority != r.Priority) || (r.Context != "" && task.Context != r.Context) {
+		return false
+	}
+	due, ok := task.DueDate()
+	return ok && now.Sub(due.AddDate(0, 0, 1)) > r.Overdue
+}
+
+// Match is a rule and the tasks it applies to
+type Match struct {
+	Rule  Rule
+	Tasks []*domain.Task
+}
+
+// Evaluate returns, in rule order, the rules that apply to some of tasks
+func Evaluate(rules []Rule, tasks []*domain.Task, now time.Time) []Match {
+	var matches []Match
+	for _, rule := range rules {
+		match := Match{Rule: rule}
+		for _, task := range tasks {
+			if rule.Applies(task, now) {
+				match.Tasks = append(match.Tasks, task)
+			}
+		}
+		if len(match.Tasks) > 0 {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// Subject is the one-line summary of the match
+func (m Match) Subject() string {
+	return fmt.Sprintf("Escalation %s: %d overdue task(s)", m.Rule.Name, len(m.Tasks))
+}
+
+// Text is the match as a plain-text message: the subject, then a line per task
+func (m Match) Text() string {
+	return m.Subject() + "\n" + m.list()
+}
+
+// list describes the tasks of the match, a line each
+func (m Match) list() string {
+	var b strings.Builder
+	for _, task := range m.Tasks {
+		due, _ := task.DueDate()
+		fmt.Fprintf(&b, "- [%s] %s (%s, due %s", task.ID[:8], task.Title, task.Priority, due.Format(time.DateOnly))
+		if task.Assignee != "" {
+			fmt.Fprintf(&b, ", assigned to %s", task.Assignee)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// webhookMessage is the JSON body a webhook receives; chat services such
+// as Slack show text and ignore the rest
+type webhookMessage struct {
+	Text       string        `json:"text"`
+	Escalation string        `json:"escalation"`
+	Tasks      []webhookTask `json:"tasks"`
+}
+
+// webhookTask is an escalated task as sent to a webhook
+type webhookTask struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Priority string `json:"priority"`
+	Context  string `json:"context,omitempty"`
+	Assignee string `json:"assignee,omitempty"`
+	Due      string `json:"due"`
+}
+
+// Notifier delivers matches to the webhook and email recipients of their
+// rule, and to the desktop
+type Notifier struct {
+	Client  *http.Client    // nil uses one with a 10 second timeout
+	Mailer  *digest.Mailer  // needed for rules with email recipients
+	Desktop notify.Notifier // needed for rules with Desktop set
+}
+
+// Notify delivers a match, to its webhook first, then by email, then to
+// the desktop
+func (n *Notifier) Notify(ctx context.Context, m Match) error {
+	if m.Rule.Webhook != "" {
+		if err := n.post(ctx, m); err != nil {
+			return err
+		}
+	}
+	if len(m.Rule.Email) > 0 {
+		if n.Mailer == nil {
+			return fmt.Errorf("escalation %s has email recipients but no SMTP server is configured", m.Rule.Name)
+		}
+		if err := n.Mailer.Send(ctx, m.Rule.Email, Message(m, n.Mailer.From, time.Now())); err != nil {
+			return fmt.Errorf("failed to email escalation %s: %w", m.Rule.Name, err)
+		}
+	}
+	if m.Rule.Desktop {
+		if n.Desktop == nil {
+			return fmt.Errorf("escalation %s notifies the desktop but no notifier is set", m.Rule.Name)
+		}
+		if err := n.Desktop.Notify(ctx, notify.Notification{Title: m.Subject(), Body: m.list(), Urgent: true}); err != nil {
+			return fmt.Errorf("escalation %s: %w", m.Rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// post sends the match to the webhook of its rule
+func (n *Notifier) post(ctx context.Context, m Match) error {
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+
+	msg := webhookMessage{Text: m.Text(), Escalation: m.Rule.Name}
+	for _, task := range m.Tasks {
+		due, _ := task.DueDate()
+		msg.Tasks = append(msg.Tasks, webhookTask{
+			ID:       task.ID,
+			Title:    task.Title,
+			Priority: string(task.Priority),
+			Context:  task.Context,
+			Assignee: task.Assignee,
+			Due:      due.Format(time.DateOnly),
+		})
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode escalation: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.Rule.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook of escalation %s: %w", m.Rule.Name, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook of escalation %s returned %s", m.Rule.Name, resp.Status)
+	}
+	return nil
+}
+
+// Message renders the match as a plain-text email from the given sender
+func Message(m Match, from string, now time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.Rule.Email, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject()))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(m.Text(), "\n", "\r\n"))
+	return b.Bytes()
+}
@@ -0,0 +1,108 @@
+// Package events delivers the domain events published by the task service,
+// such as a task being created or completed, to subscribers that react to
+// them: webhooks, notifiers, or Go code embedding the service. The service
+// does not know about its subscribers.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// Type identifies the kind of an event
+type Type string
+
+const (
+	TaskCreated   Type = "task.created"
+	TaskUpdated   Type = "task.updated"
+	TaskCompleted Type = "task.completed"
+	TaskDeleted   Type = "task.deleted"
+	TaskOverdue   Type = "task.overdue"   // published by TaskService.PublishOverdue
+	TaskDelegated Type = "task.delegated" // published by TaskService.DelegateTask
+)
+
+// Types lists every event type
+var Types = []Type{TaskCreated, TaskUpdated, TaskCompleted, TaskDeleted, TaskOverdue, TaskDelegated}
+
+// Event is something that happened to a task
+type Event struct {
+	Type   Type
+	TaskID string
+	Task   *domain.Task // the task after the change; nil for TaskDeleted
+	At     time.Time
+	By     string // user who made the change, when known; set for TaskDelegated
+}
+
+// New creates an event of type t for task, which happened now
+func New(t Type, task *domain.Task) Event {
+	return Event{Type: t, TaskID: task.ID, Task: task, At: time.Now()}
+}
+
+// Subscriber handles an event. Returned errors are logged; they do not undo
+// the change or stop other subscribers.
+type Subscriber func(ctx context.Context, event Event) error
+
+// Publisher accepts events; Bus is the usual implementation
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// subscription is a subscriber and the event types it wants
+type subscription struct {
+	name  string
+	types []Type // empty for every type
+	fn    Subscriber
+}
+
+// Bus delivers each published event to the subscribers of its type, one
+// after another, in the order they subscribed
+type Bus struct {
+	mu     sync.RWMutex
+	subs   []subscription
+	logger *slog.Logger
+}
+
+// NewBus creates a bus without subscribers
+func NewBus(logger *slog.Logger) *Bus {
+	return &Bus{logger: logger}
+}
+
+// Subscribe registers fn under name (used in logs) for events of the given
+// types, or of every type if none are given
+func (b *Bus) Subscribe(name string, fn Subscriber, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, subscription{name: name, types: types, fn: fn})
+}
+
+// Publish delivers event to its subscribers and returns once they are done
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subs := slices.Clone(b.subs)
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if len(sub.types) > 0 && !slices.Contains(sub.types, event.Type) {
+			continue
+		}
+		if err := sub.fn(ctx, event); err != nil {
+			b.logger.Warn("Event subscriber failed", "subscriber", sub.name, "event", event.Type, "task_id", event.TaskID, "error", err)
+		}
+	}
+}
+
+// ParseType parses an event type name such as "task.created"
+func ParseType(s string) (Type, error) {
+	for _, t := range Types {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown event %q (must be one of task.created, task.updated, task.completed, task.deleted, task.overdue, task.delegated)", s)
+}
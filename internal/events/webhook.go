@@ -0,0 +1,78 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds each request of a webhook without its own client
+const webhookTimeout = 10 * time.Second
+
+// webhookPayload is the JSON body a webhook receives
+type webhookPayload struct {
+	Event  Type         `json:"event"`
+	TaskID string       `json:"task_id"`
+	At     time.Time    `json:"at"`
+	Task   *webhookTask `json:"task,omitempty"`
+	By     string       `json:"by,omitempty"`
+}
+
+// webhookTask is the summary of the task sent with an event
+type webhookTask struct {
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	Context     string     `json:"context,omitempty"`
+	Assignee    string     `json:"assignee,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Webhook returns a subscriber that POSTs each event as JSON to url. A
+// response other than 2xx is an error. A nil client uses one with a 10
+// second timeout.
+func Webhook(url string, client *http.Client) Subscriber {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+
+	return func(ctx context.Context, event Event) error {
+		payload := webhookPayload{Event: event.Type, TaskID: event.TaskID, At: event.At, By: event.By}
+		if t := event.Task; t != nil {
+			payload.Task = &webhookTask{
+				Title:       t.Title,
+				Status:      string(t.Status),
+				Priority:    string(t.Priority),
+				Context:     t.Context,
+				Assignee:    t.Assignee,
+				CompletedAt: t.CompletedAt,
+			}
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create webhook request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to call webhook: %w", err)
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("webhook %s returned %s", url, resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,206 @@
+// Package export provides stable serializations of task data.
+// The canonical form is deterministic so that two exports can be diffed
+// or hashed to verify that a sync or restore produced identical data.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// canonicalTask is the normalized form of a task. Fields are declared in
+// alphabetical order so the encoded key order is stable.
+type canonicalTask struct {
+	Assignee    string                 `json:"assignee"`
+	CompletedAt *string                `json:"completed_at"`
+	Context     string                 `json:"context"`
+	CreatedAt   string                 `json:"created_at"`
+	CreatedBy   string                 `json:"created_by"`
+	Description string                 `json:"description"`
+	Fields      map[string]string      `json:"fields"`
+	Estimate    int64                  `json:"estimate"` // seconds
+	ID          string                 `json:"id"`
+	Location    string                 `json:"location,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Pinned      bool                   `json:"pinned"`
+	Points      int                    `json:"points"`
+	Priority    string                 `json:"priority"`
+	Schema      int                    `json:"schema_version"`
+	Status      string                 `json:"status"`
+	Title       string                 `json:"title"`
+	UpdatedAt   string                 `json:"updated_at"`
+	WaitUntil   *string                `json:"wait_until"`
+}
+
+// WriteCanonical writes tasks as JSON Lines, one task per line, sorted by ID.
+// Timestamps are UTC RFC 3339 with nanoseconds, and metadata is re-encoded
+// with sorted keys at every level.
+func WriteCanonical(w io.Writer, tasks []*domain.Task) error {
+	sorted := make([]*domain.Task, len(tasks))
+	copy(sorted, tasks)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	for _, task := range sorted {
+		if err := WriteCanonicalTask(w, task); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteCanonicalTask writes a single task as one canonical JSON line. When
+// streaming, tasks must be written in ID order to produce the canonical form.
+func WriteCanonicalTask(w io.Writer, task *domain.Task) error {
+	line, err := canonicalize(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task %s: %w", task.ID, err)
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+// Digest returns the hex SHA-256 of the canonical form of tasks
+func Digest(tasks []*domain.Task) (string, error) {
+	h := sha256.New()
+	if err := WriteCanonical(h, tasks); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canonicalize encodes a single task in canonical form
+func canonicalize(task *domain.Task) ([]byte, error) {
+	ct := canonicalTask{
+		Assignee:    task.Assignee,
+		Context:     task.Context,
+		CreatedAt:   formatTime(task.CreatedAt),
+		CreatedBy:   task.CreatedBy,
+		Description: task.Description,
+		Estimate:    int64(task.Estimate / time.Second),
+		Fields:      task.Fields,
+		ID:          task.ID,
+		Location:    task.Location,
+		Metadata:    make(map[string]interface{}, len(task.Metadata)),
+		Pinned:      task.Pinned,
+		Points:      task.Points,
+		Priority:    string(task.Priority),
+		Schema:      SchemaVersion,
+		Status:      string(task.Status),
+		Title:       task.Title,
+		UpdatedAt:   formatTime(task.UpdatedAt),
+	}
+
+	if ct.Fields == nil {
+		ct.Fields = map[string]string{}
+	}
+	if task.CompletedAt != nil {
+		completed := formatTime(*task.CompletedAt)
+		ct.CompletedAt = &completed
+	}
+	if task.WaitUntil != nil {
+		wait := formatTime(*task.WaitUntil)
+		ct.WaitUntil = &wait
+	}
+
+	// Decoding into generic values lets the encoder sort nested object keys;
+	// UseNumber keeps numbers exactly as stored.
+	for key, raw := range task.Metadata {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("invalid metadata %q: %w", key, err)
+		}
+		ct.Metadata[key] = v
+	}
+
+	return json.Marshal(ct)
+}
+
+// formatTime renders t in UTC with full precision
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// ReadCanonical reads tasks from a canonical export, checking every line
+// against Schema. Errors name the line and, for schema mismatches, the
+// offending property, e.g. "line 3: /priority: must be one of low, medium,
+// high, not urgent".
+func ReadCanonical(r io.Reader) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		task, err := parseCanonical(data)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// parseCanonical decodes one validated line of a canonical export
+func parseCanonical(line []byte) (*domain.Task, error) {
+	if err := ValidateCanonicalTask(line); err != nil {
+		return nil, err
+	}
+
+	var ct struct {
+		canonicalTask
+		Metadata map[string]json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(line, &ct); err != nil {
+		return nil, err
+	}
+
+	task := &domain.Task{
+		ID:          ct.ID,
+		Title:       ct.Title,
+		Description: ct.Description,
+		Status:      domain.TaskStatus(ct.Status),
+		Priority:    domain.TaskPriority(ct.Priority),
+		Context:     ct.Context,
+		Assignee:    ct.Assignee,
+		CreatedBy:   ct.CreatedBy,
+		Pinned:      ct.Pinned,
+		Estimate:    time.Duration(ct.Estimate) * time.Second,
+		Points:      ct.Points,
+		Location:    ct.Location,
+		Fields:      ct.Fields,
+		Metadata:    domain.Metadata(ct.Metadata),
+	}
+	// The schema guarantees the timestamps parse
+	task.CreatedAt, _ = time.Parse(time.RFC3339Nano, ct.CreatedAt)
+	task.UpdatedAt, _ = time.Parse(time.RFC3339Nano, ct.UpdatedAt)
+	if ct.CompletedAt != nil {
+		completed, _ := time.Parse(time.RFC3339Nano, *ct.CompletedAt)
+		task.CompletedAt = &completed
+	}
+	if ct.WaitUntil != nil {
+		wait, _ := time.Parse(time.RFC3339Nano, *ct.WaitUntil)
+		task.WaitUntil = &wait
+	}
+
+	return task, nil
+}
@@ -0,0 +1,221 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// obsidianTagKeys are the metadata keys holding labels set by integrations
+var obsidianTagKeys = []string{"todoist.labels", "trello.labels", MetadataOrgTags, MetadataTodoTxtProjects}
+
+// obsidianFrontMatter is the YAML front matter of a task note. Field names
+// are plain so Dataview queries can use them directly, e.g.
+// TABLE priority, due FROM "tasks" WHERE status = "pending".
+type obsidianFrontMatter struct {
+	TaskID    string   `yaml:"task_id"`
+	Status    string   `yaml:"status"`
+	Priority  string   `yaml:"priority"`
+	Context   string   `yaml:"context,omitempty"`
+	Assignee  string   `yaml:"assignee,omitempty"`
+	Created   string   `yaml:"created"`
+	Completed string   `yaml:"completed,omitempty"`
+	Due       string   `yaml:"due,omitempty"`
+	Tags      []string `yaml:"tags,omitempty"`
+
+	Fields map[string]string `yaml:",inline"` // user-defined fields
+}
+
+// VaultResult counts what WriteObsidianVault did
+type VaultResult struct {
+	Written   int // notes created or changed
+	Unchanged int // notes already up to date
+	Removed   int // notes of tasks that no longer exist, or were renamed
+}
+
+// WriteObsidianVault writes one Markdown note per task into dir, named after
+// the task title, with YAML front matter (task_id, status, priority, context,
+// assignee, created, completed, due, tags) for Dataview queries. The due
+// date and tags come from task metadata such as due, todoist.due, and
+// trello.labels; the context is also a tag. Notes are only rewritten when
+// they change, and notes from an earlier export whose task is gone (or was
+// renamed) are removed. Other files in dir are left alone.
+func WriteObsidianVault(dir string, tasks []*domain.Task) (*VaultResult, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	sorted := make([]*domain.Task, len(tasks))
+	copy(sorted, tasks)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].CreatedAt.Before(sorted[j].CreatedAt) })
+
+	result := &VaultResult{}
+	written := make(map[string]bool, len(sorted))
+	taken := make(map[string]bool, len(sorted)) // lowercased, for case-insensitive file systems
+	for _, task := range sorted {
+		name := obsidianFileName(task, taken)
+		written[name] = true
+		taken[strings.ToLower(name)] = true
+
+		note, err := obsidianNote(task)
+		if err != nil {
+			return result, err
+		}
+
+		path := filepath.Join(dir, name)
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, note) {
+			result.Unchanged++
+			continue
+		}
+		if err := os.WriteFile(path, note, 0644); err != nil {
+			return result, fmt.Errorf("failed to write note: %w", err)
+		}
+		result.Written++
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read vault directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".md" || written[name] {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if !isTaskNote(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return result, fmt.Errorf("failed to remove stale note: %w", err)
+		}
+		result.Removed++
+	}
+
+	return result, nil
+}
+
+// obsidianNote renders the note of a task
+func obsidianNote(task *domain.Task) ([]byte, error) {
+	fm := obsidianFrontMatter{
+		TaskID:   task.ID,
+		Status:   string(task.Status),
+		Priority: string(task.Priority),
+		Context:  task.Context,
+		Assignee: task.Assignee,
+		Created:  task.CreatedAt.Local().Format("2006-01-02T15:04:05"),
+		Fields:   task.Fields,
+	}
+	if due, ok := task.DueDate(); ok {
+		fm.Due = due.Format(time.DateOnly)
+	}
+	if task.CompletedAt != nil {
+		fm.Completed = task.CompletedAt.Local().Format("2006-01-02T15:04:05")
+	}
+
+	tags, err := obsidianTags(task)
+	if err != nil {
+		return nil, err
+	}
+	fm.Tags = tags
+
+	front, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode front matter of task %s: %w", task.ID, err)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	b.Write(front)
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n", task.Title)
+	if task.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimRight(task.Description, "\n"))
+	}
+	return b.Bytes(), nil
+}
+
+// obsidianTags returns the context and metadata labels as Obsidian tags,
+// which cannot contain spaces
+func obsidianTags(task *domain.Task) ([]string, error) {
+	seen := make(map[string]bool)
+	var tags []string
+	add := func(tag string) {
+		tag = strings.Join(strings.Fields(strings.TrimPrefix(tag, "#")), "-")
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	add(task.Context)
+	for _, key := range obsidianTagKeys {
+		var labels []string
+		if _, err := task.Metadata.Get(key, &labels); err != nil {
+			return nil, fmt.Errorf("invalid metadata %q on task %s: %w", key, task.ID, err)
+		}
+		for _, label := range labels {
+			add(label)
+		}
+	}
+	return tags, nil
+}
+
+// obsidianFileName returns a file name for the note of task, made from its
+// title without the characters Obsidian does not allow in links. The short
+// task ID is added when the title is taken (taken holds lowercased names).
+func obsidianFileName(task *domain.Task, taken map[string]bool) string {
+	title := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|#^[]`, r) {
+			return ' '
+		}
+		return r
+	}, task.Title)
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" || strings.HasPrefix(title, ".") {
+		title = "Task" + title
+	}
+
+	name := title + ".md"
+	if taken[strings.ToLower(name)] {
+		name = fmt.Sprintf("%s (%s).md", title, shortID(task.ID))
+	}
+	return name
+}
+
+// isTaskNote reports whether the file at path is a note written by
+// WriteObsidianVault, i.e. its front matter has a task_id
+func isTaskNote(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != "---" {
+		return false
+	}
+	for scanner.Scan() && scanner.Text() != "---" {
+		if strings.HasPrefix(scanner.Text(), "task_id: ") {
+			return true
+		}
+	}
+	return false
+}
+
+// shortID returns the first 8 characters of a task ID
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
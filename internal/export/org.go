@@ -0,0 +1,129 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+const (
+	// MetadataOrgTags is the task metadata key holding Org tags other than the context
+	MetadataOrgTags = "org.tags"
+	// MetadataOrgDeadline is the task metadata key holding an Org DEADLINE timestamp
+	MetadataOrgDeadline = "org.deadline"
+	// MetadataOrgScheduled is the task metadata key holding an Org SCHEDULED timestamp
+	MetadataOrgScheduled = "org.scheduled"
+
+	// OrgTimeLayout is the layout of inactive Org timestamps, without brackets
+	OrgTimeLayout = "2006-01-02 Mon 15:04"
+)
+
+// priorityLetters maps task priorities to the letters used by Org and todo.txt
+var priorityLetters = map[domain.TaskPriority]string{
+	domain.TaskPriorityHigh:   "A",
+	domain.TaskPriorityMedium: "B",
+	domain.TaskPriorityLow:    "C",
+}
+
+// WriteOrgTask writes a task as an Org-mode heading:
+//
+//   - DONE [#A] Title                    :context:tag:
+//     CLOSED: [2026-01-02 Fri 10:00] DEADLINE: <2026-01-05 Mon>
+//     :PROPERTIES:
+//     :ID:       <task ID>
+//     :CREATED:  [2026-01-01 Thu 09:00]
+//     :END:
+//     Description
+//
+// The context is the first tag; characters Org does not allow in tags are
+// replaced with "_", and the exact context is then kept in a CONTEXT
+// property. Tags, DEADLINE, and SCHEDULED read from Org are written back
+// from the task metadata. Times are local, as in Org.
+func WriteOrgTask(w io.Writer, task *domain.Task) error {
+	var b strings.Builder
+
+	keyword := "TODO"
+	if task.Status == domain.TaskStatusCompleted {
+		keyword = "DONE"
+	}
+	fmt.Fprintf(&b, "* %s [#%s] %s", keyword, priorityLetters[task.Priority], task.Title)
+
+	var tags []string
+	if task.Context != "" {
+		tags = append(tags, orgTag(task.Context))
+	}
+	var extra []string
+	if _, err := task.Metadata.Get(MetadataOrgTags, &extra); err != nil {
+		return fmt.Errorf("invalid metadata %q on task %s: %w", MetadataOrgTags, task.ID, err)
+	}
+	tags = append(tags, extra...)
+	if len(tags) > 0 {
+		fmt.Fprintf(&b, " :%s:", strings.Join(tags, ":"))
+	}
+	b.WriteString("\n")
+
+	var planning []string
+	if task.CompletedAt != nil {
+		planning = append(planning, "CLOSED: "+orgTime(*task.CompletedAt))
+	}
+	if deadline, ok := task.Metadata.GetString(MetadataOrgDeadline); ok {
+		planning = append(planning, "DEADLINE: "+deadline)
+	}
+	if scheduled, ok := task.Metadata.GetString(MetadataOrgScheduled); ok {
+		planning = append(planning, "SCHEDULED: "+scheduled)
+	}
+	if len(planning) > 0 {
+		fmt.Fprintf(&b, "  %s\n", strings.Join(planning, " "))
+	}
+
+	b.WriteString("  :PROPERTIES:\n")
+	fmt.Fprintf(&b, "  :ID:       %s\n", task.ID)
+	fmt.Fprintf(&b, "  :CREATED:  %s\n", orgTime(task.CreatedAt))
+	if task.Context != "" && orgTag(task.Context) != task.Context {
+		fmt.Fprintf(&b, "  :CONTEXT:  %s\n", task.Context)
+	}
+	names := make([]string, 0, len(task.Fields))
+	for name := range task.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-10s %s\n", ":"+strings.ToUpper(name)+":", task.Fields[name])
+	}
+	b.WriteString("  :END:\n")
+
+	// Indenting the description keeps lines starting with "*" from becoming headings
+	if task.Description != "" {
+		for _, line := range strings.Split(task.Description, "\n") {
+			if line == "" {
+				b.WriteString("\n")
+				continue
+			}
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// orgTag turns a context into a valid Org tag, which may only contain
+// letters, digits, "_", "@", "#", and "%"
+func orgTag(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_@#%", r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// orgTime formats t as an inactive Org timestamp
+func orgTime(t time.Time) string {
+	return "[" + t.Local().Format(OrgTimeLayout) + "]"
+}
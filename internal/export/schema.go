@@ -0,0 +1,236 @@
+package export
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the version of the canonical export format written by
+// this version of task, recorded in every line as schema_version. It only
+// changes when a change to the format could break readers.
+const SchemaVersion = 1
+
+// Schema is the JSON Schema of one line of a canonical export
+//
+//go:embed schema/task.v1.json
+var Schema []byte
+
+// SchemaError reports where a document does not match the schema
+type SchemaError struct {
+	Path    string // JSON Pointer to the offending value, e.g. /priority; empty for the document
+	Message string
+}
+
+// Error implements the error interface
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// schemaNode is the subset of JSON Schema used by Schema: type, enum,
+// const, required, properties, additionalProperties, minimum, minLength,
+// and the uuid and date-time formats
+type schemaNode struct {
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Const                any                    `json:"const"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Minimum              *float64               `json:"minimum"`
+	MinLength            *int                   `json:"minLength"`
+	Format               string                 `json:"format"`
+}
+
+// schemaTypes is the type keyword, a single type name or a list of them
+type schemaTypes []string
+
+// UnmarshalJSON accepts a type name or a list of type names
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = schemaTypes{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// taskSchema is Schema, parsed
+var taskSchema = func() *schemaNode {
+	var node schemaNode
+	if err := json.Unmarshal(Schema, &node); err != nil {
+		panic(fmt.Sprintf("invalid embedded schema: %v", err))
+	}
+	return &node
+}()
+
+// ValidateCanonicalTask checks one line of a canonical export against
+// Schema. A newer schema_version is reported as such rather than as a
+// mismatch. Errors are *SchemaError.
+func ValidateCanonicalTask(line []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return &SchemaError{Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+
+	if obj, ok := doc.(map[string]any); ok {
+		if n, ok := obj["schema_version"].(json.Number); ok {
+			if v, err := n.Int64(); err == nil && v > SchemaVersion {
+				return &SchemaError{Path: "/schema_version", Message: fmt.Sprintf("version %d is newer than this version of task reads (%d); upgrade task", v, SchemaVersion)}
+			}
+		}
+	}
+
+	return taskSchema.validate("", doc)
+}
+
+// validate checks value, found at path, against the node
+func (s *schemaNode) validate(path string, value any) error {
+	fail := func(format string, args ...any) error {
+		return &SchemaError{Path: path, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(name string) bool { return hasType(value, name) }) {
+		return fail("must be %s, not %s", strings.Join(s.Type, " or "), typeName(value))
+	}
+	if s.Enum != nil && !slices.ContainsFunc(s.Enum, func(v any) bool { return sameValue(v, value) }) {
+		names := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			names[i] = fmt.Sprint(v)
+		}
+		return fail("must be one of %s, not %v", strings.Join(names, ", "), value)
+	}
+	if s.Const != nil && !sameValue(s.Const, value) {
+		return fail("must be %v, not %v", s.Const, value)
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.MinLength != nil && utf8.RuneCountInString(v) < *s.MinLength {
+			return fail("must be at least %d character(s) long", *s.MinLength)
+		}
+		switch s.Format {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				return fail("must be an RFC 3339 date and time, not %q", v)
+			}
+		case "uuid":
+			if _, err := uuid.Parse(v); err != nil {
+				return fail("must be a UUID, not %q", v)
+			}
+		}
+	case json.Number:
+		if f, err := v.Float64(); err == nil && s.Minimum != nil && f < *s.Minimum {
+			return fail("must be at least %v", *s.Minimum)
+		}
+	case map[string]any:
+		return s.validateObject(path, v)
+	}
+	return nil
+}
+
+// validateObject checks the properties of an object, in name order so
+// that the first error reported is always the same
+func (s *schemaNode) validateObject(path string, obj map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("missing required property %q", name)}
+		}
+	}
+
+	var additional *schemaNode
+	allowAdditional := true
+	if len(s.AdditionalProperties) > 0 {
+		if err := json.Unmarshal(s.AdditionalProperties, &allowAdditional); err != nil {
+			additional = &schemaNode{}
+			if err := json.Unmarshal(s.AdditionalProperties, additional); err != nil {
+				return errors.New("invalid additionalProperties in schema")
+			}
+			allowAdditional = true
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		child := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+		node, ok := s.Properties[name]
+		switch {
+		case ok:
+		case !allowAdditional:
+			return &SchemaError{Path: child, Message: "unknown property"}
+		case additional != nil:
+			node = additional
+		default:
+			continue
+		}
+		if err := node.validate(child, obj[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasType reports whether value, decoded with UseNumber, is of the JSON Schema type name
+func hasType(value any, name string) bool {
+	switch v := value.(type) {
+	case nil:
+		return name == "null"
+	case bool:
+		return name == "boolean"
+	case string:
+		return name == "string"
+	case json.Number:
+		if name == "number" {
+			return true
+		}
+		_, err := v.Int64()
+		return name == "integer" && err == nil
+	case []any:
+		return name == "array"
+	case map[string]any:
+		return name == "object"
+	}
+	return false
+}
+
+// typeName returns the JSON Schema type of value, for error messages
+func typeName(value any) string {
+	for _, name := range []string{"null", "boolean", "string", "integer", "number", "array", "object"} {
+		if hasType(value, name) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// sameValue compares a value from the schema with one from a document;
+// numbers are compared by their text
+func sameValue(schema, value any) bool {
+	if n, ok := value.(json.Number); ok {
+		return fmt.Sprint(schema) == n.String()
+	}
+	return schema == value
+}
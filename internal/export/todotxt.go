@@ -0,0 +1,87 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+const (
+	// MetadataTodoTxtProjects is the task metadata key holding todo.txt +projects
+	MetadataTodoTxtProjects = "todotxt.projects"
+	// MetadataTodoTxtContexts is the task metadata key holding todo.txt
+	// @contexts other than the first, which becomes the task context
+	MetadataTodoTxtContexts = "todotxt.contexts"
+	// MetadataTodoTxtPrefix prefixes the metadata keys holding todo.txt
+	// key:value tags, e.g. due:2026-01-31 is kept as todotxt.due
+	MetadataTodoTxtPrefix = "todotxt."
+)
+
+// WriteTodoTxtTask writes a task as one todo.txt line
+// (https://github.com/todotxt/todo.txt):
+//
+//	(A) 2026-01-01 Title @context +project due:2026-01-31 id:<task ID>
+//	x 2026-01-02 2026-01-01 Title @context id:<task ID> pri:A
+//
+// Completed tasks keep their priority as a pri: tag, as the format asks to
+// drop it from the front. Projects, extra contexts, and key:value tags read
+// from todo.txt are written back from the task metadata. The description
+// has no place in a todo.txt line and is not exported.
+func WriteTodoTxtTask(w io.Writer, task *domain.Task) error {
+	var parts []string
+
+	priority := priorityLetters[task.Priority]
+	created := task.CreatedAt.Local().Format(time.DateOnly)
+	if task.Status == domain.TaskStatusCompleted {
+		completed := task.UpdatedAt
+		if task.CompletedAt != nil {
+			completed = *task.CompletedAt
+		}
+		parts = append(parts, "x", completed.Local().Format(time.DateOnly), created)
+	} else {
+		parts = append(parts, "("+priority+")", created)
+	}
+	parts = append(parts, task.Title)
+
+	if task.Context != "" {
+		parts = append(parts, "@"+task.Context)
+	}
+	for _, key := range []string{MetadataTodoTxtContexts, MetadataTodoTxtProjects} {
+		var words []string
+		if _, err := task.Metadata.Get(key, &words); err != nil {
+			return fmt.Errorf("invalid metadata %q on task %s: %w", key, task.ID, err)
+		}
+		sigil := "@"
+		if key == MetadataTodoTxtProjects {
+			sigil = "+"
+		}
+		for _, word := range words {
+			parts = append(parts, sigil+word)
+		}
+	}
+
+	var keys []string
+	for key := range task.Metadata {
+		if strings.HasPrefix(key, MetadataTodoTxtPrefix) && key != MetadataTodoTxtContexts && key != MetadataTodoTxtProjects {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value, ok := task.Metadata.GetString(key); ok {
+			parts = append(parts, strings.TrimPrefix(key, MetadataTodoTxtPrefix)+":"+value)
+		}
+	}
+
+	parts = append(parts, "id:"+task.ID)
+	if task.Status == domain.TaskStatusCompleted {
+		parts = append(parts, "pri:"+priority)
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(parts, " "))
+	return err
+}
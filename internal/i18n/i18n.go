@@ -0,0 +1,125 @@
+// Package i18n translates user-facing messages. Messages are written in
+// English in the code and looked up by that English text in a catalog per
+// language, locales/<language>.json, mapping each message to its
+// translation; messages missing from a catalog are shown in English.
+// Adding a language only takes a new catalog.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// English is the language messages are written in
+const English = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Printer formats messages in one language
+type Printer struct {
+	language string
+	messages map[string]string
+}
+
+// Detect returns the language to use: configured when set, otherwise the
+// first of LC_ALL, LC_MESSAGES, and LANG that is set, as POSIX does. The
+// result is a tag such as "pt_BR", without encoding or modifier; the C and
+// POSIX locales are English.
+func Detect(configured string) string {
+	value := configured
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value != "" {
+			break
+		}
+		value = os.Getenv(name)
+	}
+
+	value, _, _ = strings.Cut(value, ".")
+	value, _, _ = strings.Cut(value, "@")
+	value = strings.ReplaceAll(value, "-", "_")
+	if value == "" || value == "C" || value == "POSIX" {
+		return English
+	}
+	return value
+}
+
+// Languages returns the languages with a catalog, plus English
+func Languages() []string {
+	languages := []string{English}
+	entries, _ := locales.ReadDir("locales")
+	for _, entry := range entries {
+		languages = append(languages, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Supported reports whether language, or its base language (pt for pt_BR),
+// is English or has a catalog
+func Supported(language string) bool {
+	for _, candidate := range candidates(language) {
+		if candidate == English {
+			return true
+		}
+		if _, err := locales.Open("locales/" + candidate + ".json"); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// New creates a printer for language, using the catalog of the language
+// itself or else of its base language. Unsupported languages print English.
+func New(language string) (*Printer, error) {
+	for _, candidate := range candidates(language) {
+		if candidate == English {
+			break
+		}
+		data, err := locales.ReadFile("locales/" + candidate + ".json")
+		if err != nil {
+			continue
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return &Printer{language: English}, fmt.Errorf("failed to read %s messages: %w", candidate, err)
+		}
+		return &Printer{language: candidate, messages: messages}, nil
+	}
+	return &Printer{language: English}, nil
+}
+
+// candidates returns language followed by its base language, if different
+func candidates(language string) []string {
+	base, _, _ := strings.Cut(language, "_")
+	if base == language {
+		return []string{language}
+	}
+	return []string{language, base}
+}
+
+// Language returns the language of the catalog in use
+func (p *Printer) Language() string {
+	if p == nil {
+		return English
+	}
+	return p.language
+}
+
+// Sprintf translates format, then formats it with args like fmt.Sprintf.
+// A nil Printer prints English.
+func (p *Printer) Sprintf(format string, args ...any) string {
+	if p != nil {
+		if translated, ok := p.messages[format]; ok && translated != "" {
+			format = translated
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"io"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/export"
+)
+
+// CanonicalProvider is the external reference provider name for tasks read
+// from a canonical export
+const CanonicalProvider = "task"
+
+// ParseCanonical reads tasks written by "task export canonical", checking
+// each line against export.Schema. Tasks keep their IDs and every attribute
+// except the time of their last update; tasks without a context get
+// project, if set.
+func ParseCanonical(r io.Reader, project string) ([]Item, error) {
+	tasks, err := export.ReadCanonical(r)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(tasks))
+	for _, task := range tasks {
+		item := Item{
+			RemoteID:    task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Priority:    task.Priority,
+			Context:     task.Context,
+			Completed:   task.Status == domain.TaskStatusCompleted,
+			Metadata:    make(map[string]interface{}, len(task.Metadata)),
+			CreatedAt:   task.CreatedAt,
+			Options: []domain.TaskOption{
+				domain.WithID(task.ID),
+				domain.WithWaitUntil(task.WaitUntil),
+				domain.WithPinned(task.Pinned),
+				domain.WithEstimate(task.Estimate),
+				domain.WithPoints(task.Points),
+				domain.WithLocation(task.Location),
+			},
+		}
+		if item.Context == "" {
+			item.Context = contextName(project)
+		}
+		if task.Assignee != "" {
+			item.Options = append(item.Options, domain.WithAssignee(task.Assignee))
+		}
+		if task.CreatedBy != "" {
+			item.Options = append(item.Options, domain.WithCreatedBy(task.CreatedBy))
+		}
+		if task.CompletedAt != nil {
+			item.CompletedAt = *task.CompletedAt
+		}
+		for key, raw := range task.Metadata {
+			item.Metadata[key] = raw
+		}
+		for name, value := range task.Fields {
+			item.Options = append(item.Options, domain.WithField(name, value))
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,181 @@
+// Package importer brings tasks over from other task managers. Each format
+// parser turns an export (or an API listing) into Items; the Importer then
+// creates all new items as tasks in one atomic batch and links each one to
+// its source item, so importing the same export again skips what was
+// already imported.
+package importer
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/integrations/linking"
+	"github.com/edson-mazvila/task-manager/internal/service"
+)
+
+// Item is a task read from another task manager
+type Item struct {
+	RemoteID    string // ID in the source, used to skip re-imports; empty if the source has none
+	Title       string
+	Description string
+	Priority    domain.TaskPriority
+	Context     string                 // from the source project, list, or board
+	Completed   bool                   // imported as a completed task
+	Metadata    map[string]interface{} // source attributes with no task field, e.g. labels and due dates
+	CreatedAt   time.Time              // zero when the source has no creation date
+	CompletedAt time.Time              // with Completed; zero when the source has no completion date
+	Options     []domain.TaskOption    // further attributes, for sources that have them
+}
+
+// Tasks is the part of the task service used by the importer
+type Tasks interface {
+	CreateTasks(ctx context.Context, drafts []service.NewTaskDraft) ([]*domain.Task, error)
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Result lists what an import did, or would do in a dry run
+type Result struct {
+	Created []*domain.Task
+	Items   []Item // the items behind Created, in the same order
+	Skipped int    // items imported before
+}
+
+// Importer creates tasks from imported items
+type Importer struct {
+	tasks  Tasks
+	linker *linking.Linker
+	logger *slog.Logger
+}
+
+// NewImporter creates a new importer
+func NewImporter(tasks Tasks, linker *linking.Linker, logger *slog.Logger) *Importer {
+	return &Importer{
+		tasks:  tasks,
+		linker: linker,
+		logger: logger,
+	}
+}
+
+// Import creates a task for every item not imported from provider before.
+// The tasks and their links are created in one transaction: if any item is
+// invalid, no task is created. With dryRun the items are still validated,
+// but nothing is written; tasks should then be a dry-run service.
+func (i *Importer) Import(ctx context.Context, provider string, items []Item, dryRun bool) (*Result, error) {
+	result := &Result{}
+
+	var drafts []service.NewTaskDraft
+	for _, item := range items {
+		if item.RemoteID != "" {
+			_, found, err := i.linker.TaskID(ctx, provider, item.RemoteID)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				result.Skipped++
+				continue
+			}
+		}
+
+		priority := item.Priority
+		if priority == "" {
+			priority = domain.TaskPriorityMedium
+		}
+
+		var opts []domain.TaskOption
+		if item.Context != "" {
+			opts = append(opts, domain.WithTaskContext(item.Context))
+		}
+		if len(item.Metadata) > 0 {
+			opts = append(opts, withMetadata(item.Metadata))
+		}
+		opts = append(opts, item.Options...)
+		opts = append(opts, withDates(item))
+
+		drafts = append(drafts, service.NewTaskDraft{
+			Title:       item.Title,
+			Description: item.Description,
+			Priority:    priority,
+			Options:     opts,
+		})
+		result.Items = append(result.Items, item)
+	}
+
+	if len(drafts) == 0 {
+		return result, nil
+	}
+
+	err := i.tasks.WithTx(ctx, func(ctx context.Context) error {
+		created, err := i.tasks.CreateTasks(ctx, drafts)
+		if err != nil {
+			return err
+		}
+		result.Created = created
+		if dryRun {
+			return nil
+		}
+
+		for n, task := range created {
+			if item := result.Items[n]; item.RemoteID != "" {
+				if _, err := i.linker.Link(ctx, provider, item.RemoteID, task.ID, linking.RemoteVersion{}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	i.logger.Info("Tasks imported", "provider", provider, "created", len(result.Created), "skipped", result.Skipped)
+	return result, nil
+}
+
+// withMetadata stores each value under its key in the task's metadata
+func withMetadata(values map[string]interface{}) domain.TaskOption {
+	return func(t *domain.Task) {
+		for key, value := range values {
+			// Values are plain strings and slices, which always encode
+			_ = t.SetMetadata(key, value)
+		}
+	}
+}
+
+// withDates applies the creation date and completion of an item, keeping
+// the dates from the source when it has them
+func withDates(item Item) domain.TaskOption {
+	return func(t *domain.Task) {
+		if !item.CreatedAt.IsZero() {
+			t.CreatedAt = item.CreatedAt
+			t.UpdatedAt = item.CreatedAt
+		}
+		if item.Completed {
+			completed := item.CompletedAt
+			if completed.IsZero() {
+				completed = time.Now()
+			}
+			t.Status = domain.TaskStatusCompleted
+			t.CompletedAt = &completed
+			if completed.Before(t.CreatedAt) {
+				t.CreatedAt = completed
+			}
+			t.UpdatedAt = completed
+		}
+	}
+}
+
+// contextName turns a project, list, or board name into a task context,
+// e.g. "Home Renovation" becomes "home-renovation"
+func contextName(name string) string {
+	fields := strings.FieldsFunc(domain.NormalizeContext(name), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '@'
+	})
+	return strings.Join(fields, "-")
+}
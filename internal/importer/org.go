@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/export"
+)
+
+// OrgProvider is the external reference provider name for Org-mode headings
+const OrgProvider = "org"
+
+// orgKeywords maps the TODO keywords recognized in headings to whether
+// they mark the task as completed
+var orgKeywords = map[string]bool{
+	"TODO": false, "NEXT": false, "STARTED": false, "WAITING": false, "HOLD": false,
+	"DONE": true, "CANCELED": true, "CANCELLED": true,
+}
+
+var (
+	orgHeading  = regexp.MustCompile(`^(\*+)\s+(.*)$`)
+	orgCookie   = regexp.MustCompile(`^\[#([A-Za-z])\]\s*`)
+	orgTags     = regexp.MustCompile(`\s+:([\p{L}\p{N}_@#%:]+):\s*$`)
+	orgPlanning = regexp.MustCompile(`(CLOSED|DEADLINE|SCHEDULED):\s*([\[<][^\]>]*[\]>])`)
+	orgProperty = regexp.MustCompile(`^:([^:\s]+):\s*(.*)$`)
+	orgDrawer   = regexp.MustCompile(`^:[\w-]+:$`)
+)
+
+// ParseOrg reads tasks from an Org-mode file. Headings with a TODO keyword
+// (TODO, NEXT, STARTED, WAITING, HOLD, DONE, CANCELED) become items; other
+// headings are outline structure and are skipped. Priority cookies [#A],
+// [#B], [#C] map to high, medium, low. The first tag (or a CONTEXT property)
+// becomes the context and the other tags are kept in the metadata, as are
+// DEADLINE and SCHEDULED timestamps. CLOSED and a CREATED property set the
+// dates, an ID property identifies the heading so it is not imported twice,
+// and the text under the heading becomes the description. Headings without
+// a context get project, if set.
+func ParseOrg(r io.Reader, project string) ([]Item, error) {
+	var items []Item
+	var body []string
+	var current *Item
+	inDrawer, inProperties := false, false
+
+	finish := func() {
+		if current == nil {
+			return
+		}
+		current.Description = strings.TrimSpace(dedent(body))
+		if current.Context == "" {
+			current.Context = contextName(project)
+		}
+		items = append(items, *current)
+		current, body = nil, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if m := orgHeading.FindStringSubmatch(text); m != nil {
+			finish()
+			item, ok, err := parseOrgHeading(m[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if ok {
+				current = &item
+			}
+			inDrawer, inProperties = false, false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(text)
+		switch {
+		case inDrawer:
+			if strings.EqualFold(trimmed, ":END:") {
+				inDrawer, inProperties = false, false
+				continue
+			}
+			if inProperties {
+				if err := applyOrgProperty(current, trimmed); err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+			}
+		case strings.EqualFold(trimmed, ":PROPERTIES:"):
+			inDrawer, inProperties = true, true
+		case orgDrawer.MatchString(trimmed):
+			// Other drawers, such as :LOGBOOK:, are skipped
+			inDrawer = true
+		case len(body) == 0 && orgPlanning.MatchString(trimmed):
+			if err := applyOrgPlanning(current, trimmed); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		default:
+			body = append(body, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Org file: %w", err)
+	}
+	finish()
+
+	return items, nil
+}
+
+// parseOrgHeading reads the title of a heading (after the stars). It
+// returns false for headings without a TODO keyword.
+func parseOrgHeading(heading string) (Item, bool, error) {
+	keyword, rest, _ := strings.Cut(heading, " ")
+	completed, ok := orgKeywords[keyword]
+	if !ok {
+		return Item{}, false, nil
+	}
+	item := Item{Completed: completed, Metadata: make(map[string]interface{})}
+
+	rest = strings.TrimSpace(rest)
+	if m := orgCookie.FindStringSubmatch(rest); m != nil {
+		item.Priority = letterPriority(m[1])
+		rest = rest[len(m[0]):]
+	}
+
+	if m := orgTags.FindStringSubmatchIndex(rest); m != nil {
+		tags := strings.Split(rest[m[2]:m[3]], ":")
+		rest = rest[:m[0]]
+		item.Context = contextName(tags[0])
+		if len(tags) > 1 {
+			item.Metadata[export.MetadataOrgTags] = tags[1:]
+		}
+	}
+
+	item.Title = strings.TrimSpace(rest)
+	if item.Title == "" {
+		return Item{}, false, fmt.Errorf("%s heading has no title", keyword)
+	}
+	return item, true, nil
+}
+
+// applyOrgProperty applies a line of a property drawer to item
+func applyOrgProperty(item *Item, line string) error {
+	m := orgProperty.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	value := strings.TrimSpace(m[2])
+
+	switch strings.ToUpper(m[1]) {
+	case "ID":
+		item.RemoteID = value
+	case "CONTEXT":
+		item.Context = contextName(value)
+	case "CREATED":
+		created, err := parseOrgTime(value)
+		if err != nil {
+			return err
+		}
+		item.CreatedAt = created
+	}
+	return nil
+}
+
+// applyOrgPlanning applies a planning line (CLOSED, DEADLINE, SCHEDULED) to item
+func applyOrgPlanning(item *Item, line string) error {
+	for _, m := range orgPlanning.FindAllStringSubmatch(line, -1) {
+		switch m[1] {
+		case "CLOSED":
+			closed, err := parseOrgTime(m[2])
+			if err != nil {
+				return err
+			}
+			item.CompletedAt = closed
+		case "DEADLINE":
+			item.Metadata[export.MetadataOrgDeadline] = m[2]
+		case "SCHEDULED":
+			item.Metadata[export.MetadataOrgScheduled] = m[2]
+		}
+	}
+	return nil
+}
+
+// parseOrgTime parses an Org timestamp such as [2026-01-02 Fri 10:00] in
+// local time; the day name and time are optional
+func parseOrgTime(value string) (time.Time, error) {
+	fields := strings.Fields(strings.Trim(value, "[]<>"))
+	if len(fields) == 0 {
+		return time.Time{}, fmt.Errorf("invalid Org timestamp %q", value)
+	}
+
+	layout, text := time.DateOnly, fields[0]
+	for _, field := range fields[1:] {
+		if strings.Contains(field, ":") {
+			layout, text = layout+" 15:04", text+" "+field
+			break
+		}
+	}
+
+	t, err := time.ParseInLocation(layout, text, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid Org timestamp %q", value)
+	}
+	return t, nil
+}
+
+// letterPriority maps the priority letters of Org and todo.txt to task
+// priorities: A is high, B medium, and anything lower is low
+func letterPriority(letter string) domain.TaskPriority {
+	switch strings.ToUpper(letter) {
+	case "A":
+		return domain.TaskPriorityHigh
+	case "B":
+		return domain.TaskPriorityMedium
+	default:
+		return domain.TaskPriorityLow
+	}
+}
+
+// dedent removes the indentation common to the non-blank lines
+func dedent(lines []string) string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		}
+		out[i] = line
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+const (
+	// TodoistProvider is the external reference provider name for Todoist tasks
+	TodoistProvider = "todoist"
+	// TodoistAPIURL is the Todoist REST API endpoint
+	TodoistAPIURL = "https://api.todoist.com/rest/v2"
+
+	// MetadataTodoistLabels is the task metadata key holding Todoist labels
+	MetadataTodoistLabels = "todoist.labels"
+	// MetadataTodoistDue is the task metadata key holding the Todoist due date
+	MetadataTodoistDue = "todoist.due"
+)
+
+// todoistPriority maps Todoist priorities p1 (urgent) to p4 (none) to task priorities
+func todoistPriority(p int) domain.TaskPriority {
+	switch p {
+	case 1:
+		return domain.TaskPriorityHigh
+	case 2:
+		return domain.TaskPriorityMedium
+	default:
+		return domain.TaskPriorityLow
+	}
+}
+
+// ParseTodoistCSV reads a Todoist project exported as CSV (Project > Export
+// as template). Every task row becomes an item in the given project; "@label"
+// words are taken out of the title as labels, and note rows are appended to
+// the description of the task above them. CSV exports carry no task IDs, so
+// importing the same file twice creates the tasks twice.
+func ParseTodoistCSV(r io.Reader, project string) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"TYPE", "CONTENT"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("not a Todoist CSV export: missing %s column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []Item
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		switch strings.ToLower(field(record, "TYPE")) {
+		case "task":
+			title, labels := splitLabels(field(record, "CONTENT"))
+			if title == "" {
+				return nil, fmt.Errorf("line %d: task has no content", line)
+			}
+
+			priority := 4
+			if p := field(record, "PRIORITY"); p != "" {
+				if priority, err = strconv.Atoi(p); err != nil || priority < 1 || priority > 4 {
+					return nil, fmt.Errorf("line %d: invalid priority %q (must be 1-4)", line, p)
+				}
+			}
+
+			items = append(items, Item{
+				Title:       title,
+				Description: field(record, "DESCRIPTION"),
+				Priority:    todoistPriority(priority),
+				Context:     contextName(project),
+				Metadata:    todoistMetadata(labels, field(record, "DATE")),
+			})
+		case "note":
+			if len(items) == 0 {
+				continue
+			}
+			last := &items[len(items)-1]
+			last.Description = strings.TrimSpace(last.Description + "\n\n" + field(record, "CONTENT"))
+		}
+	}
+	return items, nil
+}
+
+// splitLabels removes "@label" words from content and returns them separately
+func splitLabels(content string) (string, []string) {
+	var words, labels []string
+	for _, word := range strings.Fields(content) {
+		if len(word) > 1 && strings.HasPrefix(word, "@") {
+			labels = append(labels, word[1:])
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), labels
+}
+
+// todoistMetadata returns the metadata kept for a Todoist task
+func todoistMetadata(labels []string, due string) map[string]interface{} {
+	metadata := make(map[string]interface{})
+	if len(labels) > 0 {
+		metadata[MetadataTodoistLabels] = labels
+	}
+	if due != "" {
+		metadata[MetadataTodoistDue] = due
+	}
+	return metadata
+}
+
+// TodoistClient reads active tasks from the Todoist REST API
+type TodoistClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewTodoistClient creates a client authenticating with an API token
+// (Settings > Integrations > Developer in Todoist)
+func NewTodoistClient(token, baseURL string) *TodoistClient {
+	if baseURL == "" {
+		baseURL = TodoistAPIURL
+	}
+	return &TodoistClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// todoistTask is a task as returned by the Todoist REST API
+type todoistTask struct {
+	ID          string   `json:"id"`
+	Content     string   `json:"content"`
+	Description string   `json:"description"`
+	Priority    int      `json:"priority"` // 4 is p1 (urgent), 1 is p4 (none)
+	ProjectID   string   `json:"project_id"`
+	Labels      []string `json:"labels"`
+	Due         *struct {
+		Date     string `json:"date"`
+		Datetime string `json:"datetime"`
+	} `json:"due"`
+}
+
+// Items returns every active Todoist task as an item, with its project as context
+func (c *TodoistClient) Items(ctx context.Context) ([]Item, error) {
+	var projects []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := c.get(ctx, "/projects", &projects); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(projects))
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+
+	var tasks []todoistTask
+	if err := c.get(ctx, "/tasks", &tasks); err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(tasks))
+	for _, t := range tasks {
+		due := ""
+		if t.Due != nil {
+			due = t.Due.Date
+			if t.Due.Datetime != "" {
+				due = t.Due.Datetime
+			}
+		}
+
+		items = append(items, Item{
+			RemoteID:    t.ID,
+			Title:       t.Content,
+			Description: t.Description,
+			Priority:    todoistPriority(5 - t.Priority),
+			Context:     contextName(names[t.ProjectID]),
+			Metadata:    todoistMetadata(t.Labels, due),
+		})
+	}
+	return items, nil
+}
+
+// get fetches path from the API and decodes the JSON response into out
+func (c *TodoistClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("Todoist request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Todoist API error %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Todoist response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/export"
+)
+
+// TodoTxtProvider is the external reference provider name for todo.txt lines
+const TodoTxtProvider = "todotxt"
+
+// todoTxtPriority matches the priority at the start of a todo.txt line
+var todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+
+// ParseTodoTxt reads tasks from a todo.txt file, one per line
+// (https://github.com/todotxt/todo.txt). Lines starting with "x" are
+// completed; priorities (A), (B), (C) and lower map to high, medium, low,
+// and a pri: tag sets the priority of completed lines. The first @context
+// becomes the context; further contexts, +projects, and key:value tags such
+// as due: are kept in the metadata. An id: tag identifies the line so it is
+// not imported twice. Lines without a context get project, if set.
+func ParseTodoTxt(r io.Reader, project string) ([]Item, error) {
+	var items []Item
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 {
+			continue
+		}
+
+		item, err := parseTodoTxtLine(words)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if item.Context == "" {
+			item.Context = contextName(project)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt: %w", err)
+	}
+
+	return items, nil
+}
+
+// parseTodoTxtLine reads the words of one todo.txt line
+func parseTodoTxtLine(words []string) (Item, error) {
+	item := Item{Metadata: make(map[string]interface{})}
+
+	// date takes a leading date off words, or returns the zero time
+	date := func() time.Time {
+		if len(words) == 0 {
+			return time.Time{}
+		}
+		t, err := time.ParseInLocation(time.DateOnly, words[0], time.Local)
+		if err != nil {
+			return time.Time{}
+		}
+		words = words[1:]
+		return t
+	}
+
+	if words[0] == "x" {
+		item.Completed = true
+		words = words[1:]
+		item.CompletedAt = date()
+	} else if m := todoTxtPriority.FindStringSubmatch(words[0]); m != nil {
+		item.Priority = letterPriority(m[1])
+		words = words[1:]
+	}
+	item.CreatedAt = date()
+
+	var title, contexts, projects []string
+	for _, word := range words {
+		switch {
+		case len(word) > 1 && word[0] == '@':
+			if item.Context == "" {
+				item.Context = contextName(word[1:])
+			} else {
+				contexts = append(contexts, word[1:])
+			}
+		case len(word) > 1 && word[0] == '+':
+			projects = append(projects, word[1:])
+		default:
+			key, value, ok := todoTxtTag(word)
+			switch {
+			case !ok:
+				title = append(title, word)
+			case key == "id":
+				item.RemoteID = value
+			case key == "pri":
+				item.Priority = letterPriority(value)
+			default:
+				item.Metadata[export.MetadataTodoTxtPrefix+key] = value
+			}
+		}
+	}
+
+	item.Title = strings.Join(title, " ")
+	if item.Title == "" {
+		return Item{}, fmt.Errorf("task has no title")
+	}
+	if len(contexts) > 0 {
+		item.Metadata[export.MetadataTodoTxtContexts] = contexts
+	}
+	if len(projects) > 0 {
+		item.Metadata[export.MetadataTodoTxtProjects] = projects
+	}
+	return item, nil
+}
+
+// todoTxtTag splits a key:value tag; URLs and words with more than one
+// colon are not tags
+func todoTxtTag(word string) (string, string, bool) {
+	key, value, ok := strings.Cut(word, ":")
+	if !ok || key == "" || value == "" || strings.Contains(value, ":") || strings.HasPrefix(value, "//") {
+		return "", "", false
+	}
+	return key, value, true
+}
@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+const (
+	// TrelloProvider is the external reference provider name for Trello cards
+	TrelloProvider = "trello"
+
+	// MetadataTrelloLabels is the task metadata key holding Trello labels
+	MetadataTrelloLabels = "trello.labels"
+	// MetadataTrelloDue is the task metadata key holding the Trello due date
+	MetadataTrelloDue = "trello.due"
+	// MetadataTrelloList is the task metadata key holding the card's list
+	MetadataTrelloList = "trello.list"
+)
+
+// TrelloLists says what the lists of a board become
+type TrelloLists string
+
+const (
+	// TrelloListsContext turns each list into a context
+	TrelloListsContext TrelloLists = "context"
+	// TrelloListsStatus treats lists as workflow columns: cards in a done
+	// list are completed and the board name becomes the context
+	TrelloListsStatus TrelloLists = "status"
+)
+
+// doneLists are list names (lowercased) whose cards count as completed
+var doneLists = map[string]bool{"done": true, "complete": true, "completed": true, "finished": true}
+
+// trelloBoard is the part of a Trello board JSON export that is imported
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Desc   string  `json:"desc"`
+		IDList string  `json:"idList"`
+		Closed bool    `json:"closed"`
+		Pos    float64 `json:"pos"`
+		Due    string  `json:"due"`
+		Labels []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string  `json:"idCard"`
+		Name       string  `json:"name"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// ParseTrelloJSON reads a Trello board exported as JSON (Menu > Print,
+// export, and share > Export as JSON). Open cards in open lists become
+// items, in list order. Checklists are appended to the description as
+// "- [ ]" / "- [x]" lines; labels, due dates, and the list name are kept as
+// metadata. A label named high, medium, or low (optionally prefixed with
+// "priority") sets the priority. project, if set, replaces the board name.
+func ParseTrelloJSON(r io.Reader, lists TrelloLists, project string) ([]Item, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, fmt.Errorf("not a Trello board export: %w", err)
+	}
+	if board.Lists == nil || board.Cards == nil {
+		return nil, fmt.Errorf("not a Trello board export: missing lists or cards")
+	}
+	if project == "" {
+		project = board.Name
+	}
+
+	listNames := make(map[string]string, len(board.Lists))
+	listOrder := make(map[string]int, len(board.Lists))
+	for i, list := range board.Lists {
+		if !list.Closed {
+			listNames[list.ID] = list.Name
+			listOrder[list.ID] = i
+		}
+	}
+
+	checklists := make(map[string][]string)
+	sort.SliceStable(board.Checklists, func(i, j int) bool { return board.Checklists[i].Pos < board.Checklists[j].Pos })
+	for _, checklist := range board.Checklists {
+		items := checklist.CheckItems
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+
+		lines := []string{checklist.Name + ":"}
+		for _, item := range items {
+			box := "[ ]"
+			if item.State == "complete" {
+				box = "[x]"
+			}
+			lines = append(lines, "- "+box+" "+item.Name)
+		}
+		checklists[checklist.IDCard] = append(checklists[checklist.IDCard], strings.Join(lines, "\n"))
+	}
+
+	cards := board.Cards
+	sort.SliceStable(cards, func(i, j int) bool {
+		if listOrder[cards[i].IDList] != listOrder[cards[j].IDList] {
+			return listOrder[cards[i].IDList] < listOrder[cards[j].IDList]
+		}
+		return cards[i].Pos < cards[j].Pos
+	})
+
+	var items []Item
+	for _, card := range cards {
+		list, open := listNames[card.IDList]
+		if card.Closed || !open {
+			continue
+		}
+
+		item := Item{
+			RemoteID:    card.ID,
+			Title:       strings.TrimSpace(card.Name),
+			Description: strings.TrimSpace(strings.Join(append([]string{card.Desc}, checklists[card.ID]...), "\n\n")),
+			Metadata:    map[string]interface{}{MetadataTrelloList: list},
+		}
+
+		switch lists {
+		case TrelloListsStatus:
+			item.Context = contextName(project)
+			item.Completed = doneLists[strings.ToLower(strings.TrimSpace(list))]
+		default:
+			item.Context = contextName(list)
+		}
+
+		var labels []string
+		for _, label := range card.Labels {
+			name := label.Name
+			if name == "" {
+				name = label.Color
+			}
+			if p := labelPriority(name); p != "" {
+				item.Priority = p
+			}
+			labels = append(labels, name)
+		}
+		if len(labels) > 0 {
+			item.Metadata[MetadataTrelloLabels] = labels
+		}
+		if card.Due != "" {
+			item.Metadata[MetadataTrelloDue] = card.Due
+		}
+
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// labelPriority maps a label such as "high" or "priority: low" to a task
+// priority, or returns "" for other labels
+func labelPriority(label string) domain.TaskPriority {
+	name := strings.ToLower(strings.TrimSpace(label))
+	name = strings.TrimLeft(strings.TrimPrefix(name, "priority"), ":/- ")
+	switch p := domain.TaskPriority(name); p {
+	case domain.TaskPriorityLow, domain.TaskPriorityMedium, domain.TaskPriorityHigh:
+		return p
+	}
+	return ""
+}
@@ -0,0 +1,263 @@
+// Package git links tasks to the branches and commits of a git repository.
+// It runs the git command rather than reading the repository itself, so it
+// sees exactly what the user's git does (worktrees, hooks path, config).
+// Links are stored in task metadata: the branch created for a task and the
+// commits whose message carries a "task: <id>" trailer.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// MetadataBranch is the task metadata key holding the linked branch name
+	MetadataBranch = "git.branch"
+	// MetadataCommits is the task metadata key holding the linked commits
+	MetadataCommits = "git.commits"
+	// TrailerKey is the commit message trailer naming the tasks a commit belongs to
+	TrailerKey = "task"
+	// BranchPrefix starts the name of every branch created for a task
+	BranchPrefix = "task/"
+
+	// hookMarker identifies hooks installed by InstallHook
+	hookMarker = "# Installed by task-manager"
+)
+
+var (
+	// ErrNotRepository is returned when the directory is not inside a git repository
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrHookExists is returned when a hook not installed by task-manager is in the way
+	ErrHookExists = errors.New("a post-commit hook already exists")
+)
+
+// Commit is a commit read from the repository log
+type Commit struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Subject string
+	TaskIDs []string // values of the commit's task trailers
+}
+
+// CommitLink is the record of a commit kept in task metadata
+type CommitLink struct {
+	Hash    string    `json:"hash"`
+	Subject string    `json:"subject"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+}
+
+// Link returns the metadata record of the commit
+func (c Commit) Link() CommitLink {
+	return CommitLink{Hash: c.Hash, Subject: c.Subject, Author: c.Author, Date: c.Date}
+}
+
+// Repository is a git working tree
+type Repository struct {
+	dir string
+}
+
+// Open returns the repository containing dir
+func Open(ctx context.Context, dir string) (*Repository, error) {
+	out, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("git is not installed: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, dir)
+	}
+	return &Repository{dir: strings.TrimSpace(out)}, nil
+}
+
+// Dir returns the top-level directory of the working tree
+func (r *Repository) Dir() string {
+	return r.dir
+}
+
+// CurrentBranch returns the checked out branch, or an empty string when HEAD is detached
+func (r *Repository) CurrentBranch(ctx context.Context) (string, error) {
+	out, err := run(ctx, r.dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// BranchExists reports whether a local branch called name exists
+func (r *Repository) BranchExists(ctx context.Context, name string) (bool, error) {
+	_, err := run(ctx, r.dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateBranch creates a branch called name at HEAD
+func (r *Repository) CreateBranch(ctx context.Context, name string) error {
+	_, err := run(ctx, r.dir, "branch", name)
+	return err
+}
+
+// Checkout switches the working tree to the branch called name
+func (r *Repository) Checkout(ctx context.Context, name string) error {
+	_, err := run(ctx, r.dir, "checkout", "--quiet", name)
+	return err
+}
+
+// Commits returns up to max commits reachable from rev, newest first.
+// A max of zero returns every commit.
+func (r *Repository) Commits(ctx context.Context, rev string, max int) ([]Commit, error) {
+	// Fields are separated by US and records by RS, which cannot appear in
+	// the hash, author, or date and are vanishingly rare in messages
+	args := []string{"log", "--format=%H%x1f%an%x1f%aI%x1f%B%x1e"}
+	if max > 0 {
+		args = append(args, "-n", strconv.Itoa(max))
+	}
+	args = append(args, rev, "--")
+
+	out, err := run(ctx, r.dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse commit date %q: %w", fields[2], err)
+		}
+
+		message := strings.TrimSpace(fields[3])
+		subject, _, _ := strings.Cut(message, "\n")
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Date:    date,
+			Subject: subject,
+			TaskIDs: ParseTrailers(message),
+		})
+	}
+	return commits, nil
+}
+
+// InstallHook writes a post-commit hook that runs command after every
+// commit. A hook installed earlier by task-manager is replaced; any other
+// hook is only replaced when force is set.
+func (r *Repository) InstallHook(ctx context.Context, command string, force bool) (string, error) {
+	out, err := run(ctx, r.dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	hooksDir := strings.TrimSpace(out)
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(r.dir, hooksDir)
+	}
+	path := filepath.Join(hooksDir, "post-commit")
+
+	if existing, err := os.ReadFile(path); err == nil && !force && !bytes.Contains(existing, []byte(hookMarker)) {
+		return path, fmt.Errorf("%w: %s (use --force to replace it)", ErrHookExists, path)
+	}
+
+	script := fmt.Sprintf("#!/bin/sh\n%s\n%s >/dev/null 2>&1 || true\n", hookMarker, command)
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return path, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return path, fmt.Errorf("failed to write hook: %w", err)
+	}
+	return path, nil
+}
+
+// ParseTrailers returns the task IDs named by "task:" trailers in the last
+// paragraph of a commit message. Several IDs may share one trailer,
+// separated by commas or spaces.
+func ParseTrailers(message string) []string {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	paragraphs := strings.Split(message, "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var ids []string
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), TrailerKey) {
+			continue
+		}
+		for _, id := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			ids = append(ids, strings.ToLower(id))
+		}
+	}
+	return ids
+}
+
+// BranchName returns the branch name for a task, e.g.
+// "task/1a2b3c4d-fix-login-redirect"
+func BranchName(id, title string) string {
+	short := id
+	if len(short) > 8 {
+		short = short[:8]
+	}
+
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if slug.Len() >= 40 {
+			break
+		}
+	}
+
+	if slug.Len() == 0 {
+		return BranchPrefix + short
+	}
+	return BranchPrefix + short + "-" + strings.TrimRight(slug.String(), "-")
+}
+
+// run runs git with args in dir and returns its standard output
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s: %w", args[0], msg, err)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
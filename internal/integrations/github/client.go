@@ -0,0 +1,199 @@
+// Package github syncs tasks with the issues of a GitHub repository: open
+// issues are imported as tasks and completing a task closes its issue. The
+// mapping between issues and tasks is kept by the shared linking module, so
+// syncing again only touches issues that changed.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the GitHub REST API endpoint
+const DefaultBaseURL = "https://api.github.com"
+
+// repoPattern matches an "owner/name" repository reference
+var repoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// nextLinkPattern extracts the next page URL from a Link header
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Issue is a GitHub issue as returned by the REST API
+type Issue struct {
+	Number      int        `json:"number"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	State       string     `json:"state"`
+	HTMLURL     string     `json:"html_url"`
+	Labels      []Label    `json:"labels"`
+	Milestone   *Milestone `json:"milestone"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	PullRequest *struct{}  `json:"pull_request,omitempty"`
+}
+
+// Label is an issue label
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Milestone is an issue milestone
+type Milestone struct {
+	Title string `json:"title"`
+}
+
+// APIError is returned when GitHub answers with an error status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub API error %d: %s", e.StatusCode, e.Message)
+}
+
+// Client is a minimal GitHub REST API client
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithBaseURL points the client at another API endpoint, e.g. GitHub Enterprise
+func WithBaseURL(url string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// NewClient creates a client authenticating with token
+func NewClient(token string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL: DefaultBaseURL,
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ValidateRepo checks that repo has the form "owner/name"
+func ValidateRepo(repo string) error {
+	if !repoPattern.MatchString(repo) {
+		return fmt.Errorf("invalid repository %q (expected owner/name)", repo)
+	}
+	return nil
+}
+
+// OpenIssues returns every open issue of repo, excluding pull requests
+func (c *Client) OpenIssues(ctx context.Context, repo string) ([]Issue, error) {
+	var issues []Issue
+	url := fmt.Sprintf("%s/repos/%s/issues?state=open&per_page=100", c.baseURL, repo)
+
+	for url != "" {
+		var page []Issue
+		header, err := c.do(ctx, http.MethodGet, url, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, issue := range page {
+			if issue.PullRequest == nil {
+				issues = append(issues, issue)
+			}
+		}
+
+		url = ""
+		if m := nextLinkPattern.FindStringSubmatch(header.Get("Link")); m != nil {
+			url = m[1]
+		}
+	}
+	return issues, nil
+}
+
+// Issue returns a single issue of repo
+func (c *Client) Issue(ctx context.Context, repo string, number int) (*Issue, error) {
+	var issue Issue
+	url := fmt.Sprintf("%s/repos/%s/issues/%d", c.baseURL, repo, number)
+	if _, err := c.do(ctx, http.MethodGet, url, nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// CloseIssue closes an issue of repo as completed
+func (c *Client) CloseIssue(ctx context.Context, repo string, number int) (*Issue, error) {
+	var issue Issue
+	url := fmt.Sprintf("%s/repos/%s/issues/%d", c.baseURL, repo, number)
+	body := map[string]string{"state": "closed", "state_reason": "completed"}
+	if _, err := c.do(ctx, http.MethodPatch, url, body, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// do sends a request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, url string, body, out interface{}) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: apiErr.Message}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	return resp.Header, nil
+}
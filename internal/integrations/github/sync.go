@@ -0,0 +1,312 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/integrations/linking"
+)
+
+const (
+	// Provider is the external reference provider name for GitHub issues
+	Provider = "github"
+	// MetadataIssue is the task metadata key holding the linked issue
+	MetadataIssue = "github.issue"
+)
+
+// IssueLink is the record of an issue kept in task metadata
+type IssueLink struct {
+	Repo      string   `json:"repo"`
+	Number    int      `json:"number"`
+	URL       string   `json:"url"`
+	Labels    []string `json:"labels,omitempty"`
+	Milestone string   `json:"milestone,omitempty"`
+}
+
+// Tasks is the part of the task service used by the syncer
+type Tasks interface {
+	CreateTask(ctx context.Context, title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) (*domain.Task, error)
+	GetTask(ctx context.Context, id string) (*domain.Task, error)
+	UpdateTask(ctx context.Context, id, title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) (*domain.Task, error)
+	CompleteTask(ctx context.Context, id string) (*domain.Task, error)
+	SetTaskMetadata(ctx context.Context, id, key string, value interface{}) (*domain.Task, error)
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Item is an issue touched by a sync
+type Item struct {
+	Number int
+	Title  string
+	TaskID string
+}
+
+// Result lists what a sync did, or would do in a dry run
+type Result struct {
+	Imported  []Item // open issues created as tasks
+	Updated   []Item // tasks refreshed from issues edited on GitHub
+	Closed    []Item // issues closed because their task was completed
+	Completed []Item // tasks completed because their issue was closed
+}
+
+// Empty reports whether the sync changed nothing
+func (r *Result) Empty() bool {
+	return len(r.Imported)+len(r.Updated)+len(r.Closed)+len(r.Completed) == 0
+}
+
+// Syncer syncs one repository's issues with local tasks
+type Syncer struct {
+	client *Client
+	tasks  Tasks
+	linker *linking.Linker
+	logger *slog.Logger
+}
+
+// NewSyncer creates a new syncer
+func NewSyncer(client *Client, tasks Tasks, linker *linking.Linker, logger *slog.Logger) *Syncer {
+	return &Syncer{
+		client: client,
+		tasks:  tasks,
+		linker: linker,
+		logger: logger,
+	}
+}
+
+// Sync imports new open issues of repo, refreshes tasks whose issue changed,
+// closes issues whose task was completed, and completes tasks whose issue was
+// closed on GitHub. With dryRun nothing is written locally or on GitHub.
+func (s *Syncer) Sync(ctx context.Context, repo string, dryRun bool) (*Result, error) {
+	if err := ValidateRepo(repo); err != nil {
+		return nil, err
+	}
+
+	issues, err := s.client.OpenIssues(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+
+	result := &Result{}
+	open := make(map[string]bool, len(issues))
+
+	for i := range issues {
+		issue := &issues[i]
+		remoteID := RemoteID(repo, issue.Number)
+		open[remoteID] = true
+
+		change, ref, err := s.linker.Classify(ctx, Provider, remoteID, version(issue))
+		if err != nil {
+			return result, err
+		}
+
+		if change == linking.ChangeNew {
+			item := Item{Number: issue.Number, Title: issue.Title}
+			if !dryRun {
+				task, err := s.importIssue(ctx, repo, issue)
+				if err != nil {
+					return result, err
+				}
+				item.TaskID = task.ID
+			}
+			result.Imported = append(result.Imported, item)
+			continue
+		}
+
+		task, err := s.tasks.GetTask(ctx, ref.TaskID)
+		if err != nil {
+			return result, fmt.Errorf("failed to get task for issue #%d: %w", issue.Number, err)
+		}
+		item := Item{Number: issue.Number, Title: issue.Title, TaskID: task.ID}
+
+		// A completed task closes its issue, whatever else changed
+		if task.Status == domain.TaskStatusCompleted {
+			if !dryRun {
+				closed, err := s.client.CloseIssue(ctx, repo, issue.Number)
+				if err != nil {
+					return result, fmt.Errorf("failed to close issue #%d: %w", issue.Number, err)
+				}
+				if _, err := s.linker.Link(ctx, Provider, remoteID, task.ID, version(closed)); err != nil {
+					return result, err
+				}
+			}
+			result.Closed = append(result.Closed, item)
+			continue
+		}
+
+		if change == linking.ChangeModified {
+			if !dryRun {
+				if err := s.updateTask(ctx, repo, task.ID, issue); err != nil {
+					return result, err
+				}
+			}
+			result.Updated = append(result.Updated, item)
+		}
+	}
+
+	// Linked issues that are no longer open were closed (or moved) on GitHub
+	refs, err := s.linker.Refs(ctx, Provider)
+	if err != nil {
+		return result, err
+	}
+	for _, ref := range refs {
+		refRepo, number, ok := ParseRemoteID(ref.RemoteID)
+		if !ok || refRepo != repo || open[ref.RemoteID] {
+			continue
+		}
+
+		task, err := s.tasks.GetTask(ctx, ref.TaskID)
+		if err != nil {
+			return result, fmt.Errorf("failed to get task for issue #%d: %w", number, err)
+		}
+		if task.Status == domain.TaskStatusCompleted {
+			continue
+		}
+
+		issue, err := s.client.Issue(ctx, repo, number)
+		if err != nil {
+			return result, fmt.Errorf("failed to get issue #%d: %w", number, err)
+		}
+		if issue.State != "closed" {
+			continue
+		}
+
+		if !dryRun {
+			err := s.tasks.WithTx(ctx, func(ctx context.Context) error {
+				if _, err := s.tasks.CompleteTask(ctx, task.ID); err != nil {
+					return err
+				}
+				_, err := s.linker.Link(ctx, Provider, ref.RemoteID, task.ID, version(issue))
+				return err
+			})
+			if err != nil {
+				return result, err
+			}
+		}
+		result.Completed = append(result.Completed, Item{Number: number, Title: issue.Title, TaskID: task.ID})
+	}
+
+	s.logger.Info("GitHub sync finished", "repo", repo, "imported", len(result.Imported),
+		"updated", len(result.Updated), "closed", len(result.Closed), "completed", len(result.Completed))
+	return result, nil
+}
+
+// importIssue creates a task for issue and links them, in one transaction
+// when the task service supports them. The task ID is derived from the issue
+// as well, so an import interrupted before linking does not create the task
+// twice.
+func (s *Syncer) importIssue(ctx context.Context, repo string, issue *Issue) (*domain.Task, error) {
+	priority := issuePriority(issue)
+	if priority == "" {
+		priority = domain.TaskPriorityMedium
+	}
+
+	opts := []domain.TaskOption{domain.WithIdempotencyKey(Provider + ":" + RemoteID(repo, issue.Number))}
+	if name := milestoneContext(issue); name != "" {
+		opts = append(opts, domain.WithTaskContext(name))
+	}
+
+	var task *domain.Task
+	err := s.tasks.WithTx(ctx, func(ctx context.Context) error {
+		created, err := s.tasks.CreateTask(ctx, issue.Title, issue.Body, priority, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to import issue #%d: %w", issue.Number, err)
+		}
+		if _, err := s.tasks.SetTaskMetadata(ctx, created.ID, MetadataIssue, issueLink(repo, issue)); err != nil {
+			return err
+		}
+		if _, err := s.linker.Link(ctx, Provider, RemoteID(repo, issue.Number), created.ID, version(issue)); err != nil {
+			return err
+		}
+		task = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// updateTask refreshes the task linked to issue with the issue's fields, in
+// one transaction with the link
+func (s *Syncer) updateTask(ctx context.Context, repo, taskID string, issue *Issue) error {
+	var opts []domain.TaskOption
+	if name := milestoneContext(issue); name != "" {
+		opts = append(opts, domain.WithTaskContext(name))
+	}
+
+	return s.tasks.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := s.tasks.UpdateTask(ctx, taskID, issue.Title, issue.Body, issuePriority(issue), opts...); err != nil {
+			return fmt.Errorf("failed to update task from issue #%d: %w", issue.Number, err)
+		}
+		if _, err := s.tasks.SetTaskMetadata(ctx, taskID, MetadataIssue, issueLink(repo, issue)); err != nil {
+			return err
+		}
+		_, err := s.linker.Link(ctx, Provider, RemoteID(repo, issue.Number), taskID, version(issue))
+		return err
+	})
+}
+
+// RemoteID returns the external reference ID of an issue, e.g. "owner/name#12"
+func RemoteID(repo string, number int) string {
+	return repo + "#" + strconv.Itoa(number)
+}
+
+// ParseRemoteID splits an external reference ID into repository and issue number
+func ParseRemoteID(remoteID string) (string, int, bool) {
+	repo, num, ok := strings.Cut(remoteID, "#")
+	if !ok {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(num)
+	if err != nil {
+		return "", 0, false
+	}
+	return repo, number, true
+}
+
+// version returns the linking version of an issue
+func version(issue *Issue) linking.RemoteVersion {
+	updated := issue.UpdatedAt
+	return linking.RemoteVersion{UpdatedAt: &updated}
+}
+
+// issueLink returns the metadata record of an issue
+func issueLink(repo string, issue *Issue) IssueLink {
+	link := IssueLink{Repo: repo, Number: issue.Number, URL: issue.HTMLURL}
+	for _, label := range issue.Labels {
+		link.Labels = append(link.Labels, label.Name)
+	}
+	if issue.Milestone != nil {
+		link.Milestone = issue.Milestone.Title
+	}
+	return link
+}
+
+// issuePriority maps a priority label such as "high", "priority: high", or
+// "priority/low" to a task priority, or returns "" if the issue has none
+func issuePriority(issue *Issue) domain.TaskPriority {
+	for _, label := range issue.Labels {
+		name := strings.ToLower(strings.TrimSpace(label.Name))
+		name = strings.TrimLeft(strings.TrimPrefix(name, "priority"), ":/- ")
+		switch p := domain.TaskPriority(name); p {
+		case domain.TaskPriorityLow, domain.TaskPriorityMedium, domain.TaskPriorityHigh:
+			return p
+		}
+	}
+	return ""
+}
+
+// milestoneContext turns the issue milestone into a task context, e.g.
+// "v1.2 Release" becomes "v1.2-release"
+func milestoneContext(issue *Issue) string {
+	if issue.Milestone == nil {
+		return ""
+	}
+	fields := strings.FieldsFunc(domain.NormalizeContext(issue.Milestone.Title), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '@'
+	})
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,498 @@
+// Package google is the Google Tasks provider for two-way task sync. It
+// authorizes with OAuth 2.0 as an installed application: the user downloads
+// an OAuth client (type "Desktop app") from the Google Cloud console, and
+// "task sync google login" stores it in the config directory, opens the
+// consent page, and caches the resulting token in the data directory.
+package google
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/integrations/tasksync"
+	"github.com/edson-mazvila/task-manager/internal/xdg"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// Name is the provider name used in external references and metadata keys
+	Name = "google"
+	// TasksAPIURL is the Google Tasks API endpoint
+	TasksAPIURL = "https://tasks.googleapis.com/tasks/v1"
+	// Scope grants read and write access to the user's tasks
+	Scope = "https://www.googleapis.com/auth/tasks"
+
+	// DefaultList is the user's default task list
+	DefaultList = "@default"
+)
+
+// endpoint is Google's OAuth 2.0 endpoint
+var endpoint = oauth2.Endpoint{
+	AuthURL:  "https://accounts.google.com/o/oauth2/auth",
+	TokenURL: "https://oauth2.googleapis.com/token",
+}
+
+// CredentialsPath returns where the OAuth client credentials are kept
+func CredentialsPath() (string, error) {
+	dir, err := xdg.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "google-credentials.json"), nil
+}
+
+// TokenPath returns where the OAuth token is cached
+func TokenPath() (string, error) {
+	dir, err := xdg.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "google-token.json"), nil
+}
+
+// ParseCredentials reads an OAuth client JSON file as downloaded from the
+// Google Cloud console
+func ParseCredentials(data []byte) (*oauth2.Config, error) {
+	var file struct {
+		Installed *struct {
+			ClientID     string `json:"client_id"`
+			ClientSecret string `json:"client_secret"`
+		} `json:"installed"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid credentials file: %w", err)
+	}
+	if file.Installed == nil || file.Installed.ClientID == "" {
+		return nil, errors.New("invalid credentials file: expected an OAuth client of type \"Desktop app\"")
+	}
+
+	return &oauth2.Config{
+		ClientID:     file.Installed.ClientID,
+		ClientSecret: file.Installed.ClientSecret,
+		Endpoint:     endpoint,
+		Scopes:       []string{Scope},
+	}, nil
+}
+
+// LoadCredentials reads the OAuth client credentials at path
+func LoadCredentials(path string) (*oauth2.Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no Google credentials at %s (run task sync google login --credentials <file>)", tasksync.ErrNotAuthorized, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	return ParseCredentials(data)
+}
+
+// LoadToken reads the cached OAuth token at path
+func LoadToken(path string) (*oauth2.Token, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: not logged in to Google (run task sync google login)", tasksync.ErrNotAuthorized)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("invalid token file %s: %w", path, err)
+	}
+	return &token, nil
+}
+
+// SaveToken writes the OAuth token to path, readable only by the user
+func SaveToken(path string, token *oauth2.Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	return nil
+}
+
+// Login runs the OAuth consent flow: it listens on a loopback port, passes
+// the consent page URL to open, and exchanges the code Google redirects back
+// with for a token.
+func Login(ctx context.Context, cfg *oauth2.Config, open func(url string)) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the OAuth redirect: %w", err)
+	}
+	defer listener.Close()
+
+	login := *cfg
+	login.RedirectURL = fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+	state := hex.EncodeToString(stateBytes)
+	verifier := oauth2.GenerateVerifier()
+
+	type callback struct {
+		code string
+		err  error
+	}
+	done := make(chan callback, 1)
+	var once sync.Once
+
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+
+			query := r.URL.Query()
+			result := callback{code: query.Get("code")}
+			switch {
+			case query.Get("state") != state:
+				result.err = errors.New("OAuth state mismatch")
+			case query.Get("error") != "":
+				result.err = fmt.Errorf("authorization denied: %s", query.Get("error"))
+			case result.code == "":
+				result.err = errors.New("no authorization code in redirect")
+			}
+
+			if result.err != nil {
+				http.Error(w, result.err.Error(), http.StatusBadRequest)
+			} else {
+				fmt.Fprintln(w, "Task Manager is authorized. You can close this window.")
+			}
+			once.Do(func() { done <- result })
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	open(login.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce, oauth2.S256ChallengeOption(verifier)))
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-done:
+		if result.err != nil {
+			return nil, result.err
+		}
+		token, err := login.Exchange(ctx, result.code, oauth2.VerifierOption(verifier))
+		if err != nil {
+			return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+		}
+		return token, nil
+	}
+}
+
+// savingTokenSource writes refreshed tokens back to the token cache
+type savingTokenSource struct {
+	source oauth2.TokenSource
+	path   string
+	mu     sync.Mutex
+	last   string
+}
+
+// Token implements oauth2.TokenSource
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.AccessToken != s.last {
+		s.last = token.AccessToken
+		if err := SaveToken(s.path, token); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// Provider syncs with one Google Tasks list
+type Provider struct {
+	http    *http.Client
+	baseURL string
+	list    string
+	listID  string
+}
+
+// ProviderOption configures a Provider
+type ProviderOption func(*Provider)
+
+// WithBaseURL points the provider at another API endpoint
+func WithBaseURL(url string) ProviderOption {
+	return func(p *Provider) {
+		p.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the (already authorized) HTTP client used for requests
+func WithHTTPClient(hc *http.Client) ProviderOption {
+	return func(p *Provider) {
+		p.http = hc
+	}
+}
+
+// NewProvider creates a provider for the task list titled list (the
+// default list when empty). Requests are authorized with token, which is
+// refreshed as needed and saved back to tokenPath.
+func NewProvider(ctx context.Context, cfg *oauth2.Config, token *oauth2.Token, tokenPath, list string, opts ...ProviderOption) *Provider {
+	source := &savingTokenSource{
+		source: cfg.TokenSource(ctx, token),
+		path:   tokenPath,
+		last:   token.AccessToken,
+	}
+
+	p := &Provider{
+		http:    oauth2.NewClient(ctx, oauth2.ReuseTokenSource(token, source)),
+		baseURL: TasksAPIURL,
+		list:    list,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Name implements tasksync.Provider
+func (p *Provider) Name() string {
+	return Name
+}
+
+// apiTask is a task as returned by the Google Tasks API
+type apiTask struct {
+	ID      string  `json:"id,omitempty"`
+	Title   string  `json:"title"`
+	Notes   string  `json:"notes"`
+	Status  string  `json:"status"`
+	Due     *string `json:"due"`
+	Updated string  `json:"updated,omitempty"`
+	Deleted bool    `json:"deleted,omitempty"`
+	Hidden  bool    `json:"hidden,omitempty"`
+	Parent  string  `json:"parent,omitempty"`
+}
+
+// List implements tasksync.Provider
+func (p *Provider) List(ctx context.Context) ([]tasksync.RemoteTask, error) {
+	listID, err := p.listIdentifier(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []tasksync.RemoteTask
+	pageToken := ""
+	for {
+		query := url.Values{
+			"showCompleted": {"true"},
+			"showHidden":    {"true"},
+			"maxResults":    {"100"},
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		var page struct {
+			Items         []apiTask `json:"items"`
+			NextPageToken string    `json:"nextPageToken"`
+		}
+		if err := p.do(ctx, http.MethodGet, "/lists/"+url.PathEscape(listID)+"/tasks?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+
+		for _, t := range page.Items {
+			if t.Deleted {
+				continue
+			}
+			rt, err := fromAPI(t)
+			if err != nil {
+				return nil, err
+			}
+			tasks = append(tasks, rt)
+		}
+
+		if page.NextPageToken == "" {
+			return tasks, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// Create implements tasksync.Provider
+func (p *Provider) Create(ctx context.Context, task tasksync.RemoteTask) (tasksync.RemoteTask, error) {
+	listID, err := p.listIdentifier(ctx)
+	if err != nil {
+		return tasksync.RemoteTask{}, err
+	}
+
+	var created apiTask
+	if err := p.do(ctx, http.MethodPost, "/lists/"+url.PathEscape(listID)+"/tasks", toAPI(task), &created); err != nil {
+		return tasksync.RemoteTask{}, err
+	}
+	return fromAPI(created)
+}
+
+// Update implements tasksync.Provider
+func (p *Provider) Update(ctx context.Context, task tasksync.RemoteTask) (tasksync.RemoteTask, error) {
+	listID, err := p.listIdentifier(ctx)
+	if err != nil {
+		return tasksync.RemoteTask{}, err
+	}
+
+	fields := toAPI(task)
+	body := map[string]interface{}{
+		"title":  fields.Title,
+		"notes":  fields.Notes,
+		"status": fields.Status,
+		"due":    fields.Due,
+	}
+	if !task.Completed {
+		// Reopening a task requires clearing its completion time
+		body["completed"] = nil
+	}
+
+	var updated apiTask
+	path := "/lists/" + url.PathEscape(listID) + "/tasks/" + url.PathEscape(task.ID)
+	if err := p.do(ctx, http.MethodPatch, path, body, &updated); err != nil {
+		return tasksync.RemoteTask{}, err
+	}
+	return fromAPI(updated)
+}
+
+// listIdentifier returns the ID of the task list to sync with
+func (p *Provider) listIdentifier(ctx context.Context) (string, error) {
+	if p.listID != "" {
+		return p.listID, nil
+	}
+	if p.list == "" || p.list == DefaultList {
+		p.listID = DefaultList
+		return p.listID, nil
+	}
+
+	var lists struct {
+		Items []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/users/@me/lists?maxResults=100", nil, &lists); err != nil {
+		return "", err
+	}
+	for _, l := range lists.Items {
+		if strings.EqualFold(l.Title, p.list) {
+			p.listID = l.ID
+			return p.listID, nil
+		}
+	}
+	return "", fmt.Errorf("no Google Tasks list named %q", p.list)
+}
+
+// do sends a request and decodes the JSON response into out
+func (p *Provider) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return fmt.Errorf("%w: Google rejected the token, run task sync google login again: %v", tasksync.ErrNotAuthorized, err)
+		}
+		return fmt.Errorf("Google Tasks request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w: Google rejected the token, run task sync google login again", tasksync.ErrNotAuthorized)
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("Google Tasks API error %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Google Tasks response: %w", err)
+	}
+	return nil
+}
+
+// fromAPI converts an API task to a remote task
+func fromAPI(t apiTask) (tasksync.RemoteTask, error) {
+	updated, err := time.Parse(time.RFC3339, t.Updated)
+	if err != nil {
+		return tasksync.RemoteTask{}, fmt.Errorf("invalid update time %q on task %s: %w", t.Updated, t.ID, err)
+	}
+
+	rt := tasksync.RemoteTask{
+		ID:        t.ID,
+		Title:     t.Title,
+		Notes:     t.Notes,
+		Completed: t.Status == "completed",
+		UpdatedAt: updated,
+	}
+	// Google Tasks keeps only the date of a due time
+	if t.Due != nil && len(*t.Due) >= len("2006-01-02") {
+		rt.Due = (*t.Due)[:len("2006-01-02")]
+	}
+	return rt, nil
+}
+
+// toAPI converts a remote task to an API task
+func toAPI(rt tasksync.RemoteTask) apiTask {
+	t := apiTask{
+		ID:     rt.ID,
+		Title:  rt.Title,
+		Notes:  rt.Notes,
+		Status: "needsAction",
+	}
+	if rt.Completed {
+		t.Status = "completed"
+	}
+	if rt.Due != "" {
+		due := rt.Due + "T00:00:00.000Z"
+		t.Due = &due
+	}
+	return t
+}
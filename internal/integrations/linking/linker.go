@@ -0,0 +1,155 @@
+// Package linking provides the shared bookkeeping used by every sync
+// integration to map remote items to local tasks. Providers ask the Linker
+// whether a remote item is new, changed, or already up to date, and record
+// the mapping after importing or pushing it, which prevents duplicate imports
+// and enables incremental sync.
+package linking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// Change describes how a remote item relates to what was last synced
+type Change int
+
+const (
+	// ChangeNew means the remote item has never been linked to a task
+	ChangeNew Change = iota
+	// ChangeModified means the remote item changed since the last sync
+	ChangeModified
+	// ChangeUnchanged means the remote item matches the last synced version
+	ChangeUnchanged
+)
+
+// String returns a human-readable name for the change
+func (c Change) String() string {
+	switch c {
+	case ChangeNew:
+		return "new"
+	case ChangeModified:
+		return "modified"
+	case ChangeUnchanged:
+		return "unchanged"
+	default:
+		return "unknown"
+	}
+}
+
+// RemoteVersion identifies a specific version of a remote item.
+// Providers should set ETag when available and UpdatedAt otherwise.
+type RemoteVersion struct {
+	ETag      string
+	UpdatedAt *time.Time
+}
+
+// Linker manages external references for sync integrations
+type Linker struct {
+	refs   domain.ExternalRefRepository
+	logger *slog.Logger
+}
+
+// NewLinker creates a new linker
+func NewLinker(refs domain.ExternalRefRepository, logger *slog.Logger) *Linker {
+	return &Linker{
+		refs:   refs,
+		logger: logger,
+	}
+}
+
+// Classify reports whether the remote item is new, modified, or unchanged since
+// it was last linked. The existing reference is returned when one exists.
+func (l *Linker) Classify(ctx context.Context, provider, remoteID string, version RemoteVersion) (Change, *domain.ExternalRef, error) {
+	ref, err := l.refs.Get(ctx, provider, remoteID)
+	if err != nil {
+		if errors.Is(err, domain.ErrExternalRefNotFound) {
+			return ChangeNew, nil, nil
+		}
+		return ChangeNew, nil, err
+	}
+
+	if version.ETag != "" && version.ETag != ref.ETag {
+		return ChangeModified, ref, nil
+	}
+
+	if version.UpdatedAt != nil && (ref.RemoteUpdatedAt == nil || version.UpdatedAt.After(*ref.RemoteUpdatedAt)) {
+		return ChangeModified, ref, nil
+	}
+
+	return ChangeUnchanged, ref, nil
+}
+
+// TaskID returns the local task linked to a remote item
+func (l *Linker) TaskID(ctx context.Context, provider, remoteID string) (string, bool, error) {
+	ref, err := l.refs.Get(ctx, provider, remoteID)
+	if err != nil {
+		if errors.Is(err, domain.ErrExternalRefNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return ref.TaskID, true, nil
+}
+
+// RemoteID returns the remote item linked to a local task for the provider
+func (l *Linker) RemoteID(ctx context.Context, provider, taskID string) (string, bool, error) {
+	ref, err := l.refs.GetByTask(ctx, provider, taskID)
+	if err != nil {
+		if errors.Is(err, domain.ErrExternalRefNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return ref.RemoteID, true, nil
+}
+
+// Link records that the remote item at the given version is synced with taskID
+func (l *Linker) Link(ctx context.Context, provider, remoteID, taskID string, version RemoteVersion) (*domain.ExternalRef, error) {
+	if provider == "" || remoteID == "" {
+		return nil, fmt.Errorf("provider and remote ID are required")
+	}
+	if taskID == "" {
+		return nil, domain.ErrInvalidTaskID
+	}
+
+	now := time.Now()
+	ref := &domain.ExternalRef{
+		Provider:        provider,
+		RemoteID:        remoteID,
+		TaskID:          taskID,
+		ETag:            version.ETag,
+		RemoteUpdatedAt: version.UpdatedAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := l.refs.Upsert(ctx, ref); err != nil {
+		return nil, err
+	}
+
+	l.logger.Debug("Remote item linked", "provider", provider, "remote_id", remoteID, "task_id", taskID)
+	return ref, nil
+}
+
+// Unlink removes the mapping for a remote item. Missing mappings are ignored.
+func (l *Linker) Unlink(ctx context.Context, provider, remoteID string) error {
+	if err := l.refs.Delete(ctx, provider, remoteID); err != nil && !errors.Is(err, domain.ErrExternalRefNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Refs returns every reference recorded for a provider
+func (l *Linker) Refs(ctx context.Context, provider string) ([]*domain.ExternalRef, error) {
+	return l.refs.ListByProvider(ctx, provider)
+}
+
+// TaskRefs returns every reference linked to a task across providers
+func (l *Linker) TaskRefs(ctx context.Context, taskID string) ([]*domain.ExternalRef, error) {
+	return l.refs.ListByTask(ctx, taskID)
+}
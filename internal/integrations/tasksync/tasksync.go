@@ -0,0 +1,305 @@
+// Package tasksync keeps tasks in two-way sync with a remote task service
+// such as Google Tasks. A Provider lists, creates, and updates remote tasks;
+// the Syncer pairs them with local tasks through the shared linking module
+// and copies title, notes (the description), due date, and completion in
+// whichever direction changed since the last sync. When both sides changed,
+// the most recent edit wins.
+package tasksync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/integrations/linking"
+)
+
+// ErrNotAuthorized is returned by providers that need to be logged in first
+var ErrNotAuthorized = errors.New("not authorized")
+
+// RemoteTask is a task as seen by a provider
+type RemoteTask struct {
+	ID        string
+	Title     string
+	Notes     string
+	Due       string // YYYY-MM-DD, or empty
+	Completed bool
+	UpdatedAt time.Time
+}
+
+// Provider is a remote task service
+type Provider interface {
+	// Name identifies the provider in external references and metadata keys
+	Name() string
+	// List returns every remote task, including completed ones
+	List(ctx context.Context) ([]RemoteTask, error)
+	// Create adds a remote task and returns it as stored
+	Create(ctx context.Context, task RemoteTask) (RemoteTask, error)
+	// Update replaces the fields of the remote task with task.ID and returns it as stored
+	Update(ctx context.Context, task RemoteTask) (RemoteTask, error)
+}
+
+// Tasks is the part of the task service used by the syncer
+type Tasks interface {
+	CreateTask(ctx context.Context, title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) (*domain.Task, error)
+	GetTask(ctx context.Context, id string) (*domain.Task, error)
+	ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error)
+	UpdateTask(ctx context.Context, id, title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) (*domain.Task, error)
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Result lists the titles of the tasks a sync changed, or would change in a dry run
+type Result struct {
+	Pulled        []string // remote tasks created locally
+	UpdatedLocal  []string // local tasks updated from their remote task
+	Pushed        []string // local tasks created remotely
+	UpdatedRemote []string // remote tasks updated from their local task
+	Unlinked      []string // local tasks whose remote task was deleted, which are no longer synced
+}
+
+// Empty reports whether the sync changed nothing
+func (r *Result) Empty() bool {
+	return len(r.Pulled)+len(r.UpdatedLocal)+len(r.Pushed)+len(r.UpdatedRemote)+len(r.Unlinked) == 0
+}
+
+// Syncer syncs local tasks with one provider
+type Syncer struct {
+	provider Provider
+	tasks    Tasks
+	linker   *linking.Linker
+	logger   *slog.Logger
+}
+
+// NewSyncer creates a new syncer
+func NewSyncer(provider Provider, tasks Tasks, linker *linking.Linker, logger *slog.Logger) *Syncer {
+	return &Syncer{
+		provider: provider,
+		tasks:    tasks,
+		linker:   linker,
+		logger:   logger,
+	}
+}
+
+// DueKey returns the task metadata key holding the due date synced with the provider
+func (s *Syncer) DueKey() string {
+	return s.provider.Name() + ".due"
+}
+
+// unlinkedKey returns the task metadata key marking tasks whose remote task
+// was deleted, so they are not pushed again
+func (s *Syncer) unlinkedKey() string {
+	return s.provider.Name() + ".unlinked"
+}
+
+// Sync pairs remote tasks with local tasks and copies changes both ways.
+// Remote tasks not seen before are created locally in taskContext. Pending
+// local tasks in taskContext (every context when empty) that are not linked
+// yet are created remotely. With dryRun nothing is written on either side.
+func (s *Syncer) Sync(ctx context.Context, taskContext string, dryRun bool) (*Result, error) {
+	provider := s.provider.Name()
+
+	remote, err := s.provider.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s tasks: %w", provider, err)
+	}
+
+	result := &Result{}
+	seen := make(map[string]bool, len(remote))
+
+	for _, rt := range remote {
+		seen[rt.ID] = true
+		updated := rt.UpdatedAt
+		version := linking.RemoteVersion{UpdatedAt: &updated}
+
+		change, ref, err := s.linker.Classify(ctx, provider, rt.ID, version)
+		if err != nil {
+			return result, err
+		}
+
+		if change == linking.ChangeNew {
+			// Completed remote tasks from before the first sync are left behind
+			if rt.Completed {
+				continue
+			}
+			if !dryRun {
+				if err := s.pull(ctx, rt, taskContext, version); err != nil {
+					return result, err
+				}
+			}
+			result.Pulled = append(result.Pulled, rt.Title)
+			continue
+		}
+
+		task, err := s.tasks.GetTask(ctx, ref.TaskID)
+		if err != nil {
+			return result, fmt.Errorf("failed to get task linked to %s task %s: %w", provider, rt.ID, err)
+		}
+
+		remoteChanged := change == linking.ChangeModified
+		localChanged := task.UpdatedAt.After(ref.UpdatedAt)
+		if remoteChanged && localChanged {
+			// Both sides were edited: the most recent edit wins
+			remoteChanged = rt.UpdatedAt.After(task.UpdatedAt)
+			localChanged = !remoteChanged
+		}
+
+		switch {
+		case remoteChanged:
+			if !dryRun {
+				err := s.tasks.WithTx(ctx, func(ctx context.Context) error {
+					if _, err := s.tasks.UpdateTask(ctx, task.ID, "", "", "", s.applyRemote(rt)); err != nil {
+						return fmt.Errorf("failed to update task from %s: %w", provider, err)
+					}
+					_, err := s.linker.Link(ctx, provider, rt.ID, task.ID, version)
+					return err
+				})
+				if err != nil {
+					return result, err
+				}
+			}
+			result.UpdatedLocal = append(result.UpdatedLocal, rt.Title)
+		case localChanged:
+			if !dryRun {
+				pushed := s.toRemote(task)
+				pushed.ID = rt.ID
+				if err := s.push(ctx, task, pushed, s.provider.Update); err != nil {
+					return result, err
+				}
+			}
+			result.UpdatedRemote = append(result.UpdatedRemote, task.Title)
+		}
+	}
+
+	// Linked tasks missing from the remote list were deleted remotely
+	refs, err := s.linker.Refs(ctx, provider)
+	if err != nil {
+		return result, err
+	}
+	linked := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		linked[ref.TaskID] = true
+		if seen[ref.RemoteID] {
+			continue
+		}
+		task, err := s.tasks.GetTask(ctx, ref.TaskID)
+		if err != nil {
+			return result, err
+		}
+		if !dryRun {
+			markUnlinked := func(t *domain.Task) { _ = t.SetMetadata(s.unlinkedKey(), true) }
+			err := s.tasks.WithTx(ctx, func(ctx context.Context) error {
+				if _, err := s.tasks.UpdateTask(ctx, task.ID, "", "", "", markUnlinked); err != nil {
+					return err
+				}
+				return s.linker.Unlink(ctx, provider, ref.RemoteID)
+			})
+			if err != nil {
+				return result, err
+			}
+		}
+		result.Unlinked = append(result.Unlinked, task.Title)
+	}
+
+	// Pending local tasks that were never synced are created remotely
+	status := domain.TaskStatusPending
+	filter := domain.TaskFilter{Status: &status}
+	if taskContext != "" {
+		filter.Context = &taskContext
+	}
+	local, err := s.tasks.ListTasks(ctx, filter)
+	if err != nil {
+		return result, err
+	}
+	for _, task := range local {
+		if linked[task.ID] || task.Metadata.Has(s.unlinkedKey()) {
+			continue
+		}
+		if !dryRun {
+			if err := s.push(ctx, task, s.toRemote(task), s.provider.Create); err != nil {
+				return result, err
+			}
+		}
+		result.Pushed = append(result.Pushed, task.Title)
+	}
+
+	s.logger.Info("Task sync finished", "provider", provider, "pulled", len(result.Pulled),
+		"updated_local", len(result.UpdatedLocal), "pushed", len(result.Pushed),
+		"updated_remote", len(result.UpdatedRemote), "unlinked", len(result.Unlinked))
+	return result, nil
+}
+
+// pull creates a local task for a remote task and links them, in one
+// transaction when the task service supports them. The task ID is derived
+// from the remote ID as well, so a sync interrupted before linking does not
+// create the task twice when it is run again.
+func (s *Syncer) pull(ctx context.Context, rt RemoteTask, taskContext string, version linking.RemoteVersion) error {
+	opts := []domain.TaskOption{s.applyRemote(rt), domain.WithIdempotencyKey(s.provider.Name() + ":" + rt.ID)}
+	if taskContext != "" {
+		opts = append(opts, domain.WithTaskContext(taskContext))
+	}
+
+	return s.tasks.WithTx(ctx, func(ctx context.Context) error {
+		task, err := s.tasks.CreateTask(ctx, remoteTitle(rt), rt.Notes, domain.TaskPriorityMedium, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to create task from %s: %w", s.provider.Name(), err)
+		}
+		_, err = s.linker.Link(ctx, s.provider.Name(), rt.ID, task.ID, version)
+		return err
+	})
+}
+
+// push writes a local task to the provider with write (Create or Update) and
+// links the result
+func (s *Syncer) push(ctx context.Context, task *domain.Task, rt RemoteTask, write func(context.Context, RemoteTask) (RemoteTask, error)) error {
+	stored, err := write(ctx, rt)
+	if err != nil {
+		return fmt.Errorf("failed to write %q to %s: %w", task.Title, s.provider.Name(), err)
+	}
+	updated := stored.UpdatedAt
+	_, err = s.linker.Link(ctx, s.provider.Name(), stored.ID, task.ID, linking.RemoteVersion{UpdatedAt: &updated})
+	return err
+}
+
+// applyRemote returns an option copying the synced fields of rt onto a task
+func (s *Syncer) applyRemote(rt RemoteTask) domain.TaskOption {
+	return func(t *domain.Task) {
+		t.Title = remoteTitle(rt)
+		t.Description = rt.Notes
+
+		if rt.Due != "" {
+			_ = t.SetMetadata(s.DueKey(), rt.Due)
+		} else {
+			t.DeleteMetadata(s.DueKey())
+		}
+
+		switch {
+		case rt.Completed && t.Status != domain.TaskStatusCompleted:
+			t.MarkCompleted()
+		case !rt.Completed && t.Status == domain.TaskStatusCompleted:
+			t.Status = domain.TaskStatusPending
+			t.CompletedAt = nil
+		}
+	}
+}
+
+// toRemote returns the synced fields of a local task
+func (s *Syncer) toRemote(task *domain.Task) RemoteTask {
+	due, _ := task.Metadata.GetString(s.DueKey())
+	return RemoteTask{
+		Title:     task.Title,
+		Notes:     task.Description,
+		Due:       due,
+		Completed: task.Status == domain.TaskStatusCompleted,
+	}
+}
+
+// remoteTitle returns the title of rt, which remote services may leave empty
+func remoteTitle(rt RemoteTask) string {
+	if rt.Title == "" {
+		return "(untitled)"
+	}
+	return rt.Title
+}
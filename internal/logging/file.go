@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+)
+
+// backupTimeFormat names rotated files, e.g. task.log.20261015-040439.123
+const backupTimeFormat = "20060102-150405.000"
+
+// RotatingFile is an io.WriteCloser appending to a log file. When a write
+// would grow the file past maxSize, the file is renamed with a timestamp
+// suffix and a new one is started; rotated files beyond maxBackups or older
+// than maxAge are removed. Several processes may share the file: a process
+// that finds the file rotated by another one reopens it before writing.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewRotatingFile opens the log file at path for appending, creating it and
+// its directory if needed. Zero limits disable size rotation, age-based
+// removal, or the backup count limit respectively.
+func NewRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the log file, rotating it first if needed
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.rotatedElsewhere() {
+		f.file.Close()
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// open opens the log file for appending and records its size
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotatedElsewhere reports whether the path no longer refers to the open file
+func (f *RotatingFile) rotatedElsewhere() bool {
+	current, err := f.file.Stat()
+	if err != nil {
+		return true
+	}
+	onDisk, err := os.Stat(f.path)
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(current, onDisk)
+}
+
+// rotate renames the log file to a timestamped backup, starts a new file,
+// and removes backups that are no longer kept
+func (f *RotatingFile) rotate() error {
+	f.file.Close()
+
+	backup := f.path + "." + time.Now().Format(backupTimeFormat)
+	if err := os.Rename(f.path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.prune()
+	return nil
+}
+
+// prune removes rotated files beyond maxBackups or older than maxAge
+func (f *RotatingFile) prune() {
+	backups := Backups(f.path)
+	cutoff := time.Now().Add(-f.maxAge)
+
+	// Backups are sorted oldest first
+	for i, path := range backups {
+		tooMany := f.maxBackups > 0 && len(backups)-i > f.maxBackups
+		tooOld := false
+		if f.maxAge > 0 {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+				tooOld = true
+			}
+		}
+		if tooMany || tooOld {
+			os.Remove(path)
+		}
+	}
+}
+
+// Backups returns the rotated files of the log file at path, oldest first
+func Backups(path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+
+	var backups []string
+	for _, match := range matches {
+		suffix := strings.TrimPrefix(match, path+".")
+		if _, err := time.Parse(backupTimeFormat, suffix); err == nil {
+			backups = append(backups, match)
+		}
+	}
+
+	// The timestamp format sorts chronologically
+	sort.Strings(backups)
+	return backups
+}
+
+// Output returns the writer log records go to: a rotating log file when
+// logging.output is "file", stderr otherwise. The returned function closes
+// the log file.
+func Output(cfg config.LoggingConfig, stderr io.Writer) (io.Writer, func() error, error) {
+	if cfg.Output != config.LogOutputFile {
+		return stderr, func() error { return nil }, nil
+	}
+
+	f, err := NewRotatingFile(cfg.FilePath(), int64(cfg.MaxSizeMB)<<20, cfg.MaxAge, cfg.MaxBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
@@ -0,0 +1,93 @@
+// Package logging builds the application's structured loggers. A central
+// Registry hands out one named slog.Logger per subsystem so that debug output
+// can be enabled for a single area (e.g. the repository) without flooding the
+// output with debug logs from every other layer.
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+)
+
+// Subsystem names accepted by the logging.debug configuration
+const (
+	CLI        = "cli"
+	Service    = "service"
+	Repository = "repository"
+	Storage    = "storage"
+	Sync       = "sync"
+	HTTP       = "http"
+)
+
+// Registry creates and caches per-subsystem loggers sharing one output
+type Registry struct {
+	mu      sync.Mutex
+	out     io.Writer
+	format  string
+	level   slog.Level
+	debug   map[string]bool
+	loggers map[string]*slog.Logger
+}
+
+// NewRegistry creates a logger registry from the logging configuration
+func NewRegistry(cfg config.LoggingConfig, out io.Writer) *Registry {
+	debug := make(map[string]bool, len(cfg.Debug))
+	for _, name := range cfg.Debug {
+		debug[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	return &Registry{
+		out:     out,
+		format:  cfg.Format,
+		level:   ParseLevel(cfg.Level),
+		debug:   debug,
+		loggers: make(map[string]*slog.Logger),
+	}
+}
+
+// Logger returns the logger for a subsystem. Debug records are emitted only
+// when the global level is debug or the subsystem is listed in logging.debug.
+func (r *Registry) Logger(subsystem string) *slog.Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if logger, ok := r.loggers[subsystem]; ok {
+		return logger
+	}
+
+	level := r.level
+	if r.debug[subsystem] {
+		level = slog.LevelDebug
+	}
+
+	logger := slog.New(r.newHandler(level)).With("subsystem", subsystem)
+	r.loggers[subsystem] = logger
+	return logger
+}
+
+// newHandler creates a handler writing to the registry output at the given level
+func (r *Registry) newHandler(level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if r.format == "json" {
+		return slog.NewJSONHandler(r.out, opts)
+	}
+	return slog.NewTextHandler(r.out, opts)
+}
+
+// ParseLevel converts a configured level name to an slog.Level, defaulting to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,305 @@
+// Package mcp serves the task service as Model Context Protocol tools
+// (https://modelcontextprotocol.io), so AI assistants can list, search, and
+// manage tasks. Messages are JSON-RPC 2.0, one per line, over stdin and
+// stdout as in the MCP stdio transport.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// protocolVersions are the MCP revisions the server speaks, newest first
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxMessageSize bounds a single JSON-RPC message
+const maxMessageSize = 4 << 20
+
+// request is a JSON-RPC request or notification (no ID)
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// Server answers MCP requests with the task tools
+type Server struct {
+	tasks    Tasks
+	readOnly bool
+	version  string
+	logger   *slog.Logger
+	create   []domain.TaskOption
+	timeout  time.Duration
+	fields   []domain.FieldDef
+	tools    []tool
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithReadOnly only offers the tools that read tasks
+func WithReadOnly() Option {
+	return func(s *Server) {
+		s.readOnly = true
+	}
+}
+
+// WithVersion sets the server version reported to clients
+func WithVersion(version string) Option {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// WithLogger sets the logger for protocol errors
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithCreateOptions sets task options applied to every task created through
+// the server, such as the creator
+func WithCreateOptions(opts ...domain.TaskOption) Option {
+	return func(s *Server) {
+		s.create = opts
+	}
+}
+
+// WithCallTimeout bounds each tool call; 0 waits forever
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.timeout = timeout
+	}
+}
+
+// WithFields declares the user-defined fields search_tasks queries can use
+func WithFields(defs []domain.FieldDef) Option {
+	return func(s *Server) {
+		s.fields = defs
+	}
+}
+
+// NewServer creates a server exposing tasks as MCP tools
+func NewServer(tasks Tasks, opts ...Option) *Server {
+	s := &Server{tasks: tasks, version: "dev", logger: slog.Default()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	for _, t := range taskTools() {
+		if !s.readOnly || t.readOnly {
+			s.tools = append(s.tools, t)
+		}
+	}
+	return s
+}
+
+// Serve reads requests from r and writes responses to w until r is
+// exhausted or ctx is canceled. Requests are handled one at a time.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	enc := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		resp := s.handle(ctx, line)
+		if resp == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// handle answers one message, returning nil for notifications
+func (s *Server) handle(ctx context.Context, line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		s.logger.Warn("Invalid MCP message", "error", err)
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error: " + err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.ID == nil {
+			return nil
+		}
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{codeInvalidRequest, "invalid JSON-RPC 2.0 request"}}
+	}
+
+	result, err := s.dispatch(ctx, req.Method, req.Params)
+	if req.ID == nil {
+		// Notifications, such as notifications/initialized, get no response
+		return nil
+	}
+
+	resp := &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	if err != nil {
+		var rerr *rpcError
+		if !errors.As(err, &rerr) {
+			rerr = &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rerr
+	}
+	return resp
+}
+
+// dispatch runs a method and returns its result
+func (s *Server) dispatch(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		version := protocolVersions[0]
+		if slices.Contains(protocolVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		instructions := "Tools for the user's task list. Tasks have a title, description, priority (low, medium, high), status (pending, completed), an optional GTD context, and an optional assignee."
+		if s.readOnly {
+			instructions += " The server is read-only: tasks can be listed and searched but not changed."
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "task-manager", "version": s.version},
+			"instructions":    instructions,
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		tools := make([]map[string]interface{}, 0, len(s.tools))
+		for _, t := range s.tools {
+			tools = append(tools, map[string]interface{}{
+				"name":        t.name,
+				"description": t.description,
+				"inputSchema": t.schema,
+				"annotations": map[string]bool{"readOnlyHint": t.readOnly, "destructiveHint": t.destructive},
+			})
+		}
+		return map[string]interface{}{"tools": tools}, nil
+
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.callTool(ctx, p.Name, p.Arguments)
+
+	default:
+		if strings.HasPrefix(method, "notifications/") {
+			return nil, nil
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+// callTool runs a tool. Failures of the tool itself, such as an unknown
+// task ID, are reported in the result so the model can see and correct them.
+func (s *Server) callTool(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
+	idx := slices.IndexFunc(s.tools, func(t tool) bool { return t.name == name })
+	if idx < 0 {
+		if s.readOnly && slices.ContainsFunc(taskTools(), func(t tool) bool { return t.name == name }) {
+			return toolError(fmt.Errorf("%s is not available: the server is read-only", name)), nil
+		}
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + name}
+	}
+
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	out, err := s.tools[idx].run(ctx, s, args)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	text, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": string(text)}},
+	}, nil
+}
+
+// toolError is the result of a failed tool call
+func toolError(err error) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": err.Error()}},
+		"isError": true,
+	}
+}
+
+// decodeParams unmarshals request params, which may be absent
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
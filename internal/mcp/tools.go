@@ -0,0 +1,419 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/query"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+)
+
+// Tasks is the part of the task service exposed as tools
+type Tasks interface {
+	CreateTask(ctx context.Context, title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) (*domain.Task, error)
+	GetTask(ctx context.Context, id string) (*domain.Task, error)
+	ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error)
+	UpdateTask(ctx context.Context, id, title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) (*domain.Task, error)
+	CompleteTask(ctx context.Context, id string) (*domain.Task, error)
+	DeleteTask(ctx context.Context, id string) error
+}
+
+// Result limits for list_tasks and search_tasks
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// tool is an MCP tool backed by the task service
+type tool struct {
+	name        string
+	description string
+	schema      map[string]interface{}
+	readOnly    bool // offered in read-only mode
+	destructive bool
+	run         func(ctx context.Context, s *Server, args json.RawMessage) (interface{}, error)
+}
+
+// taskJSON is the JSON form of a task in tool results
+type taskJSON struct {
+	ID          string            `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	Status      string            `json:"status"`
+	Priority    string            `json:"priority"`
+	Context     string            `json:"context,omitempty"`
+	Assignee    string            `json:"assignee,omitempty"`
+	CreatedBy   string            `json:"created_by,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	WaitUntil   *time.Time        `json:"wait_until,omitempty"`
+	Pinned      bool              `json:"pinned,omitempty"`
+	Estimate    string            `json:"estimate,omitempty"`
+	Points      int               `json:"points,omitempty"`
+	Location    string            `json:"location,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"`
+	Metadata    domain.Metadata   `json:"metadata,omitempty"`
+}
+
+// toJSON converts a task for a tool result
+func toJSON(task *domain.Task) taskJSON {
+	return taskJSON{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      string(task.Status),
+		Priority:    string(task.Priority),
+		Context:     task.Context,
+		Assignee:    task.Assignee,
+		CreatedBy:   task.CreatedBy,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		CompletedAt: task.CompletedAt,
+		WaitUntil:   task.WaitUntil,
+		Pinned:      task.Pinned,
+		Estimate:    ui.Duration(task.Estimate),
+		Points:      task.Points,
+		Location:    task.Location,
+		Fields:      task.Fields,
+		Metadata:    task.Metadata,
+	}
+}
+
+// taskList is the result of list_tasks and search_tasks
+type taskList struct {
+	Count     int        `json:"count"`
+	Truncated bool       `json:"truncated,omitempty"` // more tasks matched than limit
+	Tasks     []taskJSON `json:"tasks"`
+}
+
+// filterArgs are the arguments narrowing list_tasks and search_tasks
+type filterArgs struct {
+	Status   string `json:"status"`
+	Priority string `json:"priority"`
+	Context  string `json:"context"`
+	Assignee string `json:"assignee"`
+	Limit    int    `json:"limit"`
+}
+
+// filterProperties are the schema properties of filterArgs
+func filterProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"status":   map[string]interface{}{"type": "string", "enum": []string{"pending", "completed"}},
+		"priority": map[string]interface{}{"type": "string", "enum": []string{"low", "medium", "high"}},
+		"context":  map[string]interface{}{"type": "string", "description": "GTD context, e.g. home or office"},
+		"assignee": map[string]interface{}{"type": "string", "description": "Registered user name"},
+		"limit":    map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxLimit, "description": fmt.Sprintf("Maximum tasks returned (default %d)", defaultLimit)},
+	}
+}
+
+// filter converts the arguments into a task filter
+func (a filterArgs) filter() (domain.TaskFilter, error) {
+	filter := domain.TaskFilter{}
+	if a.Status != "" {
+		status := domain.TaskStatus(a.Status)
+		if status != domain.TaskStatusPending && status != domain.TaskStatusCompleted {
+			return filter, fmt.Errorf("invalid status: %s (must be pending or completed)", a.Status)
+		}
+		filter.Status = &status
+	}
+	if a.Priority != "" {
+		priority, err := parsePriority(a.Priority)
+		if err != nil {
+			return filter, err
+		}
+		filter.Priority = &priority
+	}
+	if a.Context != "" {
+		name := domain.NormalizeContext(a.Context)
+		filter.Context = &name
+	}
+	if a.Assignee != "" {
+		name := domain.NormalizeUserName(a.Assignee)
+		filter.Assignee = &name
+	}
+	if a.Limit < 0 || a.Limit > maxLimit {
+		return filter, fmt.Errorf("invalid limit: %d (must be between 1 and %d)", a.Limit, maxLimit)
+	}
+	return filter, nil
+}
+
+// list runs filter and returns at most limit tasks
+func list(ctx context.Context, tasks Tasks, filter domain.TaskFilter, limit int) (*taskList, error) {
+	found, err := tasks.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	result := &taskList{Count: len(found), Tasks: []taskJSON{}}
+	if len(found) > limit {
+		found = found[:limit]
+		result.Truncated = true
+	}
+	for _, task := range found {
+		result.Tasks = append(result.Tasks, toJSON(task))
+	}
+	return result, nil
+}
+
+// idArgs are the arguments of the tools acting on one task
+type idArgs struct {
+	ID string `json:"id"`
+}
+
+// idSchema is the input schema of the tools acting on one task
+func idSchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"id": map[string]interface{}{"type": "string", "description": "Task ID, as returned by list_tasks"},
+	}, "id")
+}
+
+// taskTools returns every tool, read-only ones first
+func taskTools() []tool {
+	listProps := filterProperties()
+	listProps["query"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Filter expression, e.g. status:pending AND (priority>=medium OR context:office) AND created<2025-01-01. Fields: id, title, description, status, priority, context, assignee, created_by, created, updated, completed.",
+	}
+	searchProps := filterProperties()
+	searchProps["text"] = map[string]interface{}{"type": "string", "description": "Text to find in titles and descriptions"}
+
+	return []tool{
+		{
+			name:        "list_tasks",
+			description: "List tasks, optionally filtered by status, priority, context, assignee, or a query expression.",
+			schema:      objectSchema(listProps),
+			readOnly:    true,
+			run: func(ctx context.Context, s *Server, raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					filterArgs
+					Query string `json:"query"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				filter, err := args.filter()
+				if err != nil {
+					return nil, err
+				}
+				if args.Query != "" {
+					expr, err := query.Parse(args.Query, s.fields...)
+					if err != nil {
+						return nil, fmt.Errorf("invalid query: %w", err)
+					}
+					filter.Query = expr
+				}
+				return list(ctx, s.tasks, filter, args.Limit)
+			},
+		},
+		{
+			name:        "search_tasks",
+			description: "Find tasks whose title or description contains text (case-insensitive).",
+			schema:      objectSchema(searchProps, "text"),
+			readOnly:    true,
+			run: func(ctx context.Context, s *Server, raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					filterArgs
+					Text string `json:"text"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if args.Text == "" {
+					return nil, fmt.Errorf("text is required")
+				}
+				filter, err := args.filter()
+				if err != nil {
+					return nil, err
+				}
+				filter.Query = &domain.QueryOr{
+					Left:  &domain.QueryCond{Field: domain.QueryFieldTitle, Op: domain.QueryMatch, Value: args.Text},
+					Right: &domain.QueryCond{Field: domain.QueryFieldDescription, Op: domain.QueryMatch, Value: args.Text},
+				}
+				return list(ctx, s.tasks, filter, args.Limit)
+			},
+		},
+		{
+			name:        "get_task",
+			description: "Get one task by ID, including its metadata.",
+			schema:      idSchema(),
+			readOnly:    true,
+			run: func(ctx context.Context, s *Server, raw json.RawMessage) (interface{}, error) {
+				var args idArgs
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				task, err := s.tasks.GetTask(ctx, args.ID)
+				if err != nil {
+					return nil, err
+				}
+				return toJSON(task), nil
+			},
+		},
+		{
+			name:        "create_task",
+			description: "Create a pending task.",
+			schema: objectSchema(map[string]interface{}{
+				"title":           map[string]interface{}{"type": "string"},
+				"description":     map[string]interface{}{"type": "string"},
+				"priority":        map[string]interface{}{"type": "string", "enum": []string{"low", "medium", "high"}, "description": "Defaults to medium"},
+				"context":         map[string]interface{}{"type": "string", "description": "GTD context, e.g. home or office"},
+				"assignee":        map[string]interface{}{"type": "string", "description": "Registered user name"},
+				"idempotency_key": map[string]interface{}{"type": "string", "description": "Retrying with the same key returns the task created first instead of a duplicate"},
+			}, "title"),
+			run: func(ctx context.Context, s *Server, raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					Title       string `json:"title"`
+					Description string `json:"description"`
+					Priority    string `json:"priority"`
+					Context     string `json:"context"`
+					Assignee    string `json:"assignee"`
+					Key         string `json:"idempotency_key"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				priority := domain.TaskPriorityMedium
+				if args.Priority != "" {
+					var err error
+					if priority, err = parsePriority(args.Priority); err != nil {
+						return nil, err
+					}
+				}
+
+				opts := append([]domain.TaskOption{}, s.create...)
+				if args.Context != "" {
+					opts = append(opts, domain.WithTaskContext(args.Context))
+				}
+				if args.Assignee != "" {
+					opts = append(opts, domain.WithAssignee(args.Assignee))
+				}
+				if args.Key != "" {
+					opts = append(opts, domain.WithIdempotencyKey(args.Key))
+				}
+				task, err := s.tasks.CreateTask(ctx, args.Title, args.Description, priority, opts...)
+				if err != nil {
+					return nil, err
+				}
+				return toJSON(task), nil
+			},
+		},
+		{
+			name:        "update_task",
+			description: "Change the title, description, priority, context, or assignee of a task. Omitted fields are left unchanged.",
+			schema: objectSchema(map[string]interface{}{
+				"id":          map[string]interface{}{"type": "string", "description": "Task ID, as returned by list_tasks"},
+				"title":       map[string]interface{}{"type": "string"},
+				"description": map[string]interface{}{"type": "string"},
+				"priority":    map[string]interface{}{"type": "string", "enum": []string{"low", "medium", "high"}},
+				"context":     map[string]interface{}{"type": "string"},
+				"assignee":    map[string]interface{}{"type": "string"},
+			}, "id"),
+			run: func(ctx context.Context, s *Server, raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					ID          string `json:"id"`
+					Title       string `json:"title"`
+					Description string `json:"description"`
+					Priority    string `json:"priority"`
+					Context     string `json:"context"`
+					Assignee    string `json:"assignee"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				var priority domain.TaskPriority
+				if args.Priority != "" {
+					var err error
+					if priority, err = parsePriority(args.Priority); err != nil {
+						return nil, err
+					}
+				}
+
+				var opts []domain.TaskOption
+				if args.Context != "" {
+					opts = append(opts, domain.WithTaskContext(args.Context))
+				}
+				if args.Assignee != "" {
+					opts = append(opts, domain.WithAssignee(args.Assignee))
+				}
+				task, err := s.tasks.UpdateTask(ctx, args.ID, args.Title, args.Description, priority, opts...)
+				if err != nil {
+					return nil, err
+				}
+				return toJSON(task), nil
+			},
+		},
+		{
+			name:        "complete_task",
+			description: "Mark a task as completed.",
+			schema:      idSchema(),
+			run: func(ctx context.Context, s *Server, raw json.RawMessage) (interface{}, error) {
+				var args idArgs
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				task, err := s.tasks.CompleteTask(ctx, args.ID)
+				if err != nil {
+					return nil, err
+				}
+				return toJSON(task), nil
+			},
+		},
+		{
+			name:        "delete_task",
+			description: "Delete a task permanently.",
+			schema:      idSchema(),
+			destructive: true,
+			run: func(ctx context.Context, s *Server, raw json.RawMessage) (interface{}, error) {
+				var args idArgs
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if err := s.tasks.DeleteTask(ctx, args.ID); err != nil {
+					return nil, err
+				}
+				return map[string]string{"deleted": args.ID}, nil
+			},
+		},
+	}
+}
+
+// objectSchema returns a JSON Schema for an object with properties
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// decodeArgs unmarshals tool arguments, rejecting unknown ones so typos
+// are reported instead of ignored
+func decodeArgs(raw json.RawMessage, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// parsePriority validates a priority argument
+func parsePriority(value string) (domain.TaskPriority, error) {
+	priority := domain.TaskPriority(value)
+	if priority != domain.TaskPriorityLow && priority != domain.TaskPriorityMedium && priority != domain.TaskPriorityHigh {
+		return "", fmt.Errorf("invalid priority: %s (must be low, medium, or high)", value)
+	}
+	return priority, nil
+}
@@ -0,0 +1,26 @@
+//go:build darwin
+
+package notify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// desktopCommand is the notification command of the platform
+const desktopCommand = "osascript"
+
+// desktopArgs returns the arguments of osascript showing n through
+// Notification Center; urgent notifications play a sound
+func desktopArgs(n Notification) []string {
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.Body), appleScriptString(n.Title))
+	if n.Urgent {
+		script += ` sound name "default"`
+	}
+	return []string{"-e", script}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
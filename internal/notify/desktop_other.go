@@ -0,0 +1,11 @@
+//go:build !linux && !freebsd && !openbsd && !netbsd && !darwin && !windows
+
+package notify
+
+// desktopCommand is empty: there is no notification command on this platform
+const desktopCommand = ""
+
+// desktopArgs is not used on this platform
+func desktopArgs(n Notification) []string {
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package notify
+
+// desktopCommand is the notification command of the platform
+const desktopCommand = "notify-send"
+
+// desktopArgs returns the arguments of notify-send showing n
+func desktopArgs(n Notification) []string {
+	args := []string{"--app-name=task"}
+	if n.Urgent {
+		args = append(args, "--urgency=critical")
+	}
+	return append(args, "--", n.Title, n.Body)
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package notify
+
+import "strings"
+
+// desktopCommand is the notification command of the platform
+const desktopCommand = "powershell.exe"
+
+// toastScript shows a toast with a title and a body, given as PowerShell
+// string literals, under the application ID of PowerShell itself, since
+// task is not a registered application
+const toastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode(` + "%TITLE%" + `)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode(` + "%BODY%" + `)) > $null
+$id = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($id).Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+
+// desktopArgs returns the arguments of PowerShell showing n as a toast;
+// Windows does not distinguish urgent toasts without an application of
+// their own
+func desktopArgs(n Notification) []string {
+	script := strings.NewReplacer("%TITLE%", powerShellString(n.Title), "%BODY%", powerShellString(n.Body)).Replace(toastScript)
+	return []string{"-NoProfile", "-NonInteractive", "-Command", script}
+}
+
+// powerShellString quotes s as a PowerShell verbatim string literal
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,121 @@
+// Package notify shows notifications to the user: on the desktop, through
+// the notification command of the platform (notify-send on Linux and the
+// BSDs, osascript on macOS, a PowerShell toast on Windows), or in the log
+// where there is no desktop. Commands pick a Notifier with New from the
+// notifications.backend setting.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// Backends
+const (
+	BackendAuto    = "auto"    // the desktop when available, otherwise the log
+	BackendDesktop = "desktop" // the desktop, failing where unavailable
+	BackendLog     = "log"     // the log, at info level
+	BackendNone    = "none"    // nowhere
+)
+
+// Backends lists the accepted backends
+var Backends = []string{BackendAuto, BackendDesktop, BackendLog, BackendNone}
+
+// ErrUnsupported is returned by NewDesktop when the platform or its
+// notification command is not available
+var ErrUnsupported = errors.New("desktop notifications are not available")
+
+// Notification is a message for the user
+type Notification struct {
+	Title  string
+	Body   string
+	Urgent bool // shown as critical where the platform distinguishes it
+}
+
+// Notifier shows notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// New returns the notifier of backend, one of Backends; empty means auto
+func New(backend string, logger *slog.Logger) (Notifier, error) {
+	switch strings.ToLower(backend) {
+	case "", BackendAuto:
+		desktop, err := NewDesktop()
+		if err != nil {
+			logger.Debug("Falling back to logged notifications", "error", err)
+			return Log(logger), nil
+		}
+		return desktop, nil
+	case BackendDesktop:
+		return NewDesktop()
+	case BackendLog:
+		return Log(logger), nil
+	case BackendNone:
+		return Nop(), nil
+	}
+	return nil, fmt.Errorf("invalid notification backend: %s (must be auto, desktop, log, or none)", backend)
+}
+
+// desktopNotifier runs the notification command of the platform
+type desktopNotifier struct {
+	path string
+}
+
+// NewDesktop returns a notifier showing notifications on the desktop, or
+// ErrUnsupported if the notification command of the platform is missing
+func NewDesktop() (Notifier, error) {
+	if desktopCommand == "" {
+		return nil, ErrUnsupported
+	}
+	path, err := exec.LookPath(desktopCommand)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s not found", ErrUnsupported, desktopCommand)
+	}
+	return &desktopNotifier{path: path}, nil
+}
+
+// Notify implements Notifier
+func (d *desktopNotifier) Notify(ctx context.Context, n Notification) error {
+	out, err := exec.CommandContext(ctx, d.path, desktopArgs(n)...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("failed to show notification: %w: %s", err, msg)
+		}
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+	return nil
+}
+
+// logNotifier writes notifications to the log
+type logNotifier struct {
+	logger *slog.Logger
+}
+
+// Log returns a notifier writing notifications to logger at info level
+func Log(logger *slog.Logger) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+// Notify implements Notifier
+func (l *logNotifier) Notify(ctx context.Context, n Notification) error {
+	l.logger.InfoContext(ctx, "Notification", "title", n.Title, "body", n.Body, "urgent", n.Urgent)
+	return nil
+}
+
+// nopNotifier drops notifications
+type nopNotifier struct{}
+
+// Nop returns a notifier that drops every notification
+func Nop() Notifier {
+	return nopNotifier{}
+}
+
+// Notify implements Notifier
+func (nopNotifier) Notify(ctx context.Context, n Notification) error {
+	return nil
+}
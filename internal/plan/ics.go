@@ -0,0 +1,77 @@
+package plan
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/ui"
+)
+
+// icsDate is the layout of dates in iCalendar
+const icsDate = "20060102"
+
+// WriteICS writes the planned days of p as an iCalendar (RFC 5545)
+// calendar, with one all-day event per task and day. Event UIDs derive from
+// the task and day, so importing the same plan again adds no duplicates.
+func WriteICS(w io.Writer, p *Plan, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//task-manager//task plan//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", "Task plan")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, day := range p.Days {
+		for _, entry := range day.Entries {
+			summary := fmt.Sprintf("%s (%s)", entry.Task.Title, ui.Duration(entry.Duration))
+			description := fmt.Sprintf("Task %s, estimated at %s", entry.Task.ID, ui.Duration(entry.Task.Estimate))
+			if entry.Due {
+				description += "; finish it by this day"
+			}
+
+			line("BEGIN", "VEVENT")
+			line("UID", entry.Task.ID+"-"+day.Date.Format(icsDate)+"@task-manager")
+			line("DTSTAMP", stamp)
+			line("DTSTART;VALUE=DATE", day.Date.Format(icsDate))
+			line("DTEND;VALUE=DATE", day.Date.AddDate(0, 0, 1).Format(icsDate))
+			line("SUMMARY", escapeText(summary))
+			line("DESCRIPTION", escapeText(description))
+			if entry.Task.Context != "" {
+				line("CATEGORIES", escapeText(entry.Task.Context))
+			}
+			line("TRANSP", "TRANSPARENT")
+			line("END", "VEVENT")
+		}
+	}
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// escapeText escapes an iCalendar TEXT value
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line ending in CRLF, folded so that no line
+// is longer than 75 octets and no UTF-8 sequence is split
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts
+	}
+	w.WriteString(s + "\r\n")
+}
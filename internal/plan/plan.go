@@ -0,0 +1,194 @@
+// Package plan lays pending tasks out over the coming days by their
+// estimates, so that each day holds about as much work as there are hours
+// for it. Tasks with a due date come first, earliest first, and are never
+// planned after it: when the days before are full, the day the task is due
+// takes it anyway and goes over capacity. Long tasks are split across days.
+// Nothing is stored; a plan is a proposal.
+package plan
+
+import (
+	"sort"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// Options configures Schedule
+type Options struct {
+	Start    time.Time     // first day planned; the time of day is ignored
+	Days     int           // calendar days planned, e.g. 7 for a week
+	Capacity time.Duration // working time available each day
+	Weekends bool          // plan Saturdays and Sundays too
+}
+
+// Entry is the part of a task planned for a day, or left unplanned
+type Entry struct {
+	Task     *domain.Task
+	Duration time.Duration
+	Due      bool // the last day planned before the task is due, or it is overdue
+}
+
+// Day is the work planned for one day
+type Day struct {
+	Date    time.Time // midnight, in the location of Options.Start
+	Entries []Entry
+	Load    time.Duration
+}
+
+// Plan is a proposed schedule
+type Plan struct {
+	Capacity    time.Duration
+	Days        []Day
+	Unplanned   []Entry        // work without a due date in the period that does not fit
+	Unestimated []*domain.Task // tasks left out because they have no estimate
+
+	end time.Time // midnight after the last day of the period
+}
+
+// Over reports whether the day holds more work than the capacity
+func (p *Plan) Over(day Day) bool {
+	return day.Load > p.Capacity
+}
+
+// Load returns the work planned over all days
+func (p *Plan) Load() time.Duration {
+	var total time.Duration
+	for _, day := range p.Days {
+		total += day.Load
+	}
+	return total
+}
+
+// Schedule plans the pending tasks among tasks over the days of opts.
+// Snoozed tasks are not planned before they wake.
+func Schedule(tasks []*domain.Task, opts Options) *Plan {
+	start := startOfDay(opts.Start)
+	p := &Plan{Capacity: opts.Capacity, end: start.AddDate(0, 0, opts.Days)}
+	for date := start; date.Before(p.end); date = date.AddDate(0, 0, 1) {
+		if !opts.Weekends && (date.Weekday() == time.Saturday || date.Weekday() == time.Sunday) {
+			continue
+		}
+		p.Days = append(p.Days, Day{Date: date})
+	}
+
+	var planned []*domain.Task
+	for _, task := range tasks {
+		switch {
+		case task.Status != domain.TaskStatusPending:
+		case task.Estimate <= 0:
+			p.Unestimated = append(p.Unestimated, task)
+		default:
+			planned = append(planned, task)
+		}
+	}
+	Order(planned)
+
+	for _, task := range planned {
+		p.place(task)
+	}
+	return p
+}
+
+// place plans a task into the first days with room, between the day it
+// wakes and the day it is due
+func (p *Plan) place(task *domain.Task) {
+	first := 0
+	if task.WaitUntil != nil {
+		for first < len(p.Days) && p.Days[first].Date.AddDate(0, 0, 1).Before(*task.WaitUntil) {
+			first++
+		}
+	}
+
+	last, due := len(p.Days)-1, false
+	if day, ok := task.DueDate(); ok && day.Before(p.end) && len(p.Days) > 0 {
+		last, due = 0, true // overdue, or due before the first working day
+		for i := len(p.Days) - 1; i > 0; i-- {
+			if !p.Days[i].Date.After(day) {
+				last = i
+				break
+			}
+		}
+	}
+	if first > last {
+		if !due {
+			p.Unplanned = append(p.Unplanned, Entry{Task: task, Duration: task.Estimate})
+			return
+		}
+		first = last // snoozed past the day it is due
+	}
+
+	remaining := task.Estimate
+	for i := first; i <= last && remaining > 0; i++ {
+		free := p.Capacity - p.Days[i].Load
+		if free <= 0 {
+			continue
+		}
+		part := min(free, remaining)
+		p.add(i, task, part, due && i == last)
+		remaining -= part
+	}
+	if remaining <= 0 {
+		return
+	}
+
+	if due {
+		p.add(last, task, remaining, true)
+		return
+	}
+	p.Unplanned = append(p.Unplanned, Entry{Task: task, Duration: remaining})
+}
+
+// add plans part of a task on the day with index i, merging it with a part
+// already planned there
+func (p *Plan) add(i int, task *domain.Task, d time.Duration, due bool) {
+	day := &p.Days[i]
+	day.Load += d
+	for j := range day.Entries {
+		if day.Entries[j].Task == task {
+			day.Entries[j].Duration += d
+			return
+		}
+	}
+	day.Entries = append(day.Entries, Entry{Task: task, Duration: d, Due: due})
+}
+
+// Order sorts tasks in the order Schedule plans them: due soonest first,
+// then pinned, then by priority, then oldest first
+func Order(tasks []*domain.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool { return before(tasks[i], tasks[j]) })
+}
+
+// before orders tasks for planning: by due date, tasks without one last,
+// then pinned first, then by priority from high to low, then oldest first
+func before(a, b *domain.Task) bool {
+	aDue, aOK := a.DueDate()
+	bDue, bOK := b.DueDate()
+	switch {
+	case aOK != bOK:
+		return aOK
+	case aOK && !aDue.Equal(bDue):
+		return aDue.Before(bDue)
+	case a.Pinned != b.Pinned:
+		return a.Pinned
+	case priorityRank(a.Priority) != priorityRank(b.Priority):
+		return priorityRank(a.Priority) < priorityRank(b.Priority)
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+// priorityRank orders priorities from high to low
+func priorityRank(p domain.TaskPriority) int {
+	switch p {
+	case domain.TaskPriorityHigh:
+		return 0
+	case domain.TaskPriorityMedium:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// startOfDay returns midnight of the day of t
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
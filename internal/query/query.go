@@ -0,0 +1,294 @@
+// Package query parses the filter expressions accepted by "task list -q",
+// such as
+//
+//	status:pending AND (priority:high OR context:office) AND due<2025-01-01
+//
+// into a domain.QueryExpr that repositories translate into SQL. Terms are
+// field<op>value comparisons combined with AND, OR, NOT and parentheses;
+// adjacent terms without an operator are joined with AND. Values containing
+// spaces or operator characters can be double-quoted. User-defined fields
+// passed to Parse can be used like built-in ones.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// fieldKind groups fields by the values and operators they accept
+type fieldKind int
+
+const (
+	kindText fieldKind = iota
+	kindKeyword
+	kindPriority
+	kindDate
+)
+
+// fields maps the names usable in a query to task fields
+var fields = map[string]struct {
+	field domain.QueryField
+	kind  fieldKind
+}{
+	"id":          {domain.QueryFieldID, kindText},
+	"title":       {domain.QueryFieldTitle, kindText},
+	"description": {domain.QueryFieldDescription, kindText},
+	"status":      {domain.QueryFieldStatus, kindKeyword},
+	"priority":    {domain.QueryFieldPriority, kindPriority},
+	"context":     {domain.QueryFieldContext, kindKeyword},
+	"assignee":    {domain.QueryFieldAssignee, kindKeyword},
+	"created_by":  {domain.QueryFieldCreatedBy, kindKeyword},
+	"created":     {domain.QueryFieldCreated, kindDate},
+	"updated":     {domain.QueryFieldUpdated, kindDate},
+	"completed":   {domain.QueryFieldCompleted, kindDate},
+	"due":         {domain.QueryFieldDue, kindDate},
+}
+
+// Fields returns the field names accepted in queries
+func Fields() []string {
+	return []string{"id", "title", "description", "status", "priority", "context",
+		"assignee", "created_by", "created", "updated", "completed", "due"}
+}
+
+// Parse parses a query expression that may refer to the given user-defined
+// fields
+func Parse(input string, custom ...domain.FieldDef) (domain.QueryExpr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, custom: custom}
+	if p.peek().kind == tokenEOF {
+		return nil, fmt.Errorf("query is empty")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos+1)
+	}
+
+	return expr, nil
+}
+
+// parser is a recursive descent parser over the lexed tokens
+type parser struct {
+	tokens []token
+	pos    int
+	custom []domain.FieldDef
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses: and { OR and }
+func (p *parser) parseOr() (domain.QueryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &domain.QueryOr{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd parses: unary { [AND] unary }
+func (p *parser) parseAnd() (domain.QueryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.isKeyword("AND") {
+			p.next()
+		} else if tok.kind == tokenEOF || tok.kind == tokenRParen || tok.isKeyword("OR") {
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &domain.QueryAnd{Left: left, Right: right}
+	}
+}
+
+// parseUnary parses: NOT unary | '(' or ')' | term
+func (p *parser) parseUnary() (domain.QueryExpr, error) {
+	tok := p.peek()
+
+	switch {
+	case tok.isKeyword("NOT"):
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &domain.QueryNot{Expr: expr}, nil
+
+	case tok.kind == tokenLParen:
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d, found %s", closing.pos+1, closing)
+		}
+		return expr, nil
+	}
+
+	return p.parseTerm()
+}
+
+// parseTerm parses: field op value
+func (p *parser) parseTerm() (domain.QueryExpr, error) {
+	name := p.next()
+	if name.kind != tokenWord {
+		return nil, fmt.Errorf("expected a field at position %d, found %s", name.pos+1, name)
+	}
+
+	op := p.next()
+	if op.kind != tokenOp {
+		return nil, fmt.Errorf("expected an operator after %q at position %d (e.g. %s:value)", name.text, op.pos+1, name.text)
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, fmt.Errorf("expected a value after %s%s at position %d", name.text, op.text, value.pos+1)
+	}
+
+	cond, err := p.condition(strings.ToLower(name.text), domain.QueryOp(op.text), value.text)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s%s: %w", name.text, op.text, value.text, err)
+	}
+	return cond, nil
+}
+
+// condition validates a comparison and normalizes its value
+func (p *parser) condition(name string, op domain.QueryOp, value string) (*domain.QueryCond, error) {
+	ordered := op == domain.QueryLess || op == domain.QueryLessEqual ||
+		op == domain.QueryGreater || op == domain.QueryGreaterEqual
+
+	f, ok := fields[name]
+	if !ok {
+		for _, def := range p.custom {
+			if def.Name == name {
+				return customCondition(def, op, ordered, value)
+			}
+		}
+		names := Fields()
+		for _, def := range p.custom {
+			names = append(names, def.Name)
+		}
+		return nil, fmt.Errorf("unknown field %q (use %s)", name, strings.Join(names, ", "))
+	}
+	if ordered && f.kind != kindPriority && f.kind != kindDate {
+		return nil, fmt.Errorf("%s cannot be compared with %s", name, op)
+	}
+
+	cond := &domain.QueryCond{Field: f.field, Op: op}
+
+	switch f.kind {
+	case kindText:
+		cond.Value = value
+
+	case kindKeyword:
+		switch f.field {
+		case domain.QueryFieldStatus:
+			status := domain.TaskStatus(strings.ToLower(value))
+			if status != domain.TaskStatusPending && status != domain.TaskStatusCompleted {
+				return nil, fmt.Errorf("invalid status %q (must be pending or completed)", value)
+			}
+			cond.Value = string(status)
+		case domain.QueryFieldContext:
+			cond.Value = domain.NormalizeContext(value)
+		default:
+			cond.Value = domain.NormalizeUserName(value)
+		}
+
+	case kindPriority:
+		priority := domain.TaskPriority(strings.ToLower(value))
+		if priority != domain.TaskPriorityLow && priority != domain.TaskPriorityMedium && priority != domain.TaskPriorityHigh {
+			return nil, fmt.Errorf("invalid priority %q (must be low, medium, or high)", value)
+		}
+		cond.Value = string(priority)
+
+	case kindDate:
+		day, err := parseDay(value)
+		if err != nil {
+			return nil, err
+		}
+		cond.Date = day
+	}
+
+	return cond, nil
+}
+
+// customCondition validates a comparison on a user-defined field. Number and
+// date fields can be ordered; dates also accept "today" and "yesterday".
+func customCondition(def domain.FieldDef, op domain.QueryOp, ordered bool, value string) (*domain.QueryCond, error) {
+	if ordered && def.Type != domain.FieldTypeNumber && def.Type != domain.FieldTypeDate {
+		return nil, fmt.Errorf("%s cannot be compared with %s", def.Name, op)
+	}
+
+	if def.Type == domain.FieldTypeDate {
+		if day, err := parseDay(value); err == nil {
+			value = day.Format(time.DateOnly)
+		}
+	}
+	normalized, err := def.Normalize(value)
+	if err != nil {
+		return nil, err
+	}
+	if normalized == "" {
+		return nil, fmt.Errorf("%s needs a value", def.Name)
+	}
+
+	return &domain.QueryCond{Field: domain.QueryFieldCustom, Op: op, Value: normalized, Name: def.Name, Type: def.Type}, nil
+}
+
+// parseDay parses YYYY-MM-DD, "today" or "yesterday" as the start of that
+// day in local time, matching how task timestamps are stored
+func parseDay(value string) (time.Time, error) {
+	switch strings.ToLower(value) {
+	case "today", "yesterday":
+		now := time.Now()
+		day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		if strings.EqualFold(value, "yesterday") {
+			day = day.AddDate(0, 0, -1)
+		}
+		return day, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD, today, or yesterday)", value)
+	}
+	return day, nil
+}
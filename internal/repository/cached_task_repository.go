@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// CachePolicy controls what CachedTaskRepository keeps in memory
+type CachePolicy struct {
+	Size int           // tasks, and separately List results, kept at most
+	TTL  time.Duration // how long an entry is used before it is read again
+}
+
+// CachedTaskRepository is a TaskRepository decorator that keeps recently
+// read tasks and List results in memory, so repeated reads of the same
+// tasks skip the database. Writes through it drop the tasks they may have
+// changed and every cached List result. Writes by other processes go
+// unnoticed until the entries expire. Reads inside a transaction bypass the
+// cache, since what they see may yet be rolled back; with
+// service.WithTransactions this keeps write calls from updating a task from
+// a stale copy.
+type CachedTaskRepository struct {
+	inner  domain.TaskRepository
+	policy CachePolicy
+
+	mu    sync.Mutex
+	tasks *lru[*domain.Task]
+	lists *lru[[]*domain.Task]
+}
+
+// NewCachedTaskRepository wraps inner with a cache limited by policy
+func NewCachedTaskRepository(inner domain.TaskRepository, policy CachePolicy) *CachedTaskRepository {
+	if policy.Size < 1 {
+		policy.Size = 1
+	}
+	return &CachedTaskRepository{
+		inner:  inner,
+		policy: policy,
+		tasks:  newLRU[*domain.Task](policy.Size),
+		lists:  newLRU[[]*domain.Task](policy.Size),
+	}
+}
+
+// invalidate drops the cached tasks with the given IDs, or every task if
+// none are given, and every List result
+func (r *CachedTaskRepository) invalidate(ids ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(ids) == 0 {
+		r.tasks.clear()
+	}
+	for _, id := range ids {
+		r.tasks.remove(id)
+	}
+	r.lists.clear()
+}
+
+// Create delegates to the wrapped repository and invalidates the cached lists
+func (r *CachedTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	err := r.inner.Create(ctx, task)
+	r.invalidate(task.ID)
+	return err
+}
+
+// CreateBatch delegates to the wrapped repository and invalidates the cached lists
+func (r *CachedTaskRepository) CreateBatch(ctx context.Context, tasks []*domain.Task) error {
+	err := r.inner.CreateBatch(ctx, tasks)
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	r.invalidate(ids...)
+	return err
+}
+
+// GetByID returns the cached task, or reads it and caches it
+func (r *CachedTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	if inTx(ctx) {
+		return r.inner.GetByID(ctx, id)
+	}
+
+	r.mu.Lock()
+	task, ok := r.tasks.get(id, time.Now())
+	r.mu.Unlock()
+	if ok {
+		return task.Clone(), nil
+	}
+
+	task, err := r.inner.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.tasks.put(id, task.Clone(), time.Now().Add(r.policy.TTL))
+	r.mu.Unlock()
+	return task, nil
+}
+
+// List returns the cached result for filter, or lists the tasks and caches
+// the result. Filters relative to the current time are not cached.
+func (r *CachedTaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	if inTx(ctx) || filter.AwakeAt != nil {
+		return r.inner.List(ctx, filter)
+	}
+
+	key := filterKey(filter)
+	r.mu.Lock()
+	tasks, ok := r.lists.get(key, time.Now())
+	r.mu.Unlock()
+	if ok {
+		return cloneTasks(tasks), nil
+	}
+
+	tasks, err := r.inner.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.lists.put(key, cloneTasks(tasks), time.Now().Add(r.policy.TTL))
+	r.mu.Unlock()
+	return tasks, nil
+}
+
+// Stream delegates to the wrapped repository
+func (r *CachedTaskRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	return r.inner.Stream(ctx, filter, fn)
+}
+
+// Count delegates to the wrapped repository
+func (r *CachedTaskRepository) Count(ctx context.Context, filter domain.TaskFilter) (int64, error) {
+	return r.inner.Count(ctx, filter)
+}
+
+// CountByStatus delegates to the wrapped repository
+func (r *CachedTaskRepository) CountByStatus(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskStatus]int64, error) {
+	return r.inner.CountByStatus(ctx, filter)
+}
+
+// CountByPriority delegates to the wrapped repository
+func (r *CachedTaskRepository) CountByPriority(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskPriority]int64, error) {
+	return r.inner.CountByPriority(ctx, filter)
+}
+
+// EffortByContext delegates to the wrapped repository
+func (r *CachedTaskRepository) EffortByContext(ctx context.Context, filter domain.TaskFilter) (map[string]domain.Effort, error) {
+	return r.inner.EffortByContext(ctx, filter)
+}
+
+// Update delegates to the wrapped repository and invalidates the task
+func (r *CachedTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	err := r.inner.Update(ctx, task)
+	r.invalidate(task.ID)
+	return err
+}
+
+// UpdateWhere delegates to the wrapped repository and empties the cache,
+// not knowing which tasks matched
+func (r *CachedTaskRepository) UpdateWhere(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch, now time.Time) (int64, error) {
+	n, err := r.inner.UpdateWhere(ctx, filter, patch, now)
+	r.invalidate()
+	return n, err
+}
+
+// Delete delegates to the wrapped repository and invalidates the task
+func (r *CachedTaskRepository) Delete(ctx context.Context, id string) error {
+	err := r.inner.Delete(ctx, id)
+	r.invalidate(id)
+	return err
+}
+
+// cloneTasks returns copies of tasks
+func cloneTasks(tasks []*domain.Task) []*domain.Task {
+	clones := make([]*domain.Task, len(tasks))
+	for i, task := range tasks {
+		clones[i] = task.Clone()
+	}
+	return clones
+}
+
+// filterKey returns a string identifying filter among the filters List may
+// be called with
+func filterKey(filter domain.TaskFilter) string {
+	var b strings.Builder
+	field := func(name string, value any) {
+		fmt.Fprintf(&b, "%s=%v;", name, value)
+	}
+	if filter.Status != nil {
+		field("status", *filter.Status)
+	}
+	if filter.Priority != nil {
+		field("priority", *filter.Priority)
+	}
+	if filter.Context != nil {
+		field("context", *filter.Context)
+	}
+	if filter.Assignee != nil {
+		field("assignee", *filter.Assignee)
+	}
+	if filter.CreatedBy != nil {
+		field("created_by", *filter.CreatedBy)
+	}
+	if filter.Owner != nil {
+		field("owner", *filter.Owner)
+	}
+	if filter.Pinned != nil {
+		field("pinned", *filter.Pinned)
+	}
+	if filter.DueBefore != nil {
+		field("due_before", filter.DueBefore.Format(time.DateOnly))
+	}
+	if filter.IdleSince != nil {
+		field("idle_since", filter.IdleSince.UnixNano())
+	}
+	if filter.Location != nil {
+		field("location", *filter.Location)
+	}
+	if filter.FromDate != nil {
+		field("from", filter.FromDate.UnixNano())
+	}
+	if filter.ToDate != nil {
+		field("to", filter.ToDate.UnixNano())
+	}
+	if filter.Query != nil {
+		field("query", queryKey(filter.Query))
+	}
+	return b.String()
+}
+
+// queryKey returns a string identifying a query expression
+func queryKey(expr domain.QueryExpr) string {
+	switch e := expr.(type) {
+	case *domain.QueryAnd:
+		return "(" + queryKey(e.Left) + " and " + queryKey(e.Right) + ")"
+	case *domain.QueryOr:
+		return "(" + queryKey(e.Left) + " or " + queryKey(e.Right) + ")"
+	case *domain.QueryNot:
+		return "not " + queryKey(e.Expr)
+	case *domain.QueryCond:
+		return fmt.Sprintf("%q%s%q/%d/%q/%s", e.Field, e.Op, e.Value, e.Date.UnixNano(), e.Name, e.Type)
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+// lru is a least recently used cache of values by key whose entries also
+// expire
+type lru[V any] struct {
+	size    int
+	order   *list.List // of *lruEntry[V], most recently used first
+	entries map[string]*list.Element
+}
+
+// lruEntry is a value in an lru and when it expires
+type lruEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+// newLRU creates an lru holding at most size entries
+func newLRU[V any](size int) *lru[V] {
+	return &lru[V]{size: size, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// get returns the value under key unless it is missing or expired at now
+func (c *lru[V]) get(key string, now time.Time) (V, bool) {
+	var zero V
+	elem, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := elem.Value.(*lruEntry[V])
+	if !now.Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// put stores value under key until expires, evicting the least recently
+// used entry when the cache is full
+func (c *lru[V]) put(key string, value V, expires time.Time) {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = &lruEntry[V]{key: key, value: value, expires: expires}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expires: expires})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// remove drops the entry under key, if any
+func (c *lru[V]) remove(key string) {
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// clear drops every entry
+func (c *lru[V]) clear() {
+	c.order.Init()
+	clear(c.entries)
+}
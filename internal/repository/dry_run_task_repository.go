@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// DryRunTaskRepository is a read-only TaskRepository decorator used for dry runs.
+// Reads are delegated to the wrapped repository, while writes are validated
+// against existing data and logged but never persisted.
+type DryRunTaskRepository struct {
+	inner  domain.TaskRepository
+	logger *slog.Logger
+}
+
+// NewDryRunTaskRepository wraps inner so that no writes reach it
+func NewDryRunTaskRepository(inner domain.TaskRepository, logger *slog.Logger) *DryRunTaskRepository {
+	return &DryRunTaskRepository{
+		inner:  inner,
+		logger: logger,
+	}
+}
+
+// Create logs the task that would be created
+func (r *DryRunTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	r.logger.Info("Dry run: task not created", "task_id", task.ID)
+	return nil
+}
+
+// CreateBatch logs the tasks that would be created
+func (r *DryRunTaskRepository) CreateBatch(ctx context.Context, tasks []*domain.Task) error {
+	r.logger.Info("Dry run: tasks not created", "count", len(tasks))
+	return nil
+}
+
+// GetByID delegates to the wrapped repository
+func (r *DryRunTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	return r.inner.GetByID(ctx, id)
+}
+
+// List delegates to the wrapped repository
+func (r *DryRunTaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	return r.inner.List(ctx, filter)
+}
+
+// Stream delegates to the wrapped repository
+func (r *DryRunTaskRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	return r.inner.Stream(ctx, filter, fn)
+}
+
+// Count delegates to the wrapped repository
+func (r *DryRunTaskRepository) Count(ctx context.Context, filter domain.TaskFilter) (int64, error) {
+	return r.inner.Count(ctx, filter)
+}
+
+// CountByStatus delegates to the wrapped repository
+func (r *DryRunTaskRepository) CountByStatus(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskStatus]int64, error) {
+	return r.inner.CountByStatus(ctx, filter)
+}
+
+// CountByPriority delegates to the wrapped repository
+func (r *DryRunTaskRepository) CountByPriority(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskPriority]int64, error) {
+	return r.inner.CountByPriority(ctx, filter)
+}
+
+// EffortByContext delegates to the wrapped repository
+func (r *DryRunTaskRepository) EffortByContext(ctx context.Context, filter domain.TaskFilter) (map[string]domain.Effort, error) {
+	return r.inner.EffortByContext(ctx, filter)
+}
+
+// Update checks that the task exists and logs the update that would be made
+func (r *DryRunTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	if _, err := r.inner.GetByID(ctx, task.ID); err != nil {
+		return err
+	}
+	r.logger.Info("Dry run: task not updated", "task_id", task.ID)
+	return nil
+}
+
+// UpdateWhere counts the matching tasks and logs the update that would be made
+func (r *DryRunTaskRepository) UpdateWhere(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch, now time.Time) (int64, error) {
+	n, err := r.inner.Count(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	r.logger.Info("Dry run: tasks not updated", "count", n)
+	return n, nil
+}
+
+// Delete checks that the task exists and logs the deletion that would be made
+func (r *DryRunTaskRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.inner.GetByID(ctx, id); err != nil {
+		return err
+	}
+	r.logger.Info("Dry run: task not deleted", "task_id", id)
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/encryption"
+)
+
+// EncryptedCommentRepository is a CommentRepository decorator that encrypts
+// comment bodies before they are written and decrypts them after they are
+// read, as EncryptedTaskRepository does for task text. Authors stay in plain
+// text. The caller's comments are never modified.
+type EncryptedCommentRepository struct {
+	inner  domain.CommentRepository
+	cipher *encryption.Cipher
+}
+
+// NewEncryptedCommentRepository wraps inner so comment bodies are stored encrypted with cipher
+func NewEncryptedCommentRepository(inner domain.CommentRepository, cipher *encryption.Cipher) *EncryptedCommentRepository {
+	return &EncryptedCommentRepository{
+		inner:  inner,
+		cipher: cipher,
+	}
+}
+
+// Add encrypts the comment and delegates to the wrapped repository
+func (r *EncryptedCommentRepository) Add(ctx context.Context, comment *domain.Comment) error {
+	sealed := *comment
+
+	var err error
+	if sealed.Body, err = r.cipher.Encrypt(comment.Body); err != nil {
+		return fmt.Errorf("failed to encrypt comment: %w", err)
+	}
+	return r.inner.Add(ctx, &sealed)
+}
+
+// ListByTask delegates to the wrapped repository and decrypts the results
+func (r *EncryptedCommentRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.Comment, error) {
+	comments, err := r.inner.ListByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		if comment.Body, err = r.cipher.Decrypt(comment.Body); err != nil {
+			return nil, fmt.Errorf("failed to decrypt comment %s: %w", comment.ID, err)
+		}
+	}
+	return comments, nil
+}
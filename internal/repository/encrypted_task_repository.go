@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/encryption"
+)
+
+// EncryptedTaskRepository is a TaskRepository decorator that encrypts task
+// titles and descriptions before they are written and decrypts them after
+// they are read. The caller's tasks are never modified. Filters run against
+// the stored ciphertext, so query conditions on title or description do not
+// match encrypted tasks.
+type EncryptedTaskRepository struct {
+	inner  domain.TaskRepository
+	cipher *encryption.Cipher
+}
+
+// NewEncryptedTaskRepository wraps inner so task text is stored encrypted with cipher
+func NewEncryptedTaskRepository(inner domain.TaskRepository, cipher *encryption.Cipher) *EncryptedTaskRepository {
+	return &EncryptedTaskRepository{
+		inner:  inner,
+		cipher: cipher,
+	}
+}
+
+// seal returns an encrypted copy of task
+func (r *EncryptedTaskRepository) seal(task *domain.Task) (*domain.Task, error) {
+	sealed := *task
+
+	var err error
+	if sealed.Title, err = r.cipher.Encrypt(task.Title); err != nil {
+		return nil, fmt.Errorf("failed to encrypt task: %w", err)
+	}
+	if sealed.Description, err = r.cipher.Encrypt(task.Description); err != nil {
+		return nil, fmt.Errorf("failed to encrypt task: %w", err)
+	}
+
+	return &sealed, nil
+}
+
+// open decrypts task in place
+func (r *EncryptedTaskRepository) open(task *domain.Task) error {
+	var err error
+	if task.Title, err = r.cipher.Decrypt(task.Title); err != nil {
+		return fmt.Errorf("failed to decrypt task %s: %w", task.ID, err)
+	}
+	if task.Description, err = r.cipher.Decrypt(task.Description); err != nil {
+		return fmt.Errorf("failed to decrypt task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// Create encrypts the task and delegates to the wrapped repository
+func (r *EncryptedTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	sealed, err := r.seal(task)
+	if err != nil {
+		return err
+	}
+	return r.inner.Create(ctx, sealed)
+}
+
+// CreateBatch encrypts the tasks and delegates to the wrapped repository
+func (r *EncryptedTaskRepository) CreateBatch(ctx context.Context, tasks []*domain.Task) error {
+	sealed := make([]*domain.Task, len(tasks))
+	for i, task := range tasks {
+		var err error
+		if sealed[i], err = r.seal(task); err != nil {
+			return err
+		}
+	}
+	return r.inner.CreateBatch(ctx, sealed)
+}
+
+// GetByID delegates to the wrapped repository and decrypts the result
+func (r *EncryptedTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	task, err := r.inner.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.open(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// List delegates to the wrapped repository and decrypts the results
+func (r *EncryptedTaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	tasks, err := r.inner.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if err := r.open(task); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// Stream delegates to the wrapped repository, decrypting each task before fn sees it
+func (r *EncryptedTaskRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	return r.inner.Stream(ctx, filter, func(task *domain.Task) error {
+		if err := r.open(task); err != nil {
+			return err
+		}
+		return fn(task)
+	})
+}
+
+// Count delegates to the wrapped repository
+func (r *EncryptedTaskRepository) Count(ctx context.Context, filter domain.TaskFilter) (int64, error) {
+	return r.inner.Count(ctx, filter)
+}
+
+// CountByStatus delegates to the wrapped repository
+func (r *EncryptedTaskRepository) CountByStatus(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskStatus]int64, error) {
+	return r.inner.CountByStatus(ctx, filter)
+}
+
+// CountByPriority delegates to the wrapped repository
+func (r *EncryptedTaskRepository) CountByPriority(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskPriority]int64, error) {
+	return r.inner.CountByPriority(ctx, filter)
+}
+
+// EffortByContext delegates to the wrapped repository
+func (r *EncryptedTaskRepository) EffortByContext(ctx context.Context, filter domain.TaskFilter) (map[string]domain.Effort, error) {
+	return r.inner.EffortByContext(ctx, filter)
+}
+
+// Update encrypts the task and delegates to the wrapped repository
+func (r *EncryptedTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	sealed, err := r.seal(task)
+	if err != nil {
+		return err
+	}
+	return r.inner.Update(ctx, sealed)
+}
+
+// UpdateWhere encrypts a patched description and delegates to the wrapped repository
+func (r *EncryptedTaskRepository) UpdateWhere(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch, now time.Time) (int64, error) {
+	if patch.Description != nil {
+		description, err := r.cipher.Encrypt(*patch.Description)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encrypt task: %w", err)
+		}
+		patch.Description = &description
+	}
+	return r.inner.UpdateWhere(ctx, filter, patch, now)
+}
+
+// Delete delegates to the wrapped repository
+func (r *EncryptedTaskRepository) Delete(ctx context.Context, id string) error {
+	return r.inner.Delete(ctx, id)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/storage"
+)
+
+// RetryPolicy controls how RetryTaskRepository retries busy writes
+type RetryPolicy struct {
+	Attempts int           // total attempts, at least 1
+	Backoff  time.Duration // delay before the first retry, doubled after each one
+}
+
+// RetryTaskRepository is a TaskRepository decorator that retries writes
+// failing with SQLITE_BUSY or SQLITE_LOCKED, e.g. while another process holds
+// the write lock. A busy write never commits, so retrying it is safe. Reads
+// are delegated unchanged. Writes made in a transaction of TxManager are not
+// retried: SQLite fails them at once rather than deadlock, and waiting only
+// holds the locks the other writer needs, so TxManager.WithRetry runs the
+// whole transaction again instead.
+type RetryTaskRepository struct {
+	inner  domain.TaskRepository
+	policy RetryPolicy
+	logger *slog.Logger
+}
+
+// NewRetryTaskRepository wraps inner so busy writes are retried according to policy
+func NewRetryTaskRepository(inner domain.TaskRepository, policy RetryPolicy, logger *slog.Logger) *RetryTaskRepository {
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+	return &RetryTaskRepository{
+		inner:  inner,
+		policy: policy,
+		logger: logger,
+	}
+}
+
+// retry runs op until it succeeds, fails with a non-busy error, the attempts
+// run out, or ctx is done. Within a transaction op runs once.
+func (r *RetryTaskRepository) retry(ctx context.Context, operation string, op func() error) error {
+	if inTx(ctx) {
+		return op()
+	}
+	return retryBusy(ctx, r.policy, r.logger, operation, op)
+}
+
+// retryBusy runs op until it succeeds, fails with a non-busy error, the
+// attempts of policy run out, or ctx is done
+func retryBusy(ctx context.Context, policy RetryPolicy, logger *slog.Logger, operation string, op func() error) error {
+	delay := policy.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !storage.IsBusy(err) || attempt >= policy.Attempts {
+			return err
+		}
+
+		logger.Warn("Database busy, retrying", "operation", operation, "attempt", attempt, "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Create retries the wrapped Create while the database is busy
+func (r *RetryTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	return r.retry(ctx, "Create", func() error {
+		return r.inner.Create(ctx, task)
+	})
+}
+
+// CreateBatch retries the wrapped CreateBatch while the database is busy
+func (r *RetryTaskRepository) CreateBatch(ctx context.Context, tasks []*domain.Task) error {
+	return r.retry(ctx, "CreateBatch", func() error {
+		return r.inner.CreateBatch(ctx, tasks)
+	})
+}
+
+// GetByID delegates to the wrapped repository
+func (r *RetryTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	return r.inner.GetByID(ctx, id)
+}
+
+// List delegates to the wrapped repository
+func (r *RetryTaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	return r.inner.List(ctx, filter)
+}
+
+// Stream delegates to the wrapped repository
+func (r *RetryTaskRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	return r.inner.Stream(ctx, filter, fn)
+}
+
+// Count delegates to the wrapped repository
+func (r *RetryTaskRepository) Count(ctx context.Context, filter domain.TaskFilter) (int64, error) {
+	return r.inner.Count(ctx, filter)
+}
+
+// CountByStatus delegates to the wrapped repository
+func (r *RetryTaskRepository) CountByStatus(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskStatus]int64, error) {
+	return r.inner.CountByStatus(ctx, filter)
+}
+
+// CountByPriority delegates to the wrapped repository
+func (r *RetryTaskRepository) CountByPriority(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskPriority]int64, error) {
+	return r.inner.CountByPriority(ctx, filter)
+}
+
+// EffortByContext delegates to the wrapped repository
+func (r *RetryTaskRepository) EffortByContext(ctx context.Context, filter domain.TaskFilter) (map[string]domain.Effort, error) {
+	return r.inner.EffortByContext(ctx, filter)
+}
+
+// Update retries the wrapped Update while the database is busy
+func (r *RetryTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	return r.retry(ctx, "Update", func() error {
+		return r.inner.Update(ctx, task)
+	})
+}
+
+// UpdateWhere retries the wrapped UpdateWhere while the database is busy
+func (r *RetryTaskRepository) UpdateWhere(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch, now time.Time) (int64, error) {
+	var n int64
+	err := r.retry(ctx, "UpdateWhere", func() error {
+		var err error
+		n, err = r.inner.UpdateWhere(ctx, filter, patch, now)
+		return err
+	})
+	return n, err
+}
+
+// Delete retries the wrapped Delete while the database is busy
+func (r *RetryTaskRepository) Delete(ctx context.Context, id string) error {
+	return r.retry(ctx, "Delete", func() error {
+		return r.inner.Delete(ctx, id)
+	})
+}
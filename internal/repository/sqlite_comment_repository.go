@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// SQLiteCommentRepository implements CommentRepository for SQLite database.
+// Comments are removed automatically when their task is deleted.
+type SQLiteCommentRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteCommentRepository creates a new SQLite comment repository
+func NewSQLiteCommentRepository(db *sql.DB, logger *slog.Logger) *SQLiteCommentRepository {
+	return &SQLiteCommentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Add records a comment
+func (r *SQLiteCommentRepository) Add(ctx context.Context, comment *domain.Comment) error {
+	query := `
+		INSERT INTO task_comments (id, task_id, author, body, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, comment.ID, comment.TaskID, comment.Author, comment.Body, comment.CreatedAt); err != nil {
+		r.logger.Error("Failed to add comment", "error", err, "task_id", comment.TaskID)
+		return fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	r.logger.Debug("Comment added", "task_id", comment.TaskID, "author", comment.Author)
+	return nil
+}
+
+// ListByTask retrieves the comments on a task, oldest first
+func (r *SQLiteCommentRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.Comment, error) {
+	query := `
+		SELECT id, task_id, author, body, created_at FROM task_comments
+		WHERE task_id = ?
+		ORDER BY created_at, id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, taskID)
+	if err != nil {
+		r.logger.Error("Failed to list comments", "error", err, "task_id", taskID)
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*domain.Comment
+	for rows.Next() {
+		comment := &domain.Comment{}
+		if err := rows.Scan(&comment.ID, &comment.TaskID, &comment.Author, &comment.Body, &comment.CreatedAt); err != nil {
+			r.logger.Error("Failed to scan comment", "error", err)
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating comments", "error", err)
+		return nil, fmt.Errorf("error iterating comments: %w", err)
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// externalRefColumns lists the external_refs columns in the order expected by scanExternalRef.
+const externalRefColumns = "provider, remote_id, task_id, etag, remote_updated_at, created_at, updated_at"
+
+// scanExternalRef reads a single external reference row selected with externalRefColumns.
+func scanExternalRef(row rowScanner) (*domain.ExternalRef, error) {
+	ref := &domain.ExternalRef{}
+	var remoteUpdatedAt sql.NullTime
+
+	err := row.Scan(
+		&ref.Provider,
+		&ref.RemoteID,
+		&ref.TaskID,
+		&ref.ETag,
+		&remoteUpdatedAt,
+		&ref.CreatedAt,
+		&ref.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if remoteUpdatedAt.Valid {
+		ref.RemoteUpdatedAt = &remoteUpdatedAt.Time
+	}
+
+	return ref, nil
+}
+
+// SQLiteExternalRefRepository implements ExternalRefRepository for SQLite database.
+// References are keyed by (provider, remote_id) and removed automatically
+// when the linked task is deleted.
+type SQLiteExternalRefRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteExternalRefRepository creates a new SQLite external reference repository
+func NewSQLiteExternalRefRepository(db *sql.DB, logger *slog.Logger) *SQLiteExternalRefRepository {
+	return &SQLiteExternalRefRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Get retrieves the reference for a remote item
+func (r *SQLiteExternalRefRepository) Get(ctx context.Context, provider, remoteID string) (*domain.ExternalRef, error) {
+	query := "SELECT " + externalRefColumns + " FROM external_refs WHERE provider = ? AND remote_id = ?"
+
+	ref, err := scanExternalRef(conn(ctx, r.db).QueryRowContext(ctx, query, provider, remoteID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrExternalRefNotFound
+		}
+		r.logger.Error("Failed to get external reference", "error", err, "provider", provider, "remote_id", remoteID)
+		return nil, fmt.Errorf("failed to get external reference: %w", err)
+	}
+
+	return ref, nil
+}
+
+// GetByTask retrieves the reference linking a task to the given provider
+func (r *SQLiteExternalRefRepository) GetByTask(ctx context.Context, provider, taskID string) (*domain.ExternalRef, error) {
+	query := "SELECT " + externalRefColumns + " FROM external_refs WHERE provider = ? AND task_id = ?"
+
+	ref, err := scanExternalRef(conn(ctx, r.db).QueryRowContext(ctx, query, provider, taskID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrExternalRefNotFound
+		}
+		r.logger.Error("Failed to get external reference", "error", err, "provider", provider, "task_id", taskID)
+		return nil, fmt.Errorf("failed to get external reference: %w", err)
+	}
+
+	return ref, nil
+}
+
+// ListByTask retrieves all references for a task across providers
+func (r *SQLiteExternalRefRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.ExternalRef, error) {
+	query := "SELECT " + externalRefColumns + " FROM external_refs WHERE task_id = ? ORDER BY provider"
+	return r.list(ctx, query, taskID)
+}
+
+// ListByProvider retrieves all references for a provider
+func (r *SQLiteExternalRefRepository) ListByProvider(ctx context.Context, provider string) ([]*domain.ExternalRef, error) {
+	query := "SELECT " + externalRefColumns + " FROM external_refs WHERE provider = ? ORDER BY remote_id"
+	return r.list(ctx, query, provider)
+}
+
+// list runs a query returning external reference rows
+func (r *SQLiteExternalRefRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.ExternalRef, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to list external references", "error", err)
+		return nil, fmt.Errorf("failed to list external references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []*domain.ExternalRef
+	for rows.Next() {
+		ref, err := scanExternalRef(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan external reference", "error", err)
+			return nil, fmt.Errorf("failed to scan external reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating external references", "error", err)
+		return nil, fmt.Errorf("error iterating external references: %w", err)
+	}
+
+	return refs, nil
+}
+
+// Upsert inserts a reference or updates the task, etag, and remote timestamp of an existing one
+func (r *SQLiteExternalRefRepository) Upsert(ctx context.Context, ref *domain.ExternalRef) error {
+	query := `
+		INSERT INTO external_refs (provider, remote_id, task_id, etag, remote_updated_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (provider, remote_id) DO UPDATE SET
+			task_id = excluded.task_id,
+			etag = excluded.etag,
+			remote_updated_at = excluded.remote_updated_at,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		ref.Provider,
+		ref.RemoteID,
+		ref.TaskID,
+		ref.ETag,
+		ref.RemoteUpdatedAt,
+		ref.CreatedAt,
+		ref.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to upsert external reference", "error", err, "provider", ref.Provider, "remote_id", ref.RemoteID)
+		return fmt.Errorf("failed to upsert external reference: %w", err)
+	}
+
+	r.logger.Debug("External reference saved", "provider", ref.Provider, "remote_id", ref.RemoteID, "task_id", ref.TaskID)
+	return nil
+}
+
+// Delete removes the reference for a remote item
+func (r *SQLiteExternalRefRepository) Delete(ctx context.Context, provider, remoteID string) error {
+	query := "DELETE FROM external_refs WHERE provider = ? AND remote_id = ?"
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, provider, remoteID)
+	if err != nil {
+		r.logger.Error("Failed to delete external reference", "error", err, "provider", provider, "remote_id", remoteID)
+		return fmt.Errorf("failed to delete external reference: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrExternalRefNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// SQLiteLinkRepository implements LinkRepository for SQLite database.
+// Links are removed automatically when the task they were created from is
+// deleted; the task repository removes links pointing at a deleted task.
+type SQLiteLinkRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteLinkRepository creates a new SQLite link repository
+func NewSQLiteLinkRepository(db *sql.DB, logger *slog.Logger) *SQLiteLinkRepository {
+	return &SQLiteLinkRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Add records a link. Adding a link that already exists does nothing.
+func (r *SQLiteLinkRepository) Add(ctx context.Context, link *domain.Link) error {
+	query := `
+		INSERT INTO task_links (task_id, kind, target, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (task_id, kind, target) DO NOTHING
+	`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, link.TaskID, link.Kind, link.Target, link.CreatedAt); err != nil {
+		r.logger.Error("Failed to add link", "error", err, "task_id", link.TaskID, "target", link.Target)
+		return fmt.Errorf("failed to add link: %w", err)
+	}
+
+	r.logger.Debug("Link added", "task_id", link.TaskID, "kind", link.Kind, "target", link.Target)
+	return nil
+}
+
+// Remove deletes every link between a task and target, in either direction
+func (r *SQLiteLinkRepository) Remove(ctx context.Context, taskID, target string) error {
+	query := "DELETE FROM task_links WHERE (task_id = ? AND target = ?) OR (task_id = ? AND target = ?)"
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, taskID, target, target, taskID)
+	if err != nil {
+		r.logger.Error("Failed to remove link", "error", err, "task_id", taskID, "target", target)
+		return fmt.Errorf("failed to remove link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+// ListByTask retrieves the links from a task and the links to it, oldest first
+func (r *SQLiteLinkRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.Link, error) {
+	query := `
+		SELECT task_id, kind, target, created_at FROM task_links
+		WHERE task_id = ? OR target = ?
+		ORDER BY created_at, task_id, target
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, taskID, taskID)
+	if err != nil {
+		r.logger.Error("Failed to list links", "error", err, "task_id", taskID)
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*domain.Link
+	for rows.Next() {
+		link := &domain.Link{}
+		if err := rows.Scan(&link.TaskID, &link.Kind, &link.Target, &link.CreatedAt); err != nil {
+			r.logger.Error("Failed to scan link", "error", err)
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating links", "error", err)
+		return nil, fmt.Errorf("error iterating links: %w", err)
+	}
+
+	return links, nil
+}
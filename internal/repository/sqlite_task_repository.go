@@ -6,13 +6,102 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/edson-mazvila/task-manager/internal/domain"
 )
 
+// taskColumns lists the task columns in the order expected by scanTask.
+// User-defined fields are gathered from task_fields into a JSON object.
+const taskColumns = "id, title, description, status, priority, created_at, updated_at, completed_at, context, assignee, created_by, wait_until, pinned, estimate, points, location, metadata, last_activity_at, " +
+	"(SELECT json_group_object(name, value) FROM task_fields WHERE task_id = tasks.id)"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask reads a single task row selected with taskColumns.
+func scanTask(row rowScanner) (*domain.Task, error) {
+	task := &domain.Task{}
+	var completedAt, waitUntil, lastActivity sql.NullTime
+	var estimate int64
+	var metadata, fields sql.NullString
+
+	err := row.Scan(
+		&task.ID,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.Priority,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&completedAt,
+		&task.Context,
+		&task.Assignee,
+		&task.CreatedBy,
+		&waitUntil,
+		&task.Pinned,
+		&estimate,
+		&task.Points,
+		&task.Location,
+		&metadata,
+		&lastActivity,
+		&fields,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+
+	if waitUntil.Valid {
+		task.WaitUntil = &waitUntil.Time
+	}
+
+	task.Estimate = time.Duration(estimate) * time.Second
+
+	// Maintained by triggers; fall back to the last update when unset
+	task.LastActivityAt = task.UpdatedAt
+	if lastActivity.Valid {
+		task.LastActivityAt = lastActivity.Time
+	}
+
+	if metadata.Valid && metadata.String != "" {
+		if err := json.Unmarshal([]byte(metadata.String), &task.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode task metadata: %w", err)
+		}
+	}
+
+	if fields.Valid && fields.String != "{}" {
+		if err := json.Unmarshal([]byte(fields.String), &task.Fields); err != nil {
+			return nil, fmt.Errorf("failed to decode task fields: %w", err)
+		}
+	}
+
+	return task, nil
+}
+
+// encodeMetadata serializes task metadata for storage, using an empty object when unset.
+func encodeMetadata(m domain.Metadata) (string, error) {
+	if len(m) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode task metadata: %w", err)
+	}
+	return string(data), nil
+}
+
 // SQLiteTaskRepository implements TaskRepository interface for SQLite database.
 // It provides CRUD operations with transaction support and proper error handling.
 // All SQL queries use parameterized statements to prevent SQL injection.
@@ -29,16 +118,79 @@ func NewSQLiteTaskRepository(db *sql.DB, logger *slog.Logger) *SQLiteTaskReposit
 	}
 }
 
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 // Create inserts a new task into the database.
 // Uses parameterized queries to prevent SQL injection and ensure data safety.
 // All timestamps are stored in UTC format for consistency across time zones.
+// Returns ErrDuplicateTask if a task with the same ID exists.
 func (r *SQLiteTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	tx, err := beginWrite(ctx, r.db)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.insert(ctx, tx, task); err != nil {
+		if errors.Is(err, domain.ErrDuplicateTask) {
+			return err
+		}
+		r.logger.Error("Failed to create task", "error", err, "task_id", task.ID)
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit create", "error", err)
+		return fmt.Errorf("failed to commit create: %w", err)
+	}
+
+	r.logger.Info("Task created", "task_id", task.ID)
+	return nil
+}
+
+// CreateBatch inserts several tasks in a single transaction.
+// Either all tasks are created or, on error, none are.
+func (r *SQLiteTaskRepository) CreateBatch(ctx context.Context, tasks []*domain.Task) error {
+	tx, err := beginWrite(ctx, r.db)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	for _, task := range tasks {
+		if err := r.insert(ctx, tx, task); err != nil {
+			tx.Rollback()
+			r.logger.Error("Failed to create task in batch", "error", err, "task_id", task.ID)
+			return fmt.Errorf("failed to create task %q: %w", task.Title, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit batch", "error", err)
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	r.logger.Info("Tasks created", "count", len(tasks))
+	return nil
+}
+
+// insert writes a single task row using db, which may be a transaction
+func (r *SQLiteTaskRepository) insert(ctx context.Context, db execer, task *domain.Task) error {
+	metadata, err := encodeMetadata(task.Metadata)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO tasks (id, title, description, status, priority, created_at, updated_at, completed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO tasks (id, title, description, status, priority, created_at, updated_at, completed_at, context, assignee, created_by, wait_until, pinned, estimate, points, location, metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(
+	_, err = db.ExecContext(
 		ctx,
 		query,
 		task.ID,
@@ -49,39 +201,50 @@ func (r *SQLiteTaskRepository) Create(ctx context.Context, task *domain.Task) er
 		task.CreatedAt,
 		task.UpdatedAt,
 		task.CompletedAt,
+		task.Context,
+		task.Assignee,
+		task.CreatedBy,
+		task.WaitUntil,
+		task.Pinned,
+		int64(task.Estimate/time.Second),
+		task.Points,
+		task.Location,
+		metadata,
 	)
-
 	if err != nil {
-		r.logger.Error("Failed to create task", "error", err, "task_id", task.ID)
-		return fmt.Errorf("failed to create task: %w", err)
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: tasks.id") {
+			return fmt.Errorf("task %s: %w", task.ID, domain.ErrDuplicateTask)
+		}
+		return err
 	}
 
-	r.logger.Info("Task created", "task_id", task.ID)
+	return putFields(ctx, db, task.ID, task.Fields)
+}
+
+// putFields sets user-defined fields of a task; empty values remove them
+func putFields(ctx context.Context, db execer, taskID string, fields map[string]string) error {
+	for name, value := range fields {
+		var err error
+		if value == "" {
+			_, err = db.ExecContext(ctx, "DELETE FROM task_fields WHERE task_id = ? AND name = ?", taskID, name)
+		} else {
+			_, err = db.ExecContext(ctx, `
+				INSERT INTO task_fields (task_id, name, value) VALUES (?, ?, ?)
+				ON CONFLICT (task_id, name) DO UPDATE SET value = excluded.value
+			`, taskID, name, value)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set field %s: %w", name, err)
+		}
+	}
 	return nil
 }
 
 // GetByID retrieves a task by its ID
 func (r *SQLiteTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
-	query := `
-		SELECT id, title, description, status, priority, created_at, updated_at, completed_at
-		FROM tasks
-		WHERE id = ?
-	`
-
-	task := &domain.Task{}
-	var completedAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.Priority,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-		&completedAt,
-	)
+	query := "SELECT " + taskColumns + " FROM tasks WHERE id = ?"
 
+	task, err := scanTask(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrTaskNotFound
@@ -90,16 +253,22 @@ func (r *SQLiteTaskRepository) GetByID(ctx context.Context, id string) (*domain.
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
-	if completedAt.Valid {
-		task.CompletedAt = &completedAt.Time
-	}
-
 	return task, nil
 }
 
-// List retrieves tasks based on filter criteria
-func (r *SQLiteTaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
-	query := "SELECT id, title, description, status, priority, created_at, updated_at, completed_at FROM tasks WHERE 1=1"
+// dueColumn is the SQL counterpart of Task.DueDate: the first valid
+// YYYY-MM-DD date under domain.DueDateKeys in the metadata, or NULL
+var dueColumn = func() string {
+	dates := make([]string, len(domain.DueDateKeys))
+	for i, key := range domain.DueDateKeys {
+		dates[i] = `date(substr(ltrim(json_extract(CASE WHEN json_valid(metadata) THEN metadata END, '$."` + key + `"'), '<['), 1, 10))`
+	}
+	return "COALESCE(" + strings.Join(dates, ", ") + ")"
+}()
+
+// whereClause builds the WHERE clause and arguments selecting tasks that match filter
+func whereClause(filter domain.TaskFilter) (string, []interface{}) {
+	query := " WHERE 1=1"
 	args := []interface{}{}
 
 	if filter.Status != nil {
@@ -112,6 +281,53 @@ func (r *SQLiteTaskRepository) List(ctx context.Context, filter domain.TaskFilte
 		args = append(args, *filter.Priority)
 	}
 
+	if filter.Context != nil {
+		query += " AND context = ?"
+		args = append(args, *filter.Context)
+	}
+
+	if filter.Assignee != nil {
+		query += " AND assignee = ?"
+		args = append(args, *filter.Assignee)
+	}
+
+	if filter.CreatedBy != nil {
+		query += " AND created_by = ?"
+		args = append(args, *filter.CreatedBy)
+	}
+
+	if filter.Owner != nil && *filter.Owner == "" {
+		query += " AND 0"
+	} else if filter.Owner != nil {
+		query += " AND (created_by = ? OR assignee = ?)"
+		args = append(args, *filter.Owner, *filter.Owner)
+	}
+
+	if filter.Pinned != nil {
+		query += " AND pinned = ?"
+		args = append(args, *filter.Pinned)
+	}
+
+	if filter.AwakeAt != nil {
+		query += " AND (status != 'pending' OR wait_until IS NULL OR wait_until <= ?)"
+		args = append(args, *filter.AwakeAt)
+	}
+
+	if filter.DueBefore != nil {
+		query += " AND " + dueColumn + " < ?"
+		args = append(args, filter.DueBefore.Format(time.DateOnly))
+	}
+
+	if filter.IdleSince != nil {
+		query += " AND last_activity_at < ?"
+		args = append(args, *filter.IdleSince)
+	}
+
+	if filter.Location != nil {
+		query += " AND (location = '' OR location = ?)"
+		args = append(args, *filter.Location)
+	}
+
 	if filter.FromDate != nil {
 		query += " AND created_at >= ?"
 		args = append(args, *filter.FromDate)
@@ -122,9 +338,160 @@ func (r *SQLiteTaskRepository) List(ctx context.Context, filter domain.TaskFilte
 		args = append(args, *filter.ToDate)
 	}
 
-	query += " ORDER BY created_at DESC"
+	if filter.Query != nil {
+		clause, queryArgs := queryClause(filter.Query)
+		query += " AND " + clause
+		args = append(args, queryArgs...)
+	}
+
+	return query, args
+}
+
+// queryColumns maps query fields to task columns
+var queryColumns = map[domain.QueryField]string{
+	domain.QueryFieldID:          "id",
+	domain.QueryFieldTitle:       "title",
+	domain.QueryFieldDescription: "description",
+	domain.QueryFieldStatus:      "status",
+	domain.QueryFieldPriority:    "priority",
+	domain.QueryFieldContext:     "context",
+	domain.QueryFieldAssignee:    "assignee",
+	domain.QueryFieldCreatedBy:   "created_by",
+	domain.QueryFieldCreated:     "created_at",
+	domain.QueryFieldUpdated:     "updated_at",
+	domain.QueryFieldCompleted:   "completed_at",
+	domain.QueryFieldDue:         dueColumn,
+}
+
+// priorityRank orders priorities so they can be compared with < and >
+const priorityRank = "(CASE priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 END)"
+
+// queryClause translates a parsed query expression into a parenthesized SQL
+// condition and its arguments
+func queryClause(expr domain.QueryExpr) (string, []interface{}) {
+	switch e := expr.(type) {
+	case *domain.QueryAnd:
+		left, leftArgs := queryClause(e.Left)
+		right, rightArgs := queryClause(e.Right)
+		return "(" + left + " AND " + right + ")", append(leftArgs, rightArgs...)
+
+	case *domain.QueryOr:
+		left, leftArgs := queryClause(e.Left)
+		right, rightArgs := queryClause(e.Right)
+		return "(" + left + " OR " + right + ")", append(leftArgs, rightArgs...)
+
+	case *domain.QueryNot:
+		inner, args := queryClause(e.Expr)
+		return "(NOT " + inner + ")", args
+
+	case *domain.QueryCond:
+		return condClause(e)
+	}
+
+	// Unknown expressions match nothing rather than everything
+	return "(1=0)", nil
+}
+
+// condClause translates a single comparison
+func condClause(cond *domain.QueryCond) (string, []interface{}) {
+	if cond.Field == domain.QueryFieldCustom {
+		return fieldClause(cond)
+	}
+
+	column, ok := queryColumns[cond.Field]
+	if !ok {
+		return "(1=0)", nil
+	}
+
+	switch cond.Field {
+	case domain.QueryFieldCreated, domain.QueryFieldUpdated, domain.QueryFieldCompleted:
+		// Dates compare whole days: [start, next)
+		start, next := cond.Date, cond.Date.AddDate(0, 0, 1)
+		switch cond.Op {
+		case domain.QueryLess:
+			return "(" + column + " < ?)", []interface{}{start}
+		case domain.QueryLessEqual:
+			return "(" + column + " < ?)", []interface{}{next}
+		case domain.QueryGreater:
+			return "(" + column + " >= ?)", []interface{}{next}
+		case domain.QueryGreaterEqual:
+			return "(" + column + " >= ?)", []interface{}{start}
+		case domain.QueryNotEqual:
+			return "(" + column + " IS NULL OR " + column + " < ? OR " + column + " >= ?)", []interface{}{start, next}
+		default:
+			return "(" + column + " >= ? AND " + column + " < ?)", []interface{}{start, next}
+		}
+
+	case domain.QueryFieldDue:
+		// Due dates are YYYY-MM-DD days, which order like the dates. Tasks
+		// without one only match !=.
+		day := cond.Date.Format(time.DateOnly)
+		switch cond.Op {
+		case domain.QueryMatch, domain.QueryEqual:
+			return "(" + column + " = ?)", []interface{}{day}
+		case domain.QueryNotEqual:
+			return "(" + column + " IS NULL OR " + column + " != ?)", []interface{}{day}
+		default:
+			return "(" + column + " " + string(cond.Op) + " ?)", []interface{}{day}
+		}
+
+	case domain.QueryFieldPriority:
+		switch cond.Op {
+		case domain.QueryLess, domain.QueryLessEqual, domain.QueryGreater, domain.QueryGreaterEqual:
+			return "(" + priorityRank + " " + string(cond.Op) + " " +
+				strings.Replace(priorityRank, "priority", "?", 1) + ")", []interface{}{cond.Value}
+		}
+
+	case domain.QueryFieldTitle, domain.QueryFieldDescription:
+		if cond.Op == domain.QueryMatch {
+			return "(" + column + " LIKE ? ESCAPE '\\')", []interface{}{"%" + escapeLike(cond.Value) + "%"}
+		}
+
+	case domain.QueryFieldID:
+		if cond.Op == domain.QueryMatch {
+			return "(" + column + " LIKE ? ESCAPE '\\')", []interface{}{escapeLike(cond.Value) + "%"}
+		}
+	}
+
+	if cond.Op == domain.QueryNotEqual {
+		return "(" + column + " != ?)", []interface{}{cond.Value}
+	}
+	return "(" + column + " = ?)", []interface{}{cond.Value}
+}
+
+// fieldClause translates a comparison on a user-defined field. Tasks without
+// the field only match !=.
+func fieldClause(cond *domain.QueryCond) (string, []interface{}) {
+	exists := "EXISTS (SELECT 1 FROM task_fields WHERE task_id = tasks.id AND name = ? AND "
+	args := []interface{}{cond.Name}
+
+	switch {
+	case cond.Op == domain.QueryNotEqual:
+		return "(NOT " + exists + "value = ?))", append(args, cond.Value)
+	case cond.Op == domain.QueryMatch && cond.Type == domain.FieldTypeString:
+		return "(" + exists + "value LIKE ? ESCAPE '\\'))", append(args, "%"+escapeLike(cond.Value)+"%")
+	case cond.Op == domain.QueryMatch || cond.Op == domain.QueryEqual:
+		return "(" + exists + "value = ?))", append(args, cond.Value)
+	case cond.Type == domain.FieldTypeNumber:
+		n, _ := strconv.ParseFloat(cond.Value, 64)
+		return "(" + exists + "CAST(value AS REAL) " + string(cond.Op) + " ?))", append(args, n)
+	default:
+		// Dates are stored as YYYY-MM-DD, which orders like the dates
+		return "(" + exists + "value " + string(cond.Op) + " ?))", append(args, cond.Value)
+	}
+}
+
+// escapeLike escapes LIKE wildcards so values match literally
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// List retrieves tasks based on filter criteria, pinned tasks first, then newest first
+func (r *SQLiteTaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	where, args := whereClause(filter)
+	query := "SELECT " + taskColumns + " FROM tasks" + where + " ORDER BY pinned DESC, created_at DESC"
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("Failed to list tasks", "error", err)
 		return nil, fmt.Errorf("failed to list tasks: %w", err)
@@ -133,29 +500,12 @@ func (r *SQLiteTaskRepository) List(ctx context.Context, filter domain.TaskFilte
 
 	var tasks []*domain.Task
 	for rows.Next() {
-		task := &domain.Task{}
-		var completedAt sql.NullTime
-
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-			&completedAt,
-		)
-
+		task, err := scanTask(rows)
 		if err != nil {
 			r.logger.Error("Failed to scan task", "error", err)
 			return nil, fmt.Errorf("failed to scan task: %w", err)
 		}
 
-		if completedAt.Valid {
-			task.CompletedAt = &completedAt.Time
-		}
-
 		tasks = append(tasks, task)
 	}
 
@@ -167,6 +517,144 @@ func (r *SQLiteTaskRepository) List(ctx context.Context, filter domain.TaskFilte
 	return tasks, nil
 }
 
+// Stream calls fn for each task matching filter, in ID order, reading rows
+// one at a time instead of loading the whole result set. Iteration stops at
+// the first error returned by fn, which Stream returns unchanged.
+func (r *SQLiteTaskRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	where, args := whereClause(filter)
+	query := "SELECT " + taskColumns + " FROM tasks" + where + " ORDER BY id"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to stream tasks", "error", err)
+		return fmt.Errorf("failed to stream tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan task", "error", err)
+			return fmt.Errorf("failed to scan task: %w", err)
+		}
+
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating tasks", "error", err)
+		return fmt.Errorf("error iterating tasks: %w", err)
+	}
+
+	return nil
+}
+
+// Count returns the number of tasks matching filter
+func (r *SQLiteTaskRepository) Count(ctx context.Context, filter domain.TaskFilter) (int64, error) {
+	where, args := whereClause(filter)
+
+	var n int64
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks"+where, args...).Scan(&n); err != nil {
+		r.logger.Error("Failed to count tasks", "error", err)
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	return n, nil
+}
+
+// CountByStatus returns the number of tasks matching filter for each status.
+// Statuses without tasks are omitted.
+func (r *SQLiteTaskRepository) CountByStatus(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskStatus]int64, error) {
+	counts, err := r.countBy(ctx, "status", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byStatus := make(map[domain.TaskStatus]int64, len(counts))
+	for status, n := range counts {
+		byStatus[domain.TaskStatus(status)] = n
+	}
+	return byStatus, nil
+}
+
+// CountByPriority returns the number of tasks matching filter for each priority.
+// Priorities without tasks are omitted.
+func (r *SQLiteTaskRepository) CountByPriority(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskPriority]int64, error) {
+	counts, err := r.countBy(ctx, "priority", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byPriority := make(map[domain.TaskPriority]int64, len(counts))
+	for priority, n := range counts {
+		byPriority[domain.TaskPriority(priority)] = n
+	}
+	return byPriority, nil
+}
+
+// EffortByContext totals the tasks matching filter, with their estimates
+// and points, for each context. Tasks without a context are under "".
+func (r *SQLiteTaskRepository) EffortByContext(ctx context.Context, filter domain.TaskFilter) (map[string]domain.Effort, error) {
+	where, args := whereClause(filter)
+	query := "SELECT context, COUNT(*), SUM(estimate), SUM(points) FROM tasks" + where + " GROUP BY context"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to sum task effort", "error", err)
+		return nil, fmt.Errorf("failed to sum task effort: %w", err)
+	}
+	defer rows.Close()
+
+	efforts := make(map[string]domain.Effort)
+	for rows.Next() {
+		var name string
+		var effort domain.Effort
+		var seconds int64
+		if err := rows.Scan(&name, &effort.Tasks, &seconds, &effort.Points); err != nil {
+			return nil, fmt.Errorf("failed to scan effort: %w", err)
+		}
+		effort.Estimate = time.Duration(seconds) * time.Second
+		efforts[name] = effort
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating effort: %w", err)
+	}
+
+	return efforts, nil
+}
+
+// countBy counts tasks matching filter grouped by column
+func (r *SQLiteTaskRepository) countBy(ctx context.Context, column string, filter domain.TaskFilter) (map[string]int64, error) {
+	where, args := whereClause(filter)
+	query := "SELECT " + column + ", COUNT(*) FROM tasks" + where + " GROUP BY " + column
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to count tasks", "column", column, "error", err)
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var value string
+		var n int64
+		if err := rows.Scan(&value, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan count: %w", err)
+		}
+		counts[value] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating counts: %w", err)
+	}
+
+	return counts, nil
+}
+
 // Update updates an existing task
 func (r *SQLiteTaskRepository) Update(ctx context.Context, task *domain.Task) error {
 	// First check if the task exists
@@ -179,13 +667,25 @@ func (r *SQLiteTaskRepository) Update(ctx context.Context, task *domain.Task) er
 		return domain.ErrTaskNotFound
 	}
 
+	metadata, err := encodeMetadata(task.Metadata)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE tasks
-		SET title = ?, description = ?, status = ?, priority = ?, updated_at = ?, completed_at = ?
+		SET title = ?, description = ?, status = ?, priority = ?, updated_at = ?, completed_at = ?, context = ?, assignee = ?, wait_until = ?, pinned = ?, estimate = ?, points = ?, location = ?, metadata = ?
 		WHERE id = ?
 	`
 
-	result, err := r.db.ExecContext(
+	tx, err := beginWrite(ctx, r.db)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
 		ctx,
 		query,
 		task.Title,
@@ -194,6 +694,14 @@ func (r *SQLiteTaskRepository) Update(ctx context.Context, task *domain.Task) er
 		task.Priority,
 		task.UpdatedAt,
 		task.CompletedAt,
+		task.Context,
+		task.Assignee,
+		task.WaitUntil,
+		task.Pinned,
+		int64(task.Estimate/time.Second),
+		task.Points,
+		task.Location,
+		metadata,
 		task.ID,
 	)
 
@@ -212,15 +720,150 @@ func (r *SQLiteTaskRepository) Update(ctx context.Context, task *domain.Task) er
 		return domain.ErrTaskNotFound
 	}
 
+	// The task's fields replace the stored ones
+	if _, err := tx.ExecContext(ctx, "DELETE FROM task_fields WHERE task_id = ?", task.ID); err != nil {
+		r.logger.Error("Failed to update task fields", "error", err, "task_id", task.ID)
+		return fmt.Errorf("failed to update task fields: %w", err)
+	}
+	if err := putFields(ctx, tx, task.ID, task.Fields); err != nil {
+		r.logger.Error("Failed to update task fields", "error", err, "task_id", task.ID)
+		return fmt.Errorf("failed to update task fields: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit update", "error", err)
+		return fmt.Errorf("failed to commit update: %w", err)
+	}
+
 	r.logger.Info("Task updated", "task_id", task.ID)
 	return nil
 }
 
-// Delete deletes a task by its ID
+// UpdateWhere applies patch to every task matching filter in a single
+// statement, so either all matching tasks change or none do. It returns the
+// number of tasks updated.
+func (r *SQLiteTaskRepository) UpdateWhere(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch, now time.Time) (int64, error) {
+	if patch.IsEmpty() {
+		return 0, nil
+	}
+
+	set := []string{"updated_at = ?"}
+	args := []interface{}{now}
+
+	if patch.Description != nil {
+		set = append(set, "description = ?")
+		args = append(args, *patch.Description)
+	}
+	if patch.Status != nil {
+		// Column references on the right-hand side see the old row, so
+		// completed_at is only touched when the status actually changes
+		set = append(set,
+			"completed_at = CASE WHEN status = ? THEN completed_at WHEN ? = 'completed' THEN ? ELSE NULL END",
+			"status = ?",
+		)
+		args = append(args, *patch.Status, *patch.Status, now, *patch.Status)
+	}
+	if patch.Priority != nil {
+		set = append(set, "priority = ?")
+		args = append(args, *patch.Priority)
+	}
+	if patch.Context != nil {
+		set = append(set, "context = ?")
+		args = append(args, *patch.Context)
+	}
+	if patch.Assignee != nil {
+		set = append(set, "assignee = ?")
+		args = append(args, *patch.Assignee)
+	}
+	if patch.Due != nil {
+		// Only the user's due date changes; those kept by integrations stay
+		metadata := `CASE WHEN json_valid(metadata) THEN metadata ELSE '{}' END`
+		path := `'$."` + domain.DueKey + `"'`
+		if *patch.Due == "" {
+			set = append(set, "metadata = json_remove("+metadata+", "+path+")")
+		} else {
+			set = append(set, "metadata = json_set("+metadata+", "+path+", ?)")
+			args = append(args, *patch.Due)
+		}
+	}
+
+	where, whereArgs := whereClause(filter)
+	query := "UPDATE tasks SET " + strings.Join(set, ", ") + where
+
+	tx, err := beginWrite(ctx, r.db)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Select the tasks before updating them, since the update may change
+	// whether they match the filter
+	var ids []string
+	if len(patch.Fields) > 0 {
+		if ids, err = selectIDs(ctx, tx, where, whereArgs); err != nil {
+			r.logger.Error("Failed to update tasks", "error", err)
+			return 0, fmt.Errorf("failed to update tasks: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, query, append(args, whereArgs...)...)
+	if err != nil {
+		r.logger.Error("Failed to update tasks", "error", err)
+		return 0, fmt.Errorf("failed to update tasks: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err)
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := putFields(ctx, tx, id, patch.Fields); err != nil {
+			r.logger.Error("Failed to update task fields", "error", err, "task_id", id)
+			return 0, fmt.Errorf("failed to update task fields: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit update", "error", err)
+		return 0, fmt.Errorf("failed to commit update: %w", err)
+	}
+
+	r.logger.Info("Tasks updated", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+// selectIDs returns the IDs of the tasks matching a WHERE clause
+func selectIDs(ctx context.Context, tx dbtx, where string, args []interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM tasks"+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Delete deletes a task by its ID and records a tombstone for sync
 func (r *SQLiteTaskRepository) Delete(ctx context.Context, id string) error {
-	query := "DELETE FROM tasks WHERE id = ?"
+	tx, err := beginWrite(ctx, r.db)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := tx.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id)
 	if err != nil {
 		r.logger.Error("Failed to delete task", "error", err, "task_id", id)
 		return fmt.Errorf("failed to delete task: %w", err)
@@ -236,6 +879,98 @@ func (r *SQLiteTaskRepository) Delete(ctx context.Context, id string) error {
 		return domain.ErrTaskNotFound
 	}
 
+	if err := deleteLinksTo(ctx, tx, id); err != nil {
+		r.logger.Error("Failed to delete task links", "error", err, "task_id", id)
+		return fmt.Errorf("failed to delete task links: %w", err)
+	}
+
+	if err := putTombstone(ctx, tx, &domain.Tombstone{TaskID: id, DeletedAt: time.Now().UTC()}); err != nil {
+		r.logger.Error("Failed to record tombstone", "error", err, "task_id", id)
+		return fmt.Errorf("failed to record tombstone: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit delete", "error", err)
+		return fmt.Errorf("failed to commit delete: %w", err)
+	}
+
 	r.logger.Info("Task deleted", "task_id", id)
 	return nil
 }
+
+// ListTombstones returns every recorded task deletion
+func (r *SQLiteTaskRepository) ListTombstones(ctx context.Context) ([]*domain.Tombstone, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT task_id, deleted_at FROM tombstones ORDER BY task_id")
+	if err != nil {
+		r.logger.Error("Failed to list tombstones", "error", err)
+		return nil, fmt.Errorf("failed to list tombstones: %w", err)
+	}
+	defer rows.Close()
+
+	var tombstones []*domain.Tombstone
+	for rows.Next() {
+		t := &domain.Tombstone{}
+		if err := rows.Scan(&t.TaskID, &t.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tombstone: %w", err)
+		}
+		tombstones = append(tombstones, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tombstones: %w", err)
+	}
+
+	return tombstones, nil
+}
+
+// ApplyTombstone deletes the task if it exists and records the tombstone.
+// An existing tombstone keeps the later of the two deletion times.
+func (r *SQLiteTaskRepository) ApplyTombstone(ctx context.Context, tombstone *domain.Tombstone) error {
+	tx, err := beginWrite(ctx, r.db)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", tombstone.TaskID); err != nil {
+		r.logger.Error("Failed to delete task", "error", err, "task_id", tombstone.TaskID)
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	if err := deleteLinksTo(ctx, tx, tombstone.TaskID); err != nil {
+		r.logger.Error("Failed to delete task links", "error", err, "task_id", tombstone.TaskID)
+		return fmt.Errorf("failed to delete task links: %w", err)
+	}
+
+	if err := putTombstone(ctx, tx, tombstone); err != nil {
+		r.logger.Error("Failed to record tombstone", "error", err, "task_id", tombstone.TaskID)
+		return fmt.Errorf("failed to record tombstone: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit tombstone", "error", err)
+		return fmt.Errorf("failed to commit tombstone: %w", err)
+	}
+
+	r.logger.Info("Tombstone applied", "task_id", tombstone.TaskID)
+	return nil
+}
+
+// deleteLinksTo removes the links to a deleted task; links from it go with
+// its row
+func deleteLinksTo(ctx context.Context, db execer, id string) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM task_links WHERE target = ?", id)
+	return err
+}
+
+// putTombstone inserts or advances the tombstone for a task
+func putTombstone(ctx context.Context, db execer, tombstone *domain.Tombstone) error {
+	query := `
+		INSERT INTO tombstones (task_id, deleted_at) VALUES (?, ?)
+		ON CONFLICT (task_id) DO UPDATE SET deleted_at = MAX(deleted_at, excluded.deleted_at)
+	`
+
+	_, err := db.ExecContext(ctx, query, tombstone.TaskID, tombstone.DeletedAt.UTC())
+	return err
+}
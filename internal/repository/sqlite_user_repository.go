@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// SQLiteUserRepository implements UserRepository for SQLite database
+type SQLiteUserRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteUserRepository creates a new SQLite user repository
+func NewSQLiteUserRepository(db *sql.DB, logger *slog.Logger) *SQLiteUserRepository {
+	return &SQLiteUserRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create inserts a new user. Returns ErrDuplicateUser if the name is taken.
+func (r *SQLiteUserRepository) Create(ctx context.Context, user *domain.User) error {
+	query := "INSERT INTO users (name, email, role, created_at) VALUES (?, ?, ?, ?)"
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, user.Name, user.Email, user.Role, user.CreatedAt); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return domain.ErrDuplicateUser
+		}
+		r.logger.Error("Failed to create user", "error", err, "user", user.Name)
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	r.logger.Info("User created", "user", user.Name)
+	return nil
+}
+
+// GetByName retrieves a user by name
+func (r *SQLiteUserRepository) GetByName(ctx context.Context, name string) (*domain.User, error) {
+	query := "SELECT name, email, role, created_at FROM users WHERE name = ?"
+
+	user := &domain.User{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, name).Scan(&user.Name, &user.Email, &user.Role, &user.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		r.logger.Error("Failed to get user", "error", err, "user", name)
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// List retrieves all users ordered by name
+func (r *SQLiteUserRepository) List(ctx context.Context) ([]*domain.User, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT name, email, role, created_at FROM users ORDER BY name")
+	if err != nil {
+		r.logger.Error("Failed to list users", "error", err)
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user := &domain.User{}
+		if err := rows.Scan(&user.Name, &user.Email, &user.Role, &user.CreatedAt); err != nil {
+			r.logger.Error("Failed to scan user", "error", err)
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating users", "error", err)
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetRole changes the role of a user. Returns ErrUserNotFound if there is no such user.
+func (r *SQLiteUserRepository) SetRole(ctx context.Context, name string, role domain.Role) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET role = ? WHERE name = ?", role, name)
+	if err != nil {
+		r.logger.Error("Failed to set user role", "error", err, "user", name)
+		return fmt.Errorf("failed to set user role: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	r.logger.Info("User role changed", "user", name, "role", role)
+	return nil
+}
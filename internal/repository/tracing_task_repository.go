@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingTaskRepository is a TaskRepository decorator that records a span
+// for every call, so slow queries show up beneath the service span that
+// issued them.
+type TracingTaskRepository struct {
+	inner  domain.TaskRepository
+	tracer trace.Tracer
+}
+
+// NewTracingTaskRepository wraps inner with tracing
+func NewTracingTaskRepository(inner domain.TaskRepository) *TracingTaskRepository {
+	return &TracingTaskRepository{
+		inner:  inner,
+		tracer: tracing.Tracer("repository"),
+	}
+}
+
+// start opens a span for a repository operation
+func (r *TracingTaskRepository) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system", "sqlite"), attribute.String("db.operation", operation))
+	return r.tracer.Start(ctx, "TaskRepository."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// Create traces the wrapped Create
+func (r *TracingTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	ctx, span := r.start(ctx, "Create", attribute.String("task.id", task.ID))
+	err := r.inner.Create(ctx, task)
+	tracing.End(span, err)
+	return err
+}
+
+// CreateBatch traces the wrapped CreateBatch
+func (r *TracingTaskRepository) CreateBatch(ctx context.Context, tasks []*domain.Task) error {
+	ctx, span := r.start(ctx, "CreateBatch", attribute.Int("task.count", len(tasks)))
+	err := r.inner.CreateBatch(ctx, tasks)
+	tracing.End(span, err)
+	return err
+}
+
+// GetByID traces the wrapped GetByID
+func (r *TracingTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	ctx, span := r.start(ctx, "GetByID", attribute.String("task.id", id))
+	task, err := r.inner.GetByID(ctx, id)
+	tracing.End(span, err)
+	return task, err
+}
+
+// List traces the wrapped List
+func (r *TracingTaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	ctx, span := r.start(ctx, "List")
+	tasks, err := r.inner.List(ctx, filter)
+	span.SetAttributes(attribute.Int("task.count", len(tasks)))
+	tracing.End(span, err)
+	return tasks, err
+}
+
+// Stream traces the wrapped Stream, counting the tasks handed to fn
+func (r *TracingTaskRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	ctx, span := r.start(ctx, "Stream")
+	n := 0
+	err := r.inner.Stream(ctx, filter, func(task *domain.Task) error {
+		n++
+		return fn(task)
+	})
+	span.SetAttributes(attribute.Int("task.count", n))
+	tracing.End(span, err)
+	return err
+}
+
+// Count traces the wrapped Count
+func (r *TracingTaskRepository) Count(ctx context.Context, filter domain.TaskFilter) (int64, error) {
+	ctx, span := r.start(ctx, "Count")
+	n, err := r.inner.Count(ctx, filter)
+	span.SetAttributes(attribute.Int64("task.count", n))
+	tracing.End(span, err)
+	return n, err
+}
+
+// CountByStatus traces the wrapped CountByStatus
+func (r *TracingTaskRepository) CountByStatus(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskStatus]int64, error) {
+	ctx, span := r.start(ctx, "CountByStatus")
+	counts, err := r.inner.CountByStatus(ctx, filter)
+	tracing.End(span, err)
+	return counts, err
+}
+
+// CountByPriority traces the wrapped CountByPriority
+func (r *TracingTaskRepository) CountByPriority(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskPriority]int64, error) {
+	ctx, span := r.start(ctx, "CountByPriority")
+	counts, err := r.inner.CountByPriority(ctx, filter)
+	tracing.End(span, err)
+	return counts, err
+}
+
+// EffortByContext traces the wrapped EffortByContext
+func (r *TracingTaskRepository) EffortByContext(ctx context.Context, filter domain.TaskFilter) (map[string]domain.Effort, error) {
+	ctx, span := r.start(ctx, "EffortByContext")
+	efforts, err := r.inner.EffortByContext(ctx, filter)
+	tracing.End(span, err)
+	return efforts, err
+}
+
+// Update traces the wrapped Update
+func (r *TracingTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	ctx, span := r.start(ctx, "Update", attribute.String("task.id", task.ID))
+	err := r.inner.Update(ctx, task)
+	tracing.End(span, err)
+	return err
+}
+
+// UpdateWhere traces the wrapped UpdateWhere
+func (r *TracingTaskRepository) UpdateWhere(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch, now time.Time) (int64, error) {
+	ctx, span := r.start(ctx, "UpdateWhere")
+	n, err := r.inner.UpdateWhere(ctx, filter, patch, now)
+	span.SetAttributes(attribute.Int64("task.count", n))
+	tracing.End(span, err)
+	return n, err
+}
+
+// Delete traces the wrapped Delete
+func (r *TracingTaskRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := r.start(ctx, "Delete", attribute.String("task.id", id))
+	err := r.inner.Delete(ctx, id)
+	tracing.End(span, err)
+	return err
+}
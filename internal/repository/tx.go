@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// txKey is the context key of a transaction started by TxManager, one per
+// database, so work on two databases such as a sync keeps their
+// transactions apart
+type txKey struct {
+	db *sql.DB
+}
+
+// anyTxKey marks a context carrying a transaction on any database
+type anyTxKey struct{}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx
+type dbtx interface {
+	execer
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// TxManager implements domain.TxManager for the SQLite repositories: every
+// repository call made with the context passed to fn runs in the same
+// transaction.
+type TxManager struct {
+	db     *sql.DB
+	policy RetryPolicy
+	logger *slog.Logger
+}
+
+// NewTxManager creates a transaction manager for db
+func NewTxManager(db *sql.DB, logger *slog.Logger) *TxManager {
+	return &TxManager{
+		db:     db,
+		policy: RetryPolicy{Attempts: 1},
+		logger: logger,
+	}
+}
+
+// WithRetry makes WithTx run a transaction again from the start, according
+// to policy, when it fails because the database is busy. Rolling back
+// releases the locks another writer may be waiting for, which retrying a
+// single statement in the transaction would keep holding. fn must then be
+// safe to run more than once.
+func (m *TxManager) WithRetry(policy RetryPolicy) *TxManager {
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+	m.policy = policy
+	return m
+}
+
+// WithTx runs fn in a transaction, committed if fn returns nil and rolled
+// back otherwise. Called again from within fn, it joins the transaction
+// already running on the same database; only the outermost call retries.
+func (m *TxManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{m.db}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	return retryBusy(ctx, m.policy, m.logger, "transaction", func() error {
+		return m.run(ctx, fn)
+	})
+}
+
+// run runs fn in a new transaction
+func (m *TxManager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		m.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ctx = context.WithValue(context.WithValue(ctx, txKey{m.db}, tx), anyTxKey{}, true)
+	if err := fn(ctx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		m.logger.Error("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// inTx reports whether ctx carries a transaction started by TxManager, on
+// any database
+func inTx(ctx context.Context) bool {
+	return ctx.Value(anyTxKey{}) != nil
+}
+
+// conn returns the transaction on db that ctx carries, if any, or db
+func conn(ctx context.Context, db *sql.DB) dbtx {
+	if tx, ok := ctx.Value(txKey{db}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// writeTx is the transaction of a single repository write: its own, or the
+// one ctx carries, which Commit and Rollback then leave to its owner
+type writeTx struct {
+	*sql.Tx
+	joined bool
+}
+
+// beginWrite starts a transaction for a repository write, or joins the one
+// on db that ctx carries
+func beginWrite(ctx context.Context, db *sql.DB) (*writeTx, error) {
+	if tx, ok := ctx.Value(txKey{db}).(*sql.Tx); ok {
+		return &writeTx{Tx: tx, joined: true}, nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &writeTx{Tx: tx}, nil
+}
+
+// Commit commits the transaction unless it was joined
+func (t *writeTx) Commit() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+// Rollback rolls the transaction back unless it was joined
+func (t *writeTx) Rollback() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
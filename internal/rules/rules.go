@@ -0,0 +1,116 @@
+// Package rules turns conventions declared in config.yaml into task hooks,
+// so teams can enforce rules such as "office tasks need a description"
+// without writing Go code.
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// metadataPrefix selects a metadata key as a rule field, e.g. "metadata.github"
+const metadataPrefix = "metadata."
+
+// fields are the task fields rules can refer to, besides metadata keys
+var fields = map[string]func(*domain.Task) string{
+	"title":       func(t *domain.Task) string { return t.Title },
+	"description": func(t *domain.Task) string { return t.Description },
+	"status":      func(t *domain.Task) string { return string(t.Status) },
+	"priority":    func(t *domain.Task) string { return string(t.Priority) },
+	"context":     func(t *domain.Task) string { return t.Context },
+	"assignee":    func(t *domain.Task) string { return t.Assignee },
+	"created_by":  func(t *domain.Task) string { return t.CreatedBy },
+}
+
+// Compile converts rule declarations into task hooks.
+// It fails if a rule has no name, requires nothing, or names an unknown field.
+func Compile(rules []config.RuleConfig) ([]domain.TaskHook, error) {
+	hooks := make([]domain.TaskHook, 0, len(rules))
+
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if len(rule.Require) == 0 {
+			return nil, fmt.Errorf("rule %q: require must list at least one field", rule.Name)
+		}
+		for name := range rule.When {
+			if !known(name) {
+				return nil, fmt.Errorf("rule %q: unknown field %q in when", rule.Name, name)
+			}
+		}
+		for _, name := range rule.Require {
+			if !known(name) {
+				return nil, fmt.Errorf("rule %q: unknown field %q in require", rule.Name, name)
+			}
+		}
+
+		hooks = append(hooks, hook(rule))
+	}
+
+	return hooks, nil
+}
+
+// hook builds the task hook enforcing a single rule
+func hook(rule config.RuleConfig) domain.TaskHook {
+	return func(ctx context.Context, task *domain.Task) error {
+		for name, want := range rule.When {
+			if !matches(name, value(task, name), want) {
+				return nil
+			}
+		}
+
+		var missing []string
+		for _, name := range rule.Require {
+			if value(task, name) == "" {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		message := rule.Message
+		if message == "" {
+			message = fmt.Sprintf("%s required", strings.Join(missing, ", "))
+		}
+		return &domain.RuleViolation{Rule: rule.Name, Message: message}
+	}
+}
+
+// known reports whether name is a field rules can refer to
+func known(name string) bool {
+	if strings.HasPrefix(name, metadataPrefix) {
+		return len(name) > len(metadataPrefix)
+	}
+	_, ok := fields[name]
+	return ok
+}
+
+// value returns the field of task as a string, empty when unset.
+// Metadata strings are unquoted; other metadata values are compared as JSON.
+func value(task *domain.Task, name string) string {
+	if key, ok := strings.CutPrefix(name, metadataPrefix); ok {
+		if str, ok := task.Metadata.GetString(key); ok {
+			return str
+		}
+		if !task.Metadata.Has(key) {
+			return ""
+		}
+		return string(task.Metadata[key])
+	}
+	return fields[name](task)
+}
+
+// matches compares a task value with the value a rule expects, ignoring
+// case and, for contexts, a leading "@"
+func matches(name, got, want string) bool {
+	if name == "context" {
+		want = domain.NormalizeContext(want)
+	}
+	return strings.EqualFold(got, strings.TrimSpace(want))
+}
@@ -0,0 +1,84 @@
+// Package secrets keeps credentials in the operating system keyring (the
+// macOS Keychain, the Secret Service on Linux, or the Windows Credential
+// Manager) so they do not have to be written to the config file or the
+// environment.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// service is the keyring service name all secrets are stored under
+const service = "task-manager"
+
+// Secret names
+const (
+	DBPassword   = "db-password"   // PostgreSQL password
+	DBPassphrase = "db-passphrase" // passphrase of an encrypted SQLite database
+	GitHubToken  = "github-token"  // token used by "task sync github"
+	TodoistToken = "todoist-token" // token used by "task import --format todoist api"
+	SMTPPassword = "smtp-password" // SMTP password used by "task digest"
+)
+
+// ErrNotFound is returned when the keyring holds no value for a secret
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// Names returns the names of the secrets that can be stored
+func Names() []string {
+	return []string{DBPassword, DBPassphrase, GitHubToken, TodoistToken, SMTPPassword}
+}
+
+// Get reads the secret called name from the keyring
+func Get(name string) (string, error) {
+	value, err := keyring.Get(service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from keyring: %w", name, err)
+	}
+	return value, nil
+}
+
+// Set stores value as the secret called name, replacing any previous value
+func Set(name, value string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	if value == "" {
+		return errors.New("secret value cannot be empty")
+	}
+	if err := keyring.Set(service, name, value); err != nil {
+		return fmt.Errorf("failed to write %s to keyring: %w", name, err)
+	}
+	return nil
+}
+
+// Delete removes the secret called name from the keyring
+func Delete(name string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	err := keyring.Delete(service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove %s from keyring: %w", name, err)
+	}
+	return nil
+}
+
+// validName checks that name is one of Names
+func validName(name string) error {
+	for _, n := range Names() {
+		if n == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown secret: %s (must be %s)", name, strings.Join(Names(), ", "))
+}
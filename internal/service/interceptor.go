@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/events"
+	"github.com/edson-mazvila/task-manager/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Call describes a TaskService method call to the interceptors around it
+type Call struct {
+	Method string // TaskService method, e.g. "CreateTask"
+	TaskID string // task read or changed, if any; creates set it once the ID is known
+	Write  bool   // whether the call changes tasks
+
+	// Repo is the repository the call works on. Interceptors may replace it,
+	// typically with a decorator of the current one, before calling next.
+	Repo domain.TaskRepository
+
+	// Links holds the links between tasks, for the calls that change them;
+	// nil without WithLinks. Interceptors may replace it like Repo.
+	Links domain.LinkRepository
+
+	// Comments holds the comments on tasks, like Links; nil without
+	// WithComments
+	Comments domain.CommentRepository
+
+	// Events are the events the call produced, published by Publish once
+	// it succeeds
+	Events []events.Event
+
+	// Checks run in order on every task a write call is about to create
+	// or update, and can adjust it or reject the call. Interceptors add
+	// to them before calling next, as Normalize, Hooks, and Validate do.
+	Checks []domain.TaskHook
+}
+
+// check runs the checks of the call on task, stopping at the first error
+func (c *Call) check(ctx context.Context, task *domain.Task) error {
+	for _, check := range c.Checks {
+		if err := check(ctx, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler performs a call
+type Handler func(ctx context.Context, call *Call) error
+
+// Interceptor runs around every TaskService call. It can inspect or adjust
+// the call, reject it by returning an error without calling next, or act
+// on the error next returns.
+type Interceptor func(ctx context.Context, call *Call, next Handler) error
+
+// Chain composes interceptors into one; the first is the outermost
+func Chain(interceptors ...Interceptor) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, call *Call) error {
+				return interceptor(ctx, call, inner)
+			}
+		}
+		return next(ctx, call)
+	}
+}
+
+// Tracing records a span named "TaskService.<Method>" for every call
+func Tracing() Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		ctx, span := tracer.Start(ctx, "TaskService."+call.Method)
+		err := next(ctx, call)
+		if call.TaskID != "" {
+			span.SetAttributes(attribute.String("task.id", call.TaskID))
+		}
+		tracing.End(span, err)
+		return err
+	}
+}
+
+// Logging logs every call: rejected input at warn level, other failures at
+// error level, and successful writes at info level
+func Logging(logger *slog.Logger) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		err := next(ctx, call)
+
+		attrs := []any{"method", call.Method}
+		if call.TaskID != "" {
+			attrs = append(attrs, "task_id", call.TaskID)
+		}
+		switch {
+		case err != nil && rejected(err):
+			logger.Warn("Task call rejected", append(attrs, "error", err)...)
+		case err != nil:
+			logger.Error("Task call failed", append(attrs, "error", err)...)
+		case call.Write:
+			logger.Info("Task call succeeded", attrs...)
+		default:
+			logger.Debug("Task call succeeded", attrs...)
+		}
+		return err
+	}
+}
+
+// rejected reports whether err is a problem with the caller's input rather
+// than a failure of the service
+func rejected(err error) bool {
+	return errors.Is(err, domain.ErrValidation) || errors.Is(err, domain.ErrTaskNotFound) ||
+		errors.Is(err, domain.ErrInvalidTaskID) || errors.Is(err, domain.ErrUserNotFound) ||
+		errors.Is(err, domain.ErrForbidden)
+}
+
+// Normalize tidies the whitespace in the title and description of the
+// tasks write calls save, as rules.Normalize does
+func Normalize(rules domain.ValidationRules) Interceptor {
+	return addCheck(func(ctx context.Context, task *domain.Task) error {
+		rules.Normalize(task)
+		return nil
+	})
+}
+
+// Hooks runs hooks on the tasks write calls save, stopping at the first
+// that rejects the task
+func Hooks(hooks ...domain.TaskHook) Interceptor {
+	return addCheck(hooks...)
+}
+
+// Validate rejects write calls that would save a task breaking rules
+func Validate(rules domain.ValidationRules) Interceptor {
+	return addCheck(func(ctx context.Context, task *domain.Task) error {
+		if err := task.ValidateWith(rules); err != nil {
+			return fmt.Errorf("task validation failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// addCheck returns an interceptor adding checks to write calls
+func addCheck(checks ...domain.TaskHook) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		if call.Write {
+			call.Checks = append(call.Checks, checks...)
+		}
+		return next(ctx, call)
+	}
+}
+
+// Observe calls fn after every call with how long it took and its error,
+// e.g. to record metrics
+func Observe(fn func(call *Call, elapsed time.Duration, err error)) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		start := time.Now()
+		err := next(ctx, call)
+		fn(call, time.Since(start), err)
+		return err
+	}
+}
+
+// Authorize rejects the calls for which allow returns an error
+func Authorize(allow func(ctx context.Context, call *Call) error) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		if err := allow(ctx, call); err != nil {
+			return err
+		}
+		return next(ctx, call)
+	}
+}
+
+// DryRun makes calls work on the repository readOnly wraps theirs in, one
+// that reads normally but never writes such as
+// repository.NewDryRunTaskRepository, so results reflect what would have
+// changed. Links and comments are not written either. Nothing having
+// changed, the events of the call are dropped.
+func DryRun(readOnly func(domain.TaskRepository) domain.TaskRepository) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		call.Repo = readOnly(call.Repo)
+		if call.Links != nil {
+			call.Links = &dryRunLinks{LinkRepository: call.Links}
+		}
+		if call.Comments != nil {
+			call.Comments = &dryRunComments{CommentRepository: call.Comments}
+		}
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("dry_run", true))
+		err := next(ctx, call)
+		call.Events = nil
+		return err
+	}
+}
+
+// dryRunLinks reads links normally but never writes them
+type dryRunLinks struct {
+	domain.LinkRepository
+}
+
+// Add does nothing
+func (l *dryRunLinks) Add(ctx context.Context, link *domain.Link) error {
+	return nil
+}
+
+// Remove does nothing
+func (l *dryRunLinks) Remove(ctx context.Context, taskID, target string) error {
+	return nil
+}
+
+// dryRunComments reads comments normally but never writes them
+type dryRunComments struct {
+	domain.CommentRepository
+}
+
+// Add does nothing
+func (c *dryRunComments) Add(ctx context.Context, comment *domain.Comment) error {
+	return nil
+}
+
+// Transaction runs write calls in a transaction of tx. It belongs inside
+// Publish, so that events are only published once the call is committed.
+// When tx runs the transaction again, the events of the failed attempt are
+// dropped.
+func Transaction(tx domain.TxManager) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		if !call.Write {
+			return next(ctx, call)
+		}
+		events := len(call.Events)
+		return tx.WithTx(ctx, func(ctx context.Context) error {
+			call.Events = call.Events[:events]
+			return next(ctx, call)
+		})
+	}
+}
+
+// Publish publishes the events of each successful call to publisher
+func Publish(publisher events.Publisher) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		if err := next(ctx, call); err != nil {
+			return err
+		}
+		for _, event := range call.Events {
+			publisher.Publish(ctx, event)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// Roles enforces the role of user on every call: viewers may only read,
+// members may create tasks and change the tasks they created or are
+// assigned (see domain.User.Owns), and admins may do anything. Members
+// work on repositories that check each task before changing it or its
+// links, so bulk updates are rejected if they match a task the member does
+// not own. Comments leave the task unchanged, so members may comment on
+// any task.
+func Roles(user *domain.User) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		if !call.Write {
+			return next(ctx, call)
+		}
+
+		switch user.Role {
+		case domain.RoleAdmin:
+			return next(ctx, call)
+		case domain.RoleMember:
+			owned := &ownedRepository{TaskRepository: call.Repo, user: user}
+			call.Repo = owned
+			if call.Links != nil {
+				call.Links = &ownedLinks{LinkRepository: call.Links, tasks: owned}
+			}
+			return next(ctx, call)
+		default:
+			return fmt.Errorf("%s is a %s and cannot change tasks: %w", describe(user), user.Role, domain.ErrForbidden)
+		}
+	}
+}
+
+// As returns a copy of the service that enforces the role of user (see Roles)
+func (s *TaskService) As(user *domain.User) *TaskService {
+	as := *s
+	as.interceptors = append(slices.Clone(s.interceptors), Roles(user))
+	return &as
+}
+
+// describe names a user in errors; the user may not be registered
+func describe(user *domain.User) string {
+	if user.Name == "" {
+		return "the current user"
+	}
+	return user.Name
+}
+
+// ownedRepository lets a member create tasks and change only their own
+type ownedRepository struct {
+	domain.TaskRepository
+	user *domain.User
+}
+
+// forbidden reports a change to a task the member does not own
+func (r *ownedRepository) forbidden(task *domain.Task) error {
+	return fmt.Errorf("%s can only change tasks they created or are assigned, not %s: %w", r.user.Name, task.ID, domain.ErrForbidden)
+}
+
+// check verifies that the member owns the stored task with the given ID
+func (r *ownedRepository) check(ctx context.Context, id string) error {
+	task, err := r.TaskRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !r.user.Owns(task) {
+		return r.forbidden(task)
+	}
+	return nil
+}
+
+// Create creates a task, recorded as created by the member
+func (r *ownedRepository) Create(ctx context.Context, task *domain.Task) error {
+	if task.CreatedBy == "" {
+		task.CreatedBy = r.user.Name
+	}
+	return r.TaskRepository.Create(ctx, task)
+}
+
+// CreateBatch creates tasks, recorded as created by the member
+func (r *ownedRepository) CreateBatch(ctx context.Context, tasks []*domain.Task) error {
+	for _, task := range tasks {
+		if task.CreatedBy == "" {
+			task.CreatedBy = r.user.Name
+		}
+	}
+	return r.TaskRepository.CreateBatch(ctx, tasks)
+}
+
+// Update updates a task the member owns
+func (r *ownedRepository) Update(ctx context.Context, task *domain.Task) error {
+	if err := r.check(ctx, task.ID); err != nil {
+		return err
+	}
+	return r.TaskRepository.Update(ctx, task)
+}
+
+// UpdateWhere updates the matching tasks if the member owns all of them
+func (r *ownedRepository) UpdateWhere(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch, now time.Time) (int64, error) {
+	tasks, err := r.TaskRepository.List(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	for _, task := range tasks {
+		if !r.user.Owns(task) {
+			return 0, r.forbidden(task)
+		}
+	}
+	return r.TaskRepository.UpdateWhere(ctx, filter, patch, now)
+}
+
+// Delete deletes a task the member owns
+func (r *ownedRepository) Delete(ctx context.Context, id string) error {
+	if err := r.check(ctx, id); err != nil {
+		return err
+	}
+	return r.TaskRepository.Delete(ctx, id)
+}
+
+// ownedLinks lets a member change only the links of their own tasks
+type ownedLinks struct {
+	domain.LinkRepository
+	tasks *ownedRepository
+}
+
+// Add links a task the member owns
+func (l *ownedLinks) Add(ctx context.Context, link *domain.Link) error {
+	if err := l.tasks.check(ctx, link.TaskID); err != nil {
+		return err
+	}
+	return l.LinkRepository.Add(ctx, link)
+}
+
+// Remove unlinks a task the member owns
+func (l *ownedLinks) Remove(ctx context.Context, taskID, target string) error {
+	if err := l.tasks.check(ctx, taskID); err != nil {
+		return err
+	}
+	return l.LinkRepository.Remove(ctx, taskID, target)
+}
+
+// Scope limits every call to the tasks user owns (see domain.User.Owns):
+// lists and counts leave other tasks out, and reading or changing one of
+// them by ID fails with domain.ErrTaskNotFound
+func Scope(user *domain.User) Interceptor {
+	return func(ctx context.Context, call *Call, next Handler) error {
+		call.Repo = &scopedRepository{TaskRepository: call.Repo, owner: user.Name}
+		return next(ctx, call)
+	}
+}
+
+// ScopedTo returns a copy of the service that only sees the tasks user
+// owns (see Scope)
+func (s *TaskService) ScopedTo(user *domain.User) *TaskService {
+	scoped := *s
+	scoped.interceptors = append(slices.Clone(s.interceptors), Scope(user))
+	return &scoped
+}
+
+// scopedRepository adds the owner to every filter
+type scopedRepository struct {
+	domain.TaskRepository
+	owner string
+}
+
+// scope returns filter limited to the owner's tasks
+func (r *scopedRepository) scope(filter domain.TaskFilter) domain.TaskFilter {
+	filter.Owner = &r.owner
+	return filter
+}
+
+// GetByID retrieves a task the owner owns
+func (r *scopedRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	task, err := r.TaskRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !(&domain.User{Name: r.owner}).Owns(task) {
+		return nil, domain.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// List retrieves the owner's tasks matching filter
+func (r *scopedRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	return r.TaskRepository.List(ctx, r.scope(filter))
+}
+
+// Stream calls fn for the owner's tasks matching filter
+func (r *scopedRepository) Stream(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	return r.TaskRepository.Stream(ctx, r.scope(filter), fn)
+}
+
+// Count counts the owner's tasks matching filter
+func (r *scopedRepository) Count(ctx context.Context, filter domain.TaskFilter) (int64, error) {
+	return r.TaskRepository.Count(ctx, r.scope(filter))
+}
+
+// CountByStatus counts the owner's tasks matching filter by status
+func (r *scopedRepository) CountByStatus(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskStatus]int64, error) {
+	return r.TaskRepository.CountByStatus(ctx, r.scope(filter))
+}
+
+// CountByPriority counts the owner's tasks matching filter by priority
+func (r *scopedRepository) CountByPriority(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskPriority]int64, error) {
+	return r.TaskRepository.CountByPriority(ctx, r.scope(filter))
+}
+
+// EffortByContext sums the effort of the owner's tasks matching filter by context
+func (r *scopedRepository) EffortByContext(ctx context.Context, filter domain.TaskFilter) (map[string]domain.Effort, error) {
+	return r.TaskRepository.EffortByContext(ctx, r.scope(filter))
+}
+
+// UpdateWhere updates the owner's tasks matching filter
+func (r *scopedRepository) UpdateWhere(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch, now time.Time) (int64, error) {
+	return r.TaskRepository.UpdateWhere(ctx, r.scope(filter), patch, now)
+}
+
+// Update updates a task the owner owns
+func (r *scopedRepository) Update(ctx context.Context, task *domain.Task) error {
+	if _, err := r.GetByID(ctx, task.ID); err != nil {
+		return err
+	}
+	return r.TaskRepository.Update(ctx, task)
+}
+
+// Delete deletes a task the owner owns
+func (r *scopedRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return r.TaskRepository.Delete(ctx, id)
+}
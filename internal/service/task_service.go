@@ -2,69 +2,292 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/events"
+	"github.com/edson-mazvila/task-manager/internal/tracing"
 	"github.com/google/uuid"
 )
 
-// TaskService provides business logic for task management
+// tracer records a span for every service method
+var tracer = tracing.Tracer("service")
+
+// TaskService provides business logic for task management. Cross-cutting
+// concerns run as interceptors around each method (see Interceptor):
+// tracing and logging always, then the write checks, then those added with
+// WithInterceptors, and innermost the transaction of write calls when
+// WithTransactions is used. The write checks run on each task about to be
+// written: whitespace normalization (Normalize), the hooks added with
+// WithHooks (Hooks), and validation (Validate), followed by the assignee
+// check.
 type TaskService struct {
-	repo   domain.TaskRepository
-	logger *slog.Logger
+	repo         domain.TaskRepository
+	users        domain.UserRepository
+	links        domain.LinkRepository
+	comments     domain.CommentRepository
+	hooks        []domain.TaskHook
+	rules        domain.ValidationRules
+	interceptors []Interceptor
+	tx           domain.TxManager
+	logger       *slog.Logger
+}
+
+// Option configures optional TaskService dependencies
+type Option func(*TaskService)
+
+// WithUserRepository enables assignee validation against registered users
+func WithUserRepository(users domain.UserRepository) Option {
+	return func(s *TaskService) {
+		s.users = users
+	}
+}
+
+// WithLinks enables linking tasks to other tasks and URLs (see LinkTask)
+func WithLinks(links domain.LinkRepository) Option {
+	return func(s *TaskService) {
+		s.links = links
+	}
+}
+
+// WithComments enables commenting on tasks (see AddComment)
+func WithComments(comments domain.CommentRepository) Option {
+	return func(s *TaskService) {
+		s.comments = comments
+	}
+}
+
+// WithHooks registers hooks run on every task before it is created or updated
+func WithHooks(hooks ...domain.TaskHook) Option {
+	return func(s *TaskService) {
+		s.hooks = append(s.hooks, hooks...)
+	}
+}
+
+// WithValidation replaces the default limits on titles and descriptions
+func WithValidation(rules domain.ValidationRules) Option {
+	return func(s *TaskService) {
+		s.rules = rules
+	}
+}
+
+// WithInterceptors adds interceptors run around every call, inside the
+// built-in tracing and logging, the first outermost
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(s *TaskService) {
+		s.interceptors = append(s.interceptors, interceptors...)
+	}
+}
+
+// WithEvents publishes the events of successful calls, such as
+// task.created, to publisher (see the events package)
+func WithEvents(publisher events.Publisher) Option {
+	return WithInterceptors(Publish(publisher))
+}
+
+// WithTransactions runs each write call, and the functions passed to
+// WithTx, in a transaction of tx, so a call that changes several tables
+// either changes all of them or none. Events are published once the
+// transaction of the call is committed.
+func WithTransactions(tx domain.TxManager) Option {
+	return func(s *TaskService) {
+		s.tx = tx
+	}
 }
 
 // NewTaskService creates a new task service
-func NewTaskService(repo domain.TaskRepository, logger *slog.Logger) *TaskService {
-	return &TaskService{
+func NewTaskService(repo domain.TaskRepository, logger *slog.Logger, opts ...Option) *TaskService {
+	s := &TaskService{
 		repo:   repo,
+		rules:  domain.DefaultValidationRules,
 		logger: logger,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// DryRun returns a copy of the service whose operations behave normally but
+// never write to the database, working on the repository readOnly wraps
+// theirs in (see DryRun). Results reflect what would have changed.
+func (s *TaskService) DryRun(readOnly func(domain.TaskRepository) domain.TaskRepository) *TaskService {
+	dry := *s
+	dry.interceptors = append(slices.Clone(s.interceptors), DryRun(readOnly))
+	return &dry
+}
+
+// invoke runs handler for call through the interceptors
+func (s *TaskService) invoke(ctx context.Context, call *Call, handler Handler) error {
+	call.Repo = s.repo
+	call.Links = s.links
+	call.Comments = s.comments
+	chain := []Interceptor{Tracing(), Logging(s.logger), Normalize(s.rules), Hooks(s.hooks...), Validate(s.rules)}
+	chain = append(chain, s.interceptors...)
+	if s.tx != nil {
+		chain = append(chain, Transaction(s.tx))
+	}
+	return Chain(chain...)(ctx, call, handler)
+}
+
+// WithTx runs fn in one transaction, so the service calls and repository
+// writes fn makes with the context it is given are committed together, or
+// rolled back together if fn fails. Calls made within fn publish their
+// events as they return, before the transaction is committed, so when the
+// transaction manager retries fn on a busy database they are published
+// again. Without WithTransactions fn simply runs.
+func (s *TaskService) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.tx == nil {
+		return fn(ctx)
+	}
+	return s.tx.WithTx(ctx, fn)
+}
+
+// checkAssignee verifies that the task's assignee is a registered user.
+// The check is skipped when no user repository is configured.
+func (s *TaskService) checkAssignee(ctx context.Context, task *domain.Task) error {
+	if task.Assignee == "" || s.users == nil {
+		return nil
+	}
+
+	if _, err := s.users.GetByName(ctx, task.Assignee); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return fmt.Errorf("unknown assignee %q: %w", task.Assignee, err)
+		}
+		return err
+	}
+
+	return nil
 }
 
 // CreateTask creates a new task with validation and persistence.
 // It generates a UUID, sets default status to Pending, and validates all fields
-// before persisting to the repository. Returns the created task or an error.
-func (s *TaskService) CreateTask(ctx context.Context, title, description string, priority domain.TaskPriority) (*domain.Task, error) {
+// before persisting to the repository. Optional attributes such as the GTD context
+// are applied through opts. Returns the created task or an error.
+//
+// When opts give the task an ID (domain.WithID or domain.WithIdempotencyKey)
+// and a task with that ID exists, the existing task is returned instead, so
+// callers can retry a create without making duplicates.
+func (s *TaskService) CreateTask(ctx context.Context, title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) (*domain.Task, error) {
+	var task *domain.Task
+	err := s.invoke(ctx, &Call{Method: "CreateTask", Write: true}, func(ctx context.Context, call *Call) error {
+		task = newTask(title, description, priority, opts...)
+
+		id, err := uuid.Parse(task.ID)
+		if err != nil {
+			return fmt.Errorf("%w: %q is not a UUID", domain.ErrInvalidTaskID, task.ID)
+		}
+		task.ID = id.String()
+		call.TaskID = task.ID
+
+		if err := call.check(ctx, task); err != nil {
+			return err
+		}
+		if err := s.checkAssignee(ctx, task); err != nil {
+			return err
+		}
+
+		if err := call.Repo.Create(ctx, task); err != nil {
+			if errors.Is(err, domain.ErrDuplicateTask) {
+				if existing, getErr := call.Repo.GetByID(ctx, task.ID); getErr == nil {
+					task = existing
+					return nil
+				}
+			}
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+		call.Events = append(call.Events, events.New(events.TaskCreated, task))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// NewTaskDraft describes a task to be created by CreateTasks
+type NewTaskDraft struct {
+	Title       string
+	Description string
+	Priority    domain.TaskPriority
+	Options     []domain.TaskOption
+}
+
+// CreateTasks creates several tasks atomically. All drafts are validated first;
+// if any is invalid, nothing is created and the returned error identifies it
+// by its position (starting at 1).
+func (s *TaskService) CreateTasks(ctx context.Context, drafts []NewTaskDraft) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+	err := s.invoke(ctx, &Call{Method: "CreateTasks", Write: true}, func(ctx context.Context, call *Call) error {
+		tasks = make([]*domain.Task, 0, len(drafts))
+		for i, draft := range drafts {
+			task := newTask(draft.Title, draft.Description, draft.Priority, draft.Options...)
+			if err := call.check(ctx, task); err != nil {
+				return fmt.Errorf("task %d: %w", i+1, err)
+			}
+			if err := s.checkAssignee(ctx, task); err != nil {
+				return fmt.Errorf("task %d: %w", i+1, err)
+			}
+			tasks = append(tasks, task)
+		}
+
+		if err := call.Repo.CreateBatch(ctx, tasks); err != nil {
+			return fmt.Errorf("failed to create tasks: %w", err)
+		}
+		for _, task := range tasks {
+			call.Events = append(call.Events, events.New(events.TaskCreated, task))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// newTask builds a pending task with a fresh ID and timestamps
+func newTask(title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) *domain.Task {
+	now := time.Now()
 	task := &domain.Task{
 		ID:          uuid.New().String(),
 		Title:       title,
 		Description: description,
 		Status:      domain.TaskStatusPending,
 		Priority:    priority,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
-	if err := task.Validate(); err != nil {
-		s.logger.Warn("Task validation failed", "error", err)
-		return nil, fmt.Errorf("task validation failed: %w", err)
+	for _, opt := range opts {
+		opt(task)
 	}
 
-	if err := s.repo.Create(ctx, task); err != nil {
-		s.logger.Error("Failed to create task", "error", err)
-		return nil, fmt.Errorf("failed to create task: %w", err)
-	}
-
-	s.logger.Info("Task created successfully", "task_id", task.ID, "title", task.Title)
-	return task, nil
+	return task
 }
 
 // GetTask retrieves a task by ID from the repository.
 // Returns ErrInvalidTaskID if the ID is empty, or ErrTaskNotFound if no task exists.
 func (s *TaskService) GetTask(ctx context.Context, id string) (*domain.Task, error) {
-	if id == "" {
-		return nil, domain.ErrInvalidTaskID
-	}
+	var task *domain.Task
+	err := s.invoke(ctx, &Call{Method: "GetTask", TaskID: id}, func(ctx context.Context, call *Call) error {
+		if id == "" {
+			return domain.ErrInvalidTaskID
+		}
 
-	task, err := s.repo.GetByID(ctx, id)
+		var err error
+		task, err = call.Repo.GetByID(ctx, id)
+		return err
+	})
 	if err != nil {
-		s.logger.Error("Failed to get task", "error", err, "task_id", id)
 		return nil, err
 	}
-
 	return task, nil
 }
 
@@ -72,102 +295,505 @@ func (s *TaskService) GetTask(ctx context.Context, id string) (*domain.Task, err
 // The filter supports status and priority filtering. Pass empty filter for all tasks.
 // Results are ordered by creation date (newest first).
 func (s *TaskService) ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
-	tasks, err := s.repo.List(ctx, filter)
+	var tasks []*domain.Task
+	err := s.invoke(ctx, &Call{Method: "ListTasks"}, func(ctx context.Context, call *Call) error {
+		var err error
+		if tasks, err = call.Repo.List(ctx, filter); err != nil {
+			return fmt.Errorf("failed to list tasks: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to list tasks", "error", err)
-		return nil, fmt.Errorf("failed to list tasks: %w", err)
+		return nil, err
 	}
-
-	s.logger.Debug("Tasks listed", "count", len(tasks))
 	return tasks, nil
 }
 
-// UpdateTask updates an existing task with partial field updates.
-// Only non-empty fields are updated, allowing partial updates without overwriting existing data.
-// The task is validated after updates and the UpdatedAt timestamp is refreshed.
-func (s *TaskService) UpdateTask(ctx context.Context, id, title, description string, priority domain.TaskPriority) (*domain.Task, error) {
-	if id == "" {
-		return nil, domain.ErrInvalidTaskID
+// StreamTasks calls fn for each task matching filter, in ID order, without
+// loading them all into memory. An error returned by fn stops the stream.
+func (s *TaskService) StreamTasks(ctx context.Context, filter domain.TaskFilter, fn func(*domain.Task) error) error {
+	return s.invoke(ctx, &Call{Method: "StreamTasks"}, func(ctx context.Context, call *Call) error {
+		if err := call.Repo.Stream(ctx, filter, fn); err != nil {
+			return fmt.Errorf("failed to stream tasks: %w", err)
+		}
+		return nil
+	})
+}
+
+// CountTasks returns the number of tasks matching filter without loading them
+func (s *TaskService) CountTasks(ctx context.Context, filter domain.TaskFilter) (int64, error) {
+	var n int64
+	err := s.invoke(ctx, &Call{Method: "CountTasks"}, func(ctx context.Context, call *Call) error {
+		var err error
+		if n, err = call.Repo.Count(ctx, filter); err != nil {
+			return fmt.Errorf("failed to count tasks: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
+	return n, nil
+}
 
-	// Get existing task
-	task, err := s.repo.GetByID(ctx, id)
+// CountTasksByStatus returns the number of tasks matching filter for each status
+func (s *TaskService) CountTasksByStatus(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskStatus]int64, error) {
+	var counts map[domain.TaskStatus]int64
+	err := s.invoke(ctx, &Call{Method: "CountTasksByStatus"}, func(ctx context.Context, call *Call) error {
+		var err error
+		if counts, err = call.Repo.CountByStatus(ctx, filter); err != nil {
+			return fmt.Errorf("failed to count tasks by status: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to get task for update", "error", err, "task_id", id)
 		return nil, err
 	}
+	return counts, nil
+}
 
-	// Update fields
-	if title != "" {
-		task.Title = title
-	}
-	if description != "" {
-		task.Description = description
-	}
-	if priority != "" {
-		task.Priority = priority
+// CountTasksByPriority returns the number of tasks matching filter for each priority
+func (s *TaskService) CountTasksByPriority(ctx context.Context, filter domain.TaskFilter) (map[domain.TaskPriority]int64, error) {
+	var counts map[domain.TaskPriority]int64
+	err := s.invoke(ctx, &Call{Method: "CountTasksByPriority"}, func(ctx context.Context, call *Call) error {
+		var err error
+		if counts, err = call.Repo.CountByPriority(ctx, filter); err != nil {
+			return fmt.Errorf("failed to count tasks by priority: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	task.UpdatedAt = time.Now()
+	return counts, nil
+}
 
-	// Validate updated task
-	if err := task.Validate(); err != nil {
-		s.logger.Warn("Task validation failed", "error", err)
-		return nil, fmt.Errorf("task validation failed: %w", err)
+// EffortByContext totals the tasks matching filter, with their estimates and
+// points, for each context
+func (s *TaskService) EffortByContext(ctx context.Context, filter domain.TaskFilter) (map[string]domain.Effort, error) {
+	var efforts map[string]domain.Effort
+	err := s.invoke(ctx, &Call{Method: "EffortByContext"}, func(ctx context.Context, call *Call) error {
+		var err error
+		if efforts, err = call.Repo.EffortByContext(ctx, filter); err != nil {
+			return fmt.Errorf("failed to sum task effort: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return efforts, nil
+}
 
-	// Save updated task
-	if err := s.repo.Update(ctx, task); err != nil {
-		s.logger.Error("Failed to update task", "error", err, "task_id", id)
-		return nil, fmt.Errorf("failed to update task: %w", err)
-	}
+// UpdateTask updates an existing task with partial field updates.
+// Only non-empty fields are updated, allowing partial updates without overwriting existing data.
+// Options in opts are always applied. The task is validated after updates and the
+// UpdatedAt timestamp is refreshed.
+func (s *TaskService) UpdateTask(ctx context.Context, id, title, description string, priority domain.TaskPriority, opts ...domain.TaskOption) (*domain.Task, error) {
+	var task *domain.Task
+	err := s.invoke(ctx, &Call{Method: "UpdateTask", TaskID: id, Write: true}, func(ctx context.Context, call *Call) error {
+		if id == "" {
+			return domain.ErrInvalidTaskID
+		}
+
+		// Get existing task
+		var err error
+		if task, err = call.Repo.GetByID(ctx, id); err != nil {
+			return err
+		}
+		wasCompleted := task.Status == domain.TaskStatusCompleted
+
+		// Update fields
+		if title != "" {
+			task.Title = title
+		}
+		if description != "" {
+			task.Description = description
+		}
+		if priority != "" {
+			task.Priority = priority
+		}
+		for _, opt := range opts {
+			opt(task)
+		}
+		task.UpdatedAt = time.Now()
+
+		if err := call.check(ctx, task); err != nil {
+			return err
+		}
+		if err := s.checkAssignee(ctx, task); err != nil {
+			return err
+		}
 
-	s.logger.Info("Task updated successfully", "task_id", task.ID)
+		// Save updated task
+		if err := call.Repo.Update(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+		call.Events = append(call.Events, updateEvents(task, wasCompleted)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return task, nil
 }
 
-// CompleteTask marks a task as completed
-func (s *TaskService) CompleteTask(ctx context.Context, id string) (*domain.Task, error) {
-	if id == "" {
-		return nil, domain.ErrInvalidTaskID
+// UpdateTasks applies patch to every task matching filter in one atomic update.
+// Each matching task is first checked with the patched values (hooks, validation,
+// and assignee), and the whole change is rejected if any task would be invalid.
+// Hooks can reject a bulk update but their adjustments are not saved.
+// Returns the number of tasks updated.
+func (s *TaskService) UpdateTasks(ctx context.Context, filter domain.TaskFilter, patch domain.TaskPatch) (int64, error) {
+	var n int64
+	err := s.invoke(ctx, &Call{Method: "UpdateTasks", Write: true}, func(ctx context.Context, call *Call) error {
+		if patch.IsEmpty() {
+			return fmt.Errorf("no changes given")
+		}
+		if patch.Description != nil {
+			description := s.rules.NormalizeDescription(*patch.Description)
+			patch.Description = &description
+		}
+
+		tasks, err := call.Repo.List(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list tasks: %w", err)
+		}
+
+		now := time.Now()
+		for _, task := range tasks {
+			wasCompleted := task.Status == domain.TaskStatusCompleted
+			patch.Apply(task, now)
+			call.Events = append(call.Events, updateEvents(task, wasCompleted)...)
+			if err := call.check(ctx, task); err != nil {
+				return fmt.Errorf("task %s: %w", task.ID, err)
+			}
+		}
+
+		if patch.Assignee != nil {
+			if err := s.checkAssignee(ctx, &domain.Task{Assignee: *patch.Assignee}); err != nil {
+				return err
+			}
+		}
+
+		if n, err = call.Repo.UpdateWhere(ctx, filter, patch, now); err != nil {
+			return fmt.Errorf("failed to update tasks: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
+	return n, nil
+}
+
+// DelegateTask assigns a task to the registered user to on behalf of by,
+// the user handing it over, if known. Besides task.updated it records a
+// task.delegated event naming by, so subscribers can tell the new assignee.
+func (s *TaskService) DelegateTask(ctx context.Context, id, to, by string) (*domain.Task, error) {
+	var task *domain.Task
+	err := s.invoke(ctx, &Call{Method: "DelegateTask", TaskID: id, Write: true}, func(ctx context.Context, call *Call) error {
+		if id == "" {
+			return domain.ErrInvalidTaskID
+		}
+		to = domain.NormalizeUserName(to)
+		if to == "" {
+			return fmt.Errorf("no user to delegate to: %w", domain.ErrValidation)
+		}
+
+		var err error
+		if task, err = call.Repo.GetByID(ctx, id); err != nil {
+			return err
+		}
+		if task.Assignee == to {
+			return fmt.Errorf("task is already assigned to %s: %w", to, domain.ErrValidation)
+		}
+
+		task.Assignee = to
+		task.UpdatedAt = time.Now()
+		if err := s.checkAssignee(ctx, task); err != nil {
+			return err
+		}
 
-	// Get existing task
-	task, err := s.repo.GetByID(ctx, id)
+		if err := call.Repo.Update(ctx, task); err != nil {
+			return fmt.Errorf("failed to delegate task: %w", err)
+		}
+		delegated := events.New(events.TaskDelegated, task)
+		delegated.By = domain.NormalizeUserName(by)
+		call.Events = append(call.Events, events.New(events.TaskUpdated, task), delegated)
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to get task for completion", "error", err, "task_id", id)
 		return nil, err
 	}
+	return task, nil
+}
 
-	// Check if already completed
-	if task.Status == domain.TaskStatusCompleted {
-		s.logger.Warn("Task already completed", "task_id", id)
-		return task, nil
-	}
+// CompleteTask marks a task as completed
+func (s *TaskService) CompleteTask(ctx context.Context, id string) (*domain.Task, error) {
+	var task *domain.Task
+	err := s.invoke(ctx, &Call{Method: "CompleteTask", TaskID: id, Write: true}, func(ctx context.Context, call *Call) error {
+		if id == "" {
+			return domain.ErrInvalidTaskID
+		}
 
-	// Mark as completed
-	task.MarkCompleted()
+		// Get existing task
+		var err error
+		if task, err = call.Repo.GetByID(ctx, id); err != nil {
+			return err
+		}
 
-	// Save updated task
-	if err := s.repo.Update(ctx, task); err != nil {
-		s.logger.Error("Failed to complete task", "error", err, "task_id", id)
-		return nil, fmt.Errorf("failed to complete task: %w", err)
-	}
+		// Check if already completed
+		if task.Status == domain.TaskStatusCompleted {
+			s.logger.Warn("Task already completed", "task_id", id)
+			return nil
+		}
+
+		// Mark as completed
+		task.MarkCompleted()
 
-	s.logger.Info("Task completed successfully", "task_id", task.ID)
+		// Save updated task
+		if err := call.Repo.Update(ctx, task); err != nil {
+			return fmt.Errorf("failed to complete task: %w", err)
+		}
+		call.Events = append(call.Events, events.New(events.TaskCompleted, task))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return task, nil
 }
 
 // DeleteTask deletes a task
 func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
-	if id == "" {
-		return domain.ErrInvalidTaskID
+	return s.invoke(ctx, &Call{Method: "DeleteTask", TaskID: id, Write: true}, func(ctx context.Context, call *Call) error {
+		if id == "" {
+			return domain.ErrInvalidTaskID
+		}
+		if err := call.Repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		call.Events = append(call.Events, events.Event{Type: events.TaskDeleted, TaskID: id, At: time.Now()})
+		return nil
+	})
+}
+
+// SetTaskMetadata stores an integration-specific value under key in the task's metadata.
+// A nil value removes the key. The UpdatedAt timestamp is refreshed.
+func (s *TaskService) SetTaskMetadata(ctx context.Context, id, key string, value interface{}) (*domain.Task, error) {
+	var task *domain.Task
+	err := s.invoke(ctx, &Call{Method: "SetTaskMetadata", TaskID: id, Write: true}, func(ctx context.Context, call *Call) error {
+		if id == "" {
+			return domain.ErrInvalidTaskID
+		}
+
+		var err error
+		if task, err = call.Repo.GetByID(ctx, id); err != nil {
+			return err
+		}
+
+		if value == nil {
+			task.DeleteMetadata(key)
+		} else if err := task.SetMetadata(key, value); err != nil {
+			return err
+		}
+		task.UpdatedAt = time.Now()
+
+		if err := call.Repo.Update(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task metadata: %w", err)
+		}
+		call.Events = append(call.Events, events.New(events.TaskUpdated, task))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return task, nil
+}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete task", "error", err, "task_id", id)
+// LinkTask records a link from a task to another task or a URL. Changing
+// the links of a task counts as changing the task, so members may only link
+// the tasks they own.
+func (s *TaskService) LinkTask(ctx context.Context, link *domain.Link) error {
+	return s.invoke(ctx, &Call{Method: "LinkTask", TaskID: link.TaskID, Write: true}, func(ctx context.Context, call *Call) error {
+		if call.Links == nil {
+			return errLinksUnsupported
+		}
+		if err := link.Validate(); err != nil {
+			return err
+		}
+		if _, err := call.Repo.GetByID(ctx, link.TaskID); err != nil {
+			return err
+		}
+		return call.Links.Add(ctx, link)
+	})
+}
+
+// UnlinkTask removes the links between a task and another task, in either
+// direction, or a URL
+func (s *TaskService) UnlinkTask(ctx context.Context, taskID, target string) error {
+	return s.invoke(ctx, &Call{Method: "UnlinkTask", TaskID: taskID, Write: true}, func(ctx context.Context, call *Call) error {
+		if call.Links == nil {
+			return errLinksUnsupported
+		}
+		if taskID == "" {
+			return domain.ErrInvalidTaskID
+		}
+		return call.Links.Remove(ctx, taskID, target)
+	})
+}
+
+// AddComment adds a comment to a task. Commenting leaves the task itself
+// unchanged, so members may comment on any task they can see; viewers
+// cannot comment.
+func (s *TaskService) AddComment(ctx context.Context, comment *domain.Comment) error {
+	return s.invoke(ctx, &Call{Method: "AddComment", TaskID: comment.TaskID, Write: true}, func(ctx context.Context, call *Call) error {
+		if call.Comments == nil {
+			return errCommentsUnsupported
+		}
+		if comment.ID == "" {
+			comment.ID = uuid.New().String()
+		}
+		if comment.CreatedAt.IsZero() {
+			comment.CreatedAt = time.Now()
+		}
+		if err := comment.Validate(); err != nil {
+			return err
+		}
+		if _, err := call.Repo.GetByID(ctx, comment.TaskID); err != nil {
+			return err
+		}
+		return call.Comments.Add(ctx, comment)
+	})
+}
+
+// ListComments returns the comments on a task, oldest first
+func (s *TaskService) ListComments(ctx context.Context, taskID string) ([]*domain.Comment, error) {
+	var comments []*domain.Comment
+	err := s.invoke(ctx, &Call{Method: "ListComments", TaskID: taskID}, func(ctx context.Context, call *Call) error {
+		if call.Comments == nil {
+			return errCommentsUnsupported
+		}
+		if _, err := call.Repo.GetByID(ctx, taskID); err != nil {
+			return err
+		}
+		var err error
+		comments, err = call.Comments.ListByTask(ctx, taskID)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return comments, nil
+}
 
-	s.logger.Info("Task deleted successfully", "task_id", id)
-	return nil
+var (
+	// errLinksUnsupported is returned by link calls without WithLinks
+	errLinksUnsupported = errors.New("task links are not supported by this storage")
+	// errCommentsUnsupported is returned by comment calls without WithComments
+	errCommentsUnsupported = errors.New("task comments are not supported by this storage")
+)
+
+// updateEvents returns the events for a task that was changed: task.updated,
+// and task.completed if the change completed it
+func updateEvents(task *domain.Task, wasCompleted bool) []events.Event {
+	evs := []events.Event{events.New(events.TaskUpdated, task)}
+	if !wasCompleted && task.Status == domain.TaskStatusCompleted {
+		evs = append(evs, events.New(events.TaskCompleted, task))
+	}
+	return evs
+}
+
+// ReportByContext sums up the open and completed tasks matching filter for
+// each context, sorted by context, counting the open tasks past their due
+// date as of now. Counts and estimates are grouped in the database; due
+// dates, kept in metadata, are checked on the open tasks that have one.
+func (s *TaskService) ReportByContext(ctx context.Context, filter domain.TaskFilter, now time.Time) ([]*domain.ContextReport, error) {
+	var reports []*domain.ContextReport
+	err := s.invoke(ctx, &Call{Method: "ReportByContext"}, func(ctx context.Context, call *Call) error {
+		byContext := make(map[string]*domain.ContextReport)
+		report := func(name string) *domain.ContextReport {
+			if byContext[name] == nil {
+				byContext[name] = &domain.ContextReport{Context: name}
+			}
+			return byContext[name]
+		}
+
+		for _, status := range []domain.TaskStatus{domain.TaskStatusPending, domain.TaskStatusCompleted} {
+			if filter.Status != nil && *filter.Status != status {
+				continue
+			}
+			f := filter
+			f.Status = &status
+			efforts, err := call.Repo.EffortByContext(ctx, f)
+			if err != nil {
+				return fmt.Errorf("failed to sum task effort: %w", err)
+			}
+			for name, effort := range efforts {
+				if status == domain.TaskStatusPending {
+					report(name).Open = effort
+				} else {
+					report(name).Completed = effort
+				}
+			}
+		}
+
+		if filter.Status == nil || *filter.Status == domain.TaskStatusPending {
+			pending := domain.TaskStatusPending
+			f := filter
+			f.Status = &pending
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			err := call.Repo.Stream(ctx, f, func(task *domain.Task) error {
+				if due, ok := task.DueDate(); ok && due.Before(today) {
+					report(task.Context).Overdue++
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to stream tasks: %w", err)
+			}
+		}
+
+		for _, r := range byContext {
+			reports = append(reports, r)
+		}
+		slices.SortFunc(reports, func(a, b *domain.ContextReport) int {
+			return strings.Compare(a.Context, b.Context)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// PublishOverdue finds the pending tasks matching filter that are past their
+// due date as of now, and records a task.overdue event for each, published
+// to the subscribers set with WithEvents. Snoozed tasks are left out. Due
+// dates come from task metadata (see domain.DueDateKeys).
+func (s *TaskService) PublishOverdue(ctx context.Context, filter domain.TaskFilter, now time.Time) ([]*domain.Task, error) {
+	var overdue []*domain.Task
+	err := s.invoke(ctx, &Call{Method: "PublishOverdue"}, func(ctx context.Context, call *Call) error {
+		pending := domain.TaskStatusPending
+		filter.Status = &pending
+		filter.AwakeAt = &now
+		tasks, err := call.Repo.List(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list tasks: %w", err)
+		}
+
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		for _, task := range tasks {
+			if due, ok := task.DueDate(); ok && due.Before(today) {
+				overdue = append(overdue, task)
+				call.Events = append(call.Events, events.New(events.TaskOverdue, task))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return overdue, nil
 }
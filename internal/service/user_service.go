@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// UserService provides business logic for managing users of a shared database
+type UserService struct {
+	repo   domain.UserRepository
+	actor  *domain.User
+	logger *slog.Logger
+}
+
+// NewUserService creates a new user service
+func NewUserService(repo domain.UserRepository, logger *slog.Logger) *UserService {
+	return &UserService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// As returns a copy of the service acting for actor: once roles are
+// enforced, only admins may register users or change roles
+func (s *UserService) As(actor *domain.User) *UserService {
+	as := *s
+	as.actor = actor
+	return &as
+}
+
+// checkAdmin rejects user management by an actor who is not an admin
+func (s *UserService) checkAdmin() error {
+	if s.actor != nil && s.actor.Role != domain.RoleAdmin {
+		return fmt.Errorf("only admins can manage users: %w", domain.ErrForbidden)
+	}
+	return nil
+}
+
+// CreateUser registers a new member with a normalized name
+func (s *UserService) CreateUser(ctx context.Context, name, email string) (*domain.User, error) {
+	if err := s.checkAdmin(); err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		Name:      domain.NormalizeUserName(name),
+		Email:     email,
+		Role:      domain.RoleMember,
+		CreatedAt: time.Now(),
+	}
+
+	if err := user.Validate(); err != nil {
+		s.logger.Warn("User validation failed", "error", err)
+		return nil, fmt.Errorf("user validation failed: %w", err)
+	}
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		s.logger.Error("Failed to create user", "error", err, "user", user.Name)
+		return nil, err
+	}
+
+	s.logger.Info("User created successfully", "user", user.Name)
+	return user, nil
+}
+
+// GetUser retrieves a user by name
+func (s *UserService) GetUser(ctx context.Context, name string) (*domain.User, error) {
+	return s.repo.GetByName(ctx, domain.NormalizeUserName(name))
+}
+
+// ListUsers retrieves all users
+func (s *UserService) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	users, err := s.repo.List(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list users", "error", err)
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+// SetRole changes the role of a user. Making the first admin turns role
+// enforcement on (see RolesEnforced); from then on only admins may change roles.
+func (s *UserService) SetRole(ctx context.Context, name string, role domain.Role) (*domain.User, error) {
+	if err := s.checkAdmin(); err != nil {
+		return nil, err
+	}
+	if _, err := domain.ParseRole(string(role)); err != nil {
+		return nil, err
+	}
+
+	name = domain.NormalizeUserName(name)
+	if err := s.repo.SetRole(ctx, name, role); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User role changed", "user", name, "role", role)
+	return s.repo.GetByName(ctx, name)
+}
+
+// RolesEnforced reports whether some user is an admin, which makes the
+// database multi-user: every change is then checked against the role of
+// the user making it
+func (s *UserService) RolesEnforced(ctx context.Context) (bool, error) {
+	users, err := s.ListUsers(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, user := range users {
+		if user.Role == domain.RoleAdmin {
+			return true, nil
+		}
+	}
+	return false, nil
+}
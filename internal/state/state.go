@@ -0,0 +1,81 @@
+// Package state persists small pieces of per-user CLI state between invocations,
+// such as the active GTD context. State lives in a JSON file in the XDG state
+// directory so it survives across shells and terminals.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/edson-mazvila/task-manager/internal/xdg"
+)
+
+// State holds the persisted CLI state
+type State struct {
+	ActiveContext string `json:"active_context,omitempty" yaml:"active_context,omitempty"`
+}
+
+// Store reads and writes State from a JSON file
+type Store struct {
+	path string
+}
+
+// NewStore creates a new state store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the default state file location,
+// $XDG_STATE_HOME/task-manager/state.json
+func DefaultPath() (string, error) {
+	return xdg.Path(xdg.StateDir, "state.json")
+}
+
+// Path returns the location of the state file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the state file. A missing file yields an empty state.
+func (s *Store) Load() (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &State{}, nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	st := &State{}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	return st, nil
+}
+
+// Save writes the state file atomically, creating its directory if needed
+func (s *Store) Save(st *State) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
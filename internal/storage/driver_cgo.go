@@ -0,0 +1,45 @@
+//go:build !purego
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName is the database/sql driver used for SQLite. The default build
+// uses mattn/go-sqlite3, which requires cgo; build with -tags purego for a
+// pure-Go driver.
+const driverName = "sqlite3"
+
+// driverDSN builds a go-sqlite3 connection string
+func driverDSN(dbPath string, o options) string {
+	// Open with foreign key enforcement
+	dsn := dbPath + "?_foreign_keys=on"
+	if o.busyTimeout > 0 {
+		dsn += fmt.Sprintf("&_busy_timeout=%d", o.busyTimeout.Milliseconds())
+	}
+	if o.writeLock {
+		dsn += "&_txlock=immediate"
+	}
+	return dsn
+}
+
+// IsDatabaseError reports whether err originated in the SQLite driver,
+// e.g. because the database is locked, read-only, or corrupt
+func IsDatabaseError(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr)
+}
+
+// IsBusy reports whether err means the database was locked by another
+// connection, so the operation can be retried
+func IsBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
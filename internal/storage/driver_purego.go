@@ -0,0 +1,50 @@
+//go:build purego
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// driverName is the database/sql driver used for SQLite. Builds tagged
+// purego use modernc.org/sqlite, so CGO_ENABLED=0 cross-compiles work.
+const driverName = "sqlite"
+
+// driverDSN builds a modernc.org/sqlite connection string. Pragmas are set
+// per connection, and times are written in the same format go-sqlite3 uses
+// so databases can move between builds.
+func driverDSN(dbPath string, o options) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	if o.busyTimeout > 0 {
+		params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", o.busyTimeout.Milliseconds()))
+	}
+	if o.writeLock {
+		params.Set("_txlock", "immediate")
+	}
+	params.Set("_time_format", "sqlite")
+	return "file:" + dbPath + "?" + params.Encode()
+}
+
+// IsDatabaseError reports whether err originated in the SQLite driver,
+// e.g. because the database is locked, read-only, or corrupt
+func IsDatabaseError(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr)
+}
+
+// IsBusy reports whether err means the database was locked by another
+// connection, so the operation can be retried
+func IsBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff // primary result code
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
@@ -8,26 +8,61 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-
-	_ "github.com/mattn/go-sqlite3"
+	"strings"
+	"time"
 )
 
 // SQLiteStorage manages SQLite database connections and migrations
 type SQLiteStorage struct {
 	db     *sql.DB
+	path   string
 	logger *slog.Logger
 }
 
+// Option configures how the SQLite database is opened
+type Option func(*options)
+
+// options holds the connection settings applied by Option
+type options struct {
+	busyTimeout time.Duration
+	writeLock   bool
+}
+
+// WithBusyTimeout sets how long SQLite waits for a lock held by another
+// connection before failing with "database is locked"
+func WithBusyTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.busyTimeout = d
+	}
+}
+
+// WithWriteLock makes every transaction take the database write lock when it
+// begins (BEGIN IMMEDIATE). Writers in different processes then queue on the
+// busy timeout instead of failing when a read lock cannot be upgraded.
+func WithWriteLock() Option {
+	return func(o *options) {
+		o.writeLock = true
+	}
+}
+
+// dsn builds the driver connection string for dbPath with the given options
+func dsn(dbPath string, opts []Option) string {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return driverDSN(dbPath, o)
+}
+
 // NewSQLiteStorage creates a new SQLite storage instance
-func NewSQLiteStorage(ctx context.Context, dbPath string, logger *slog.Logger) (*SQLiteStorage, error) {
+func NewSQLiteStorage(ctx context.Context, dbPath string, logger *slog.Logger, opts ...Option) (*SQLiteStorage, error) {
 	// Ensure the directory exists
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	// Open database connection
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open(driverName, dsn(dbPath, opts))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -44,6 +79,7 @@ func NewSQLiteStorage(ctx context.Context, dbPath string, logger *slog.Logger) (
 
 	storage := &SQLiteStorage{
 		db:     db,
+		path:   dbPath,
 		logger: logger,
 	}
 
@@ -63,6 +99,11 @@ func (s *SQLiteStorage) DB() *sql.DB {
 	return s.db
 }
 
+// Path returns the database file path
+func (s *SQLiteStorage) Path() string {
+	return s.path
+}
+
 // Close closes the database connection
 func (s *SQLiteStorage) Close() error {
 	if s.db != nil {
@@ -71,28 +112,364 @@ func (s *SQLiteStorage) Close() error {
 	return nil
 }
 
-// runMigrations runs database migrations
-func (s *SQLiteStorage) runMigrations(ctx context.Context) error {
-	// Create migrations table if it doesn't exist
-	_, err := s.db.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS migrations (
-			version TEXT PRIMARY KEY,
-			applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
+// sidecarSuffixes are the files SQLite may keep next to the main database file
+var sidecarSuffixes = []string{"-wal", "-shm", "-journal"}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns an error describing any problems
+func (s *SQLiteStorage) IntegrityCheck(ctx context.Context) error {
+	return integrityCheck(ctx, s.db)
+}
+
+// QuickCheck runs PRAGMA quick_check, a faster integrity check that skips
+// verifying that indexes match their tables
+func (s *SQLiteStorage) QuickCheck(ctx context.Context) error {
+	return pragmaCheck(ctx, s.db, "quick_check")
+}
+
+// Ping checks that the database can be reached and returns the SQLite version
+func (s *SQLiteStorage) Ping(ctx context.Context) (string, error) {
+	var version string
+	if err := s.db.QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to query database: %w", err)
+	}
+	return version, nil
+}
+
+// MigrationStatus describes the schema version of a database
+type MigrationStatus struct {
+	Applied []string // known migrations recorded as applied
+	Pending []string // known migrations not applied yet
+	Unknown []string // applied migrations this build does not know, e.g. from a newer version
+}
+
+// Migrations returns which migrations the database has applied
+func (s *SQLiteStorage) Migrations(ctx context.Context) (*MigrationStatus, error) {
+	applied, err := s.getAppliedMigrations(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
-	// Get applied migrations
-	appliedMigrations, err := s.getAppliedMigrations(ctx)
+	status := &MigrationStatus{}
+	for version := range migrations {
+		if applied[version] {
+			status.Applied = append(status.Applied, version)
+		} else {
+			status.Pending = append(status.Pending, version)
+		}
+	}
+	for version := range applied {
+		if _, ok := migrations[version]; !ok {
+			status.Unknown = append(status.Unknown, version)
+		}
+	}
+	sort.Strings(status.Applied)
+	sort.Strings(status.Pending)
+	sort.Strings(status.Unknown)
+	return status, nil
+}
+
+// Optimize refreshes the query planner statistics with ANALYZE and lets
+// SQLite apply any other optimizations it deems useful (PRAGMA optimize).
+// It returns how long the maintenance took.
+func (s *SQLiteStorage) Optimize(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+
+	for _, stmt := range []string{"ANALYZE", "PRAGMA optimize"} {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return 0, fmt.Errorf("failed to run %s: %w", stmt, err)
+		}
+	}
+
+	elapsed := time.Since(start)
+	s.logger.Info("Database optimized", "duration", elapsed)
+	return elapsed, nil
+}
+
+// MaintenanceReport describes what Maintain did
+type MaintenanceReport struct {
+	SizeBefore       int64 // bytes taken by the database and its journal files
+	SizeAfter        int64
+	TombstonesPruned int64
+	Elapsed          time.Duration
+}
+
+// Maintain deletes the tombstones recorded before tombstoneCutoff, unless it
+// is zero, then rebuilds the database file to reclaim free pages (VACUUM),
+// rebuilds the indexes (REINDEX), and refreshes the query planner
+// statistics (see Optimize). A database synced with a pruned tombstone's
+// task still in it brings the task back.
+func (s *SQLiteStorage) Maintain(ctx context.Context, tombstoneCutoff time.Time) (*MaintenanceReport, error) {
+	start := time.Now()
+	report := &MaintenanceReport{SizeBefore: s.size()}
+
+	if !tombstoneCutoff.IsZero() {
+		result, err := s.db.ExecContext(ctx, "DELETE FROM tombstones WHERE deleted_at < ?", tombstoneCutoff.UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to prune tombstones: %w", err)
+		}
+		if report.TombstonesPruned, err = result.RowsAffected(); err != nil {
+			return nil, fmt.Errorf("failed to prune tombstones: %w", err)
+		}
+	}
+
+	for _, stmt := range []string{"VACUUM", "REINDEX"} {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to run %s: %w", stmt, err)
+		}
+	}
+	if _, err := s.Optimize(ctx); err != nil {
+		return nil, err
+	}
+	// Fold the write-ahead log back into the database file, so the size
+	// after reflects the reclaimed space
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return nil, fmt.Errorf("failed to checkpoint: %w", err)
+	}
+
+	report.SizeAfter = s.size()
+	report.Elapsed = time.Since(start)
+	s.logger.Info("Database maintained", "size_before", report.SizeBefore, "size_after", report.SizeAfter,
+		"tombstones_pruned", report.TombstonesPruned, "duration", report.Elapsed)
+	return report, nil
+}
+
+// CountTombstones returns how many tombstones were recorded before cutoff
+func (s *SQLiteStorage) CountTombstones(ctx context.Context, cutoff time.Time) (int64, error) {
+	var n int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM tombstones WHERE deleted_at < ?", cutoff.UTC()).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count tombstones: %w", err)
+	}
+	return n, nil
+}
+
+// size returns the bytes taken by the database file and its sidecar files
+func (s *SQLiteStorage) size() int64 {
+	var total int64
+	for _, suffix := range append([]string{""}, sidecarSuffixes...) {
+		if info, err := os.Stat(s.path + suffix); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// integrityCheck runs PRAGMA integrity_check against db
+func integrityCheck(ctx context.Context, db *sql.DB) error {
+	return pragmaCheck(ctx, db, "integrity_check")
+}
+
+// pragmaCheck runs an integrity checking pragma, integrity_check or
+// quick_check, against db
+func pragmaCheck(ctx context.Context, db *sql.DB, pragma string) error {
+	rows, err := db.QueryContext(ctx, "PRAGMA "+pragma)
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+		return fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return fmt.Errorf("failed to read integrity check result: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read integrity check result: %w", err)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// tableCounts returns the row count of every user table in db
+func tableCounts(ctx context.Context, db *sql.DB) (map[string]int64, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var n int64
+		if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %q", table)).Scan(&n); err != nil {
+			return nil, fmt.Errorf("failed to count rows in %s: %w", table, err)
+		}
+		counts[table] = n
+	}
+
+	return counts, nil
+}
+
+// Archive moves completed tasks finished before cutoff into the SQLite file at
+// archivePath, creating it if needed. The archive is attached to the main
+// database and the rows are copied and deleted in one transaction. It
+// returns the number of tasks archived.
+func (s *SQLiteStorage) Archive(ctx context.Context, archivePath string, cutoff time.Time) (int64, error) {
+	// Opening the archive once brings its schema up to date
+	archive, err := NewSQLiteStorage(ctx, archivePath, s.logger)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open archive: %w", err)
+	}
+	archive.Close()
+
+	// ATTACH applies to a single connection, so pin one for the whole move
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS archive", archivePath); err != nil {
+		return 0, fmt.Errorf("failed to attach archive: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE archive")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Both files are migrated by the same code, so their columns line up
+	where := " WHERE status = 'completed' AND completed_at < ?"
+	if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO archive.tasks SELECT * FROM main.tasks"+where, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to copy tasks to archive: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO archive.task_fields SELECT * FROM main.task_fields WHERE task_id IN (SELECT id FROM main.tasks"+where+")", cutoff); err != nil {
+		return 0, fmt.Errorf("failed to copy task fields to archive: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO archive.task_links SELECT * FROM main.task_links WHERE task_id IN (SELECT id FROM main.tasks"+where+")", cutoff); err != nil {
+		return 0, fmt.Errorf("failed to copy task links to archive: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO archive.task_comments SELECT * FROM main.task_comments WHERE task_id IN (SELECT id FROM main.tasks"+where+")", cutoff); err != nil {
+		return 0, fmt.Errorf("failed to copy task comments to archive: %w", err)
+	}
+
+	// Archived rows of an encrypted database stay encrypted, so the archive
+	// needs the same key settings to be readable
+	if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO archive.encryption SELECT * FROM main.encryption"); err != nil {
+		return 0, fmt.Errorf("failed to copy encryption settings to archive: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM main.tasks"+where, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove archived tasks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit archive: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	s.logger.Info("Tasks archived", "count", n, "archive", archivePath)
+	return n, nil
+}
+
+// Relocate moves the database to dst. The data is copied with VACUUM INTO,
+// which produces a consistent single-file snapshot that already includes any
+// WAL content, and the copy is verified (integrity check and per-table row
+// counts) before the original file and its sidecar files are removed.
+// The storage is closed afterwards; callers must reopen it at dst.
+func (s *SQLiteStorage) Relocate(ctx context.Context, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("destination already exists: %s", dst)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	expected, err := tableCounts(ctx, s.db)
+	if err != nil {
+		return err
 	}
 
-	// Inline migration SQL
-	migrations := map[string]string{
-		"001_create_tasks_table": `
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", dst); err != nil {
+		return fmt.Errorf("failed to copy database: %w", err)
+	}
+
+	if err := verifyCopy(ctx, dst, expected); err != nil {
+		os.Remove(dst)
+		return err
+	}
+
+	if err := s.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+
+	for _, path := range append([]string{s.path}, sidecars(s.path)...) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn("Failed to remove old database file", "path", path, "error", err)
+		}
+	}
+
+	s.logger.Info("Database relocated", "from", s.path, "to", dst)
+	s.path = dst
+	return nil
+}
+
+// verifyCopy checks that the database at path is intact and has the expected row counts
+func verifyCopy(ctx context.Context, path string, expected map[string]int64) error {
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return fmt.Errorf("failed to open copied database: %w", err)
+	}
+	defer db.Close()
+
+	if err := integrityCheck(ctx, db); err != nil {
+		return fmt.Errorf("copied database failed verification: %w", err)
+	}
+
+	actual, err := tableCounts(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for table, n := range expected {
+		if actual[table] != n {
+			return fmt.Errorf("copied database failed verification: table %s has %d rows, expected %d", table, actual[table], n)
+		}
+	}
+
+	return nil
+}
+
+// sidecars returns the paths of SQLite sidecar files for a database file
+func sidecars(path string) []string {
+	paths := make([]string, len(sidecarSuffixes))
+	for i, suffix := range sidecarSuffixes {
+		paths[i] = path + suffix
+	}
+	return paths
+}
+
+// migrations holds the schema changes by version, applied in version order
+var migrations = map[string]string{
+	"001_create_tasks_table": `
 -- Create tasks table
 CREATE TABLE IF NOT EXISTS tasks (
     id TEXT PRIMARY KEY,
@@ -114,6 +491,185 @@ CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks(priority);
 -- Create index on created_at for faster date filtering
 CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);
 		`,
+	"002_add_task_metadata": `
+-- Add JSON metadata column for integration-specific fields
+ALTER TABLE tasks ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
+		`,
+	"003_add_task_context": `
+-- Add GTD context column (e.g. home, office, errands)
+ALTER TABLE tasks ADD COLUMN context TEXT NOT NULL DEFAULT '';
+
+-- Create index on context for faster filtering
+CREATE INDEX IF NOT EXISTS idx_tasks_context ON tasks(context);
+		`,
+	"004_create_external_refs_table": `
+-- Create external references table mapping remote items to local tasks
+CREATE TABLE IF NOT EXISTS external_refs (
+    provider TEXT NOT NULL,
+    remote_id TEXT NOT NULL,
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    etag TEXT NOT NULL DEFAULT '',
+    remote_updated_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (provider, remote_id),
+    UNIQUE (provider, task_id)
+);
+
+-- Create index on task_id for reverse lookups
+CREATE INDEX IF NOT EXISTS idx_external_refs_task_id ON external_refs(task_id);
+		`,
+	"005_add_users_and_assignee": `
+-- Create users table for shared databases
+CREATE TABLE IF NOT EXISTS users (
+    name TEXT PRIMARY KEY,
+    email TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+-- Add assignee column referencing users.name
+ALTER TABLE tasks ADD COLUMN assignee TEXT NOT NULL DEFAULT '';
+
+-- Create index on assignee for faster filtering
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON tasks(assignee);
+		`,
+	"006_create_tombstones_table": `
+-- Create tombstones table recording deleted task IDs so deletes can be synced
+CREATE TABLE IF NOT EXISTS tombstones (
+    task_id TEXT PRIMARY KEY,
+    deleted_at DATETIME NOT NULL
+);
+		`,
+	"007_add_task_created_by": `
+-- Record who created each task, for following up on delegated work
+ALTER TABLE tasks ADD COLUMN created_by TEXT NOT NULL DEFAULT '';
+
+-- Create index on created_by for faster filtering
+CREATE INDEX IF NOT EXISTS idx_tasks_created_by ON tasks(created_by);
+		`,
+	"008_create_encryption_table": `
+-- Create encryption table holding the key salt and passphrase verifier of an encrypted database
+CREATE TABLE IF NOT EXISTS encryption (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    salt BLOB NOT NULL,
+    verifier TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+		`,
+	"009_add_task_wait_until": `
+-- Snoozed tasks are hidden from default views until wait_until passes
+ALTER TABLE tasks ADD COLUMN wait_until DATETIME;
+
+-- Create index on wait_until for faster filtering
+CREATE INDEX IF NOT EXISTS idx_tasks_wait_until ON tasks(wait_until);
+		`,
+	"010_add_task_pinned": `
+-- Pinned tasks sort to the top of list
+ALTER TABLE tasks ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+		`,
+	"011_add_task_estimates": `
+-- Effort estimates: expected duration in seconds and/or story points
+ALTER TABLE tasks ADD COLUMN estimate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN points INTEGER NOT NULL DEFAULT 0;
+		`,
+	"012_create_task_fields_table": `
+-- Create the side table holding user-defined field values declared in config.yaml
+CREATE TABLE IF NOT EXISTS task_fields (
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    value TEXT NOT NULL,
+    PRIMARY KEY (task_id, name)
+);
+
+-- Create index on name and value for filtering by a field
+CREATE INDEX IF NOT EXISTS idx_task_fields_name_value ON task_fields(name, value);
+		`,
+	"013_create_task_links_table": `
+-- Create task links table recording relationships to other tasks and URLs
+CREATE TABLE IF NOT EXISTS task_links (
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('relates', 'duplicates', 'see-also')),
+    target TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (task_id, kind, target)
+);
+
+-- Create index on target for listing the links pointing at a task
+CREATE INDEX IF NOT EXISTS idx_task_links_target ON task_links(target);
+		`,
+	"014_add_user_roles": `
+-- Add the role of each user; roles are enforced once a user is an admin
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member', 'viewer'));
+		`,
+	"015_create_task_comments_table": `
+-- Create task comments table holding the comment thread of each task
+CREATE TABLE IF NOT EXISTS task_comments (
+    id TEXT PRIMARY KEY,
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    author TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+-- Create index on task and time for listing the thread of a task
+CREATE INDEX IF NOT EXISTS idx_task_comments_task_id ON task_comments(task_id, created_at);
+		`,
+	"016_add_task_location": `
+-- Machine or place a task can be done at; empty when it can be done anywhere
+ALTER TABLE tasks ADD COLUMN location TEXT NOT NULL DEFAULT '';
+		`,
+	"017_add_task_last_activity": `
+-- Time of the last change to a task, its comments, or its links; drives "task stale"
+ALTER TABLE tasks ADD COLUMN last_activity_at DATETIME;
+UPDATE tasks SET last_activity_at = updated_at;
+
+-- Create index on last activity for finding stale tasks
+CREATE INDEX IF NOT EXISTS idx_tasks_last_activity_at ON tasks(last_activity_at);
+
+-- New tasks start active; copied tasks (archive, sync) keep their activity
+CREATE TRIGGER IF NOT EXISTS tasks_activity_insert AFTER INSERT ON tasks
+WHEN NEW.last_activity_at IS NULL
+BEGIN
+    UPDATE tasks SET last_activity_at = NEW.updated_at WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_activity_update AFTER UPDATE OF updated_at ON tasks
+WHEN NEW.updated_at IS NOT OLD.updated_at
+BEGIN
+    UPDATE tasks SET last_activity_at = NEW.updated_at WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_activity_comment AFTER INSERT ON task_comments
+BEGIN
+    UPDATE tasks SET last_activity_at = NEW.created_at
+    WHERE id = NEW.task_id AND (last_activity_at IS NULL OR last_activity_at < NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_activity_link AFTER INSERT ON task_links
+BEGIN
+    UPDATE tasks SET last_activity_at = NEW.created_at
+    WHERE id = NEW.task_id AND (last_activity_at IS NULL OR last_activity_at < NEW.created_at);
+END;
+		`,
+}
+
+// runMigrations runs database migrations
+func (s *SQLiteStorage) runMigrations(ctx context.Context) error {
+	// Create migrations table if it doesn't exist
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS migrations (
+			version TEXT PRIMARY KEY,
+			applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	// Get applied migrations
+	appliedMigrations, err := s.getAppliedMigrations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
 	// Get sorted migration versions
@@ -0,0 +1,155 @@
+// Package suggest keeps a small cache of completion data (task IDs, titles,
+// contexts and assignees) so shell completion can answer without querying the
+// database. The cache records a fingerprint of the database file and is
+// treated as stale as soon as the file changes.
+package suggest
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/xdg"
+)
+
+// Task is the part of a task needed to suggest it
+type Task struct {
+	ID     string            `json:"id"`
+	Title  string            `json:"title"`
+	Status domain.TaskStatus `json:"status"`
+}
+
+// Cache holds completion data for one database
+type Cache struct {
+	Fingerprint string   `json:"fingerprint"`
+	Tasks       []Task   `json:"tasks"`
+	Contexts    []string `json:"contexts,omitempty"`
+	Assignees   []string `json:"assignees,omitempty"`
+}
+
+// Build creates a cache from tasks, tagged with the database fingerprint
+func Build(fingerprint string, tasks []*domain.Task) *Cache {
+	cache := &Cache{Fingerprint: fingerprint, Tasks: make([]Task, 0, len(tasks))}
+	contexts := make(map[string]bool)
+	assignees := make(map[string]bool)
+
+	for _, task := range tasks {
+		cache.Tasks = append(cache.Tasks, Task{ID: task.ID, Title: task.Title, Status: task.Status})
+		if task.Context != "" && !contexts[task.Context] {
+			contexts[task.Context] = true
+			cache.Contexts = append(cache.Contexts, task.Context)
+		}
+		if task.Assignee != "" && !assignees[task.Assignee] {
+			assignees[task.Assignee] = true
+			cache.Assignees = append(cache.Assignees, task.Assignee)
+		}
+	}
+
+	sort.Strings(cache.Contexts)
+	sort.Strings(cache.Assignees)
+	return cache
+}
+
+// Fingerprint identifies the current contents of the SQLite file at dbPath by
+// its location, modification time and the file change counter SQLite keeps in
+// the database header, which every committed write increments.
+func Fingerprint(dbPath string) (string, error) {
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve database path: %w", err)
+	}
+
+	f, err := os.Open(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to open database: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat database: %w", err)
+	}
+
+	// The change counter is a big-endian uint32 at offset 24 of the header
+	header := make([]byte, 4)
+	if _, err := f.ReadAt(header, 24); err != nil {
+		return "", fmt.Errorf("failed to read database header: %w", err)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", abs, binary.BigEndian.Uint32(header), info.ModTime().UnixNano()), nil
+}
+
+// Store reads and writes the cache from a JSON file
+type Store struct {
+	path string
+}
+
+// NewStore creates a new cache store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the default cache file location,
+// $XDG_CACHE_HOME/task-manager/completion.json
+func DefaultPath() (string, error) {
+	dir, err := xdg.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "completion.json"), nil
+}
+
+// Load reads the cache file. A missing file yields an empty cache, which is
+// never fresh.
+func (s *Store) Load() (*Cache, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Cache{}, nil
+		}
+		return nil, fmt.Errorf("failed to read completion cache: %w", err)
+	}
+
+	cache := &Cache{}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse completion cache: %w", err)
+	}
+
+	return cache, nil
+}
+
+// Save writes the cache file atomically, creating its directory if needed
+func (s *Store) Save(cache *Cache) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode completion cache: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write completion cache: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace completion cache: %w", err)
+	}
+
+	return nil
+}
+
+// Clear removes the cache file, if any
+func (s *Store) Clear() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove completion cache: %w", err)
+	}
+	return nil
+}
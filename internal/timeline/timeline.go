@@ -0,0 +1,125 @@
+// Package timeline reconstructs recent task activity (tasks created,
+// updated, completed, and deleted) from the timestamps kept on each task
+// and the deletion records kept for sync. Only the latest update of a task
+// is known, and deleted tasks are known by their ID alone.
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// Kind is what happened to a task
+type Kind string
+
+const (
+	Created   Kind = "created"
+	Updated   Kind = "updated"
+	Completed Kind = "completed"
+	Deleted   Kind = "deleted"
+)
+
+// Event is something that happened to a task
+type Event struct {
+	At     time.Time
+	Kind   Kind
+	TaskID string
+	Task   *domain.Task // nil for Deleted
+}
+
+// Day is the events of one calendar day
+type Day struct {
+	Date   time.Time // midnight, local time
+	Events []Event
+}
+
+// Tasks is the part of the task service used to build a timeline
+type Tasks interface {
+	ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error)
+}
+
+// Tombstones lists deletion records
+type Tombstones interface {
+	ListTombstones(ctx context.Context) ([]*domain.Tombstone, error)
+}
+
+// sameTime is how close two timestamps of a task must be to count as one
+// change, e.g. the update recorded by completing a task
+const sameTime = time.Second
+
+// Build returns the events since the given time, oldest first, for the
+// tasks matching filter. Deletions come from tombstones, which may be nil;
+// they are left out when filter has a context, which deleted tasks no
+// longer record.
+func Build(ctx context.Context, tasks Tasks, tombstones Tombstones, filter domain.TaskFilter, since time.Time) ([]Event, error) {
+	// A task changed since then was last updated since then. The query
+	// compares whole days, so it starts a day early to be safe across time
+	// zones; events are cut off at since below.
+	recent := &domain.QueryCond{
+		Field: domain.QueryFieldUpdated,
+		Op:    domain.QueryGreaterEqual,
+		Date:  time.Date(since.Year(), since.Month(), since.Day()-1, 0, 0, 0, 0, since.Location()),
+	}
+	if filter.Query != nil {
+		filter.Query = &domain.QueryAnd{Left: filter.Query, Right: recent}
+	} else {
+		filter.Query = recent
+	}
+
+	changed, err := tasks.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	var events []Event
+	add := func(at time.Time, kind Kind, task *domain.Task) {
+		if !at.Before(since) {
+			events = append(events, Event{At: at, Kind: kind, TaskID: task.ID, Task: task})
+		}
+	}
+	for _, task := range changed {
+		add(task.CreatedAt, Created, task)
+		if task.CompletedAt != nil {
+			add(*task.CompletedAt, Completed, task)
+		}
+		if task.UpdatedAt.Sub(task.CreatedAt) > sameTime &&
+			(task.CompletedAt == nil || task.UpdatedAt.Sub(*task.CompletedAt).Abs() > sameTime) {
+			add(task.UpdatedAt, Updated, task)
+		}
+	}
+
+	if tombstones != nil && filter.Context == nil {
+		deleted, err := tombstones.ListTombstones(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deletions: %w", err)
+		}
+		for _, tombstone := range deleted {
+			if !tombstone.DeletedAt.Before(since) {
+				events = append(events, Event{At: tombstone.DeletedAt, Kind: Deleted, TaskID: tombstone.TaskID})
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+	return events, nil
+}
+
+// ByDay groups events, which must be in time order, by local calendar day
+func ByDay(events []Event) []Day {
+	var days []Day
+	for _, event := range events {
+		at := event.At.Local()
+		date := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.Local)
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, Day{Date: date})
+		}
+		days[len(days)-1].Events = append(days[len(days)-1].Events, event)
+	}
+	return days
+}
@@ -0,0 +1,66 @@
+// Package tracing configures optional OpenTelemetry tracing. When tracing is
+// disabled the global no-op tracer provider is left in place, so the spans
+// started by the service and repository layers cost next to nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationPrefix is prepended to the name of every tracer
+const instrumentationPrefix = "github.com/edson-mazvila/task-manager/internal/"
+
+// Tracer returns the tracer for a layer of the application, e.g. "service"
+func Tracer(layer string) trace.Tracer {
+	return otel.Tracer(instrumentationPrefix + layer)
+}
+
+// Setup installs a global tracer provider that exports spans over OTLP/HTTP.
+// It does nothing when tracing is disabled. The returned function flushes
+// pending spans and must be called before the process exits.
+func Setup(ctx context.Context, cfg config.TracingConfig, version string) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// Without an endpoint the exporter honors the standard OTEL_EXPORTER_OTLP_* variables
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "task-manager"),
+			attribute.String("service.version", version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// End records err on span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,118 @@
+// Package trend derives task history from creation and completion times:
+// daily open and completed counts for burndown charts, and completions per
+// week for velocity. Nothing is stored; deleted and archived tasks are not
+// part of the history.
+package trend
+
+import (
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// Day is the state of the tasks at the end of one day
+type Day struct {
+	Date      time.Time
+	Open      int // created by the end of the day and not yet completed
+	Completed int // completed by the end of the day
+}
+
+// Week counts the tasks completed in one week
+type Week struct {
+	Start     time.Time // Monday
+	Created   int
+	Completed int
+}
+
+// Burndown returns one Day per calendar day from from through to, in the
+// location of to
+func Burndown(tasks []*domain.Task, from, to time.Time) []Day {
+	loc := to.Location()
+	start := startOfDay(from.In(loc))
+	end := startOfDay(to)
+
+	var days []Day
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		days = append(days, Day{Date: date})
+	}
+	if len(days) == 0 {
+		return nil
+	}
+
+	for _, task := range tasks {
+		created := startOfDay(task.CreatedAt.In(loc))
+		completed, done := completedAt(task)
+		if done {
+			completed = startOfDay(completed.In(loc))
+		}
+
+		for i := range days {
+			date := days[i].Date
+			if done && !completed.After(date) {
+				days[i].Completed++
+			} else if !created.After(date) {
+				days[i].Open++
+			}
+		}
+	}
+	return days
+}
+
+// Velocity returns the tasks created and completed in each of the last
+// weeks weeks, oldest first. The last week is the one containing now.
+func Velocity(tasks []*domain.Task, weeks int, now time.Time) []Week {
+	if weeks <= 0 {
+		return nil
+	}
+
+	current := startOfWeek(now)
+	result := make([]Week, weeks)
+	for i := range result {
+		result[i].Start = current.AddDate(0, 0, -7*(weeks-1-i))
+	}
+
+	// index returns the week t falls in, or -1 outside the range
+	index := func(t time.Time) int {
+		week := startOfWeek(t.In(now.Location()))
+		days := int(week.Sub(result[0].Start).Hours()/24 + 0.5)
+		if days < 0 || days/7 >= weeks {
+			return -1
+		}
+		return days / 7
+	}
+
+	for _, task := range tasks {
+		if i := index(task.CreatedAt); i >= 0 {
+			result[i].Created++
+		}
+		if completed, ok := completedAt(task); ok {
+			if i := index(completed); i >= 0 {
+				result[i].Completed++
+			}
+		}
+	}
+	return result
+}
+
+// completedAt returns when a completed task was completed. Tasks completed
+// before completion times were recorded fall back to their last update.
+func completedAt(task *domain.Task) (time.Time, bool) {
+	if task.Status != domain.TaskStatusCompleted {
+		return time.Time{}, false
+	}
+	if task.CompletedAt != nil {
+		return *task.CompletedAt, true
+	}
+	return task.UpdatedAt, true
+}
+
+// startOfDay returns midnight of the day of t
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns midnight of the Monday of the week of t
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Series is one line of a chart
+type Series struct {
+	Name   string
+	Role   string // theme role used to color the line
+	Mark   rune   // plot character in ASCII mode
+	Values []int
+}
+
+// LineChart plots series sharing an x axis, such as one value per day.
+// Braille mode draws 2x4 dots per character for smoother lines; ASCII mode
+// uses one Mark per character and works in any terminal.
+type LineChart struct {
+	Series     []Series
+	Height     int // rows of the plot area
+	Width      int // columns of the plot area
+	Braille    bool
+	Start, End string // labels under the first and last x positions
+}
+
+// brailleDots maps a dot position (column, row from the top) within a
+// braille character to its bit
+var brailleDots = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// Render writes the chart, its axes, and a legend to w
+func (c *LineChart) Render(w io.Writer, painter *Painter) error {
+	if len(c.Series) == 0 || len(c.Series[0].Values) == 0 {
+		return nil
+	}
+	n := len(c.Series[0].Values)
+	maxValue := 1
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			maxValue = max(maxValue, v)
+		}
+	}
+
+	// Dot resolution of the plot area
+	dotsX, dotsY := c.Width, c.Height
+	if c.Braille {
+		dotsX, dotsY = c.Width*2, c.Height*4
+	}
+
+	// grid holds the dots set in each character cell and the series that set
+	// the last one, which decides the color
+	grid := make([][]rune, c.Height)
+	owner := make([][]int, c.Height)
+	for row := range grid {
+		grid[row] = make([]rune, c.Width)
+		owner[row] = make([]int, c.Width)
+	}
+	set := func(x, y, series int) {
+		top := dotsY - 1 - y
+		if c.Braille {
+			row, col := top/4, x/2
+			grid[row][col] |= brailleDots[x%2][top%4]
+			owner[row][col] = series
+			return
+		}
+		if grid[top][x] != 0 && owner[top][x] != series {
+			grid[top][x] = '*'
+		} else {
+			grid[top][x] = c.Series[series].Mark
+		}
+		owner[top][x] = series
+	}
+
+	for si, s := range c.Series {
+		prev := -1
+		for x := 0; x < dotsX; x++ {
+			i := 0
+			if dotsX > 1 {
+				i = int(math.Round(float64(x) * float64(n-1) / float64(dotsX-1)))
+			}
+			y := int(math.Round(float64(s.Values[i]) / float64(maxValue) * float64(dotsY-1)))
+
+			// Join steps with a vertical line so the series stays connected
+			from := y
+			if prev >= 0 && prev != y {
+				from = prev + sign(y-prev)
+			}
+			for yy := min(from, y); yy <= max(from, y); yy++ {
+				set(x, yy, si)
+			}
+			prev = y
+		}
+	}
+
+	vertical, tick, corner, horizontal := "│", "┤", "└", "─"
+	if !c.Braille {
+		vertical, tick, corner, horizontal = "|", "+", "+", "-"
+	}
+	labelWidth := len(strconv.Itoa(maxValue))
+
+	var sb strings.Builder
+	for row := range grid {
+		// Label the top, middle, and bottom rows
+		label, axis := "", vertical
+		switch {
+		case row == 0:
+			label, axis = strconv.Itoa(maxValue), tick
+		case row == c.Height-1:
+			label, axis = "0", tick
+		case c.Height >= 5 && row == (c.Height-1)/2:
+			label, axis = strconv.Itoa(int(math.Round(float64(maxValue)*float64(c.Height-1-row)/float64(c.Height-1)))), tick
+		}
+		fmt.Fprintf(&sb, "%*s %s", labelWidth, label, axis)
+
+		for col, cell := range grid[row] {
+			switch {
+			case cell == 0:
+				sb.WriteByte(' ')
+			case c.Braille:
+				sb.WriteString(painter.Paint(c.Series[owner[row][col]].Role, string(0x2800+cell)))
+			default:
+				sb.WriteString(painter.Paint(c.Series[owner[row][col]].Role, string(cell)))
+			}
+		}
+		sb.WriteByte('\n')
+	}
+
+	fmt.Fprintf(&sb, "%*s %s%s\n", labelWidth, "", corner, strings.Repeat(horizontal, c.Width))
+	gap := c.Width + 1 - utf8.RuneCountInString(c.Start) - utf8.RuneCountInString(c.End)
+	fmt.Fprintf(&sb, "%*s %s%s%s\n", labelWidth, "", c.Start, strings.Repeat(" ", max(gap, 1)), c.End)
+
+	var legend []string
+	for _, s := range c.Series {
+		mark := "●"
+		if !c.Braille {
+			mark = string(s.Mark)
+		}
+		legend = append(legend, painter.Paint(s.Role, mark)+" "+s.Name)
+	}
+	fmt.Fprintf(&sb, "\n%*s  %s\n", labelWidth, "", strings.Join(legend, "   "))
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Bar returns a horizontal bar width columns long at maxValue, drawn with
+// eighth blocks, or with '#' in ASCII mode
+func Bar(value, maxValue, width int, ascii bool) string {
+	if value <= 0 || maxValue <= 0 {
+		return ""
+	}
+	if ascii {
+		return strings.Repeat("#", max(1, int(math.Round(float64(value)*float64(width)/float64(maxValue)))))
+	}
+
+	eighths := max(1, int(math.Round(float64(value)*float64(width)*8/float64(maxValue))))
+	bar := strings.Repeat("█", eighths/8)
+	if rest := eighths % 8; rest > 0 {
+		bar += string([]rune("▏▎▍▌▋▊▉")[rest-1])
+	}
+	return bar
+}
+
+// sign returns -1, 0, or 1
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,170 @@
+// Package ui contains terminal presentation helpers shared by CLI commands:
+// ANSI color themes, column-aligned tables that stay aligned when cells are
+// colorized, and charts. Commands describe what to show; this package decides
+// how.
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"golang.org/x/term"
+)
+
+// Theme roles that can be mapped to colors in the display.theme configuration
+const (
+	RoleHeader         = "header"
+	RoleID             = "id"
+	RolePriorityHigh   = "priority.high"
+	RolePriorityMedium = "priority.medium"
+	RolePriorityLow    = "priority.low"
+	RoleCompleted      = "completed"
+	RoleOverdue        = "overdue"
+	RoleSuccess        = "success"
+)
+
+// ansiCodes maps color and style names to SGR parameters
+var ansiCodes = map[string]string{
+	"bold":      "1",
+	"dim":       "2",
+	"italic":    "3",
+	"underline": "4",
+	"black":     "30",
+	"red":       "31",
+	"green":     "32",
+	"yellow":    "33",
+	"blue":      "34",
+	"magenta":   "35",
+	"cyan":      "36",
+	"white":     "37",
+	"gray":      "90",
+}
+
+// DefaultTheme returns the built-in role to color mapping
+func DefaultTheme() map[string]string {
+	return map[string]string{
+		RoleHeader:         "bold",
+		RoleID:             "cyan",
+		RolePriorityHigh:   "red",
+		RolePriorityMedium: "yellow",
+		RolePriorityLow:    "green",
+		RoleCompleted:      "dim",
+		RoleOverdue:        "bold red",
+		RoleSuccess:        "green",
+	}
+}
+
+// Painter applies theme colors to text. A disabled painter returns text unchanged.
+type Painter struct {
+	enabled bool
+	codes   map[string]string
+}
+
+// NewPainter creates a painter from the default theme merged with overrides.
+// Each override value is a space-separated list of color and style names,
+// e.g. "bold magenta"; an empty value disables coloring for that role.
+func NewPainter(enabled bool, overrides map[string]string) (*Painter, error) {
+	theme := DefaultTheme()
+	for role, spec := range overrides {
+		if _, ok := theme[role]; !ok {
+			return nil, fmt.Errorf("unknown theme role: %s (must be one of %s)", role, strings.Join(Roles(), ", "))
+		}
+		theme[role] = spec
+	}
+
+	codes := make(map[string]string, len(theme))
+	for role, spec := range theme {
+		var params []string
+		for _, name := range strings.Fields(spec) {
+			code, ok := ansiCodes[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("unknown color %q for theme role %s", name, role)
+			}
+			params = append(params, code)
+		}
+		if len(params) > 0 {
+			codes[role] = "\x1b[" + strings.Join(params, ";") + "m"
+		}
+	}
+
+	return &Painter{enabled: enabled, codes: codes}, nil
+}
+
+// Roles returns the sorted list of theme roles
+func Roles() []string {
+	var roles []string
+	for role := range DefaultTheme() {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Enabled reports whether the painter emits escape codes
+func (p *Painter) Enabled() bool {
+	return p != nil && p.enabled
+}
+
+// Paint wraps text in the color configured for role
+func (p *Painter) Paint(role, text string) string {
+	if !p.Enabled() || text == "" {
+		return text
+	}
+	code, ok := p.codes[role]
+	if !ok {
+		return text
+	}
+	return code + text + "\x1b[0m"
+}
+
+// PriorityRole returns the theme role used for a priority
+func PriorityRole(priority domain.TaskPriority) string {
+	switch priority {
+	case domain.TaskPriorityHigh:
+		return RolePriorityHigh
+	case domain.TaskPriorityLow:
+		return RolePriorityLow
+	default:
+		return RolePriorityMedium
+	}
+}
+
+// ColorEnabled decides whether output to f should be colorized.
+// Color is disabled by the --no-color flag, by a non-empty NO_COLOR
+// environment variable (https://no-color.org), or when f is not a terminal.
+func ColorEnabled(noColor bool, f *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return IsTerminal(f)
+}
+
+// IsTerminal reports whether f refers to a character device such as a TTY
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// TerminalWidth returns the width in columns of the terminal f refers to, or
+// 0 when f is not a terminal. A positive COLUMNS environment variable
+// overrides it.
+func TerminalWidth(f *os.File) int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	if !IsTerminal(f) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
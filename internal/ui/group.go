@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// GroupBy is a way of splitting a task list into sections
+type GroupBy string
+
+const (
+	GroupByStatus   GroupBy = "status"
+	GroupByPriority GroupBy = "priority"
+	GroupByContext  GroupBy = "context"
+	GroupByDue      GroupBy = "due" // overdue, today, next 7 days, later, none
+)
+
+// GroupByValues lists the valid GroupBy values
+var GroupByValues = []string{string(GroupByStatus), string(GroupByPriority), string(GroupByContext), string(GroupByDue)}
+
+// TaskGroup is a titled section of a task list
+type TaskGroup struct {
+	Title string
+	Tasks []*domain.Task
+}
+
+// GroupTasks splits tasks into groups, keeping their order within each
+// group. Groups come in a fixed order (e.g. high priority first, overdue
+// first), contexts alphabetically with tasks without one last; empty groups
+// are left out. Snoozed tasks count as pending; due dates are days relative
+// to now (see domain.Task.DueDate).
+func GroupTasks(tasks []*domain.Task, by GroupBy, now time.Time) ([]TaskGroup, error) {
+	var keyOf func(task *domain.Task) string
+	var order []string
+
+	switch by {
+	case GroupByStatus:
+		order = []string{"Pending", "Completed"}
+		keyOf = func(task *domain.Task) string {
+			if task.Status == domain.TaskStatusCompleted {
+				return "Completed"
+			}
+			return "Pending"
+		}
+	case GroupByPriority:
+		order = []string{"High", "Medium", "Low"}
+		keyOf = func(task *domain.Task) string {
+			switch task.Priority {
+			case domain.TaskPriorityHigh:
+				return "High"
+			case domain.TaskPriorityLow:
+				return "Low"
+			default:
+				return "Medium"
+			}
+		}
+	case GroupByContext:
+		keyOf = func(task *domain.Task) string {
+			if task.Context == "" {
+				return "No context"
+			}
+			return "@" + task.Context
+		}
+	case GroupByDue:
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		order = []string{"Overdue", "Today", "Next 7 days", "Later", "Earlier", "No due date"}
+		keyOf = func(task *domain.Task) string {
+			due, ok := task.DueDate()
+			switch {
+			case !ok:
+				return "No due date"
+			case due.Before(today) && task.Status == domain.TaskStatusCompleted:
+				return "Earlier"
+			case due.Before(today):
+				return "Overdue"
+			case due.Before(today.AddDate(0, 0, 1)):
+				return "Today"
+			case due.Before(today.AddDate(0, 0, 8)):
+				return "Next 7 days"
+			default:
+				return "Later"
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid group: %s (must be status, priority, context, or due)", by)
+	}
+
+	groups := make(map[string][]*domain.Task)
+	var contexts []string
+	for _, task := range tasks {
+		key := keyOf(task)
+		if _, ok := groups[key]; !ok && by == GroupByContext && task.Context != "" {
+			contexts = append(contexts, key)
+		}
+		groups[key] = append(groups[key], task)
+	}
+	if by == GroupByContext {
+		sort.Strings(contexts)
+		order = append(contexts, "No context")
+	}
+
+	var result []TaskGroup
+	for _, key := range order {
+		if len(groups[key]) > 0 {
+			result = append(result, TaskGroup{Title: key, Tasks: groups[key]})
+		}
+	}
+	return result, nil
+}
+
+// RenderGroups writes each group as a header with its task count and a
+// table of its tasks, followed by the total of all tasks. Every table shows
+// the same columns.
+func RenderGroups(w io.Writer, painter *Painter, groups []TaskGroup, opts TaskTableOptions) error {
+	var all []*domain.Task
+	for _, group := range groups {
+		all = append(all, group.Tasks...)
+	}
+	TaskColumns(all, &opts)
+
+	for i, group := range groups {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		header := painter.Paint(RoleHeader, fmt.Sprintf("%s (%d)", group.Title, len(group.Tasks)))
+		if _, err := fmt.Fprintln(w, header); err != nil {
+			return err
+		}
+		if err := TaskTable(painter, group.Tasks, opts).Render(w); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n%s\n", TaskTotal(all))
+	return err
+}
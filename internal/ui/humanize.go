@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// relativeUnits are the units used by RelativeTime, largest first
+var relativeUnits = []struct {
+	size   time.Duration
+	suffix string
+}{
+	{365 * 24 * time.Hour, "y"},
+	{30 * 24 * time.Hour, "mo"},
+	{7 * 24 * time.Hour, "w"},
+	{24 * time.Hour, "d"},
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+}
+
+// RelativeTime describes t relative to now in compact form,
+// e.g. "3d ago" for past times and "in 2h" for future ones.
+// Differences under a minute are reported as "just now".
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	for _, unit := range relativeUnits {
+		if d >= unit.size {
+			amount := fmt.Sprintf("%d%s", int64(d/unit.size), unit.suffix)
+			if future {
+				return "in " + amount
+			}
+			return amount + " ago"
+		}
+	}
+
+	return "just now"
+}
+
+// Duration formats d without trailing zero units, e.g. "2h30m" rather than
+// "2h30m0s". Zero formats as "".
+func Duration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// Bytes formats a byte count in binary units, e.g. "1.5 MiB"
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Cell is a table cell with an optional theme role used for coloring
+type Cell struct {
+	Text string
+	Role string
+}
+
+// Table renders column-aligned rows. Unlike text/tabwriter it measures
+// the visible text before applying colors, so alignment survives ANSI codes.
+type Table struct {
+	painter  *Painter
+	header   []string
+	rows     [][]Cell
+	rowRole  []string
+	maxWidth int
+	flexible []int
+}
+
+// minFlexibleWidth is the narrowest a column is truncated to by Fit
+const minFlexibleWidth = 10
+
+// NewTable creates a table with the given column headers
+func NewTable(painter *Painter, header ...string) *Table {
+	return &Table{painter: painter, header: header}
+}
+
+// AddRow appends a row. A non-empty rowRole overrides the roles of
+// individual cells, e.g. to dim every column of a completed task.
+func (t *Table) AddRow(rowRole string, cells ...Cell) {
+	t.rows = append(t.rows, cells)
+	t.rowRole = append(t.rowRole, rowRole)
+}
+
+// Fit makes rows at most width characters wide by truncating the cells of
+// the given columns, widest column first, marking cut text with "…".
+// Columns are not truncated below their header or minFlexibleWidth, so
+// rows may still be wider. A width of 0 never truncates.
+func (t *Table) Fit(width int, columns ...int) {
+	t.maxWidth = width
+	t.flexible = columns
+}
+
+// Len returns the number of rows
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Render writes the header, an underline row, and all rows to w
+func (t *Table) Render(w io.Writer) error {
+	widths := make([]int, len(t.header))
+	for i, h := range t.header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(cell.Text); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	t.shrink(widths)
+
+	var sb strings.Builder
+
+	headerCells := make([]Cell, len(t.header))
+	underline := make([]Cell, len(t.header))
+	for i, h := range t.header {
+		headerCells[i] = Cell{Text: h, Role: RoleHeader}
+		underline[i] = Cell{Text: strings.Repeat("-", utf8.RuneCountInString(h))}
+	}
+	t.writeRow(&sb, widths, headerCells, "")
+	t.writeRow(&sb, widths, underline, "")
+
+	for i, row := range t.rows {
+		t.writeRow(&sb, widths, row, t.rowRole[i])
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// shrink narrows the flexible columns of widths until rows fit in maxWidth
+func (t *Table) shrink(widths []int) {
+	if t.maxWidth <= 0 {
+		return
+	}
+	total := 2 * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > t.maxWidth {
+		widest := -1
+		for _, i := range t.flexible {
+			if i >= len(widths) {
+				continue
+			}
+			floor := minFlexibleWidth
+			if i < len(t.header) {
+				floor = max(floor, utf8.RuneCountInString(t.header[i]))
+			}
+			if widths[i] > floor && (widest < 0 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// truncate shortens text to width characters, ending it with "…" when cut
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimRight(string(runes[:width-1]), " ") + "…"
+}
+
+// writeRow pads and colors one row
+func (t *Table) writeRow(sb *strings.Builder, widths []int, cells []Cell, rowRole string) {
+	for i, cell := range cells {
+		role := cell.Role
+		if rowRole != "" {
+			role = rowRole
+		}
+		text := truncate(cell.Text, widths[i])
+		sb.WriteString(t.painter.Paint(role, text))
+
+		if i < len(cells)-1 {
+			pad := widths[i] - utf8.RuneCountInString(text) + 2
+			sb.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/task-manager/internal/domain"
+)
+
+// PinMarker marks pinned tasks in task lists and details
+const PinMarker = "★"
+
+// TaskTableOptions controls how TaskTable shows tasks
+type TaskTableOptions struct {
+	Now      time.Time  // reference time for ages, due dates and snoozes
+	Absolute bool       // dates and times instead of "3d ago" or "due in 2 days"
+	Times    TimeFormat // format of absolute dates and times
+	Width    int        // truncate titles and descriptions to fit, 0 for no limit
+	Wide     bool       // add context, due and description columns
+	// Show the due, assignee and estimate columns; TaskTable sets them
+	// when some task has a due date, assignee or estimate
+	Due      bool
+	Assignee bool
+	Estimate bool
+}
+
+// Effort describes an estimate and points, e.g. "2h", "5 pts", or
+// "2h / 5 pts". Both zero formats as "".
+func Effort(estimate time.Duration, points int64) string {
+	var parts []string
+	if estimate > 0 {
+		parts = append(parts, Duration(estimate))
+	}
+	if points > 0 {
+		parts = append(parts, strconv.FormatInt(points, 10)+" pts")
+	}
+	return strings.Join(parts, " / ")
+}
+
+// TaskColumns sets opts.Due, opts.Assignee and opts.Estimate when some task
+// has a due date, an assignee or an estimate, so that several tables show
+// the same columns
+func TaskColumns(tasks []*domain.Task, opts *TaskTableOptions) {
+	for _, task := range tasks {
+		if _, ok := task.DueDate(); ok {
+			opts.Due = true
+		}
+		if task.Assignee != "" {
+			opts.Assignee = true
+		}
+		if task.Estimate > 0 || task.Points > 0 {
+			opts.Estimate = true
+		}
+	}
+}
+
+// TaskTable builds the table of tasks shown by list: ID, title, status,
+// priority, and age, plus the optional columns opts selects. Completed and
+// snoozed tasks are dimmed.
+func TaskTable(painter *Painter, tasks []*domain.Task, opts TaskTableOptions) *Table {
+	TaskColumns(tasks, &opts)
+
+	header := []string{"ID", "TITLE", "STATUS", "PRIORITY", "CREATED"}
+	if opts.Wide {
+		header = append(header, "CONTEXT")
+	}
+	if opts.Due || opts.Wide {
+		header = append(header, "DUE")
+	}
+	if opts.Assignee {
+		header = append(header, "ASSIGNEE")
+	}
+	if opts.Estimate {
+		header = append(header, "ESTIMATE")
+	}
+	if opts.Wide {
+		header = append(header, "DESCRIPTION")
+	}
+
+	table := NewTable(painter, header...)
+	if opts.Wide {
+		table.Fit(opts.Width, 1, len(header)-1)
+	} else {
+		table.Fit(opts.Width, 1)
+	}
+	for _, task := range tasks {
+		createdAt := RelativeTime(task.CreatedAt, opts.Now)
+		if opts.Absolute {
+			createdAt = opts.Times.DateTime(task.CreatedAt)
+		}
+
+		rowRole := ""
+		status := string(task.Status)
+		if task.Status == domain.TaskStatusCompleted {
+			rowRole = RoleCompleted
+		} else if task.Waiting(opts.Now) {
+			rowRole = RoleCompleted
+			status = "snoozed"
+		}
+
+		title := task.Title
+		if task.Pinned {
+			title = PinMarker + " " + title
+		}
+
+		cells := []Cell{
+			{Text: task.ID[:8], Role: RoleID},
+			{Text: title},
+			{Text: status},
+			{Text: string(task.Priority), Role: PriorityRole(task.Priority)},
+			{Text: createdAt},
+		}
+		if opts.Wide {
+			cells = append(cells, Cell{Text: task.Context})
+		}
+		if opts.Due || opts.Wide {
+			cells = append(cells, dueCell(task, opts))
+		}
+		if opts.Assignee {
+			cells = append(cells, Cell{Text: task.Assignee})
+		}
+		if opts.Estimate {
+			cells = append(cells, Cell{Text: Effort(task.Estimate, int64(task.Points))})
+		}
+		if opts.Wide {
+			description, _, _ := strings.Cut(task.Description, "\n")
+			cells = append(cells, Cell{Text: description})
+		}
+		table.AddRow(rowRole, cells...)
+	}
+	return table
+}
+
+// dueCell shows when a task is due, relative to now while it is pending
+func dueCell(task *domain.Task, opts TaskTableOptions) Cell {
+	due, ok := task.DueDate()
+	switch {
+	case !ok:
+		return Cell{}
+	case opts.Absolute || task.Status == domain.TaskStatusCompleted:
+		return Cell{Text: opts.Times.Day(due)}
+	case DaysUntil(due, opts.Now) < 0:
+		return Cell{Text: RelativeDue(due, opts.Now), Role: RoleOverdue}
+	default:
+		return Cell{Text: RelativeDue(due, opts.Now)}
+	}
+}
+
+// TaskTotal summarizes tasks for the line under a task list, e.g.
+// "Total: 4 task(s), 3h remaining"; the remaining effort is that of tasks
+// not completed
+func TaskTotal(tasks []*domain.Task) string {
+	var estimate time.Duration
+	var points int64
+	for _, task := range tasks {
+		if task.Status != domain.TaskStatusCompleted {
+			estimate += task.Estimate
+			points += int64(task.Points)
+		}
+	}
+
+	total := fmt.Sprintf("Total: %d task(s)", len(tasks))
+	if effort := Effort(estimate, points); effort != "" {
+		total += ", " + effort + " remaining"
+	}
+	return total
+}
+
+// RenderTasks writes tasks as a table followed by their total
+func RenderTasks(w io.Writer, painter *Painter, tasks []*domain.Task, opts TaskTableOptions) error {
+	if err := TaskTable(painter, tasks, opts).Render(w); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", TaskTotal(tasks))
+	return err
+}
+
+// priorityMarks are the one-letter priorities of RenderTaskLines
+var priorityMarks = map[domain.TaskPriority]string{
+	domain.TaskPriorityHigh:   "H",
+	domain.TaskPriorityMedium: "M",
+	domain.TaskPriorityLow:    "L",
+}
+
+// RenderTaskLines writes each task on one line, e.g.
+// "2ea12586 H ★ Renew passport @home (due in 3 days)", truncated to
+// opts.Width. Completed and snoozed tasks are dimmed.
+func RenderTaskLines(w io.Writer, painter *Painter, tasks []*domain.Task, opts TaskTableOptions) error {
+	for _, task := range tasks {
+		line := task.Title
+		if task.Pinned {
+			line = PinMarker + " " + line
+		}
+		if task.Context != "" {
+			line += " @" + task.Context
+		}
+		if due := dueCell(task, opts); due.Text != "" {
+			line += " (" + due.Text + ")"
+		}
+		if opts.Width > 0 {
+			line = truncate(line, max(opts.Width-11, minFlexibleWidth))
+		}
+
+		id := painter.Paint(RoleID, task.ID[:8])
+		mark := painter.Paint(PriorityRole(task.Priority), priorityMarks[task.Priority])
+		if task.Status == domain.TaskStatusCompleted || task.Waiting(opts.Now) {
+			id = painter.Paint(RoleCompleted, task.ID[:8])
+			mark = painter.Paint(RoleCompleted, priorityMarks[task.Priority])
+			line = painter.Paint(RoleCompleted, line)
+		}
+		if _, err := fmt.Fprintf(w, "%s %s %s\n", id, mark, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Default layouts of TimeFormat
+const (
+	DefaultDateLayout = "2006-01-02"
+	DefaultTimeLayout = "15:04"
+)
+
+// TimeFormat formats timestamps for display in configured layouts and a
+// configured time zone. The zero value uses the default layouts and the
+// local time zone.
+type TimeFormat struct {
+	DateLayout string         // Go layout of dates, e.g. "02/01/2006"
+	TimeLayout string         // Go layout of times of day, e.g. "3:04PM"
+	Location   *time.Location // nil for local time
+}
+
+// NewTimeFormat creates a TimeFormat from configured layouts and an IANA
+// time zone name such as "Europe/Lisbon"; empty values use the defaults
+func NewTimeFormat(dateLayout, timeLayout, timezone string) (TimeFormat, error) {
+	f := TimeFormat{DateLayout: dateLayout, TimeLayout: timeLayout}
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return f, fmt.Errorf("unknown time zone %q: %w", timezone, err)
+		}
+		f.Location = loc
+	}
+	return f, nil
+}
+
+// In returns t in the format's time zone
+func (f TimeFormat) In(t time.Time) time.Time {
+	if f.Location == nil {
+		return t.Local()
+	}
+	return t.In(f.Location)
+}
+
+// Date formats the date of t, e.g. "2026-01-05"
+func (f TimeFormat) Date(t time.Time) string {
+	layout := f.DateLayout
+	if layout == "" {
+		layout = DefaultDateLayout
+	}
+	return f.In(t).Format(layout)
+}
+
+// Time formats the time of day of t, e.g. "14:30"
+func (f TimeFormat) Time(t time.Time) string {
+	layout := f.TimeLayout
+	if layout == "" {
+		layout = DefaultTimeLayout
+	}
+	return f.In(t).Format(layout)
+}
+
+// DateTime formats t as its date and time of day, e.g. "2026-01-05 14:30"
+func (f TimeFormat) DateTime(t time.Time) string {
+	return f.Date(t) + " " + f.Time(t)
+}
+
+// Day formats a calendar day, such as a due date, which has no time zone
+// of its own to convert from
+func (f TimeFormat) Day(day time.Time) string {
+	layout := f.DateLayout
+	if layout == "" {
+		layout = DefaultDateLayout
+	}
+	return day.Format(layout)
+}
+
+// DaysUntil returns the number of calendar days from the day of now to
+// day, negative for past days
+func DaysUntil(day, now time.Time) int {
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+// RelativeDue describes a due day relative to now, e.g. "due today",
+// "due in 3 days", or "overdue by 1 day"
+func RelativeDue(due, now time.Time) string {
+	days := DaysUntil(due, now)
+	switch {
+	case days == 0:
+		return "due today"
+	case days == 1:
+		return "due tomorrow"
+	case days > 1:
+		return fmt.Sprintf("due in %d days", days)
+	case days == -1:
+		return "overdue by 1 day"
+	default:
+		return fmt.Sprintf("overdue by %d days", -days)
+	}
+}
@@ -0,0 +1,86 @@
+// Package xdg locates the per-user directories for Task Manager files
+// following the XDG Base Directory specification: the database under
+// $XDG_DATA_HOME, the config file under $XDG_CONFIG_HOME, the completion
+// cache under $XDG_CACHE_HOME, and CLI state and logs under $XDG_STATE_HOME.
+// Older versions kept everything in ~/.task-manager; that directory is still
+// read until its files are moved with "task db migrate-xdg".
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appName is the directory created inside each base directory
+const appName = "task-manager"
+
+// DataDir returns $XDG_DATA_HOME/task-manager (~/.local/share when unset)
+func DataDir() (string, error) {
+	return dir("XDG_DATA_HOME", ".local", "share")
+}
+
+// ConfigDir returns $XDG_CONFIG_HOME/task-manager (~/.config when unset)
+func ConfigDir() (string, error) {
+	return dir("XDG_CONFIG_HOME", ".config")
+}
+
+// CacheDir returns $XDG_CACHE_HOME/task-manager (~/.cache when unset)
+func CacheDir() (string, error) {
+	return dir("XDG_CACHE_HOME", ".cache")
+}
+
+// StateDir returns $XDG_STATE_HOME/task-manager (~/.local/state when unset)
+func StateDir() (string, error) {
+	return dir("XDG_STATE_HOME", ".local", "state")
+}
+
+// LegacyDir returns ~/.task-manager, where older versions kept all files
+func LegacyDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".task-manager"), nil
+}
+
+// Path returns the file called name inside base (one of the directory
+// functions above). If only the legacy directory has the file, the legacy
+// file is returned so existing installations keep working until migrated.
+func Path(base func() (string, error), name string) (string, error) {
+	d, err := base()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(d, name)
+
+	if exists(path) {
+		return path, nil
+	}
+	if legacy, err := LegacyDir(); err == nil && exists(filepath.Join(legacy, name)) {
+		return filepath.Join(legacy, name), nil
+	}
+	return path, nil
+}
+
+// dir returns the task-manager directory inside the base directory named by
+// env, or inside the home-relative fallback when env is unset. Relative
+// values are ignored, as the specification requires.
+func dir(env string, fallback ...string) (string, error) {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, appName), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(append(append([]string{homeDir}, fallback...), appName)...), nil
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
@@ -0,0 +1,2 @@
+-- Add JSON metadata column for integration-specific fields
+ALTER TABLE tasks ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
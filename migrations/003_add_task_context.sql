@@ -0,0 +1,5 @@
+-- Add GTD context column (e.g. home, office, errands)
+ALTER TABLE tasks ADD COLUMN context TEXT NOT NULL DEFAULT '';
+
+-- Create index on context for faster filtering
+CREATE INDEX IF NOT EXISTS idx_tasks_context ON tasks(context);
@@ -0,0 +1,15 @@
+-- Create external references table mapping remote items to local tasks
+CREATE TABLE IF NOT EXISTS external_refs (
+    provider TEXT NOT NULL,
+    remote_id TEXT NOT NULL,
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    etag TEXT NOT NULL DEFAULT '',
+    remote_updated_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (provider, remote_id),
+    UNIQUE (provider, task_id)
+);
+
+-- Create index on task_id for reverse lookups
+CREATE INDEX IF NOT EXISTS idx_external_refs_task_id ON external_refs(task_id);
@@ -0,0 +1,12 @@
+-- Create users table for shared databases
+CREATE TABLE IF NOT EXISTS users (
+    name TEXT PRIMARY KEY,
+    email TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+-- Add assignee column referencing users.name
+ALTER TABLE tasks ADD COLUMN assignee TEXT NOT NULL DEFAULT '';
+
+-- Create index on assignee for faster filtering
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON tasks(assignee);
@@ -0,0 +1,5 @@
+-- Create tombstones table recording deleted task IDs so deletes can be synced
+CREATE TABLE IF NOT EXISTS tombstones (
+    task_id TEXT PRIMARY KEY,
+    deleted_at DATETIME NOT NULL
+);
@@ -0,0 +1,5 @@
+-- Record who created each task, for following up on delegated work
+ALTER TABLE tasks ADD COLUMN created_by TEXT NOT NULL DEFAULT '';
+
+-- Create index on created_by for faster filtering
+CREATE INDEX IF NOT EXISTS idx_tasks_created_by ON tasks(created_by);
@@ -0,0 +1,7 @@
+-- Create encryption table holding the key salt and passphrase verifier of an encrypted database
+CREATE TABLE IF NOT EXISTS encryption (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    salt BLOB NOT NULL,
+    verifier TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,5 @@
+-- Snoozed tasks are hidden from default views until wait_until passes
+ALTER TABLE tasks ADD COLUMN wait_until DATETIME;
+
+-- Create index on wait_until for faster filtering
+CREATE INDEX IF NOT EXISTS idx_tasks_wait_until ON tasks(wait_until);
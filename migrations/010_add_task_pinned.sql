@@ -0,0 +1,2 @@
+-- Pinned tasks sort to the top of list
+ALTER TABLE tasks ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
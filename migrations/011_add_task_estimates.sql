@@ -0,0 +1,3 @@
+-- Effort estimates: expected duration in seconds and/or story points
+ALTER TABLE tasks ADD COLUMN estimate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN points INTEGER NOT NULL DEFAULT 0;
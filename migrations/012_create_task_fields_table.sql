@@ -0,0 +1,10 @@
+-- Create the side table holding user-defined field values declared in config.yaml
+CREATE TABLE IF NOT EXISTS task_fields (
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    value TEXT NOT NULL,
+    PRIMARY KEY (task_id, name)
+);
+
+-- Create index on name and value for filtering by a field
+CREATE INDEX IF NOT EXISTS idx_task_fields_name_value ON task_fields(name, value);
@@ -0,0 +1,11 @@
+-- Create task links table recording relationships to other tasks and URLs
+CREATE TABLE IF NOT EXISTS task_links (
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('relates', 'duplicates', 'see-also')),
+    target TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (task_id, kind, target)
+);
+
+-- Create index on target for listing the links pointing at a task
+CREATE INDEX IF NOT EXISTS idx_task_links_target ON task_links(target);
@@ -0,0 +1,2 @@
+-- Add the role of each user; roles are enforced once a user is an admin
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member', 'viewer'));
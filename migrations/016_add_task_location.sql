@@ -0,0 +1,2 @@
+-- Machine or place a task can be done at; empty when it can be done anywhere
+ALTER TABLE tasks ADD COLUMN location TEXT NOT NULL DEFAULT '';
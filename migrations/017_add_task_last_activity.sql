@@ -0,0 +1,31 @@
+-- Time of the last change to a task, its comments, or its links; drives "task stale"
+ALTER TABLE tasks ADD COLUMN last_activity_at DATETIME;
+UPDATE tasks SET last_activity_at = updated_at;
+
+-- Create index on last activity for finding stale tasks
+CREATE INDEX IF NOT EXISTS idx_tasks_last_activity_at ON tasks(last_activity_at);
+
+-- New tasks start active; copied tasks (archive, sync) keep their activity
+CREATE TRIGGER IF NOT EXISTS tasks_activity_insert AFTER INSERT ON tasks
+WHEN NEW.last_activity_at IS NULL
+BEGIN
+    UPDATE tasks SET last_activity_at = NEW.updated_at WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_activity_update AFTER UPDATE OF updated_at ON tasks
+WHEN NEW.updated_at IS NOT OLD.updated_at
+BEGIN
+    UPDATE tasks SET last_activity_at = NEW.updated_at WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_activity_comment AFTER INSERT ON task_comments
+BEGIN
+    UPDATE tasks SET last_activity_at = NEW.created_at
+    WHERE id = NEW.task_id AND (last_activity_at IS NULL OR last_activity_at < NEW.created_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_activity_link AFTER INSERT ON task_links
+BEGIN
+    UPDATE tasks SET last_activity_at = NEW.created_at
+    WHERE id = NEW.task_id AND (last_activity_at IS NULL OR last_activity_at < NEW.created_at);
+END;
@@ -0,0 +1,190 @@
+// Package plugin helps write external subcommands for task. Running
+// "task foo" when foo is not a built-in command runs the first task-foo
+// executable on PATH with the remaining arguments, and with environment
+// variables describing the task installation. Plugins read those with
+// FromEnv and reach tasks through a Client, which runs the task binary
+// that started them, so they work with any database task can open.
+//
+//	func main() {
+//		client, err := plugin.New()
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		tasks, err := client.List(context.Background(), "--status", "pending")
+//		...
+//	}
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Environment variables set for plugins
+const (
+	EnvBinary       = "TASK_BIN"         // path of the task executable that ran the plugin
+	EnvPlugin       = "TASK_PLUGIN"      // name of the plugin, e.g. "foo" for task-foo
+	EnvConfigFile   = "TASK_CONFIG_FILE" // configuration file in use, which may not exist
+	EnvDatabaseType = "TASK_DB_TYPE"     // sqlite or postgres
+	EnvDatabasePath = "TASK_DB_PATH"     // SQLite database file; empty for PostgreSQL
+	EnvContext      = "TASK_CONTEXT"     // active context, empty when none is set
+)
+
+// Prefix is the prefix of plugin executables: "task foo" runs task-foo
+const Prefix = "task-"
+
+// ErrNotPlugin is returned by FromEnv when the program was not run by task
+var ErrNotPlugin = errors.New("not run as a task plugin (TASK_BIN is not set)")
+
+// Env describes the task installation that ran a plugin
+type Env struct {
+	Binary       string
+	Plugin       string
+	ConfigFile   string
+	DatabaseType string
+	DatabasePath string
+	Context      string
+}
+
+// FromEnv reads the environment set by task for plugins
+func FromEnv() (Env, error) {
+	env := Env{
+		Binary:       os.Getenv(EnvBinary),
+		Plugin:       os.Getenv(EnvPlugin),
+		ConfigFile:   os.Getenv(EnvConfigFile),
+		DatabaseType: os.Getenv(EnvDatabaseType),
+		DatabasePath: os.Getenv(EnvDatabasePath),
+		Context:      os.Getenv(EnvContext),
+	}
+	if env.Binary == "" {
+		return env, ErrNotPlugin
+	}
+	return env, nil
+}
+
+// Vars returns the environment as NAME=value pairs, as used by exec.Cmd.Env
+func (e Env) Vars() []string {
+	return []string{
+		EnvBinary + "=" + e.Binary,
+		EnvPlugin + "=" + e.Plugin,
+		EnvConfigFile + "=" + e.ConfigFile,
+		EnvDatabaseType + "=" + e.DatabaseType,
+		EnvDatabasePath + "=" + e.DatabasePath,
+		EnvContext + "=" + e.Context,
+	}
+}
+
+// Task is a task as listed by "task list --output json"
+type Task struct {
+	ID          string            `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Status      string            `json:"status"`   // pending or completed
+	Priority    string            `json:"priority"` // low, medium, or high
+	Context     string            `json:"context,omitempty"`
+	Assignee    string            `json:"assignee,omitempty"`
+	CreatedBy   string            `json:"created_by,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	WaitUntil   *time.Time        `json:"wait_until,omitempty"`
+	Pinned      bool              `json:"pinned,omitempty"`
+	Estimate    string            `json:"estimate,omitempty"` // e.g. "2h30m"
+	Points      int               `json:"points,omitempty"`
+	Location    string            `json:"location,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"`
+	Metadata    map[string]any    `json:"metadata,omitempty"`
+}
+
+// Client runs task commands for a plugin
+type Client struct {
+	env Env
+}
+
+// New creates a client for the task installation that ran the plugin
+func New() (*Client, error) {
+	env, err := FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(env), nil
+}
+
+// NewClient creates a client for the task installation env describes
+func NewClient(env Env) *Client {
+	return &Client{env: env}
+}
+
+// Env returns the environment the client was created with
+func (c *Client) Env() Env {
+	return c.env
+}
+
+// List returns the tasks "task list" shows with args, e.g. "--status",
+// "pending" or "--all"
+func (c *Client) List(ctx context.Context, args ...string) ([]Task, error) {
+	out, err := c.Output(ctx, append([]string{"list", "--output", "json"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	if err := json.Unmarshal(out, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to parse task list: %w", err)
+	}
+	return tasks, nil
+}
+
+// Add creates a task, with flags of "task add" in args, e.g. "--priority", "high"
+func (c *Client) Add(ctx context.Context, title string, args ...string) error {
+	_, err := c.Output(ctx, append([]string{"add", title}, args...)...)
+	return err
+}
+
+// Complete marks a task as completed
+func (c *Client) Complete(ctx context.Context, id string) error {
+	_, err := c.Output(ctx, "complete", id)
+	return err
+}
+
+// Output runs a task command and returns its standard output. A failing
+// command returns an error with the message task printed.
+func (c *Client) Output(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := c.command(ctx, args)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			msg, _, _ = strings.Cut(msg, "\n")
+			return nil, fmt.Errorf("task %s: %s", strings.Join(args, " "), strings.TrimPrefix(msg, "Error: "))
+		}
+		return nil, fmt.Errorf("failed to run task %s: %w", strings.Join(args, " "), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Run runs a task command attached to the plugin's standard input, output,
+// and error
+func (c *Client) Run(ctx context.Context, args ...string) error {
+	cmd := c.command(ctx, args)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// command prepares a task command; output is never paged
+func (c *Client) command(ctx context.Context, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, c.env.Binary, append([]string{"--no-pager"}, args...)...)
+	cmd.Env = append(os.Environ(), c.env.Vars()...)
+	return cmd
+}
@@ -1,12 +1,32 @@
 package integration
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/edson-mazvila/task-manager/internal/cli"
 	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/crash"
+	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/integrations/git"
+	"github.com/edson-mazvila/task-manager/internal/logging"
+	"github.com/edson-mazvila/task-manager/internal/secrets"
+	"github.com/edson-mazvila/task-manager/internal/state"
+	"github.com/edson-mazvila/task-manager/internal/suggest"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/edson-mazvila/task-manager/internal/xdg"
+	"github.com/edson-mazvila/task-manager/pkg/plugin"
+	"github.com/zalando/go-keyring"
 )
 
 // TestCLIConfiguration tests configuration loading
@@ -75,6 +95,8 @@ func TestCLIConfiguration(t *testing.T) {
 // TestConfigValidation tests configuration validation logic
 func TestConfigValidation(t *testing.T) {
 	t.Run("sqlite_creates_default_path", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
 		os.Setenv("DB_TYPE", "sqlite")
 		defer os.Unsetenv("DB_TYPE")
 
@@ -87,8 +109,8 @@ func TestConfigValidation(t *testing.T) {
 			t.Error("expected default DB path to be set")
 		}
 
-		if !strings.Contains(cfg.Database.Path, ".task-manager") {
-			t.Errorf("expected default path to contain .task-manager, got: %s", cfg.Database.Path)
+		if want := filepath.Join(home, ".local", "share", "task-manager", "tasks.db"); cfg.Database.Path != want {
+			t.Errorf("expected default path %s, got: %s", want, cfg.Database.Path)
 		}
 	})
 
@@ -137,6 +159,45 @@ func TestConfigValidation(t *testing.T) {
 			t.Errorf("expected error about invalid log format, got: %v", err)
 		}
 	})
+
+	t.Run("invalid_timezone", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(configPath, []byte("display:\n  timezone: Mars/Olympus\n"), 0644); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+		t.Setenv("CONFIG_FILE", configPath)
+
+		_, err := config.Load()
+		if err == nil || !strings.Contains(err.Error(), "display.timezone") {
+			t.Errorf("expected error about the time zone, got: %v", err)
+		}
+	})
+
+	t.Run("invalid_alias", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(configPath, []byte("aliases:\n  urgent: \"\"\n"), 0644); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+		t.Setenv("CONFIG_FILE", configPath)
+
+		_, err := config.Load()
+		if err == nil || !strings.Contains(err.Error(), "alias urgent") {
+			t.Errorf("expected error about the alias, got: %v", err)
+		}
+	})
+
+	t.Run("escalation_without_recipients", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(configPath, []byte("escalations:\n  - name: late\n    overdue: 48h\n"), 0644); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+		t.Setenv("CONFIG_FILE", configPath)
+
+		_, err := config.Load()
+		if err == nil || !strings.Contains(err.Error(), "escalation late needs a webhook, an email, or desktop") {
+			t.Errorf("expected error about the escalation, got: %v", err)
+		}
+	})
 }
 
 // TestConfigFileLoading tests YAML configuration file loading
@@ -151,6 +212,9 @@ func TestConfigFileLoading(t *testing.T) {
 logging:
   level: debug
   format: json
+
+display:
+  list_format: "{{.ID}} {{.Title}}"
 `
 
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
@@ -180,6 +244,10 @@ logging:
 	if cfg.Logging.Format != "json" {
 		t.Errorf("expected log format json, got %s", cfg.Logging.Format)
 	}
+
+	if cfg.Display.ListFormat != "{{.ID}} {{.Title}}" {
+		t.Errorf("expected list format template, got %s", cfg.Display.ListFormat)
+	}
 }
 
 // TestEnvVarOverride tests that environment variables override config file
@@ -250,3 +318,1143 @@ func TestConfigOptionalFile(t *testing.T) {
 		t.Error("expected valid config with defaults")
 	}
 }
+
+// TestConfigInit tests writing the default config file and changing settings in it
+func TestConfigInit(t *testing.T) {
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	path, err := config.UserFilePath()
+	if err != nil {
+		t.Fatalf("failed to get user config path: %v", err)
+	}
+	if filepath.Base(filepath.Dir(path)) != "task-manager" {
+		t.Errorf("expected config under a task-manager directory, got %s", path)
+	}
+	if config.FilePath() != path {
+		t.Errorf("expected Load to read %s, got %s", path, config.FilePath())
+	}
+
+	if err := config.WriteDefaultFile(path, false); err != nil {
+		t.Fatalf("failed to write default config: %v", err)
+	}
+	if err := config.WriteDefaultFile(path, false); err == nil {
+		t.Error("expected error when the config file already exists")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load default config file: %v", err)
+	}
+	if cfg.Database.Type != "sqlite" || cfg.Logging.Level != "info" || !cfg.Behavior.ConfirmDelete || cfg.Database.Retry.Attempts != 3 {
+		t.Errorf("expected default settings from the default file, got %+v", cfg)
+	}
+
+	keys := config.Keys()
+	if !config.IsKey("database.path") || !config.IsKey("behavior.timeout") {
+		t.Errorf("expected scalar settings in keys, got %v", keys)
+	}
+	if config.IsKey("rules") || config.IsKey("display.theme") || config.IsKey("database") {
+		t.Errorf("expected only scalar settings in keys, got %v", keys)
+	}
+
+	if err := config.SetFileValue(path, "logging.level", "debug"); err != nil {
+		t.Fatalf("failed to set config value: %v", err)
+	}
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("failed to load changed config: %v", err)
+	}
+	if cfg.Logging.Level != "debug" {
+		t.Errorf("expected changed log level, got %s", cfg.Logging.Level)
+	}
+}
+
+// TestXDGPaths tests XDG base directory locations and the legacy ~/.task-manager fallback
+func TestXDGPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(env, "")
+	}
+
+	dbPath, err := config.DefaultDatabasePath()
+	if err != nil {
+		t.Fatalf("failed to get default database path: %v", err)
+	}
+	if want := filepath.Join(home, ".local", "share", "task-manager", "tasks.db"); dbPath != want {
+		t.Errorf("expected %s, got %s", want, dbPath)
+	}
+
+	cachePath, _ := suggest.DefaultPath()
+	if want := filepath.Join(home, ".cache", "task-manager", "completion.json"); cachePath != want {
+		t.Errorf("expected %s, got %s", want, cachePath)
+	}
+
+	statePath, _ := state.DefaultPath()
+	if want := filepath.Join(home, ".local", "state", "task-manager", "state.json"); statePath != want {
+		t.Errorf("expected %s, got %s", want, statePath)
+	}
+
+	// Absolute XDG variables take precedence; relative ones are ignored
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	if dir, _ := xdg.DataDir(); dir != filepath.Join(dataHome, "task-manager") {
+		t.Errorf("expected data dir under XDG_DATA_HOME, got %s", dir)
+	}
+	t.Setenv("XDG_CONFIG_HOME", "relative/config")
+	if dir, _ := xdg.ConfigDir(); dir != filepath.Join(home, ".config", "task-manager") {
+		t.Errorf("expected relative XDG_CONFIG_HOME to be ignored, got %s", dir)
+	}
+
+	// An existing database in the legacy directory keeps being used until migrated
+	legacy := filepath.Join(home, ".task-manager")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatalf("failed to create legacy directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "tasks.db"), nil, 0600); err != nil {
+		t.Fatalf("failed to create legacy database: %v", err)
+	}
+	if dbPath, _ := config.DefaultDatabasePath(); dbPath != filepath.Join(legacy, "tasks.db") {
+		t.Errorf("expected legacy database to be used, got %s", dbPath)
+	}
+}
+
+// TestProjectConfig tests per-directory project files and their trust
+func TestProjectConfig(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("DB_PATH", "")
+	t.Setenv("TASK_NO_PROJECT", "")
+
+	root := t.TempDir()
+	project := "project:\n  context: work\n  priority: high\ndatabase:\n  path: .tasks/tasks.db\n"
+	if err := os.WriteFile(filepath.Join(root, ".task.yaml"), []byte(project), 0644); err != nil {
+		t.Fatalf("failed to write project file: %v", err)
+	}
+	sub := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("failed to create subdirectory: %v", err)
+	}
+	t.Chdir(sub)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.ProjectFile() != filepath.Join(root, ".task.yaml") {
+		t.Errorf("expected project file found in a parent directory, got %q", cfg.ProjectFile())
+	}
+	if cfg.Project.Context != "work" || cfg.Project.Priority != "high" {
+		t.Errorf("expected project defaults, got %+v", cfg.Project)
+	}
+	if want := filepath.Join(root, ".tasks", "tasks.db"); cfg.Database.Path != want {
+		t.Errorf("expected database path relative to the project file %s, got %s", want, cfg.Database.Path)
+	}
+
+	// Environment variables still take precedence over the project file
+	t.Setenv("DB_PATH", "/tmp/env.db")
+	if cfg, err := config.Load(); err != nil || cfg.Database.Path != "/tmp/env.db" {
+		t.Errorf("expected DB_PATH to override the project file, got %v (%v)", cfg, err)
+	}
+
+	t.Setenv("TASK_NO_PROJECT", "1")
+	if cfg, err := config.Load(); err != nil || cfg.ProjectFile() != "" || cfg.Project.Context != "" {
+		t.Errorf("expected TASK_NO_PROJECT to skip the project file, got %v (%v)", cfg, err)
+	}
+	t.Setenv("TASK_NO_PROJECT", "")
+
+	if err := os.WriteFile(filepath.Join(root, ".task.yaml"), []byte("project:\n  priority: urgent\n"), 0644); err != nil {
+		t.Fatalf("failed to write project file: %v", err)
+	}
+	if _, err := config.Load(); err == nil {
+		t.Error("expected error for an invalid project priority")
+	}
+
+	// Settings that reach the network need the file to be trusted, and
+	// editing it revokes the trust
+	project = "project:\n  context: work\nwebhooks:\n  - url: https://example.com/hook\n"
+	if err := os.WriteFile(filepath.Join(root, ".task.yaml"), []byte(project), 0644); err != nil {
+		t.Fatalf("failed to write project file: %v", err)
+	}
+	if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "task config trust") {
+		t.Errorf("expected an untrusted project file with webhooks to be rejected, got %v", err)
+	}
+	if err := config.TrustProjectFile(filepath.Join(root, ".task.yaml")); err != nil {
+		t.Fatalf("failed to trust project file: %v", err)
+	}
+	if cfg, err := config.Load(); err != nil || len(cfg.Webhooks) != 1 {
+		t.Errorf("expected a trusted project file to set webhooks, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".task.yaml"), []byte(project+"  - url: https://example.org/hook\n"), 0644); err != nil {
+		t.Fatalf("failed to write project file: %v", err)
+	}
+	if _, err := config.Load(); err == nil {
+		t.Error("expected an edited project file to lose its trust")
+	}
+}
+
+// TestProjectConfigPager tests that project files cannot choose the pager,
+// trusted or not
+func TestProjectConfigPager(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("TASK_NO_PROJECT", "")
+
+	root := t.TempDir()
+	path := filepath.Join(root, ".task.yaml")
+	if err := os.WriteFile(path, []byte("display:\n  pager: \"sh -c id\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write project file: %v", err)
+	}
+	t.Chdir(root)
+
+	if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "display.pager") {
+		t.Errorf("expected a project file with display.pager to be rejected, got %v", err)
+	}
+	if err := config.TrustProjectFile(path); err != nil {
+		t.Fatalf("failed to trust project file: %v", err)
+	}
+	if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "display.pager") {
+		t.Errorf("expected a trusted project file with display.pager to be rejected, got %v", err)
+	}
+}
+
+func TestGitIntegration(t *testing.T) {
+	ids := git.ParseTrailers("Fix login\n\nLonger body.\n\nTask: 1A2B3C4D, 5e6f7a8b\nSigned-off-by: A <a@example.com>")
+	if len(ids) != 2 || ids[0] != "1a2b3c4d" || ids[1] != "5e6f7a8b" {
+		t.Errorf("expected two task IDs from the trailer, got %v", ids)
+	}
+	if ids := git.ParseTrailers("task: 1a2b3c4d is mentioned in the subject only"); len(ids) != 0 {
+		t.Errorf("expected no trailers in a subject-only message, got %v", ids)
+	}
+	if name := git.BranchName("1a2b3c4d-0000", "Fix: login redirect (again)!"); name != "task/1a2b3c4d-fix-login-redirect-again" {
+		t.Errorf("unexpected branch name %s", name)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	if _, err := git.Open(ctx, dir); !errors.Is(err, git.ErrNotRepository) {
+		t.Fatalf("expected ErrNotRepository outside a repository, got %v", err)
+	}
+
+	gitCmd := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	gitCmd("init", "-q")
+	gitCmd("commit", "-q", "--allow-empty", "-m", "Initial commit")
+	gitCmd("commit", "-q", "--allow-empty", "-m", "Fix login", "-m", "task: 1a2b3c4d")
+
+	repo, err := git.Open(ctx, dir)
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+
+	commits, err := repo.Commits(ctx, "HEAD", 0)
+	if err != nil {
+		t.Fatalf("failed to read commits: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "Fix login" || len(commits[0].TaskIDs) != 1 || len(commits[1].TaskIDs) != 0 {
+		t.Errorf("expected the newest commit to carry the trailer, got %+v", commits)
+	}
+
+	if err := repo.CreateBranch(ctx, "task/1a2b3c4d-fix-login"); err != nil {
+		t.Fatalf("failed to create branch: %v", err)
+	}
+	if exists, err := repo.BranchExists(ctx, "task/1a2b3c4d-fix-login"); err != nil || !exists {
+		t.Errorf("expected branch to exist, got %v (%v)", exists, err)
+	}
+	if exists, err := repo.BranchExists(ctx, "task/missing"); err != nil || exists {
+		t.Errorf("expected missing branch to not exist, got %v (%v)", exists, err)
+	}
+
+	// A foreign hook is kept unless forced; our own hook is replaced
+	hook := filepath.Join(dir, ".git", "hooks", "post-commit")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\necho custom\n"), 0755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+	if _, err := repo.InstallHook(ctx, "task git scan", false); !errors.Is(err, git.ErrHookExists) {
+		t.Errorf("expected ErrHookExists for a foreign hook, got %v", err)
+	}
+	if _, err := repo.InstallHook(ctx, "task git scan", true); err != nil {
+		t.Fatalf("failed to force hook install: %v", err)
+	}
+	if _, err := repo.InstallHook(ctx, "task git scan --max 1", false); err != nil {
+		t.Errorf("expected our own hook to be replaced, got %v", err)
+	}
+}
+
+func TestLoggingSubsystemDebug(t *testing.T) {
+	os.Setenv("LOG_DEBUG", "repository, sync")
+	defer os.Unsetenv("LOG_DEBUG")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if len(cfg.Logging.Debug) != 2 || cfg.Logging.Debug[0] != "repository" {
+		t.Fatalf("expected debug subsystems [repository sync], got %v", cfg.Logging.Debug)
+	}
+
+	var buf bytes.Buffer
+	registry := logging.NewRegistry(cfg.Logging, &buf)
+
+	registry.Logger(logging.Repository).Debug("repository debug")
+	registry.Logger(logging.Service).Debug("service debug")
+	registry.Logger(logging.Service).Info("service info")
+
+	out := buf.String()
+	if !strings.Contains(out, "repository debug") {
+		t.Error("expected repository debug record to be logged")
+	}
+	if strings.Contains(out, "service debug") {
+		t.Error("expected service debug record to be suppressed")
+	}
+	if !strings.Contains(out, "subsystem=service") {
+		t.Errorf("expected records to carry the subsystem name, got: %s", out)
+	}
+
+	if registry.Logger(logging.Repository) != registry.Logger(logging.Repository) {
+		t.Error("expected registry to return the same logger for a subsystem")
+	}
+}
+
+// TestLogFileRotation tests writing logs to a rotating file
+func TestLogFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "task.log")
+
+	out, closeLog, err := logging.Output(config.LoggingConfig{Output: config.LogOutputFile, File: path, MaxSizeMB: 1, MaxBackups: 2}, os.Stderr)
+	if err != nil {
+		t.Fatalf("failed to open log output: %v", err)
+	}
+	registry := logging.NewRegistry(config.LoggingConfig{Level: "info", Format: "json"}, out)
+	registry.Logger(logging.Service).Info("written to file")
+	if err := closeLog(); err != nil {
+		t.Fatalf("failed to close log file: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"written to file"`) {
+		t.Errorf("expected record in log file, got %q", data)
+	}
+
+	// Rotate small files, keeping at most two backups
+	f, err := logging.NewRotatingFile(path, 100, 0, 2)
+	if err != nil {
+		t.Fatalf("failed to open rotating file: %v", err)
+	}
+	defer f.Close()
+
+	line := []byte(strings.Repeat("x", 59) + "\n")
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write(line); err != nil {
+			t.Fatalf("failed to write log line: %v", err)
+		}
+		time.Sleep(2 * time.Millisecond) // distinct backup timestamps
+	}
+
+	if backups := logging.Backups(path); len(backups) != 2 {
+		t.Errorf("expected 2 backups to be kept, got %v", backups)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat log file: %v", err)
+	}
+	if info.Size() != int64(len(line)) {
+		t.Errorf("expected current log file to hold one line, got %d bytes", info.Size())
+	}
+
+	// A file rotated by another process is reopened before the next write
+	if err := os.Rename(path, path+".moved"); err != nil {
+		t.Fatalf("failed to move log file: %v", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		t.Fatalf("failed to write log line: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected log file to be recreated: %v", err)
+	}
+}
+
+// TestCrashReport tests that crash reports are written with secrets redacted
+func TestCrashReport(t *testing.T) {
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Type:     "postgres",
+			User:     "tasks",
+			Password: "s3cret",
+		},
+	}
+
+	report := crash.NewReport("v1.2.3", "task list", "boom", []byte("goroutine 1 [running]:"), cfg)
+
+	dir := t.TempDir()
+	path, err := report.Write(dir)
+	if err != nil {
+		t.Fatalf("failed to write crash report: %v", err)
+	}
+
+	if filepath.Dir(path) != dir {
+		t.Errorf("expected report in %s, got %s", dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read crash report: %v", err)
+	}
+
+	content := string(data)
+	for _, want := range []string{"v1.2.3", "task list", "Panic: boom", "goroutine 1", "user: tasks"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected crash report to contain %q", want)
+		}
+	}
+	if strings.Contains(content, "s3cret") {
+		t.Error("expected database password to be redacted")
+	}
+	if cfg.Database.Password != "s3cret" {
+		t.Error("redaction must not modify the original configuration")
+	}
+}
+
+// TestConfigKeyring tests reading secrets from the OS keyring
+func TestConfigKeyring(t *testing.T) {
+	keyring.MockInit()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `database:
+  type: postgres
+  host: localhost
+  name: tasks
+  user: tasks
+  password: keyring
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	os.Setenv("CONFIG_FILE", configPath)
+	defer os.Unsetenv("CONFIG_FILE")
+
+	if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "set-secret db-password") {
+		t.Errorf("expected error pointing to set-secret, got %v", err)
+	}
+
+	if err := secrets.Set("api-token", "x"); err == nil {
+		t.Error("expected error for unknown secret name")
+	}
+	if err := secrets.Set(secrets.DBPassword, "s3cret"); err != nil {
+		t.Fatalf("failed to store secret: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.Database.Password != "s3cret" {
+		t.Errorf("expected password from keyring, got %q", cfg.Database.Password)
+	}
+	if !cfg.FromKeyring(secrets.DBPassword) || cfg.FromKeyring(secrets.DBPassphrase) {
+		t.Error("expected only the password to be reported as read from the keyring")
+	}
+	if got := cfg.Redacted().Database.Password; got != config.KeyringValue {
+		t.Errorf("expected redacted password %q, got %q", config.KeyringValue, got)
+	}
+
+	if err := secrets.Delete(secrets.DBPassword); err != nil {
+		t.Fatalf("failed to delete secret: %v", err)
+	}
+	if _, err := secrets.Get(secrets.DBPassword); !errors.Is(err, secrets.ErrNotFound) {
+		t.Errorf("expected secret to be gone, got %v", err)
+	}
+}
+
+// TestExitCodes tests mapping command errors to process exit codes
+func TestExitCodes(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	_, notFound := env.Service.GetTask(env.ctx, "nonexistent-id")
+	_, invalid := env.Service.CreateTask(env.ctx, "", "", domain.TaskPriorityLow)
+	_, driverErr := env.Storage.DB().ExecContext(env.ctx, "SELECT * FROM no_such_table")
+
+	expired, cancel := context.WithTimeout(env.ctx, -time.Second)
+	defer cancel()
+	_, timedOut := env.Service.ListTasks(expired, domain.TaskFilter{})
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, cli.ExitOK},
+		{"not_found", fmt.Errorf("failed to get task: %w", notFound), cli.ExitNotFound},
+		{"validation", fmt.Errorf("failed to create task: %w", invalid), cli.ExitValidation},
+		{"rule_violation", &domain.RuleViolation{Rule: "r", Message: "m"}, cli.ExitValidation},
+		{"storage", fmt.Errorf("failed to list tasks: %w", driverErr), cli.ExitStorage},
+		{"timeout", timedOut, cli.ExitTimeout},
+		{"forbidden", fmt.Errorf("failed to delete task: %w", domain.ErrForbidden), cli.ExitForbidden},
+		{"interrupted", fmt.Errorf("failed to list tasks: %w", context.Canceled), cli.ExitInterrupted},
+		{"other", fmt.Errorf("something else"), cli.ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cli.ExitCode(tt.err); got != tt.want {
+				t.Errorf("expected exit code %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPluginClient(t *testing.T) {
+	t.Setenv(plugin.EnvBinary, "")
+	if _, err := plugin.New(); !errors.Is(err, plugin.ErrNotPlugin) {
+		t.Errorf("expected ErrNotPlugin outside task, got %v", err)
+	}
+
+	// A stand-in for the task binary that records its arguments
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := `#!/bin/sh
+echo "$TASK_PLUGIN $*" > ` + argsFile + `
+case "$2" in
+list) echo '[{"id":"2ea12586","title":"Renew passport","status":"pending","priority":"high","context":"home","created_at":"2026-01-02T15:04:05Z","updated_at":"2026-01-02T15:04:05Z"}]' ;;
+*) echo "Error: task not found" >&2; exit 2 ;;
+esac
+`
+	binary := filepath.Join(dir, "task")
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake task binary: %v", err)
+	}
+
+	t.Setenv(plugin.EnvBinary, binary)
+	t.Setenv(plugin.EnvPlugin, "report")
+	client, err := plugin.New()
+	if err != nil {
+		t.Fatalf("failed to create plugin client: %v", err)
+	}
+
+	tasks, err := client.List(context.Background(), "--status", "pending")
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "Renew passport" || tasks[0].Priority != "high" || tasks[0].Context != "home" {
+		t.Errorf("unexpected tasks: %+v", tasks)
+	}
+	recorded, _ := os.ReadFile(argsFile)
+	if got := strings.TrimSpace(string(recorded)); got != "report --no-pager list --output json --status pending" {
+		t.Errorf("unexpected task command: %q", got)
+	}
+
+	err = client.Complete(context.Background(), "missing")
+	if err == nil || !strings.Contains(err.Error(), "task not found") {
+		t.Errorf("expected the error task printed, got %v", err)
+	}
+}
+
+// runCLI runs c with args as the command line and stdin as its input, and
+// returns what it printed to stdout; what it printed to stderr is logged.
+// HOME points at a temporary directory, so state and caches stay out of the
+// user's, and messages are in English.
+func runCLI(t *testing.T, c *cli.CLI, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("LC_ALL", "C")
+
+	in := filepath.Join(dir, "stdin")
+	if err := os.WriteFile(in, []byte(stdin), 0600); err != nil {
+		t.Fatalf("failed to write stdin: %v", err)
+	}
+	inFile, err := os.Open(in)
+	if err != nil {
+		t.Fatalf("failed to open stdin: %v", err)
+	}
+	defer inFile.Close()
+	outFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatalf("failed to create stdout: %v", err)
+	}
+	defer outFile.Close()
+	errFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatalf("failed to create stderr: %v", err)
+	}
+	defer errFile.Close()
+
+	args0, stdin0, stdout0, stderr0 := os.Args, os.Stdin, os.Stdout, os.Stderr
+	os.Args = append([]string{"task"}, args...)
+	os.Stdin, os.Stdout, os.Stderr = inFile, outFile, errFile
+	err = c.Execute()
+	os.Args, os.Stdin, os.Stdout, os.Stderr = args0, stdin0, stdout0, stderr0
+
+	if stderr, _ := os.ReadFile(errFile.Name()); len(stderr) > 0 {
+		t.Logf("task %s: stderr:\n%s", strings.Join(args, " "), stderr)
+	}
+
+	out, readErr := os.ReadFile(outFile.Name())
+	if readErr != nil {
+		t.Fatalf("failed to read stdout: %v", readErr)
+	}
+	return string(out), err
+}
+
+// newTestCLI returns a CLI over the test environment's service and storage
+func (te *TestEnvironment) newTestCLI(opts ...cli.Option) *cli.CLI {
+	return cli.NewCLI(te.Service, te.Logger, append([]cli.Option{cli.WithStorage(te.Storage)}, opts...)...)
+}
+
+// TestCSVOutput tests that list --output csv writes RFC 4180 CSV
+func TestCSVOutput(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	tricky, err := env.Service.CreateTask(env.ctx, `Buy milk, eggs and "good" bread`, "First line\nsecond line", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	done, err := env.Service.CreateTask(env.ctx, "Done already", "", domain.TaskPriorityHigh)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CompleteTask(env.ctx, done.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+
+	out, err := runCLI(t, env.newTestCLI(), "", "list", "--output", "csv")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV output: %v\n%s", err, out)
+	}
+
+	header := []string{"id", "title", "description", "status", "priority", "context", "created", "updated", "completed", "due"}
+	if len(records) != 3 || !slices.Equal(records[0], header) {
+		t.Fatalf("expected a header and 2 rows, got %q", records)
+	}
+	rows := map[string][]string{}
+	for _, record := range records[1:] {
+		rows[record[0]] = record
+	}
+
+	row := rows[tricky.ID]
+	if row == nil || row[1] != tricky.Title || row[2] != tricky.Description {
+		t.Errorf("expected commas, quotes, and newlines to survive quoting, got %q", row)
+	}
+	if row != nil && row[8] != "" {
+		t.Errorf("expected an empty completed column for a pending task, got %q", row[8])
+	}
+	if row := rows[done.ID]; row == nil || row[3] != "completed" || row[8] == "" {
+		t.Errorf("expected a completed time for a completed task, got %q", row)
+	} else if _, err := time.Parse(time.RFC3339, row[8]); err != nil {
+		t.Errorf("expected an RFC 3339 completed time, got %q", row[8])
+	}
+}
+
+// TestColorOutput tests when output is colored, and that an invalid theme
+// is reported
+func TestColorOutput(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	t.Setenv("NO_COLOR", "")
+	tty, err := os.Open(os.DevNull) // a character device, as a terminal is
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", os.DevNull, err)
+	}
+	defer tty.Close()
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	if !ui.ColorEnabled(false, tty) {
+		t.Error("expected color on a terminal")
+	}
+	if ui.ColorEnabled(true, tty) {
+		t.Error("expected --no-color to turn color off")
+	}
+	if ui.ColorEnabled(false, file) {
+		t.Error("expected no color when output is not a terminal")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if ui.ColorEnabled(false, tty) {
+		t.Error("expected NO_COLOR to turn color off")
+	}
+	t.Setenv("NO_COLOR", "")
+
+	painter, err := ui.NewPainter(true, map[string]string{ui.RoleID: "bold red"})
+	if err != nil {
+		t.Fatalf("failed to create painter: %v", err)
+	}
+	if got := painter.Paint(ui.RoleID, "abc"); !strings.Contains(got, "\x1b[") || !strings.Contains(got, "abc") {
+		t.Errorf("expected an ANSI colored ID, got %q", got)
+	}
+	plain, _ := ui.NewPainter(false, nil)
+	if got := plain.Paint(ui.RoleID, "abc"); got != "abc" {
+		t.Errorf("expected plain text without color, got %q", got)
+	}
+
+	if _, err := env.Service.CreateTask(env.ctx, "Colorful", "", domain.TaskPriorityHigh); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	// Output to a file has no escape codes, whatever the theme
+	cfg := &config.Config{Display: config.DisplayConfig{Theme: map[string]string{ui.RoleID: "bold red"}}}
+	out, err := runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), "", "list")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "Colorful") || strings.Contains(out, "\x1b[") {
+		t.Errorf("expected uncolored output to a file, got %q", out)
+	}
+
+	for name, theme := range map[string]map[string]string{
+		"unknown color": {ui.RoleID: "ultraviolet"},
+		"unknown role":  {"sparkles": "red"},
+	} {
+		cfg := &config.Config{Display: config.DisplayConfig{Theme: theme}}
+		if _, err := runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), "", "list"); err == nil || !strings.Contains(err.Error(), "invalid display theme") {
+			t.Errorf("%s: expected an invalid theme error, got %v", name, err)
+		}
+	}
+}
+
+// TestConfigExportImport tests that config export leaves secrets out of the
+// bundle and that config import writes back the exported settings
+func TestConfigExportImport(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	keyring.MockInit()
+	if err := secrets.Set(secrets.SMTPPassword, "smtp-s3cret"); err != nil {
+		t.Fatalf("failed to store secret: %v", err)
+	}
+	defer secrets.Delete(secrets.SMTPPassword)
+
+	tmpDir := t.TempDir()
+	source := filepath.Join(tmpDir, "source.yaml")
+	configContent := fmt.Sprintf(`database:
+  path: %s
+  password: db-s3cret
+  passphrase: correct-horse
+display:
+  default_command: next
+email:
+  host: smtp.example.com
+  password: keyring
+`, filepath.Join(tmpDir, "tasks.db"))
+	if err := os.WriteFile(source, []byte(configContent), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", source)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	bundle, err := runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), "", "config", "export")
+	if err != nil {
+		t.Fatalf("config export failed: %v", err)
+	}
+	for _, secret := range []string{"db-s3cret", "correct-horse", "smtp-s3cret"} {
+		if strings.Contains(bundle, secret) {
+			t.Errorf("expected bundle to leave out %q, got:\n%s", secret, bundle)
+		}
+	}
+	if cfg.Database.Password != "db-s3cret" || cfg.Email.Password != "smtp-s3cret" {
+		t.Error("expected export to leave the loaded configuration untouched")
+	}
+
+	target := filepath.Join(tmpDir, "target.yaml")
+	t.Setenv("CONFIG_FILE", target)
+	if _, err := runCLI(t, env.newTestCLI(), bundle, "config", "import", "-"); err != nil {
+		t.Fatalf("config import failed: %v", err)
+	}
+
+	imported, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load imported config: %v", err)
+	}
+	if imported.Database.Path != cfg.Database.Path || imported.Display.DefaultCommand != "next" || imported.Email.Host != "smtp.example.com" {
+		t.Errorf("expected settings to round-trip, got database.path=%q display.default_command=%q email.host=%q",
+			imported.Database.Path, imported.Display.DefaultCommand, imported.Email.Host)
+	}
+	if imported.Database.Password != "" || imported.Database.Passphrase != "" {
+		t.Errorf("expected no database secrets after import, got %q/%q", imported.Database.Password, imported.Database.Passphrase)
+	}
+	if imported.Email.Password != "smtp-s3cret" || !imported.FromKeyring(secrets.SMTPPassword) {
+		t.Errorf("expected the keyring reference to survive the round trip, got %q", imported.Email.Password)
+	}
+
+	if _, err := runCLI(t, env.newTestCLI(), bundle, "config", "import", "-"); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected import over an existing file to require --force, got %v", err)
+	}
+}
+
+// TestFlagCompletion tests that --context and --assignee complete from the
+// completion cache, which is rebuilt once the database changes
+func TestFlagCompletion(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	for _, fixture := range []struct{ context, assignee string }{
+		{"work", "bob"},
+		{"home", "alice"},
+		{"work", "alice"},
+	} {
+		if _, err := env.Service.CreateTask(env.ctx, "Task", "", domain.TaskPriorityLow,
+			domain.WithTaskContext(fixture.context), domain.WithAssignee(fixture.assignee)); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	store := suggest.NewStore(filepath.Join(t.TempDir(), "completion.json"))
+	complete := func(args ...string) []string {
+		t.Helper()
+		out, err := runCLI(t, env.newTestCLI(cli.WithSuggestionStore(store)), "", append([]string{"__complete"}, args...)...)
+		if err != nil {
+			t.Fatalf("__complete %v failed: %v", args, err)
+		}
+		// The last line is the shell directive, e.g. ":4"
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		return lines[:len(lines)-1]
+	}
+
+	if got := complete("list", "--context", ""); !slices.Equal(got, []string{"home", "work"}) {
+		t.Errorf("expected contexts [home work], got %v", got)
+	}
+	if got := complete("list", "--assignee", ""); !slices.Equal(got, []string{"alice", "bob"}) {
+		t.Errorf("expected assignees [alice bob], got %v", got)
+	}
+
+	// Completions come from the cache while it matches the database
+	cache, err := store.Load()
+	if err != nil {
+		t.Fatalf("expected completion to write the cache: %v", err)
+	}
+	cache.Contexts = append(cache.Contexts, "cached-only")
+	if err := store.Save(cache); err != nil {
+		t.Fatalf("failed to save cache: %v", err)
+	}
+	if got := complete("next", "--context", ""); !slices.Contains(got, "cached-only") {
+		t.Errorf("expected completions from the cache, got %v", got)
+	}
+
+	// A write makes the cache stale, so the next completion rebuilds it
+	if _, err := env.Service.CreateTask(env.ctx, "Weed", "", domain.TaskPriorityLow,
+		domain.WithTaskContext("garden"), domain.WithAssignee("carol")); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if got := complete("list", "--context", ""); !slices.Equal(got, []string{"garden", "home", "work"}) {
+		t.Errorf("expected rebuilt contexts [garden home work], got %v", got)
+	}
+	if got := complete("modify", "--assignee", ""); !slices.Equal(got, []string{"alice", "bob", "carol"}) {
+		t.Errorf("expected rebuilt assignees [alice bob carol], got %v", got)
+	}
+}
+
+// TestCompletionCacheRefresh tests that commands refresh the completion
+// cache only when they write to the database
+func TestCompletionCacheRefresh(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	store := suggest.NewStore(filepath.Join(t.TempDir(), "completion.json"))
+	newCLI := func() *cli.CLI { return env.newTestCLI(cli.WithSuggestionStore(store)) }
+
+	if _, err := env.Service.CreateTask(env.ctx, "Existing", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := runCLI(t, newCLI(), "", "list"); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if cache, err := store.Load(); err != nil || cache.Fingerprint != "" {
+		t.Errorf("expected a read-only command to leave the cache alone, got %+v (%v)", cache, err)
+	}
+
+	if _, err := runCLI(t, newCLI(), "", "add", "Fresh", "--context", "garden"); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	cache, err := store.Load()
+	if err != nil {
+		t.Fatalf("expected a write to refresh the cache: %v", err)
+	}
+	fingerprint, err := suggest.Fingerprint(env.DBPath)
+	if err != nil {
+		t.Fatalf("failed to fingerprint database: %v", err)
+	}
+	if cache.Fingerprint != fingerprint || len(cache.Tasks) != 2 || !slices.Equal(cache.Contexts, []string{"garden"}) {
+		t.Errorf("expected a current cache with both tasks, got %+v", cache)
+	}
+
+	if _, err := runCLI(t, newCLI(), "", "add", "Imaginary", "--dry-run"); err != nil {
+		t.Fatalf("add --dry-run failed: %v", err)
+	}
+	if after, err := store.Load(); err != nil || after.Fingerprint != fingerprint {
+		t.Errorf("expected a dry run to leave the cache alone, got %+v (%v)", after, err)
+	}
+}
+
+// TestRelativeTimes tests relative ages, and that list --absolute shows
+// dates and times instead
+func TestRelativeTimes(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		offset time.Duration
+		want   string
+	}{
+		{-30 * time.Second, "just now"},
+		{59 * time.Second, "just now"},
+		{-90 * time.Second, "1m ago"},
+		{2 * time.Hour, "in 2h"},
+		{-3 * 24 * time.Hour, "3d ago"},
+		{-15 * 24 * time.Hour, "2w ago"},
+		{45 * 24 * time.Hour, "in 1mo"},
+		{-400 * 24 * time.Hour, "1y ago"},
+	} {
+		if got := ui.RelativeTime(now.Add(tc.offset), now); got != tc.want {
+			t.Errorf("RelativeTime(now%+v) = %q, want %q", tc.offset, got, tc.want)
+		}
+	}
+
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	task, err := env.Service.CreateTask(env.ctx, "Old task", "", domain.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	created := time.Date(2025, 1, 5, 14, 30, 0, 0, time.UTC)
+	if _, err := env.Storage.DB().Exec("UPDATE tasks SET created_at = ? WHERE id = ?", created, task.ID); err != nil {
+		t.Fatalf("failed to age task: %v", err)
+	}
+
+	cfg := &config.Config{Display: config.DisplayConfig{Timezone: "UTC"}}
+	relative, err := runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), "", "list")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(relative, "y ago") || strings.Contains(relative, "2025-01-05") {
+		t.Errorf("expected a relative age, got:\n%s", relative)
+	}
+
+	absolute, err := runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), "", "list", "--absolute")
+	if err != nil {
+		t.Fatalf("list --absolute failed: %v", err)
+	}
+	if !strings.Contains(absolute, "2025-01-05 14:30") || strings.Contains(absolute, "ago") {
+		t.Errorf("expected the absolute creation time, got:\n%s", absolute)
+	}
+}
+
+// TestDeleteConfirmation tests that delete refuses to run unattended
+// without --yes, and deletes with it
+func TestDeleteConfirmation(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	task, err := env.Service.CreateTask(env.ctx, "Keep me", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	// Stdin is not a terminal, so even a "y" on it is not a confirmation
+	if _, err := runCLI(t, env.newTestCLI(), "y\n", "delete", task.ID); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("expected delete without --yes to refuse, got %v", err)
+	}
+	if _, err := env.Service.GetTask(env.ctx, task.ID); err != nil {
+		t.Errorf("expected task to survive a refused delete: %v", err)
+	}
+
+	out, err := runCLI(t, env.newTestCLI(), "", "delete", "--yes", task.ID)
+	if err != nil {
+		t.Fatalf("delete --yes failed: %v", err)
+	}
+	if !strings.Contains(out, task.ID) {
+		t.Errorf("expected deletion to be reported, got %q", out)
+	}
+	if _, err := env.Service.GetTask(env.ctx, task.ID); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("expected task to be deleted, got %v", err)
+	}
+}
+
+// TestCompleteFollowUp tests the follow-up prompt after completing a task,
+// answering it on stdin
+func TestCompleteFollowUp(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	create := func(title string) *domain.Task {
+		t.Helper()
+		task, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityHigh, domain.WithTaskContext("work"))
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		return task
+	}
+	countTasks := func() int {
+		t.Helper()
+		tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+		if err != nil {
+			t.Fatalf("failed to list tasks: %v", err)
+		}
+		return len(tasks)
+	}
+
+	task := create("Send proposal")
+	out, err := runCLI(t, env.newTestCLI(), "y\nCall about proposal\n", "complete", "--follow-up", task.ID)
+	if err != nil {
+		t.Fatalf("complete --follow-up failed: %v", err)
+	}
+	if !strings.Contains(out, "Create follow-up task?") || !strings.Contains(out, "Follow-up task created") {
+		t.Errorf("expected the follow-up prompt and confirmation, got:\n%s", out)
+	}
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	var followUps []*domain.Task
+	for _, task := range tasks {
+		if task.Title == "Call about proposal" {
+			followUps = append(followUps, task)
+		}
+	}
+	if len(followUps) != 1 {
+		t.Fatalf("expected one follow-up task, got %d", len(followUps))
+	}
+	if f := followUps[0]; f.Status != domain.TaskStatusPending || f.Priority != domain.TaskPriorityHigh || f.Context != "work" {
+		t.Errorf("expected a pending high priority follow-up in work, got %s/%s/%q", f.Status, f.Priority, f.Context)
+	}
+
+	// Declining, or giving no title, creates nothing
+	for _, answers := range []string{"n\n", "y\n\n", ""} {
+		another := create("Another")
+		before := countTasks()
+		if _, err := runCLI(t, env.newTestCLI(), answers, "complete", "--follow-up", another.ID); err != nil {
+			t.Fatalf("complete --follow-up failed: %v", err)
+		}
+		if after := countTasks(); after != before {
+			t.Errorf("answers %q: expected no follow-up task, got %d new task(s)", answers, after-before)
+		}
+	}
+
+	// behavior.follow_up_prompt only prompts when stdin is a terminal
+	cfg := &config.Config{Behavior: config.BehaviorConfig{FollowUpPrompt: true}}
+	out, err = runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), "y\nUnwanted\n", "complete", create("Unattended").ID)
+	if err != nil {
+		t.Fatalf("complete failed: %v", err)
+	}
+	if strings.Contains(out, "follow-up") {
+		t.Errorf("expected no prompt without a terminal, got:\n%s", out)
+	}
+}
+
+// TestDefaultCommand tests that a bare "task" runs display.default_command,
+// or shows help when none is configured
+func TestDefaultCommand(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	if _, err := env.Service.CreateTask(env.ctx, "Still open", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	done, err := env.Service.CreateTask(env.ctx, "Already done", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CompleteTask(env.ctx, done.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+
+	for name, cfg := range map[string]*config.Config{
+		"no config":   nil,
+		"not set":     {},
+		"only spaces": {Display: config.DisplayConfig{DefaultCommand: "   "}},
+	} {
+		var opts []cli.Option
+		if cfg != nil {
+			opts = append(opts, cli.WithConfig(cfg))
+		}
+		out, err := runCLI(t, env.newTestCLI(opts...), "")
+		if err != nil {
+			t.Fatalf("%s: task failed: %v", name, err)
+		}
+		if !strings.Contains(out, "Available Commands:") || strings.Contains(out, "Still open") {
+			t.Errorf("%s: expected help, got:\n%s", name, out)
+		}
+	}
+
+	for _, command := range []string{`list --status completed`, `task list --status "completed"`} {
+		cfg := &config.Config{Display: config.DisplayConfig{DefaultCommand: command}}
+		out, err := runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), "")
+		if err != nil {
+			t.Fatalf("%q: task failed: %v", command, err)
+		}
+		if !strings.Contains(out, "Already done") || strings.Contains(out, "Still open") {
+			t.Errorf("%q: expected only the completed task, got:\n%s", command, out)
+		}
+
+		// Arguments given on the command line are run instead
+		out, err = runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), "", "list", "--status", "pending")
+		if err != nil {
+			t.Fatalf("%q: task list failed: %v", command, err)
+		}
+		if !strings.Contains(out, "Still open") || strings.Contains(out, "Already done") {
+			t.Errorf("%q: expected only the pending task, got:\n%s", command, out)
+		}
+	}
+
+	cfg := &config.Config{Display: config.DisplayConfig{DefaultCommand: `list --status "pending`}}
+	if _, err := runCLI(t, env.newTestCLI(cli.WithConfig(cfg)), ""); err == nil || !strings.Contains(err.Error(), "display.default_command") {
+		t.Errorf("expected an invalid default_command error, got %v", err)
+	}
+}
+
+// TestListWatchFlags tests the flag combinations list --watch rejects
+func TestListWatchFlags(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list", "--watch", "--interval", "0s"}, "invalid interval"},
+		{[]string{"list", "--watch", "--interval", "-5s"}, "invalid interval"},
+		{[]string{"list", "--watch", "--archived"}, "--watch cannot be combined with --archived"},
+	} {
+		if _, err := runCLI(t, env.newTestCLI(), "", tc.args...); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+
+	// --interval only matters with --watch
+	if _, err := runCLI(t, env.newTestCLI(), "", "list", "--interval", "0s"); err != nil {
+		t.Errorf("expected list --interval without --watch to list once, got %v", err)
+	}
+}
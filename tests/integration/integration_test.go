@@ -1,18 +1,61 @@
 package integration
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/edson-mazvila/task-manager/internal/config"
+	"github.com/edson-mazvila/task-manager/internal/dbsync"
+	"github.com/edson-mazvila/task-manager/internal/digest"
+	"github.com/edson-mazvila/task-manager/internal/doctor"
 	"github.com/edson-mazvila/task-manager/internal/domain"
+	"github.com/edson-mazvila/task-manager/internal/encryption"
+	"github.com/edson-mazvila/task-manager/internal/escalation"
+	"github.com/edson-mazvila/task-manager/internal/events"
+	"github.com/edson-mazvila/task-manager/internal/export"
+	"github.com/edson-mazvila/task-manager/internal/i18n"
+	"github.com/edson-mazvila/task-manager/internal/importer"
+	"github.com/edson-mazvila/task-manager/internal/integrations/github"
+	"github.com/edson-mazvila/task-manager/internal/integrations/google"
+	"github.com/edson-mazvila/task-manager/internal/integrations/linking"
+	"github.com/edson-mazvila/task-manager/internal/integrations/tasksync"
+	"github.com/edson-mazvila/task-manager/internal/mcp"
+	"github.com/edson-mazvila/task-manager/internal/notify"
+	"github.com/edson-mazvila/task-manager/internal/plan"
+	"github.com/edson-mazvila/task-manager/internal/query"
 	"github.com/edson-mazvila/task-manager/internal/repository"
+	"github.com/edson-mazvila/task-manager/internal/rules"
 	"github.com/edson-mazvila/task-manager/internal/service"
 	"github.com/edson-mazvila/task-manager/internal/storage"
+	"github.com/edson-mazvila/task-manager/internal/suggest"
+	"github.com/edson-mazvila/task-manager/internal/timeline"
+	"github.com/edson-mazvila/task-manager/internal/trend"
+	"github.com/edson-mazvila/task-manager/internal/ui"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"golang.org/x/oauth2"
 )
 
 // TestEnvironment holds test infrastructure
@@ -62,6 +105,11 @@ func setupTestEnvironment(t *testing.T) *TestEnvironment {
 	}
 }
 
+// readOnly wraps repo for dry runs
+func (te *TestEnvironment) readOnly(repo domain.TaskRepository) domain.TaskRepository {
+	return repository.NewDryRunTaskRepository(repo, te.Logger)
+}
+
 // cleanup closes resources
 func (te *TestEnvironment) cleanup(t *testing.T) {
 	t.Helper()
@@ -617,6 +665,4346 @@ func TestDatabaseIndexes(t *testing.T) {
 	}
 }
 
+// TestTaskMetadata tests storing and reading integration metadata
+func TestTaskMetadata(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	task, err := env.Service.CreateTask(env.ctx, "Metadata Task", "", domain.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	if len(task.Metadata) != 0 {
+		t.Errorf("expected empty metadata for new task, got %v", task.Metadata)
+	}
+
+	if _, err := env.Service.SetTaskMetadata(env.ctx, task.ID, "github.remote_id", "42"); err != nil {
+		t.Fatalf("failed to set metadata: %v", err)
+	}
+	if _, err := env.Service.SetTaskMetadata(env.ctx, task.ID, "github.etag", 7); err != nil {
+		t.Fatalf("failed to set metadata: %v", err)
+	}
+
+	retrieved, err := env.Service.GetTask(env.ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to retrieve task: %v", err)
+	}
+
+	if v, ok := retrieved.Metadata.GetString("github.remote_id"); !ok || v != "42" {
+		t.Errorf("expected remote_id '42', got '%s' (found: %v)", v, ok)
+	}
+	if v, ok := retrieved.Metadata.GetInt("github.etag"); !ok || v != 7 {
+		t.Errorf("expected etag 7, got %d (found: %v)", v, ok)
+	}
+	if _, ok := retrieved.Metadata.GetString("github.etag"); ok {
+		t.Error("expected type mismatch to report not found")
+	}
+
+	// Removing a key
+	updated, err := env.Service.SetTaskMetadata(env.ctx, task.ID, "github.etag", nil)
+	if err != nil {
+		t.Fatalf("failed to delete metadata: %v", err)
+	}
+	if updated.Metadata.Has("github.etag") {
+		t.Error("expected etag to be removed")
+	}
+}
+
+// TestTaskContextFiltering tests GTD context assignment and filtering
+func TestTaskContextFiltering(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	home, err := env.Service.CreateTask(env.ctx, "Fix sink", "", domain.TaskPriorityMedium, domain.WithTaskContext("@Home"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if home.Context != "home" {
+		t.Errorf("expected normalized context 'home', got '%s'", home.Context)
+	}
+
+	if _, err := env.Service.CreateTask(env.ctx, "Write report", "", domain.TaskPriorityHigh, domain.WithTaskContext("office")); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	name := "home"
+	results, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{Context: &name})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != home.ID {
+		t.Errorf("expected only the home task, got %d task(s)", len(results))
+	}
+
+	// Clearing the context through an update
+	updated, err := env.Service.UpdateTask(env.ctx, home.ID, "", "", "", domain.WithTaskContext(""))
+	if err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if updated.Context != "" {
+		t.Errorf("expected context to be cleared, got '%s'", updated.Context)
+	}
+
+	if _, err := env.Service.CreateTask(env.ctx, "Bad", "", domain.TaskPriorityLow, domain.WithTaskContext("two words")); err == nil {
+		t.Error("expected error for context containing whitespace")
+	}
+}
+
+// TestExternalRefLinking tests remote item mapping used by sync integrations
+func TestExternalRefLinking(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	refs := repository.NewSQLiteExternalRefRepository(env.Storage.DB(), env.Logger)
+	linker := linking.NewLinker(refs, env.Logger)
+
+	task, err := env.Service.CreateTask(env.ctx, "Imported Issue", "", domain.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	change, _, err := linker.Classify(env.ctx, "github", "42", linking.RemoteVersion{ETag: "v1"})
+	if err != nil {
+		t.Fatalf("failed to classify: %v", err)
+	}
+	if change != linking.ChangeNew {
+		t.Errorf("expected new, got %s", change)
+	}
+
+	if _, err := linker.Link(env.ctx, "github", "42", task.ID, linking.RemoteVersion{ETag: "v1"}); err != nil {
+		t.Fatalf("failed to link: %v", err)
+	}
+
+	change, ref, err := linker.Classify(env.ctx, "github", "42", linking.RemoteVersion{ETag: "v1"})
+	if err != nil {
+		t.Fatalf("failed to classify: %v", err)
+	}
+	if change != linking.ChangeUnchanged || ref.TaskID != task.ID {
+		t.Errorf("expected unchanged link to %s, got %s link to %s", task.ID, change, ref.TaskID)
+	}
+
+	change, _, err = linker.Classify(env.ctx, "github", "42", linking.RemoteVersion{ETag: "v2"})
+	if err != nil {
+		t.Fatalf("failed to classify: %v", err)
+	}
+	if change != linking.ChangeModified {
+		t.Errorf("expected modified, got %s", change)
+	}
+
+	remoteID, ok, err := linker.RemoteID(env.ctx, "github", task.ID)
+	if err != nil || !ok || remoteID != "42" {
+		t.Errorf("expected remote ID 42, got %q (found: %v, err: %v)", remoteID, ok, err)
+	}
+
+	// Deleting the task removes its references
+	if err := env.Service.DeleteTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	if _, ok, _ := linker.TaskID(env.ctx, "github", "42"); ok {
+		t.Error("expected reference to be removed with its task")
+	}
+}
+
+// TestGitHubSync tests importing issues and pushing completions against a fake GitHub API
+func TestGitHubSync(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	issues := map[int]*github.Issue{
+		1: {Number: 1, Title: "Fix login", Body: "Redirect loops", State: "open", UpdatedAt: updated,
+			Labels: []github.Label{{Name: "priority: high"}, {Name: "bug"}}, Milestone: &github.Milestone{Title: "v1 Release"}},
+		2: {Number: 2, Title: "Write docs", State: "open", UpdatedAt: updated},
+	}
+	var closed []int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var number int
+		fmt.Sscanf(r.URL.Path, "/repos/acme/app/issues/%d", &number)
+		switch {
+		case r.URL.Path == "/repos/acme/app/issues":
+			list := []map[string]interface{}{{"number": 3, "title": "A pull request", "pull_request": map[string]string{}}}
+			for _, n := range []int{1, 2} {
+				if issues[n].State == "open" {
+					list = append(list, map[string]interface{}{"number": n, "title": issues[n].Title, "body": issues[n].Body,
+						"state": "open", "labels": issues[n].Labels, "milestone": issues[n].Milestone, "updated_at": issues[n].UpdatedAt})
+				}
+			}
+			json.NewEncoder(w).Encode(list)
+		case issues[number] != nil:
+			if r.Method == http.MethodPatch {
+				issues[number].State = "closed"
+				issues[number].UpdatedAt = issues[number].UpdatedAt.Add(time.Minute)
+				closed = append(closed, number)
+			}
+			json.NewEncoder(w).Encode(issues[number])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(env.Storage.DB(), env.Logger), env.Logger)
+	syncer := github.NewSyncer(github.NewClient("secret", github.WithBaseURL(server.URL)), env.Service, linker, env.Logger)
+
+	result, err := syncer.Sync(env.ctx, "acme/app", false)
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if len(result.Imported) != 2 {
+		t.Fatalf("expected 2 issues imported (pull requests skipped), got %+v", result)
+	}
+
+	login, err := env.Service.GetTask(env.ctx, result.Imported[0].TaskID)
+	if err != nil {
+		t.Fatalf("failed to get imported task: %v", err)
+	}
+	if login.Priority != domain.TaskPriorityHigh || login.Context != "v1-release" || login.Description != "Redirect loops" {
+		t.Errorf("expected priority label and milestone mapped, got %+v", login)
+	}
+	var link github.IssueLink
+	if ok, err := login.Metadata.Get(github.MetadataIssue, &link); !ok || err != nil || link.Number != 1 || len(link.Labels) != 2 {
+		t.Errorf("expected issue link in metadata, got %+v (%v)", link, err)
+	}
+
+	// Syncing again imports nothing
+	if result, err := syncer.Sync(env.ctx, "acme/app", false); err != nil || !result.Empty() {
+		t.Fatalf("expected nothing to do on re-sync, got %+v (%v)", result, err)
+	}
+
+	// Completing a task closes its issue; closing an issue completes its task
+	if _, err := env.Service.CompleteTask(env.ctx, login.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+	issues[2].State = "closed"
+
+	result, err = syncer.Sync(env.ctx, "acme/app", false)
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if len(result.Closed) != 1 || len(closed) != 1 || closed[0] != 1 {
+		t.Errorf("expected issue #1 closed on GitHub, got %+v (closed: %v)", result, closed)
+	}
+	if len(result.Completed) != 1 {
+		t.Fatalf("expected the task of issue #2 completed, got %+v", result)
+	}
+	docs, _ := env.Service.GetTask(env.ctx, result.Completed[0].TaskID)
+	if docs == nil || docs.Status != domain.TaskStatusCompleted {
+		t.Errorf("expected task completed, got %+v", docs)
+	}
+
+	if _, err := github.NewSyncer(github.NewClient("wrong", github.WithBaseURL(server.URL)), env.Service, linker, env.Logger).Sync(env.ctx, "acme/app", false); err == nil {
+		t.Error("expected error for a rejected token")
+	}
+}
+
+// TestGoogleTasksSync tests two-way sync with a Google Tasks list
+func TestGoogleTasksSync(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	type apiTask struct {
+		ID      string  `json:"id"`
+		Title   string  `json:"title"`
+		Notes   string  `json:"notes"`
+		Status  string  `json:"status"`
+		Due     *string `json:"due"`
+		Updated string  `json:"updated"`
+		Deleted bool    `json:"deleted,omitempty"`
+	}
+	stamp := func() string { return time.Now().UTC().Format(time.RFC3339Nano) }
+	due := "2026-12-01T00:00:00.000Z"
+	remote := map[string]*apiTask{
+		"g1": {ID: "g1", Title: "Renew passport", Notes: "Photos first", Status: "needsAction", Due: &due, Updated: stamp()},
+		"g2": {ID: "g2", Title: "Old chore", Status: "completed", Updated: stamp()},
+	}
+	order := []string{"g1", "g2"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		const tasksPath = "/lists/@default/tasks"
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == tasksPath:
+			items := []*apiTask{}
+			for _, id := range order {
+				items = append(items, remote[id])
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+		case r.Method == http.MethodPost && r.URL.Path == tasksPath:
+			var task apiTask
+			json.NewDecoder(r.Body).Decode(&task)
+			task.ID = fmt.Sprintf("g%d", len(order)+1)
+			task.Updated = stamp()
+			remote[task.ID] = &task
+			order = append(order, task.ID)
+			json.NewEncoder(w).Encode(task)
+		case r.Method == http.MethodPatch && remote[strings.TrimPrefix(r.URL.Path, tasksPath+"/")] != nil:
+			task := remote[strings.TrimPrefix(r.URL.Path, tasksPath+"/")]
+			json.NewDecoder(r.Body).Decode(task)
+			task.Updated = stamp()
+			json.NewEncoder(w).Encode(task)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	newSyncer := func(accessToken string) *tasksync.Syncer {
+		token := &oauth2.Token{AccessToken: accessToken, Expiry: time.Now().Add(time.Hour)}
+		provider := google.NewProvider(env.ctx, &oauth2.Config{}, token, filepath.Join(t.TempDir(), "token.json"), "",
+			google.WithBaseURL(server.URL))
+		linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(env.Storage.DB(), env.Logger), env.Logger)
+		return tasksync.NewSyncer(provider, env.Service, linker, env.Logger)
+	}
+	syncer := newSyncer("secret")
+
+	flights, err := env.Service.CreateTask(env.ctx, "Book flights", "", domain.TaskPriorityHigh, domain.WithTaskContext("travel"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "Quarterly report", "", domain.TaskPriorityMedium, domain.WithTaskContext("work")); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	result, err := syncer.Sync(env.ctx, "travel", false)
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if len(result.Pulled) != 1 || result.Pulled[0] != "Renew passport" {
+		t.Errorf("expected only the open remote task pulled, got %+v", result)
+	}
+	if len(result.Pushed) != 1 || result.Pushed[0] != "Book flights" || len(order) != 3 {
+		t.Errorf("expected only the local task in the context pushed, got %+v", result)
+	}
+
+	var passport *domain.Task
+	tasks, _ := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	for _, task := range tasks {
+		if task.Title == "Renew passport" {
+			passport = task
+		}
+	}
+	if passport == nil {
+		t.Fatal("expected the pulled task to exist")
+	}
+	if passport.Context != "travel" || passport.Description != "Photos first" {
+		t.Errorf("unexpected pulled task %+v", passport)
+	}
+	if date, _ := passport.Metadata.GetString(syncer.DueKey()); date != "2026-12-01" {
+		t.Errorf("expected due date in metadata, got %q", date)
+	}
+
+	// Syncing again changes nothing
+	if result, err := syncer.Sync(env.ctx, "travel", false); err != nil || !result.Empty() {
+		t.Fatalf("expected nothing to do on re-sync, got %+v (%v)", result, err)
+	}
+
+	// A remote edit is pulled, a local edit is pushed
+	time.Sleep(10 * time.Millisecond)
+	remote["g1"].Title = "Renew passport and ID"
+	remote["g1"].Updated = stamp()
+	if _, err := env.Service.CompleteTask(env.ctx, flights.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+
+	result, err = syncer.Sync(env.ctx, "travel", false)
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if len(result.UpdatedLocal) != 1 || len(result.UpdatedRemote) != 1 {
+		t.Fatalf("expected one update each way, got %+v", result)
+	}
+	if passport, _ = env.Service.GetTask(env.ctx, passport.ID); passport.Title != "Renew passport and ID" {
+		t.Errorf("expected remote title pulled, got %q", passport.Title)
+	}
+	if remote["g3"].Status != "completed" {
+		t.Errorf("expected remote task completed, got %q", remote["g3"].Status)
+	}
+
+	// A task deleted remotely is unlinked and not pushed again
+	remote["g1"].Deleted = true
+	if result, err := syncer.Sync(env.ctx, "travel", false); err != nil || len(result.Unlinked) != 1 {
+		t.Fatalf("expected the deleted task unlinked, got %+v (%v)", result, err)
+	}
+	if result, err := syncer.Sync(env.ctx, "travel", false); err != nil || !result.Empty() {
+		t.Fatalf("expected nothing to do after unlinking, got %+v (%v)", result, err)
+	}
+
+	if _, err := newSyncer("expired").Sync(env.ctx, "travel", false); !errors.Is(err, tasksync.ErrNotAuthorized) {
+		t.Errorf("expected ErrNotAuthorized for a rejected token, got %v", err)
+	}
+}
+
+// TestTodoistImport tests importing Todoist CSV exports and API listings
+func TestTodoistImport(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	csvExport := "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"task,Buy paint @errands,,1,1,,,tomorrow,en,UTC\n" +
+		"note,\"Matte white,\n2 litres\",,,,,,,,\n" +
+		"section,Kitchen,,,,,,,,\n" +
+		"task,Call plumber,Leaking tap,4,1,,,,en,UTC\n"
+
+	items, err := importer.ParseTodoistCSV(strings.NewReader(csvExport), "Home Renovation")
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(items))
+	}
+	if items[0].Title != "Buy paint" || items[0].Priority != domain.TaskPriorityHigh || items[0].Context != "home-renovation" ||
+		items[0].Description != "Matte white,\n2 litres" {
+		t.Errorf("unexpected first item %+v", items[0])
+	}
+	if items[1].Priority != domain.TaskPriorityLow {
+		t.Errorf("expected p4 to map to low, got %s", items[1].Priority)
+	}
+	if _, err := importer.ParseTodoistCSV(strings.NewReader("task,Title\n"), "x"); err == nil {
+		t.Error("expected error for a CSV without Todoist columns")
+	}
+
+	linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(env.Storage.DB(), env.Logger), env.Logger)
+	imp := importer.NewImporter(env.Service, linker, env.Logger)
+
+	// A dry run validates but writes nothing
+	dry := importer.NewImporter(env.Service.DryRun(env.readOnly), linker, env.Logger)
+	if result, err := dry.Import(env.ctx, importer.TodoistProvider, items, true); err != nil || len(result.Created) != 2 {
+		t.Fatalf("expected dry run to preview 2 tasks, got %+v (%v)", result, err)
+	}
+	if count, _ := env.Service.CountTasks(env.ctx, domain.TaskFilter{}); count != 0 {
+		t.Fatalf("expected dry run to create nothing, got %d tasks", count)
+	}
+
+	result, err := imp.Import(env.ctx, importer.TodoistProvider, items, false)
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	var labels []string
+	if ok, _ := result.Created[0].Metadata.Get(importer.MetadataTodoistLabels, &labels); !ok || len(labels) != 1 || labels[0] != "errands" {
+		t.Errorf("expected labels in metadata, got %v", labels)
+	}
+
+	// API tasks carry IDs, so importing them again is skipped
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects":
+			fmt.Fprint(w, `[{"id": "p1", "name": "Work"}]`)
+		case "/tasks":
+			fmt.Fprint(w, `[{"id": "t1", "content": "Ship release", "priority": 4, "project_id": "p1", "labels": ["release"], "due": {"date": "2026-11-01"}}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiItems, err := importer.NewTodoistClient("token", server.URL).Items(env.ctx)
+	if err != nil {
+		t.Fatalf("failed to read API tasks: %v", err)
+	}
+	if len(apiItems) != 1 || apiItems[0].Priority != domain.TaskPriorityHigh || apiItems[0].Context != "work" || apiItems[0].RemoteID != "t1" {
+		t.Fatalf("unexpected API items %+v", apiItems)
+	}
+	if result, err := imp.Import(env.ctx, importer.TodoistProvider, apiItems, false); err != nil || len(result.Created) != 1 {
+		t.Fatalf("expected 1 task imported from the API, got %+v (%v)", result, err)
+	}
+	if result, err := imp.Import(env.ctx, importer.TodoistProvider, apiItems, false); err != nil || len(result.Created) != 0 || result.Skipped != 1 {
+		t.Errorf("expected re-import to be skipped, got %+v (%v)", result, err)
+	}
+}
+
+// TestTrelloImport tests importing a Trello board JSON export
+func TestTrelloImport(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	board := `{
+		"name": "Website Launch",
+		"lists": [
+			{"id": "l1", "name": "To Do"},
+			{"id": "l2", "name": "Done"},
+			{"id": "l3", "name": "Old Ideas", "closed": true}
+		],
+		"cards": [
+			{"id": "c2", "name": "Write copy", "idList": "l1", "pos": 2},
+			{"id": "c1", "name": "Pick domain", "desc": "Short and memorable", "idList": "l1", "pos": 1,
+			 "due": "2026-11-01T12:00:00.000Z", "labels": [{"name": "High"}, {"name": "", "color": "green"}]},
+			{"id": "c3", "name": "Draft sitemap", "idList": "l2", "pos": 1},
+			{"id": "c4", "name": "Archived card", "idList": "l1", "closed": true},
+			{"id": "c5", "name": "Card in a closed list", "idList": "l3"}
+		],
+		"checklists": [
+			{"idCard": "c1", "name": "Candidates", "checkItems": [
+				{"name": "example.org", "state": "incomplete", "pos": 2},
+				{"name": "example.com", "state": "complete", "pos": 1}
+			]}
+		]
+	}`
+
+	items, err := importer.ParseTrelloJSON(strings.NewReader(board), importer.TrelloListsContext, "")
+	if err != nil {
+		t.Fatalf("failed to parse board: %v", err)
+	}
+	if len(items) != 3 || items[0].Title != "Pick domain" || items[1].Title != "Write copy" {
+		t.Fatalf("expected open cards in list and position order, got %+v", items)
+	}
+	if items[0].Context != "to-do" || items[0].Priority != domain.TaskPriorityHigh || items[0].Completed {
+		t.Errorf("expected list as context and label as priority, got %+v", items[0])
+	}
+	if want := "Short and memorable\n\nCandidates:\n- [x] example.com\n- [ ] example.org"; items[0].Description != want {
+		t.Errorf("expected checklist in description, got %q", items[0].Description)
+	}
+
+	items, err = importer.ParseTrelloJSON(strings.NewReader(board), importer.TrelloListsStatus, "")
+	if err != nil {
+		t.Fatalf("failed to parse board: %v", err)
+	}
+	if items[0].Context != "website-launch" || !items[2].Completed || items[0].Completed {
+		t.Errorf("expected board as context and Done cards completed, got %+v", items)
+	}
+
+	if _, err := importer.ParseTrelloJSON(strings.NewReader(`{"name": "x"}`), importer.TrelloListsContext, ""); err == nil {
+		t.Error("expected error for JSON that is not a board export")
+	}
+
+	linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(env.Storage.DB(), env.Logger), env.Logger)
+	imp := importer.NewImporter(env.Service, linker, env.Logger)
+	result, err := imp.Import(env.ctx, importer.TrelloProvider, items, false)
+	if err != nil || len(result.Created) != 3 {
+		t.Fatalf("expected 3 tasks imported, got %+v (%v)", result, err)
+	}
+	sitemap, _ := env.Service.GetTask(env.ctx, result.Created[2].ID)
+	if sitemap == nil || sitemap.Status != domain.TaskStatusCompleted {
+		t.Errorf("expected the Done card imported as completed, got %+v", sitemap)
+	}
+	var labels []string
+	if ok, _ := result.Created[0].Metadata.Get(importer.MetadataTrelloLabels, &labels); !ok || len(labels) != 2 || labels[1] != "green" {
+		t.Errorf("expected labels in metadata, got %v", labels)
+	}
+
+	if result, err := imp.Import(env.ctx, importer.TrelloProvider, items, false); err != nil || result.Skipped != 3 {
+		t.Errorf("expected re-import to be skipped, got %+v (%v)", result, err)
+	}
+}
+
+// TestPlainTextFormats tests Org-mode and todo.txt export and import
+func TestPlainTextFormats(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	todo := "x 2026-01-05 2025-12-30 File taxes @home @errands +finance due:2026-01-31 pri:A\n" +
+		"\n" +
+		"(C) 2026-01-02 Call mom see https://example.com\n"
+	items, err := importer.ParseTodoTxt(strings.NewReader(todo), "misc")
+	if err != nil {
+		t.Fatalf("failed to parse todo.txt: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", items)
+	}
+	taxes := items[0]
+	if taxes.Title != "File taxes" || !taxes.Completed || taxes.Priority != domain.TaskPriorityHigh || taxes.Context != "home" ||
+		taxes.CompletedAt.Format(time.DateOnly) != "2026-01-05" || taxes.CreatedAt.Format(time.DateOnly) != "2025-12-30" {
+		t.Errorf("unexpected completed item %+v", taxes)
+	}
+	if taxes.Metadata[export.MetadataTodoTxtPrefix+"due"] != "2026-01-31" {
+		t.Errorf("expected due: tag in metadata, got %v", taxes.Metadata)
+	}
+	if items[1].Title != "Call mom see https://example.com" || items[1].Priority != domain.TaskPriorityLow || items[1].Context != "misc" {
+		t.Errorf("unexpected pending item %+v", items[1])
+	}
+
+	org := "#+TITLE: Archive\n" +
+		"* Projects\n" +
+		"** DONE [#A] Ship v1 :work:release:\n" +
+		"   CLOSED: [2026-01-03 Sat 17:30] DEADLINE: <2026-01-05 Mon>\n" +
+		"   :LOGBOOK:\n" +
+		"   - State \"DONE\" from \"TODO\"\n" +
+		"   :END:\n" +
+		"   Release notes\n" +
+		"     * indented list\n" +
+		"** WAITING Reply from Bob\n"
+	items, err = importer.ParseOrg(strings.NewReader(org), "")
+	if err != nil {
+		t.Fatalf("failed to parse Org file: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected headings without a TODO keyword skipped, got %+v", items)
+	}
+	ship := items[0]
+	if ship.Title != "Ship v1" || !ship.Completed || ship.Priority != domain.TaskPriorityHigh || ship.Context != "work" ||
+		ship.Description != "Release notes\n  * indented list" || ship.CompletedAt.Format(export.OrgTimeLayout) != "2026-01-03 Sat 17:30" {
+		t.Errorf("unexpected DONE item %+v", ship)
+	}
+	if ship.Metadata[export.MetadataOrgDeadline] != "<2026-01-05 Mon>" {
+		t.Errorf("expected deadline in metadata, got %v", ship.Metadata)
+	}
+	if items[1].Completed || items[1].Priority != "" {
+		t.Errorf("expected WAITING heading pending without priority, got %+v", items[1])
+	}
+
+	// Tasks survive a round trip through each format
+	linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(env.Storage.DB(), env.Logger), env.Logger)
+	imp := importer.NewImporter(env.Service, linker, env.Logger)
+	result, err := imp.Import(env.ctx, importer.OrgProvider, items, false)
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	shipped, _ := env.Service.GetTask(env.ctx, result.Created[0].ID)
+	if shipped.Status != domain.TaskStatusCompleted || shipped.CompletedAt == nil || !shipped.CompletedAt.Equal(ship.CompletedAt) {
+		t.Errorf("expected the completion time kept, got %+v", shipped)
+	}
+
+	reno, err := env.Service.CreateTask(env.ctx, "Buy paint", "Matte white\n* not a heading", domain.TaskPriorityHigh,
+		domain.WithTaskContext("home-reno"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	var orgOut bytes.Buffer
+	for _, task := range []*domain.Task{shipped, reno} {
+		if err := export.WriteOrgTask(&orgOut, task); err != nil {
+			t.Fatalf("failed to write Org: %v", err)
+		}
+	}
+	if !strings.Contains(orgOut.String(), "* TODO [#A] Buy paint :home_reno:\n") {
+		t.Errorf("expected the context as a valid Org tag, got:\n%s", orgOut.String())
+	}
+	back, err := importer.ParseOrg(&orgOut, "")
+	if err != nil || len(back) != 2 {
+		t.Fatalf("failed to read the export back: %+v (%v)", back, err)
+	}
+	if back[1].RemoteID != reno.ID || back[1].Context != "home-reno" || back[1].Description != reno.Description {
+		t.Errorf("expected ID, context, and description to round-trip, got %+v", back[1])
+	}
+	var tags []string
+	if back[0].Metadata[export.MetadataOrgDeadline] != "<2026-01-05 Mon>" || !back[0].Completed {
+		t.Errorf("expected DEADLINE and completion to round-trip, got %+v", back[0])
+	} else if tags, _ = back[0].Metadata[export.MetadataOrgTags].([]string); len(tags) != 1 || tags[0] != "release" {
+		t.Errorf("expected tags to round-trip, got %v", back[0].Metadata)
+	}
+
+	var todoOut bytes.Buffer
+	if err := export.WriteTodoTxtTask(&todoOut, shipped); err != nil {
+		t.Fatalf("failed to write todo.txt: %v", err)
+	}
+	if want := "x 2026-01-03 "; !strings.HasPrefix(todoOut.String(), want) || !strings.Contains(todoOut.String(), " @work id:"+shipped.ID+" pri:A\n") {
+		t.Errorf("unexpected todo.txt line %q", todoOut.String())
+	}
+	if back, err := importer.ParseTodoTxt(&todoOut, ""); err != nil || back[0].RemoteID != shipped.ID || back[0].Priority != domain.TaskPriorityHigh {
+		t.Errorf("expected the todo.txt line to round-trip, got %+v (%v)", back, err)
+	}
+
+	if _, err := importer.ParseOrg(strings.NewReader("* TODO [#A]\n"), ""); err == nil {
+		t.Error("expected error for a heading without a title")
+	}
+}
+
+// TestObsidianExport tests writing tasks as notes of an Obsidian vault
+func TestObsidianExport(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	paint, err := env.Service.CreateTask(env.ctx, "Buy paint: white?", "Matte finish", domain.TaskPriorityHigh,
+		domain.WithTaskContext("home"), func(t *domain.Task) {
+			_ = t.SetMetadata("todoist.due", "2026-11-01T09:00:00")
+			_ = t.SetMetadata("todoist.labels", []string{"errands", "home", "hardware store"})
+		})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "Buy paint white", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "vault", "tasks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Notes.md"), []byte("# My own note\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, _ := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	result, err := export.WriteObsidianVault(dir, tasks)
+	if err != nil || result.Written != 2 {
+		t.Fatalf("expected 2 notes written, got %+v (%v)", result, err)
+	}
+
+	note, err := os.ReadFile(filepath.Join(dir, "Buy paint white.md"))
+	if err != nil {
+		t.Fatalf("expected the note named after the title: %v", err)
+	}
+	for _, want := range []string{
+		"---\ntask_id: " + paint.ID + "\nstatus: pending\npriority: high\ncontext: home\n",
+		"due: \"2026-11-01\"\n",
+		"tags:\n    - home\n    - errands\n    - hardware-store\n---\n\n# Buy paint: white?\n\nMatte finish\n",
+	} {
+		if !strings.Contains(string(note), want) {
+			t.Errorf("expected note to contain %q, got:\n%s", want, note)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("expected a second note for the clashing title, got %d files", len(entries))
+	}
+
+	// Exporting again rewrites only what changed and removes renamed notes
+	if result, err := export.WriteObsidianVault(dir, tasks); err != nil || result.Unchanged != 2 || result.Written != 0 {
+		t.Fatalf("expected nothing rewritten, got %+v (%v)", result, err)
+	}
+	if _, err := env.Service.UpdateTask(env.ctx, paint.ID, "Buy primer", "", ""); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	tasks, _ = env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	result, err = export.WriteObsidianVault(dir, tasks)
+	// The other task takes over the freed title, so its note moves too
+	if err != nil || result.Written != 2 || result.Removed != 1 {
+		t.Fatalf("expected the renamed notes replaced, got %+v (%v)", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Buy primer.md")); err != nil {
+		t.Errorf("expected the renamed note: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Notes.md")); err != nil {
+		t.Errorf("expected notes not written by the export kept: %v", err)
+	}
+}
+
+func TestEmailDigest(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	now := time.Now()
+	day := func(offset int) string { return now.AddDate(0, 0, offset).Format(time.DateOnly) }
+	create := func(title string, priority domain.TaskPriority, key, due string) *domain.Task {
+		task, err := env.Service.CreateTask(env.ctx, title, "", priority, domain.WithTaskContext("work"), func(t *domain.Task) {
+			if key != "" {
+				_ = t.SetMetadata(key, due)
+			}
+		})
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		return task
+	}
+
+	create("File taxes", domain.TaskPriorityLow, "todoist.due", day(-3))
+	create("Renew passport", domain.TaskPriorityHigh, "todotxt.due", day(-3))
+	create("Call the bank", domain.TaskPriorityMedium, "google.due", day(0)+"T00:00:00.000Z")
+	create("Plan the trip", domain.TaskPriorityMedium, "todoist.due", day(5))
+	create("No due date", domain.TaskPriorityMedium, "", "")
+	shipped := create("Ship <release>", domain.TaskPriorityHigh, "", "")
+	if _, err := env.Service.CompleteTask(env.ctx, shipped.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+
+	d, err := digest.Build(env.ctx, env.Service, domain.TaskFilter{}, now, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("failed to build digest: %v", err)
+	}
+	if len(d.Overdue) != 2 || len(d.DueToday) != 1 || len(d.Completed) != 1 {
+		t.Fatalf("expected 2 overdue, 1 due today, 1 completed, got %d, %d, %d",
+			len(d.Overdue), len(d.DueToday), len(d.Completed))
+	}
+	// Same due date, so the higher priority comes first
+	if d.Overdue[0].Task.Title != "Renew passport" {
+		t.Errorf("expected high priority overdue task first, got %q", d.Overdue[0].Task.Title)
+	}
+
+	text := d.Text()
+	for _, want := range []string{"Overdue (2)\n  - Renew passport [high] @work (due ", "Due today (1)\n  - Call the bank", "  - Ship <release> [high] @work (done "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected text to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Plan the trip") || strings.Contains(text, "No due date") {
+		t.Errorf("expected tasks not due yet to be left out, got:\n%s", text)
+	}
+
+	html, err := d.HTML()
+	if err != nil {
+		t.Fatalf("failed to render HTML: %v", err)
+	}
+	if !strings.Contains(html, "<strong>Ship &lt;release&gt;</strong>") {
+		t.Errorf("expected escaped, emphasized title in HTML, got:\n%s", html)
+	}
+
+	// Nothing due in another context
+	other := "home"
+	empty, err := digest.Build(env.ctx, env.Service, domain.TaskFilter{Context: &other}, now, 24*time.Hour)
+	if err != nil || !empty.Empty() {
+		t.Errorf("expected an empty digest for another context, got %+v (%v)", empty, err)
+	}
+
+	// Send it to a fake SMTP server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		var rcpts []string
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb := strings.ToUpper(strings.Fields(line)[0]); verb {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 localhost")
+			case "RCPT":
+				rcpts = append(rcpts, line)
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+				data, _ := tp.ReadDotBytes()
+				_ = tp.PrintfLine("250 OK")
+				received <- strings.Join(rcpts, "\n") + "\n" + string(data)
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				return
+			default:
+				_ = tp.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	to, err := digest.ParseAddresses("Me <me@example.com>, team@example.com")
+	if err != nil || len(to) != 2 || to[0] != "me@example.com" {
+		t.Fatalf("failed to parse addresses: %v %v", to, err)
+	}
+	msg, err := d.Message("tasks@example.com", to)
+	if err != nil {
+		t.Fatalf("failed to render message: %v", err)
+	}
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := &digest.Mailer{Host: "127.0.0.1", Port: addr.Port, From: "tasks@example.com"}
+	if err := mailer.Send(env.ctx, to, msg); err != nil {
+		t.Fatalf("failed to send digest: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		for _, want := range []string{
+			"RCPT TO:<me@example.com>", "RCPT TO:<team@example.com>",
+			"Subject: Task digest for ", "Content-Type: multipart/alternative",
+			"Content-Type: text/plain; charset=utf-8", "Content-Type: text/html; charset=utf-8",
+			"Renew passport",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected sent message to contain %q, got:\n%s", want, got)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server received no message")
+	}
+}
+
+func TestStandup(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	now := time.Now()
+	create := func(title string, opts ...domain.TaskOption) *domain.Task {
+		t.Helper()
+		task, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityMedium, opts...)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		return task
+	}
+
+	create("Review PR", domain.WithPinned(true))
+	create("Someday", domain.WithPinned(false))
+	late := create("Send invoice")
+	if _, err := env.Service.SetTaskMetadata(env.ctx, late.ID, "todoist.due", now.AddDate(0, 0, -1).Format(time.DateOnly)); err != nil {
+		t.Fatalf("failed to set due date: %v", err)
+	}
+	done := create("Fix login")
+	if _, err := env.Service.CompleteTask(env.ctx, done.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+
+	d, err := digest.Build(env.ctx, env.Service, domain.TaskFilter{}, now, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("failed to build digest: %v", err)
+	}
+
+	markdown, err := d.Standup(digest.StandupMarkdown)
+	if err != nil {
+		t.Fatalf("failed to render standup: %v", err)
+	}
+	want := "**Yesterday**\n- Fix login\n\n**Today**\n- Send invoice (due " + now.AddDate(0, 0, -1).Format("Mon 2 Jan") + ")\n- Review PR\n"
+	if markdown != want {
+		t.Errorf("expected standup:\n%s\ngot:\n%s", want, markdown)
+	}
+
+	slack, err := d.Standup(digest.StandupSlack)
+	if err != nil {
+		t.Fatalf("failed to render standup: %v", err)
+	}
+	if !strings.HasPrefix(slack, "*Yesterday*\n• Fix login\n") {
+		t.Errorf("expected Slack formatting, got:\n%s", slack)
+	}
+
+	if _, err := d.Standup("html"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestDueBeforeFilter(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	day := func(offset int) string { return today.AddDate(0, 0, offset).Format(time.DateOnly) }
+	create := func(title, key, due string) {
+		t.Helper()
+		task, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityMedium)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		if key != "" {
+			if _, err := env.Service.SetTaskMetadata(env.ctx, task.ID, key, due); err != nil {
+				t.Fatalf("failed to set due date: %v", err)
+			}
+		}
+	}
+
+	create("Overdue date", "todoist.due", day(-2))
+	create("Overdue Org timestamp", "org.deadline", "<"+day(-1)+" Mon>")
+	create("Due today, RFC 3339", "google.due", day(0)+"T00:00:00.000Z")
+	create("Due later", "trello.due", day(3))
+	create("Not a date", "todotxt.due", "soon")
+	create("No due date", "", "")
+
+	count := func(before time.Time) int64 {
+		t.Helper()
+		n, err := env.Service.CountTasks(env.ctx, domain.TaskFilter{DueBefore: &before})
+		if err != nil {
+			t.Fatalf("failed to count tasks: %v", err)
+		}
+		return n
+	}
+	if n := count(today); n != 2 {
+		t.Errorf("expected 2 tasks due before today, got %d", n)
+	}
+	if n := count(today.AddDate(0, 0, 1)); n != 3 {
+		t.Errorf("expected 3 tasks due by today, got %d", n)
+	}
+
+	// Listing agrees with Task.DueDate
+	tomorrow := today.AddDate(0, 0, 1)
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{DueBefore: &tomorrow})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	for _, task := range tasks {
+		if due, ok := task.DueDate(); !ok || !due.Before(tomorrow) {
+			t.Errorf("expected %q to be due by today, got %v (%v)", task.Title, due, ok)
+		}
+	}
+}
+
+func TestUserDueDate(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+
+	task, err := env.Service.CreateTask(env.ctx, "Renew passport", "", domain.TaskPriorityHigh, domain.WithDueDate(&yesterday))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if value, _ := task.Metadata.GetString(domain.DueKey); value != yesterday.Format(time.DateOnly) {
+		t.Errorf("expected %s under %q, got %q", yesterday.Format(time.DateOnly), domain.DueKey, value)
+	}
+
+	// The user's due date wins over one kept by an integration
+	if _, err := env.Service.SetTaskMetadata(env.ctx, task.ID, "todoist.due", today.AddDate(0, 0, 7).Format(time.DateOnly)); err != nil {
+		t.Fatalf("failed to set due date: %v", err)
+	}
+	n, err := env.Service.CountTasks(env.ctx, domain.TaskFilter{DueBefore: &today})
+	if err != nil {
+		t.Fatalf("failed to count tasks: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected the task to be overdue, got %d overdue", n)
+	}
+
+	// Clearing it falls back to the integration's
+	task, err = env.Service.UpdateTask(env.ctx, task.ID, "", "", "", domain.WithDueDate(nil))
+	if err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if due, ok := task.DueDate(); !ok || !due.Equal(today.AddDate(0, 0, 7)) {
+		t.Errorf("expected the todoist due date after clearing, got %v (%v)", due, ok)
+	}
+
+	// Bulk modify sets and clears the user's due date on every matching task
+	other, err := env.Service.CreateTask(env.ctx, "Book flights", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := runCLI(t, env.newTestCLI(), "", "modify", "--set", "due=+7d", "--yes"); err != nil {
+		t.Fatalf("modify --set due=+7d failed: %v", err)
+	}
+	for _, id := range []string{task.ID, other.ID} {
+		got, err := env.Service.GetTask(env.ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+		if value, _ := got.Metadata.GetString(domain.DueKey); value != today.AddDate(0, 0, 7).Format(time.DateOnly) {
+			t.Errorf("%s: expected due in a week, got %q", got.Title, value)
+		}
+	}
+
+	empty := ""
+	if _, err := env.Service.UpdateTasks(env.ctx, domain.TaskFilter{}, domain.TaskPatch{Due: &empty}); err != nil {
+		t.Fatalf("failed to clear due dates: %v", err)
+	}
+	task, err = env.Service.GetTask(env.ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if _, ok := task.Metadata.GetString(domain.DueKey); ok {
+		t.Errorf("expected the user's due date to be cleared")
+	}
+	if value, _ := task.Metadata.GetString("todoist.due"); value != today.AddDate(0, 0, 7).Format(time.DateOnly) {
+		t.Errorf("expected the todoist due date to survive, got %q", value)
+	}
+	if other, err = env.Service.GetTask(env.ctx, other.ID); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if _, ok := other.DueDate(); ok {
+		t.Errorf("expected no due date left on %s", other.Title)
+	}
+
+	if _, err := runCLI(t, env.newTestCLI(), "", "modify", "--set", "due=someday", "--yes"); err == nil || !strings.Contains(err.Error(), "invalid due date") {
+		t.Errorf("expected an invalid due date error, got %v", err)
+	}
+}
+
+func TestMCPServer(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	existing, err := env.Service.CreateTask(env.ctx, "Water the plants", "Balcony and kitchen", domain.TaskPriorityLow,
+		domain.WithTaskContext("home"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	type message struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	type toolResult struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+
+	// serve runs a session and returns the responses by request ID
+	serve := func(server *mcp.Server, requests ...string) map[int]message {
+		var out bytes.Buffer
+		if err := server.Serve(env.ctx, strings.NewReader(strings.Join(requests, "\n")+"\n"), &out); err != nil {
+			t.Fatalf("serve failed: %v", err)
+		}
+		responses := make(map[int]message)
+		dec := json.NewDecoder(&out)
+		for dec.More() {
+			var m message
+			if err := dec.Decode(&m); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			responses[m.ID] = m
+		}
+		return responses
+	}
+	call := func(id int, tool, args string) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, id, tool, args)
+	}
+	result := func(m message) toolResult {
+		t.Helper()
+		if m.Error != nil {
+			t.Fatalf("unexpected error %d: %s", m.Error.Code, m.Error.Message)
+		}
+		var r toolResult
+		if err := json.Unmarshal(m.Result, &r); err != nil || len(r.Content) != 1 {
+			t.Fatalf("invalid tool result %s: %v", m.Result, err)
+		}
+		return r
+	}
+
+	server := mcp.NewServer(env.Service, mcp.WithVersion("v1.2.3"),
+		mcp.WithCreateOptions(domain.WithCreatedBy("alice")))
+	responses := serve(server,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		call(3, "create_task", `{"title":"Book flights","description":"Lisbon in May","priority":"high","context":"Travel"}`),
+		call(4, "search_tasks", `{"text":"PLANTS"}`),
+		call(5, "list_tasks", `{"query":"priority:high"}`),
+		call(6, "complete_task", fmt.Sprintf(`{"id":%q}`, existing.ID)),
+		call(7, "update_task", `{"id":"missing","title":"x"}`),
+		call(8, "create_task", `{"title":"Typo","priorty":"high"}`),
+		call(9, "no_such_tool", `{}`),
+		`{"jsonrpc":"2.0","id":10,"method":"resources/list"}`,
+	)
+	if len(responses) != 10 {
+		t.Fatalf("expected 10 responses (none for the notification), got %d", len(responses))
+	}
+
+	var init struct {
+		ProtocolVersion string            `json:"protocolVersion"`
+		ServerInfo      map[string]string `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(responses[1].Result, &init); err != nil || init.ProtocolVersion != "2025-03-26" || init.ServerInfo["version"] != "v1.2.3" {
+		t.Errorf("unexpected initialize result: %s", responses[1].Result)
+	}
+
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(responses[2].Result, &list); err != nil || len(list.Tools) != 7 {
+		t.Errorf("expected 7 tools, got %s", responses[2].Result)
+	}
+
+	var created struct {
+		ID        string `json:"id"`
+		Context   string `json:"context"`
+		CreatedBy string `json:"created_by"`
+	}
+	if err := json.Unmarshal([]byte(result(responses[3]).Content[0].Text), &created); err != nil {
+		t.Fatal(err)
+	}
+	task, err := env.Service.GetTask(env.ctx, created.ID)
+	if err != nil || task.Title != "Book flights" || task.Priority != domain.TaskPriorityHigh ||
+		task.Context != "travel" || task.CreatedBy != "alice" {
+		t.Errorf("expected the created task to be saved, got %+v (%v)", task, err)
+	}
+
+	for id, want := range map[int]string{4: existing.ID, 5: created.ID} {
+		var found struct {
+			Count int `json:"count"`
+			Tasks []struct {
+				ID string `json:"id"`
+			} `json:"tasks"`
+		}
+		if err := json.Unmarshal([]byte(result(responses[id]).Content[0].Text), &found); err != nil ||
+			found.Count != 1 || found.Tasks[0].ID != want {
+			t.Errorf("request %d: expected only task %s, got %s", id, want, result(responses[id]).Content[0].Text)
+		}
+	}
+
+	if task, _ := env.Service.GetTask(env.ctx, existing.ID); task.Status != domain.TaskStatusCompleted {
+		t.Errorf("expected complete_task to complete the task, got %s", task.Status)
+	}
+
+	// Tool failures are results the model can read; protocol errors are not
+	for _, id := range []int{7, 8} {
+		if r := result(responses[id]); !r.IsError {
+			t.Errorf("request %d: expected a tool error, got %+v", id, r)
+		}
+	}
+	if e := responses[9].Error; e == nil || e.Code != -32602 {
+		t.Errorf("expected invalid params for an unknown tool, got %+v", e)
+	}
+	if e := responses[10].Error; e == nil || e.Code != -32601 {
+		t.Errorf("expected method not found, got %+v", e)
+	}
+
+	// Read-only mode offers no tools that change tasks
+	readOnly := mcp.NewServer(env.Service, mcp.WithReadOnly())
+	responses = serve(readOnly,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		call(2, "delete_task", fmt.Sprintf(`{"id":%q}`, created.ID)),
+		call(3, "get_task", fmt.Sprintf(`{"id":%q}`, created.ID)),
+	)
+	if err := json.Unmarshal(responses[1].Result, &list); err != nil || len(list.Tools) != 3 {
+		t.Errorf("expected 3 read-only tools, got %s", responses[1].Result)
+	}
+	if r := result(responses[2]); !r.IsError || !strings.Contains(r.Content[0].Text, "read-only") {
+		t.Errorf("expected delete to be refused, got %+v", r)
+	}
+	if r := result(responses[3]); r.IsError || !strings.Contains(r.Content[0].Text, "Book flights") {
+		t.Errorf("expected get_task to work, got %+v", r)
+	}
+	if _, err := env.Service.GetTask(env.ctx, created.ID); err != nil {
+		t.Errorf("expected the task to survive a read-only delete: %v", err)
+	}
+}
+
+func TestBurndownAndVelocity(t *testing.T) {
+	// Thursday; the week starts on Monday 12 Oct
+	now := time.Date(2026, 10, 15, 18, 0, 0, 0, time.Local)
+	day := func(offset int, hour int) time.Time {
+		return time.Date(2026, 10, 15+offset, hour, 0, 0, 0, time.Local)
+	}
+	task := func(created time.Time, completed *time.Time) *domain.Task {
+		status := domain.TaskStatusPending
+		if completed != nil {
+			status = domain.TaskStatusCompleted
+		}
+		return &domain.Task{Status: status, CreatedAt: created, UpdatedAt: created, CompletedAt: completed}
+	}
+	at := func(t time.Time) *time.Time { return &t }
+
+	tasks := []*domain.Task{
+		task(day(-10, 9), at(day(-2, 17))), // open for a week, completed this week
+		task(day(-3, 9), at(day(-3, 10))),  // completed the day it was created
+		task(day(-1, 23), nil),
+		task(day(0, 8), nil),
+		task(day(-20, 9), at(day(-15, 9))), // completed before the chart starts
+	}
+
+	days := trend.Burndown(tasks, now.AddDate(0, 0, -3), now)
+	if len(days) != 4 || !days[0].Date.Equal(day(-3, 0)) {
+		t.Fatalf("expected 4 days from 12 Oct, got %+v", days)
+	}
+	for i, want := range []trend.Day{{Open: 1, Completed: 2}, {Open: 0, Completed: 3}, {Open: 1, Completed: 3}, {Open: 2, Completed: 3}} {
+		if days[i].Open != want.Open || days[i].Completed != want.Completed {
+			t.Errorf("day %d: expected %d open, %d completed, got %+v", i, want.Open, want.Completed, days[i])
+		}
+	}
+
+	weeks := trend.Velocity(tasks, 3, now)
+	if len(weeks) != 3 || weeks[2].Start.Weekday() != time.Monday || !weeks[2].Start.Equal(day(-3, 0)) {
+		t.Fatalf("expected 3 weeks ending with the week of Monday 12 Oct, got %+v", weeks)
+	}
+	for i, want := range []trend.Week{{Created: 0, Completed: 1}, {Created: 1, Completed: 0}, {Created: 3, Completed: 2}} {
+		if weeks[i].Created != want.Created || weeks[i].Completed != want.Completed {
+			t.Errorf("week %d: expected %d created, %d completed, got %+v", i, want.Created, want.Completed, weeks[i])
+		}
+	}
+
+	chart := &ui.LineChart{
+		Series: []ui.Series{
+			{Name: "open", Mark: 'o', Values: []int{0, 1, 2}},
+			{Name: "done", Mark: '+', Values: []int{2, 2, 2}},
+		},
+		Height: 3,
+		Width:  3,
+		Start:  "a",
+		End:    "b",
+	}
+	var out bytes.Buffer
+	painter, _ := ui.NewPainter(false, nil)
+	if err := chart.Render(&out, painter); err != nil {
+		t.Fatalf("failed to render chart: %v", err)
+	}
+	want := "2 +++*\n  | o \n0 +o  \n  +---\n  a  b\n\n   o open   + done\n"
+	if out.String() != want {
+		t.Errorf("unexpected chart:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if bar := ui.Bar(3, 4, 8, false); bar != "██████" {
+		t.Errorf("expected a 6 block bar, got %q", bar)
+	}
+}
+
+// TestCapacityPlan tests scheduling tasks into days by estimate and due date,
+// and the order of task next
+func TestCapacityPlan(t *testing.T) {
+	// Thursday; the weekend is skipped
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)
+	created := now.AddDate(0, 0, -7)
+	task := func(title string, priority domain.TaskPriority, estimate time.Duration, due string) *domain.Task {
+		task := &domain.Task{ID: uuid.New().String(), Title: title, Status: domain.TaskStatusPending, Priority: priority, Estimate: estimate, CreatedAt: created}
+		if due != "" {
+			task.Metadata = domain.Metadata{"todoist.due": json.RawMessage(`"` + due + `"`)}
+		}
+		return task
+	}
+
+	release := task("Release", domain.TaskPriorityMedium, 8*time.Hour, "2026-10-16")
+	hotfix := task("Hotfix", domain.TaskPriorityHigh, 5*time.Hour, "2026-10-16")
+	refactor := task("Refactor", domain.TaskPriorityMedium, 30*time.Hour, "")
+	unestimated := task("Think", domain.TaskPriorityMedium, 0, "")
+	done := task("Done", domain.TaskPriorityHigh, time.Hour, "")
+	done.Status = domain.TaskStatusCompleted
+	later := task("Later", domain.TaskPriorityLow, time.Hour, "")
+	wake := now.AddDate(0, 0, 10)
+	later.WaitUntil = &wake
+
+	p := plan.Schedule([]*domain.Task{refactor, release, unestimated, done, later, hotfix},
+		plan.Options{Start: now, Days: 7, Capacity: 6 * time.Hour})
+
+	if len(p.Days) != 5 || p.Days[2].Date.Weekday() != time.Monday {
+		t.Fatalf("expected Thursday, Friday, and Monday to Wednesday, got %d days", len(p.Days))
+	}
+	if first := p.Days[0]; first.Load != 6*time.Hour || first.Entries[0].Task != hotfix || first.Entries[1].Task != release {
+		t.Errorf("expected the high priority task first on Thursday, got %+v", first.Entries)
+	}
+	friday := p.Days[1]
+	if friday.Load != 7*time.Hour || !p.Over(friday) || len(friday.Entries) != 1 || !friday.Entries[0].Due {
+		t.Errorf("expected the rest of the release on its due day, over capacity, got %v in %+v", friday.Load, friday.Entries)
+	}
+	for _, day := range p.Days[2:] {
+		if day.Load != 6*time.Hour || p.Over(day) || day.Entries[0].Task != refactor {
+			t.Errorf("expected the refactor to fill %s, got %v", day.Date.Format(time.DateOnly), day.Load)
+		}
+	}
+	if len(p.Unplanned) != 2 || p.Unplanned[0].Task != refactor || p.Unplanned[0].Duration != 12*time.Hour || p.Unplanned[1].Task != later {
+		t.Errorf("expected 12h of the refactor and the snoozed task to be unplanned, got %+v", p.Unplanned)
+	}
+	if len(p.Unestimated) != 1 || p.Unestimated[0] != unestimated {
+		t.Errorf("expected the task without an estimate to be left out, got %d", len(p.Unestimated))
+	}
+
+	var buf bytes.Buffer
+	if err := plan.WriteICS(&buf, p, now); err != nil {
+		t.Fatalf("failed to write calendar: %v", err)
+	}
+	ics := buf.String()
+	if n := strings.Count(ics, "BEGIN:VEVENT\r\n"); n != 6 {
+		t.Errorf("expected 6 events, got %d", n)
+	}
+	for _, want := range []string{"DTSTART;VALUE=DATE:20261019\r\n", "SUMMARY:Release (7h)\r\n", "UID:" + hotfix.ID + "-20261015@task-manager\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("expected %q in the calendar:\n%s", want, ics)
+		}
+	}
+
+	// task next lists tasks in the order they are planned
+	pinned := task("Pinned", domain.TaskPriorityLow, 0, "")
+	pinned.Pinned = true
+	next := []*domain.Task{later, refactor, pinned, release, hotfix}
+	plan.Order(next)
+	for i, want := range []*domain.Task{hotfix, release, pinned, refactor, later} {
+		if next[i] != want {
+			t.Errorf("expected %q at %d in the next order, got %q", want.Title, i, next[i].Title)
+		}
+	}
+}
+
+func TestTaskGrouping(t *testing.T) {
+	now := time.Now()
+	today := now.Format(time.DateOnly)
+	task := func(id, title string, priority domain.TaskPriority, taskContext, due string, completed bool) *domain.Task {
+		task := &domain.Task{ID: id + "0000000", Title: title, Status: domain.TaskStatusPending, Priority: priority, Context: taskContext, CreatedAt: now}
+		if completed {
+			task.Status = domain.TaskStatusCompleted
+		}
+		if due != "" {
+			_ = task.SetMetadata("todoist.due", due)
+		}
+		return task
+	}
+	tasks := []*domain.Task{
+		task("1", "Plan", domain.TaskPriorityLow, "work", today, false),
+		task("2", "Pay rent", domain.TaskPriorityHigh, "", now.AddDate(0, 0, -2).Format(time.DateOnly), false),
+		task("3", "Paid taxes", domain.TaskPriorityHigh, "home", now.AddDate(0, 0, -2).Format(time.DateOnly), true),
+		task("4", "Trip", domain.TaskPriorityMedium, "home", now.AddDate(0, 0, 30).Format(time.DateOnly), false),
+		task("5", "Read", domain.TaskPriorityMedium, "work", "", false),
+	}
+
+	titles := func(groups []ui.TaskGroup) string {
+		var parts []string
+		for _, group := range groups {
+			var names []string
+			for _, task := range group.Tasks {
+				names = append(names, task.Title)
+			}
+			parts = append(parts, group.Title+": "+strings.Join(names, ", "))
+		}
+		return strings.Join(parts, "; ")
+	}
+	for by, want := range map[ui.GroupBy]string{
+		ui.GroupByStatus:   "Pending: Plan, Pay rent, Trip, Read; Completed: Paid taxes",
+		ui.GroupByPriority: "High: Pay rent, Paid taxes; Medium: Trip, Read; Low: Plan",
+		ui.GroupByContext:  "@home: Paid taxes, Trip; @work: Plan, Read; No context: Pay rent",
+		ui.GroupByDue:      "Overdue: Pay rent; Today: Plan; Later: Trip; Earlier: Paid taxes; No due date: Read",
+	} {
+		groups, err := ui.GroupTasks(tasks, by, now)
+		if err != nil {
+			t.Fatalf("failed to group by %s: %v", by, err)
+		}
+		if got := titles(groups); got != want {
+			t.Errorf("grouping by %s: expected %q, got %q", by, want, got)
+		}
+	}
+	if _, err := ui.GroupTasks(tasks, "tag", now); err == nil {
+		t.Error("expected an error for an unknown grouping")
+	}
+
+	// Every section has the same columns, and the total covers all of them
+	tasks[0].Assignee = "alice"
+	tasks[4].Estimate = 2 * time.Hour
+	groups, err := ui.GroupTasks(tasks, ui.GroupByStatus, now)
+	if err != nil {
+		t.Fatalf("failed to group tasks: %v", err)
+	}
+	painter, err := ui.NewPainter(false, nil)
+	if err != nil {
+		t.Fatalf("failed to create painter: %v", err)
+	}
+	var out bytes.Buffer
+	if err := ui.RenderGroups(&out, painter, groups, ui.TaskTableOptions{Now: now}); err != nil {
+		t.Fatalf("failed to render groups: %v", err)
+	}
+	rendered := out.String()
+	if !strings.HasPrefix(rendered, "Pending (4)\nID ") || !strings.Contains(rendered, "\n\nCompleted (1)\nID ") {
+		t.Errorf("expected a header with a count per group, got:\n%s", rendered)
+	}
+	if strings.Count(rendered, "ASSIGNEE") != 2 || strings.Count(rendered, "ESTIMATE") != 2 {
+		t.Errorf("expected the same columns in every group, got:\n%s", rendered)
+	}
+	if !strings.HasSuffix(rendered, "\nTotal: 5 task(s), 2h remaining\n") {
+		t.Errorf("expected a total over all groups, got:\n%s", rendered)
+	}
+	if ui.Effort(90*time.Minute, 3) != "1h30m / 3 pts" {
+		t.Errorf("unexpected effort: %q", ui.Effort(90*time.Minute, 3))
+	}
+}
+
+func TestTimeFormatting(t *testing.T) {
+	now := time.Date(2026, 3, 10, 22, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		due  time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), "due today"},
+		{time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), "due tomorrow"},
+		{time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC), "due in 3 days"},
+		{time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), "overdue by 1 day"},
+		{time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), "overdue by 10 days"},
+	} {
+		if got := ui.RelativeDue(tc.due, now); got != tc.want {
+			t.Errorf("due %s: expected %q, got %q", tc.due.Format(time.DateOnly), tc.want, got)
+		}
+	}
+
+	// Layouts and time zone apply to timestamps; calendar days are not converted
+	times, err := ui.NewTimeFormat("02/01/2006", "3:04PM", "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("failed to create time format: %v", err)
+	}
+	if got := times.DateTime(now); got != "11/03/2026 7:30AM" {
+		t.Errorf("expected the time in Tokyo, got %q", got)
+	}
+	if got := times.Day(time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)); got != "10/03/2026" {
+		t.Errorf("expected the due day unchanged, got %q", got)
+	}
+	if got := (ui.TimeFormat{}).DateTime(now); got != now.Local().Format("2006-01-02 15:04") {
+		t.Errorf("expected the default layouts in local time, got %q", got)
+	}
+	if _, err := ui.NewTimeFormat("", "", "Mars/Olympus"); err == nil {
+		t.Error("expected an error for an unknown time zone")
+	}
+
+	// The list table shows due dates relative to now, or as days with Absolute
+	task := &domain.Task{ID: "abcdef0123", Title: "Renew", Status: domain.TaskStatusPending, Priority: domain.TaskPriorityLow, CreatedAt: now}
+	_ = task.SetMetadata("todoist.due", "2026-03-12")
+	painter, err := ui.NewPainter(false, nil)
+	if err != nil {
+		t.Fatalf("failed to create painter: %v", err)
+	}
+	for absolute, want := range map[bool]string{false: "due in 2 days", true: "12/03/2026"} {
+		var out bytes.Buffer
+		if err := ui.RenderTasks(&out, painter, []*domain.Task{task}, ui.TaskTableOptions{Now: now, Absolute: absolute, Times: times}); err != nil {
+			t.Fatalf("failed to render tasks: %v", err)
+		}
+		if !strings.Contains(out.String(), "DUE") || !strings.Contains(out.String(), want) {
+			t.Errorf("expected a due column with %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestListTruncation(t *testing.T) {
+	painter, err := ui.NewPainter(false, nil)
+	if err != nil {
+		t.Fatalf("failed to create painter: %v", err)
+	}
+	now := time.Now()
+	long := &domain.Task{
+		ID: "0123456789", Title: strings.Repeat("long title ", 10), Description: "First line of the description\nSecond line",
+		Status: domain.TaskStatusPending, Priority: domain.TaskPriorityHigh, Context: "work", CreatedAt: now, Pinned: true,
+	}
+	short := &domain.Task{ID: "9876543210", Title: "Short", Status: domain.TaskStatusCompleted, Priority: domain.TaskPriorityLow, CreatedAt: now}
+	tasks := []*domain.Task{long, short}
+
+	// Titles are cut so rows fit the width
+	var out bytes.Buffer
+	if err := ui.RenderTasks(&out, painter, tasks, ui.TaskTableOptions{Now: now, Width: 60}); err != nil {
+		t.Fatalf("failed to render tasks: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(out.String(), "\n"), "\n") {
+		if n := utf8.RuneCountInString(line); n > 60 {
+			t.Errorf("expected lines of at most 60 characters, got %d: %q", n, line)
+		}
+	}
+	if !strings.Contains(out.String(), "★ long title long…") {
+		t.Errorf("expected a truncated title, got:\n%s", out.String())
+	}
+
+	// Wide tables add the context, due date, and first line of the description
+	out.Reset()
+	if err := ui.RenderTasks(&out, painter, tasks, ui.TaskTableOptions{Now: now, Wide: true}); err != nil {
+		t.Fatalf("failed to render tasks: %v", err)
+	}
+	for _, want := range []string{"CONTEXT", "DUE", "DESCRIPTION", "First line of the description\n", strings.Repeat("long title ", 9)} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected wide output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	// One compact line per task
+	out.Reset()
+	if err := ui.RenderTaskLines(&out, painter, tasks, ui.TaskTableOptions{Now: now, Width: 40}); err != nil {
+		t.Fatalf("failed to render task lines: %v", err)
+	}
+	want := "01234567 H ★ long title long title long…\n98765432 L Short\n"
+	if out.String() != want {
+		t.Errorf("expected lines:\n%s\ngot:\n%s", want, out.String())
+	}
+}
+
+func TestMessageCatalog(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+
+	t.Setenv("LANG", "pt_BR.UTF-8")
+	if lang := i18n.Detect(""); lang != "pt_BR" {
+		t.Errorf("expected pt_BR from LANG, got %q", lang)
+	}
+	if lang := i18n.Detect("en"); lang != "en" {
+		t.Errorf("expected the configured language to win, got %q", lang)
+	}
+	t.Setenv("LANG", "C")
+	if lang := i18n.Detect(""); lang != i18n.English {
+		t.Errorf("expected English for the C locale, got %q", lang)
+	}
+
+	printer, err := i18n.New("pt_BR")
+	if err != nil {
+		t.Fatalf("failed to load pt_BR: %v", err)
+	}
+	if printer.Language() != "pt" {
+		t.Errorf("expected pt_BR to fall back to pt, got %q", printer.Language())
+	}
+	if got := printer.Sprintf("✓ Created %d task(s)", 3); got != "✓ 3 tarefa(s) criada(s)" {
+		t.Errorf("unexpected translation: %q", got)
+	}
+	if got := printer.Sprintf("Not in the catalog %d", 1); got != "Not in the catalog 1" {
+		t.Errorf("expected untranslated messages in English, got %q", got)
+	}
+
+	// Every confirmation the commands print is in the catalog
+	sources, err := filepath.Glob("../../internal/cli/*.go")
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("failed to find the CLI sources: %v", err)
+	}
+	english, _ := i18n.New(i18n.English)
+	confirmation := regexp.MustCompile(`"✓ [^"]*"`)
+	for _, source := range sources {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", source, err)
+		}
+		for _, literal := range confirmation.FindAllString(string(data), -1) {
+			message, _ := strconv.Unquote(literal)
+			if printer.Sprintf(message, "x") == english.Sprintf(message, "x") {
+				t.Errorf("%s: %q is not translated", filepath.Base(source), message)
+			}
+		}
+	}
+
+	if i18n.Supported("xx") {
+		t.Error("expected xx to be unsupported")
+	}
+	if fallback, _ := i18n.New("xx"); fallback.Language() != i18n.English {
+		t.Errorf("expected an unsupported language to fall back to English, got %q", fallback.Language())
+	}
+	var none *i18n.Printer
+	if got := none.Sprintf("No tasks found."); got != "No tasks found." {
+		t.Errorf("expected a nil printer to print English, got %q", got)
+	}
+
+	for _, lang := range i18n.Languages() {
+		if _, err := i18n.New(lang); err != nil {
+			t.Errorf("catalog %s: %v", lang, err)
+		}
+	}
+}
+
+func TestSnoozeTasks(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	snoozed, err := env.Service.CreateTask(env.ctx, "Renew insurance", "", domain.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "Pay rent", "", domain.TaskPriorityHigh); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	now := time.Now()
+	wake := now.Add(72 * time.Hour).Truncate(time.Second)
+	if _, err := env.Service.UpdateTask(env.ctx, snoozed.ID, "", "", "", domain.WithWaitUntil(&wake)); err != nil {
+		t.Fatalf("failed to snooze task: %v", err)
+	}
+
+	task, err := env.Service.GetTask(env.ctx, snoozed.ID)
+	if err != nil || task.WaitUntil == nil || !task.WaitUntil.Equal(wake) || !task.Waiting(now) {
+		t.Fatalf("expected the task to be snoozed until %v, got %+v (%v)", wake, task, err)
+	}
+
+	count := func(awakeAt *time.Time) int {
+		tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{AwakeAt: awakeAt})
+		if err != nil {
+			t.Fatalf("failed to list tasks: %v", err)
+		}
+		return len(tasks)
+	}
+	if n := count(&now); n != 1 {
+		t.Errorf("expected the snoozed task to be hidden, got %d task(s)", n)
+	}
+	if n := count(nil); n != 2 {
+		t.Errorf("expected both tasks without AwakeAt, got %d", n)
+	}
+	later := wake.Add(time.Minute)
+	if n := count(&later); n != 2 {
+		t.Errorf("expected the task to wake after its snooze, got %d task(s)", n)
+	}
+
+	// Completed tasks are never hidden as snoozed
+	if _, err := env.Service.CompleteTask(env.ctx, snoozed.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+	if n := count(&now); n != 2 {
+		t.Errorf("expected the completed task to show, got %d task(s)", n)
+	}
+
+	// Waking clears the snooze
+	task, err = env.Service.UpdateTask(env.ctx, snoozed.ID, "", "", "", domain.WithWaitUntil(nil))
+	if err != nil || task.WaitUntil != nil {
+		t.Fatalf("expected the snooze to be cleared, got %+v (%v)", task, err)
+	}
+	if task, _ := env.Service.GetTask(env.ctx, snoozed.ID); task.WaitUntil != nil {
+		t.Errorf("expected no snooze after waking, got %v", task.WaitUntil)
+	}
+}
+
+func TestPinnedTasks(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	var ids []string
+	for _, title := range []string{"Oldest", "Middle", "Newest"} {
+		task, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityMedium)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		ids = append(ids, task.ID)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	if _, err := env.Service.UpdateTask(env.ctx, ids[0], "", "", "", domain.WithPinned(true)); err != nil {
+		t.Fatalf("failed to pin task: %v", err)
+	}
+
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	var titles []string
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	if strings.Join(titles, ",") != "Oldest,Newest,Middle" {
+		t.Errorf("expected the pinned task first, then newest first, got %v", titles)
+	}
+	if !tasks[0].Pinned || tasks[1].Pinned {
+		t.Errorf("expected only the first task to be pinned")
+	}
+
+	pinned := true
+	tasks, err = env.Service.ListTasks(env.ctx, domain.TaskFilter{Pinned: &pinned})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != ids[0] {
+		t.Errorf("expected only the pinned task, got %d (%v)", len(tasks), err)
+	}
+
+	task, err := env.Service.UpdateTask(env.ctx, ids[0], "", "", "", domain.WithPinned(false))
+	if err != nil || task.Pinned {
+		t.Fatalf("failed to unpin task: %v", err)
+	}
+	if n, _ := env.Service.CountTasks(env.ctx, domain.TaskFilter{Pinned: &pinned}); n != 0 {
+		t.Errorf("expected no pinned tasks after unpinning, got %d", n)
+	}
+}
+
+// TestTaskEstimates tests storing estimates and summing them per context
+func TestTaskEstimates(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	spec, err := env.Service.CreateTask(env.ctx, "Write spec", "", domain.TaskPriorityMedium,
+		domain.WithTaskContext("work"), domain.WithEstimate(2*time.Hour), domain.WithPoints(3))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	ship, err := env.Service.CreateTask(env.ctx, "Ship", "", domain.TaskPriorityMedium,
+		domain.WithTaskContext("work"), domain.WithPoints(5))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "Review", "", domain.TaskPriorityLow, domain.WithEstimate(45*time.Minute)); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CompleteTask(env.ctx, ship.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+
+	got, err := env.Service.GetTask(env.ctx, spec.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if got.Estimate != 2*time.Hour || got.Points != 3 {
+		t.Errorf("expected 2h and 3 points, got %v and %d", got.Estimate, got.Points)
+	}
+
+	pending := domain.TaskStatusPending
+	efforts, err := env.Service.EffortByContext(env.ctx, domain.TaskFilter{Status: &pending})
+	if err != nil {
+		t.Fatalf("failed to sum effort: %v", err)
+	}
+	if e := efforts["work"]; e.Tasks != 1 || e.Estimate != 2*time.Hour || e.Points != 3 {
+		t.Errorf("unexpected pending effort for work: %+v", e)
+	}
+	if e := efforts[""]; e.Tasks != 1 || e.Estimate != 45*time.Minute || e.Points != 0 {
+		t.Errorf("unexpected pending effort without context: %+v", e)
+	}
+
+	got, err = env.Service.UpdateTask(env.ctx, spec.ID, "", "", "", domain.WithEstimate(0))
+	if err != nil || got.Estimate != 0 || got.Points != 3 {
+		t.Errorf("expected the estimate cleared and points kept, got %v and %d (%v)", got.Estimate, got.Points, err)
+	}
+	if _, err := env.Service.UpdateTask(env.ctx, spec.ID, "", "", "", domain.WithPoints(-1)); err == nil {
+		t.Error("expected negative points to be rejected")
+	}
+}
+
+func TestTaskLocations(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	report, err := env.Service.CreateTask(env.ctx, "Print report", "", domain.TaskPriorityMedium, domain.WithLocation(" Office-PC "))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if report.Location != "office-pc" {
+		t.Errorf("expected normalized location office-pc, got %q", report.Location)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "Backup photos", "", domain.TaskPriorityLow, domain.WithLocation("laptop")); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "Call bank", "", domain.TaskPriorityHigh); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "Bad", "", domain.TaskPriorityLow, domain.WithLocation("home office")); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected a validation error for a location with spaces, got %v", err)
+	}
+
+	here := "laptop"
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{Location: &here})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	var titles []string
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	slices.Sort(titles)
+	if !slices.Equal(titles, []string{"Backup photos", "Call bank"}) {
+		t.Errorf("expected the laptop task and the task without a location, got %v", titles)
+	}
+
+	updated, err := env.Service.UpdateTask(env.ctx, report.ID, "", "", "", domain.WithLocation(""))
+	if err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if updated.Location != "" {
+		t.Errorf("expected the location to be cleared, got %q", updated.Location)
+	}
+	if tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{Location: &here}); err != nil || len(tasks) != 3 {
+		t.Errorf("expected 3 tasks after clearing the location, got %d (%v)", len(tasks), err)
+	}
+}
+
+// TestReportByContext tests the per-project report of counts, overdue tasks
+// and estimates
+func TestReportByContext(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	now := time.Now()
+	create := func(title, project string, opts ...domain.TaskOption) *domain.Task {
+		t.Helper()
+		task, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityMedium, append(opts, domain.WithTaskContext(project))...)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		return task
+	}
+
+	create("Write copy", "website", domain.WithEstimate(2*time.Hour))
+	late := create("Fix footer", "website", domain.WithPoints(3))
+	done := create("Pick fonts", "website", domain.WithEstimate(time.Hour))
+	create("Call bank", "")
+	if _, err := env.Service.SetTaskMetadata(env.ctx, late.ID, "todoist.due", now.AddDate(0, 0, -1).Format(time.DateOnly)); err != nil {
+		t.Fatalf("failed to set due date: %v", err)
+	}
+	if _, err := env.Service.CompleteTask(env.ctx, done.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+
+	reports, err := env.Service.ReportByContext(env.ctx, domain.TaskFilter{}, now)
+	if err != nil {
+		t.Fatalf("failed to build report: %v", err)
+	}
+	if len(reports) != 2 || reports[0].Context != "" || reports[1].Context != "website" {
+		t.Fatalf("expected reports for no context and website, got %+v", reports)
+	}
+	website := reports[1]
+	if website.Open.Tasks != 2 || website.Completed.Tasks != 1 || website.Overdue != 1 {
+		t.Errorf("expected 2 open, 1 completed and 1 overdue, got %+v", website)
+	}
+	if website.Open.Estimate != 2*time.Hour || website.Open.Points != 3 || website.Completed.Estimate != time.Hour {
+		t.Errorf("unexpected website estimates: %+v", website)
+	}
+	if reports[0].Open.Tasks != 1 || reports[0].Overdue != 0 {
+		t.Errorf("expected one open task without a context, got %+v", reports[0])
+	}
+
+	// Filters apply to every column
+	completed := domain.TaskStatusCompleted
+	reports, err = env.Service.ReportByContext(env.ctx, domain.TaskFilter{Status: &completed}, now)
+	if err != nil {
+		t.Fatalf("failed to build report: %v", err)
+	}
+	if len(reports) != 1 || reports[0].Open.Tasks != 0 || reports[0].Overdue != 0 || reports[0].Completed.Tasks != 1 {
+		t.Errorf("expected only the completed website task, got %+v", reports)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	since := time.Now().Add(-time.Minute)
+	create := func(title string) *domain.Task {
+		t.Helper()
+		task, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityMedium)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		return task
+	}
+
+	edited := create("Draft agenda")
+	done := create("Book room")
+	gone := create("Order lunch")
+
+	// Updates within a second of creation are not reported, so move this one on
+	edited.Title = "Final agenda"
+	edited.UpdatedAt = edited.CreatedAt.Add(time.Minute)
+	if err := env.Repo.Update(env.ctx, edited); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if _, err := env.Service.CompleteTask(env.ctx, done.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+	if err := env.Service.DeleteTask(env.ctx, gone.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	tombstones := repository.NewSQLiteTaskRepository(env.Storage.DB(), env.Logger)
+	events, err := timeline.Build(env.ctx, env.Service, tombstones, domain.TaskFilter{}, since)
+	if err != nil {
+		t.Fatalf("failed to build timeline: %v", err)
+	}
+	var got []string
+	for _, event := range events {
+		got = append(got, string(event.Kind)+" "+event.TaskID)
+	}
+	want := []string{
+		"created " + edited.ID,
+		"created " + done.ID,
+		"completed " + done.ID,
+		"deleted " + gone.ID,
+		"updated " + edited.ID,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected events %v, got %v", want, got)
+	}
+
+	// Deleted tasks record no context, so a context filter leaves them out
+	work := "work"
+	filtered, err := timeline.Build(env.ctx, env.Service, tombstones, domain.TaskFilter{Context: &work}, since)
+	if err != nil {
+		t.Fatalf("failed to build filtered timeline: %v", err)
+	}
+	if len(filtered) != 0 {
+		t.Errorf("expected no events in context work, got %+v", filtered)
+	}
+
+	days := timeline.ByDay(events)
+	count := 0
+	for _, day := range days {
+		count += len(day.Events)
+	}
+	if count != len(events) || !days[0].Date.Equal(time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.Local)) {
+		t.Errorf("unexpected grouping by day: %+v", days)
+	}
+}
+
+// TestUserDefinedFields tests storing, querying, and bulk-setting user-defined fields
+func TestUserDefinedFields(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	defs := []domain.FieldDef{
+		{Name: "sprint", Type: domain.FieldTypeNumber},
+		{Name: "size", Type: domain.FieldTypeEnum, Values: []string{"S", "M", "L"}},
+	}
+	for _, def := range defs {
+		if err := def.Validate(); err != nil {
+			t.Fatalf("expected %s to be valid: %v", def.Name, err)
+		}
+	}
+	if err := (domain.FieldDef{Name: "status", Type: domain.FieldTypeString}).Validate(); err == nil {
+		t.Error("expected a field shadowing status to be rejected")
+	}
+	if v, err := defs[0].Normalize("04.50"); err != nil || v != "4.5" {
+		t.Errorf("expected 4.5, got %q (%v)", v, err)
+	}
+	if _, err := defs[1].Normalize("xl"); err == nil {
+		t.Error("expected an enum value outside the list to be rejected")
+	}
+
+	first, err := env.Service.CreateTask(env.ctx, "Sprint 3", "", domain.TaskPriorityMedium,
+		domain.WithField("sprint", "3"), domain.WithField("size", "m"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	second, err := env.Service.CreateTask(env.ctx, "Sprint 10", "", domain.TaskPriorityMedium, domain.WithField("sprint", "10"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "No sprint", "", domain.TaskPriorityMedium); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	got, err := env.Service.GetTask(env.ctx, first.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if got.Fields["sprint"] != "3" || got.Fields["size"] != "m" {
+		t.Errorf("unexpected fields: %v", got.Fields)
+	}
+
+	count := func(q string) int64 {
+		t.Helper()
+		expr, err := query.Parse(q, defs...)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", q, err)
+		}
+		n, err := env.Service.CountTasks(env.ctx, domain.TaskFilter{Query: expr})
+		if err != nil {
+			t.Fatalf("failed to count %q: %v", q, err)
+		}
+		return n
+	}
+	// Numbers compare numerically, not as text
+	if n := count("sprint>5"); n != 1 {
+		t.Errorf("expected 1 task with sprint>5, got %d", n)
+	}
+	if n := count("size:M"); n != 1 {
+		t.Errorf("expected 1 task with size:M, got %d", n)
+	}
+	if n := count("sprint!=3"); n != 2 {
+		t.Errorf("expected tasks without the field to match !=, got %d", n)
+	}
+	if _, err := query.Parse("size>m", defs...); err == nil {
+		t.Error("expected ordering an enum field to be rejected")
+	}
+
+	expr, _ := query.Parse("sprint:3", defs...)
+	n, err := env.Service.UpdateTasks(env.ctx, domain.TaskFilter{Query: expr}, domain.TaskPatch{Fields: map[string]string{"sprint": "4", "size": ""}})
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 task modified, got %d (%v)", n, err)
+	}
+	got, _ = env.Service.GetTask(env.ctx, first.ID)
+	if len(got.Fields) != 1 || got.Fields["sprint"] != "4" {
+		t.Errorf("expected only sprint=4 after modify, got %v", got.Fields)
+	}
+
+	got, err = env.Service.UpdateTask(env.ctx, second.ID, "", "", "", domain.WithField("sprint", ""))
+	if err != nil || len(got.Fields) != 0 {
+		t.Errorf("expected the field removed, got %v (%v)", got.Fields, err)
+	}
+	if n := count("sprint>0"); n != 1 {
+		t.Errorf("expected 1 task with a sprint, got %d", n)
+	}
+}
+
+// TestTaskLinks tests linking tasks to each other and to URLs
+func TestTaskLinks(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	links := repository.NewSQLiteLinkRepository(env.Storage.DB(), env.Logger)
+
+	var ids []string
+	for _, title := range []string{"Spec", "Old spec", "Review"} {
+		task, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityMedium)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+
+	kind, err := domain.ParseLinkKind("see also")
+	if err != nil || kind != domain.LinkSeeAlso {
+		t.Errorf("expected see-also, got %q (%v)", kind, err)
+	}
+	if err := (&domain.Link{TaskID: ids[0], Kind: domain.LinkRelates, Target: ids[0]}).Validate(); err == nil {
+		t.Error("expected a link to the task itself to be rejected")
+	}
+
+	now := time.Now()
+	for _, link := range []*domain.Link{
+		{TaskID: ids[1], Kind: domain.LinkDuplicates, Target: ids[0], CreatedAt: now},
+		{TaskID: ids[0], Kind: domain.LinkSeeAlso, Target: "https://example.com/doc", CreatedAt: now.Add(time.Second)},
+		{TaskID: ids[0], Kind: domain.LinkRelates, Target: ids[2], CreatedAt: now.Add(2 * time.Second)},
+		{TaskID: ids[0], Kind: domain.LinkRelates, Target: ids[2], CreatedAt: now.Add(3 * time.Second)},
+	} {
+		if err := link.Validate(); err != nil {
+			t.Fatalf("invalid link: %v", err)
+		}
+		if err := links.Add(env.ctx, link); err != nil {
+			t.Fatalf("failed to add link: %v", err)
+		}
+	}
+
+	// Links are listed for both ends, and adding one twice keeps one
+	got, err := links.ListByTask(env.ctx, ids[0])
+	if err != nil {
+		t.Fatalf("failed to list links: %v", err)
+	}
+	if len(got) != 3 || got[0].TaskID != ids[1] || !got[1].IsURL() || got[2].Target != ids[2] {
+		t.Fatalf("unexpected links: %+v", got)
+	}
+
+	// Unlinking works from either end
+	if err := links.Remove(env.ctx, ids[2], ids[0]); err != nil {
+		t.Fatalf("failed to remove link: %v", err)
+	}
+	if err := links.Remove(env.ctx, ids[2], ids[0]); !errors.Is(err, domain.ErrLinkNotFound) {
+		t.Errorf("expected ErrLinkNotFound, got %v", err)
+	}
+
+	// Deleting a task removes the links from and to it
+	if err := env.Service.DeleteTask(env.ctx, ids[1]); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	got, _ = links.ListByTask(env.ctx, ids[0])
+	if len(got) != 1 || got[0].Target != "https://example.com/doc" {
+		t.Errorf("expected only the URL link to remain, got %+v", got)
+	}
+}
+
+// TestTaskComments tests the comment thread of tasks
+func TestTaskComments(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	comments := repository.NewSQLiteCommentRepository(env.Storage.DB(), env.Logger)
+
+	task, err := env.Service.CreateTask(env.ctx, "Ship release", "", domain.TaskPriorityHigh)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	if err := (&domain.Comment{TaskID: task.ID, Author: "alice", Body: "  "}).Validate(); err == nil {
+		t.Error("expected an empty comment to be rejected")
+	}
+	if err := (&domain.Comment{TaskID: task.ID, Body: "Hi"}).Validate(); err == nil {
+		t.Error("expected a comment without author to be rejected")
+	}
+
+	now := time.Now()
+	for i, c := range []struct{ author, body string }{
+		{"alice", "Blocked on review"},
+		{"bob", "Reviewed, merging"},
+	} {
+		comment := &domain.Comment{ID: uuid.New().String(), TaskID: task.ID, Author: c.author, Body: c.body, CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		if err := comment.Validate(); err != nil {
+			t.Fatalf("invalid comment: %v", err)
+		}
+		if err := comments.Add(env.ctx, comment); err != nil {
+			t.Fatalf("failed to add comment: %v", err)
+		}
+	}
+
+	got, err := comments.ListByTask(env.ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to list comments: %v", err)
+	}
+	if len(got) != 2 || got[0].Author != "alice" || got[1].Body != "Reviewed, merging" {
+		t.Fatalf("unexpected comments: %+v", got)
+	}
+
+	// Deleting a task removes its comments
+	if err := env.Service.DeleteTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	if got, _ := comments.ListByTask(env.ctx, task.ID); len(got) != 0 {
+		t.Errorf("expected comments to be deleted with the task, got %+v", got)
+	}
+}
+
+// TestCommentRoles tests that commenting through the service is subject to
+// the user's role
+func TestCommentRoles(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	comments := repository.NewSQLiteCommentRepository(env.Storage.DB(), env.Logger)
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithComments(comments))
+	bob := &domain.User{Name: "bob", Role: domain.RoleMember}
+	carol := &domain.User{Name: "carol", Role: domain.RoleViewer}
+
+	theirs, err := svc.CreateTask(env.ctx, "Alice's", "", domain.TaskPriorityLow, domain.WithCreatedBy("alice"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	if err := svc.As(carol).AddComment(env.ctx, &domain.Comment{TaskID: theirs.ID, Author: "carol", Body: "Hi"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected viewers not to comment, got %v", err)
+	}
+	if err := svc.As(bob).DryRun(env.readOnly).AddComment(env.ctx, &domain.Comment{TaskID: theirs.ID, Author: "bob", Body: "Maybe"}); err != nil {
+		t.Errorf("expected a dry run to succeed, got %v", err)
+	}
+	if err := svc.As(bob).AddComment(env.ctx, &domain.Comment{TaskID: theirs.ID, Author: "bob", Body: "Can help"}); err != nil {
+		t.Errorf("expected members to comment on tasks they do not own, got %v", err)
+	}
+	if err := svc.AddComment(env.ctx, &domain.Comment{TaskID: "missing", Author: "bob", Body: "Hi"}); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("expected a comment on a missing task to be rejected, got %v", err)
+	}
+
+	got, err := svc.As(carol).ListComments(env.ctx, theirs.ID)
+	if err != nil {
+		t.Fatalf("failed to list comments: %v", err)
+	}
+	if len(got) != 1 || got[0].Author != "bob" || got[0].ID == "" || got[0].CreatedAt.IsZero() {
+		t.Errorf("expected only bob's comment, got %+v", got)
+	}
+}
+
+// TestEncryptedComments tests that comment bodies are encrypted with the
+// tasks of an encrypted database
+func TestEncryptedComments(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	plain := repository.NewSQLiteCommentRepository(env.Storage.DB(), env.Logger)
+	task, err := env.Service.CreateTask(env.ctx, "Audit", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	before := &domain.Comment{ID: uuid.New().String(), TaskID: task.ID, Author: "alice", Body: "Globex is late", CreatedAt: time.Now()}
+	if err := plain.Add(env.ctx, before); err != nil {
+		t.Fatalf("failed to add comment: %v", err)
+	}
+
+	if _, err := encryption.EncryptDatabase(env.ctx, env.Storage.DB(), "correct horse"); err != nil {
+		t.Fatalf("failed to encrypt database: %v", err)
+	}
+	cipher, err := encryption.Open(env.ctx, env.Storage.DB(), "correct horse")
+	if err != nil {
+		t.Fatalf("failed to open encrypted database: %v", err)
+	}
+	svc := service.NewTaskService(repository.NewEncryptedTaskRepository(env.Repo, cipher), env.Logger,
+		service.WithComments(repository.NewEncryptedCommentRepository(plain, cipher)))
+	if err := svc.AddComment(env.ctx, &domain.Comment{TaskID: task.ID, Author: "bob", Body: "Globex replied"}); err != nil {
+		t.Fatalf("failed to add encrypted comment: %v", err)
+	}
+
+	stored, err := plain.ListByTask(env.ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to list raw comments: %v", err)
+	}
+	for _, comment := range stored {
+		if !encryption.IsEncrypted(comment.Body) || comment.Author == "" || encryption.IsEncrypted(comment.Author) {
+			t.Errorf("expected an encrypted body and a plain author, got %q/%q", comment.Author, comment.Body)
+		}
+	}
+
+	got, err := svc.ListComments(env.ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to list comments: %v", err)
+	}
+	if len(got) != 2 || got[0].Body != "Globex is late" || got[1].Body != "Globex replied" {
+		t.Errorf("expected decrypted comments, got %+v", got)
+	}
+
+	if _, err := encryption.DecryptDatabase(env.ctx, env.Storage.DB(), "correct horse"); err != nil {
+		t.Fatalf("failed to decrypt database: %v", err)
+	}
+	if stored, _ := plain.ListByTask(env.ctx, task.ID); len(stored) != 2 || stored[1].Body != "Globex replied" {
+		t.Errorf("expected plain-text comments after decrypt, got %+v", stored)
+	}
+}
+
+// TestStaleTasks tests last activity tracking and the idle filter
+func TestStaleTasks(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	comments := repository.NewSQLiteCommentRepository(env.Storage.DB(), env.Logger)
+
+	now := time.Now()
+	old := now.AddDate(0, 0, -40)
+	var ids []string
+	for _, title := range []string{"Forgotten", "Discussed", "Fresh"} {
+		task, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityMedium)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+	for _, id := range ids[:2] {
+		if _, err := env.Storage.DB().Exec("UPDATE tasks SET updated_at = ? WHERE id = ?", old, id); err != nil {
+			t.Fatalf("failed to age task: %v", err)
+		}
+	}
+
+	forgotten, err := env.Service.GetTask(env.ctx, ids[0])
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if !forgotten.LastActivityAt.Equal(old) {
+		t.Errorf("expected last activity to follow updated_at (%v), got %v", old, forgotten.LastActivityAt)
+	}
+	if !forgotten.Stale(now, domain.DefaultStaleDays) || forgotten.Stale(now, 60) {
+		t.Error("expected a task idle for 40 days to be stale after 30 days but not after 60")
+	}
+
+	// A comment counts as activity
+	comment := &domain.Comment{ID: uuid.New().String(), TaskID: ids[1], Author: "alice", Body: "Still needed", CreatedAt: now}
+	if err := comments.Add(env.ctx, comment); err != nil {
+		t.Fatalf("failed to add comment: %v", err)
+	}
+
+	idleSince := now.AddDate(0, 0, -domain.DefaultStaleDays)
+	pending := domain.TaskStatusPending
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{Status: &pending, IdleSince: &idleSince})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != ids[0] {
+		t.Fatalf("expected only the forgotten task to be idle, got %d task(s)", len(tasks))
+	}
+
+	// So does an update
+	if _, err := env.Service.UpdateTask(env.ctx, ids[0], "Remembered", "", ""); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	tasks, err = env.Service.ListTasks(env.ctx, domain.TaskFilter{Status: &pending, IdleSince: &idleSince})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("expected no idle tasks after the update, got %d", len(tasks))
+	}
+}
+
+// TestValidationRules tests the configurable title and description checks
+func TestValidationRules(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	// The defaults reject huge titles and tidy whitespace
+	if _, err := env.Service.CreateTask(env.ctx, strings.Repeat("x", 10<<20), "", domain.TaskPriorityLow); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected a 10MB title to be rejected, got %v", err)
+	}
+	task, err := env.Service.CreateTask(env.ctx, "  Plan \n the   trip ", "\n Pack light \n", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if task.Title != "Plan the trip" || task.Description != "Pack light" {
+		t.Errorf("expected trimmed title and description, got %q and %q", task.Title, task.Description)
+	}
+	if _, err := env.Service.UpdateTask(env.ctx, task.ID, "   ", "", ""); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected a blank title to be rejected, got %v", err)
+	}
+
+	cfg := config.ValidationConfig{MaxTitleLength: 10, RequireDescription: []string{"High"}, BannedCharacters: "<>"}
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithValidation(cfg.Rules()))
+
+	for _, tc := range []struct {
+		title, description string
+		priority           domain.TaskPriority
+		want               string
+	}{
+		{"Far too long a title", "", domain.TaskPriorityLow, "too long (20 characters, at most 10)"},
+		{"Fix <b>", "", domain.TaskPriorityLow, `cannot contain "<"`},
+		{"Outage", "  ", domain.TaskPriorityHigh, "high priority tasks need a description"},
+	} {
+		_, err := svc.CreateTask(env.ctx, tc.title, tc.description, tc.priority)
+		if !errors.Is(err, domain.ErrValidation) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("creating %q: expected %q, got %v", tc.title, tc.want, err)
+		}
+	}
+	if _, err := svc.CreateTask(env.ctx, "  Outage  ", "Site down", domain.TaskPriorityHigh); err != nil {
+		t.Errorf("expected a valid task to be created, got %v", err)
+	}
+
+	// Bulk updates check the rules too
+	if _, err := svc.CreateTask(env.ctx, "Call Sam", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	high := domain.TaskPriorityHigh
+	if _, err := svc.UpdateTasks(env.ctx, domain.TaskFilter{}, domain.TaskPatch{Priority: &high}); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected raising priority without a description to be rejected, got %v", err)
+	}
+}
+
+// TestIdempotentCreate tests creating tasks with client-supplied IDs and idempotency keys
+func TestIdempotentCreate(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	first, err := env.Service.CreateTask(env.ctx, "Deploy", "", domain.TaskPriorityHigh, domain.WithIdempotencyKey("req-42"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	again, err := env.Service.CreateTask(env.ctx, "Deploy (retry)", "", domain.TaskPriorityLow, domain.WithIdempotencyKey("req-42"))
+	if err != nil {
+		t.Fatalf("failed to retry create: %v", err)
+	}
+	if again.ID != first.ID || again.Title != "Deploy" {
+		t.Errorf("expected the retry to return the first task, got %+v", again)
+	}
+	other, err := env.Service.CreateTask(env.ctx, "Deploy", "", domain.TaskPriorityHigh, domain.WithIdempotencyKey("req-43"))
+	if err != nil || other.ID == first.ID {
+		t.Errorf("expected a new task for another key, got %v (%v)", other, err)
+	}
+
+	// Explicit IDs are canonicalized and enforced by the primary key
+	id := "0F0C4D1E-9A4B-4D7A-8E2F-111111111111"
+	task, err := env.Service.CreateTask(env.ctx, "With ID", "", domain.TaskPriorityLow, domain.WithID(id))
+	if err != nil {
+		t.Fatalf("failed to create task with ID: %v", err)
+	}
+	if task.ID != strings.ToLower(id) {
+		t.Errorf("expected ID %s, got %s", strings.ToLower(id), task.ID)
+	}
+	if err := env.Repo.Create(env.ctx, task); !errors.Is(err, domain.ErrDuplicateTask) {
+		t.Errorf("expected ErrDuplicateTask from the repository, got %v", err)
+	}
+	if _, err := env.Service.CreateTask(env.ctx, "Bad ID", "", domain.TaskPriorityLow, domain.WithID("nope")); !errors.Is(err, domain.ErrInvalidTaskID) {
+		t.Errorf("expected ErrInvalidTaskID, got %v", err)
+	}
+
+	count, err := env.Service.CountTasks(env.ctx, domain.TaskFilter{})
+	if err != nil || count != 3 {
+		t.Errorf("expected 3 tasks, got %d (%v)", count, err)
+	}
+}
+
+// TestServiceInterceptors tests interceptors composed around service calls
+func TestServiceInterceptors(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	var order []string
+	trace := func(name string) service.Interceptor {
+		return func(ctx context.Context, call *service.Call, next service.Handler) error {
+			order = append(order, name+">"+call.Method)
+			err := next(ctx, call)
+			order = append(order, name+"<"+call.Method)
+			return err
+		}
+	}
+
+	var observed []string
+	errReadOnly := errors.New("read-only")
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithInterceptors(
+		trace("outer"),
+		trace("inner"),
+		service.Observe(func(call *service.Call, elapsed time.Duration, err error) {
+			observed = append(observed, fmt.Sprintf("%s %s %v", call.Method, call.TaskID, err != nil))
+		}),
+		service.Authorize(func(ctx context.Context, call *service.Call) error {
+			if call.Write && call.Method != "CreateTask" {
+				return errReadOnly
+			}
+			return nil
+		}),
+	))
+
+	task, err := svc.CreateTask(env.ctx, "Intercepted", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	want := []string{"outer>CreateTask", "inner>CreateTask", "inner<CreateTask", "outer<CreateTask"}
+	if !slices.Equal(order, want) {
+		t.Errorf("expected interceptors to nest in order %v, got %v", want, order)
+	}
+	if len(observed) != 1 || observed[0] != "CreateTask "+task.ID+" false" {
+		t.Errorf("expected the created task ID to be observed, got %v", observed)
+	}
+
+	if _, err := svc.CompleteTask(env.ctx, task.ID); !errors.Is(err, errReadOnly) {
+		t.Errorf("expected the write to be rejected, got %v", err)
+	}
+	if _, err := svc.GetTask(env.ctx, task.ID); err != nil {
+		t.Errorf("expected reads to be allowed, got %v", err)
+	}
+
+	// Dry runs are an interceptor too: the result is computed but not saved
+	dry := env.Service.DryRun(env.readOnly)
+	done, err := dry.CompleteTask(env.ctx, task.ID)
+	if err != nil || done.Status != domain.TaskStatusCompleted {
+		t.Fatalf("expected a completed task from the dry run, got %v (%v)", done, err)
+	}
+	stored, _ := env.Service.GetTask(env.ctx, task.ID)
+	if stored.Status != domain.TaskStatusPending {
+		t.Errorf("expected the dry run not to write, got %s", stored.Status)
+	}
+
+	// Interceptors can add checks, which run after the built-in ones on
+	// every task a write call saves
+	var checked []string
+	checking := service.NewTaskService(env.Repo, env.Logger, service.WithInterceptors(
+		func(ctx context.Context, call *service.Call, next service.Handler) error {
+			call.Checks = append(call.Checks, func(ctx context.Context, task *domain.Task) error {
+				checked = append(checked, task.Title)
+				if strings.Contains(task.Title, "secret") {
+					return fmt.Errorf("no secrets: %w", domain.ErrValidation)
+				}
+				return nil
+			})
+			return next(ctx, call)
+		},
+	))
+	if _, err := checking.CreateTask(env.ctx, "  Plain   title ", "", domain.TaskPriorityLow); err != nil {
+		t.Errorf("expected the checks to pass, got %v", err)
+	}
+	if _, err := checking.CreateTask(env.ctx, "The secret plan", "", domain.TaskPriorityLow); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected the added check to reject the task, got %v", err)
+	}
+	if _, err := checking.CreateTask(env.ctx, "", "", domain.TaskPriorityLow); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected validation to reject the task, got %v", err)
+	}
+	if want := []string{"Plain title", "The secret plan"}; !slices.Equal(checked, want) {
+		t.Errorf("expected the added check to see normalized, valid tasks %v, got %v", want, checked)
+	}
+}
+
+// TestEventBus tests events published by the service to subscribers
+func TestEventBus(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	var got []string
+	bus := events.NewBus(env.Logger)
+	bus.Subscribe("recorder", func(ctx context.Context, event events.Event) error {
+		got = append(got, string(event.Type))
+		return nil
+	})
+	bus.Subscribe("failing", func(ctx context.Context, event events.Event) error {
+		return errors.New("subscriber down")
+	}, events.TaskCreated)
+
+	var hooked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Event string `json:"event"`
+			Task  struct {
+				Title string `json:"title"`
+			} `json:"task"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode webhook payload: %v", err)
+		}
+		hooked = append(hooked, payload.Event+" "+payload.Task.Title)
+	}))
+	defer server.Close()
+	bus.Subscribe("webhook", events.Webhook(server.URL, server.Client()), events.TaskCompleted)
+
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithEvents(bus))
+
+	task, err := svc.CreateTask(env.ctx, "Ship it", "", domain.TaskPriorityHigh)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := svc.UpdateTask(env.ctx, task.ID, "Ship it now", "", ""); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if _, err := svc.CompleteTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+	if _, err := svc.DryRun(env.readOnly).CreateTask(env.ctx, "Not really", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to dry-run create: %v", err)
+	}
+	if _, err := svc.CreateTask(env.ctx, "", "", domain.TaskPriorityLow); err == nil {
+		t.Fatal("expected an empty title to be rejected")
+	}
+	if err := svc.DeleteTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	// Failed and dry-run calls publish nothing, and a failing subscriber
+	// does not stop the others
+	want := []string{"task.created", "task.updated", "task.completed", "task.deleted"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected events %v, got %v", want, got)
+	}
+	if len(hooked) != 1 || hooked[0] != "task.completed Ship it now" {
+		t.Errorf("expected the webhook to get task.completed only, got %v", hooked)
+	}
+
+	// Overdue tasks are found from due dates in metadata
+	late, err := svc.CreateTask(env.ctx, "Renew passport", "", domain.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	now := time.Now()
+	if _, err := svc.SetTaskMetadata(env.ctx, late.ID, "todoist.due", now.AddDate(0, 0, -2).Format(time.DateOnly)); err != nil {
+		t.Fatalf("failed to set due date: %v", err)
+	}
+	got = nil
+	overdue, err := svc.PublishOverdue(env.ctx, domain.TaskFilter{}, now)
+	if err != nil {
+		t.Fatalf("failed to publish overdue tasks: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].ID != late.ID || !slices.Equal(got, []string{"task.overdue"}) {
+		t.Errorf("expected one overdue task and event, got %d tasks and %v", len(overdue), got)
+	}
+}
+
+// TestEscalations tests evaluating escalation rules and notifying a webhook
+func TestEscalations(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	now := time.Now()
+	for _, draft := range []struct {
+		title    string
+		priority domain.TaskPriority
+		overdue  int // days past due; 0 for no due date
+	}{
+		{"Old and urgent", domain.TaskPriorityHigh, 5},
+		{"Just late", domain.TaskPriorityHigh, 1},
+		{"Old but low", domain.TaskPriorityLow, 5},
+		{"No due date", domain.TaskPriorityHigh, 0},
+	} {
+		task, err := env.Service.CreateTask(env.ctx, draft.title, "", draft.priority)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		if draft.overdue > 0 {
+			if _, err := env.Service.SetTaskMetadata(env.ctx, task.ID, "todoist.due", now.AddDate(0, 0, -draft.overdue).Format(time.DateOnly)); err != nil {
+				t.Fatalf("failed to set due date: %v", err)
+			}
+		}
+	}
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+
+	var hooked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Text       string `json:"text"`
+			Escalation string `json:"escalation"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("failed to decode escalation: %v", err)
+		}
+		hooked = append(hooked, msg.Escalation+": "+msg.Text)
+	}))
+	defer server.Close()
+
+	rules, err := escalation.FromConfig([]config.EscalationConfig{
+		{Name: "urgent", Priority: "High", Overdue: 48 * time.Hour, Webhook: server.URL},
+		{Name: "done", Context: "nowhere", Email: "lead@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("failed to read escalations: %v", err)
+	}
+
+	// Only the high-priority task whose due day ended over 48 hours ago escalates
+	matches := escalation.Evaluate(rules, tasks, now)
+	if len(matches) != 1 || matches[0].Rule.Name != "urgent" || len(matches[0].Tasks) != 1 || matches[0].Tasks[0].Title != "Old and urgent" {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+
+	notifier := &escalation.Notifier{Client: server.Client()}
+	if err := notifier.Notify(env.ctx, matches[0]); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	if len(hooked) != 1 || !strings.HasPrefix(hooked[0], "urgent: Escalation urgent: 1 overdue task(s)") || !strings.Contains(hooked[0], "Old and urgent") {
+		t.Errorf("unexpected webhook messages: %q", hooked)
+	}
+}
+
+// recordingNotifier records the notifications it is given
+type recordingNotifier struct {
+	got []notify.Notification
+}
+
+// Notify implements notify.Notifier
+func (r *recordingNotifier) Notify(ctx context.Context, n notify.Notification) error {
+	r.got = append(r.got, n)
+	return nil
+}
+
+// TestNotifiers tests picking a notification backend and desktop escalations
+func TestNotifiers(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	notifier, err := notify.New("log", logger)
+	if err != nil {
+		t.Fatalf("failed to create notifier: %v", err)
+	}
+	if err := notifier.Notify(context.Background(), notify.Notification{Title: "2 overdue task(s)"}); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	if !strings.Contains(logs.String(), `title="2 overdue task(s)"`) {
+		t.Errorf("expected the notification in the log, got %q", logs.String())
+	}
+	if _, err := notify.New("auto", logger); err != nil {
+		t.Errorf("expected auto to fall back rather than fail, got %v", err)
+	}
+	if _, err := notify.New("dbus", logger); err == nil {
+		t.Error("expected an unknown backend to be rejected")
+	}
+
+	rules, err := escalation.FromConfig([]config.EscalationConfig{{Name: "late", Desktop: true}})
+	if err != nil {
+		t.Fatalf("failed to read escalations: %v", err)
+	}
+	due := time.Now().AddDate(0, 0, -3).Format(time.DateOnly)
+	task := &domain.Task{ID: "3f2a1b9c-0000-0000-0000-000000000000", Title: "Renew passport", Status: domain.TaskStatusPending,
+		Priority: domain.TaskPriorityMedium, Metadata: domain.Metadata{"todoist.due": json.RawMessage(`"` + due + `"`)}}
+	matches := escalation.Evaluate(rules, []*domain.Task{task}, time.Now())
+	if len(matches) != 1 {
+		t.Fatalf("expected the overdue task to escalate, got %+v", matches)
+	}
+
+	desktop := &recordingNotifier{}
+	if err := (&escalation.Notifier{Desktop: desktop}).Notify(context.Background(), matches[0]); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	if len(desktop.got) != 1 || !desktop.got[0].Urgent || !strings.Contains(desktop.got[0].Body, "Renew passport") {
+		t.Errorf("unexpected desktop notifications: %+v", desktop.got)
+	}
+}
+
+// TestTransactions tests that work spanning several tables commits or rolls
+// back as a whole
+func TestTransactions(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	db := env.Storage.DB()
+	linker := linking.NewLinker(repository.NewSQLiteExternalRefRepository(db, env.Logger), env.Logger)
+	bus := events.NewBus(env.Logger)
+	svc := service.NewTaskService(env.Repo, env.Logger,
+		service.WithTransactions(repository.NewTxManager(db, env.Logger)),
+		service.WithEvents(bus))
+
+	// Events are published after the commit, so subscribers can read the
+	// database even though it has a single connection
+	var seen []string
+	bus.Subscribe("reader", func(ctx context.Context, event events.Event) error {
+		task, err := svc.GetTask(ctx, event.TaskID)
+		if err != nil {
+			return err
+		}
+		seen = append(seen, task.Title)
+		return nil
+	}, events.TaskCreated)
+	if _, err := svc.CreateTask(env.ctx, "Plain", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if !slices.Equal(seen, []string{"Plain"}) {
+		t.Errorf("expected the subscriber to read the created task, got %v", seen)
+	}
+
+	// A failure rolls back the task, its link, and a nested transaction
+	var taskID string
+	err := svc.WithTx(env.ctx, func(ctx context.Context) error {
+		task, err := svc.CreateTask(ctx, "Imported", "", domain.TaskPriorityMedium)
+		if err != nil {
+			return err
+		}
+		taskID = task.ID
+		if _, err := linker.Link(ctx, "todoist", "42", task.ID, linking.RemoteVersion{}); err != nil {
+			return err
+		}
+		if err := svc.WithTx(ctx, func(ctx context.Context) error {
+			_, err := svc.CreateTask(ctx, "Nested", "", domain.TaskPriorityLow)
+			return err
+		}); err != nil {
+			return err
+		}
+		return errors.New("remote went away")
+	})
+	if err == nil || err.Error() != "remote went away" {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+	if _, err := svc.GetTask(env.ctx, taskID); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("expected the task to be rolled back, got %v", err)
+	}
+	if _, found, err := linker.TaskID(env.ctx, "todoist", "42"); err != nil || found {
+		t.Errorf("expected the link to be rolled back, got found=%v err=%v", found, err)
+	}
+	if n, err := svc.CountTasks(env.ctx, domain.TaskFilter{}); err != nil || n != 1 {
+		t.Errorf("expected only the first task to remain, got %d (%v)", n, err)
+	}
+
+	// Success commits both
+	err = svc.WithTx(env.ctx, func(ctx context.Context) error {
+		task, err := svc.CreateTask(ctx, "Imported", "", domain.TaskPriorityMedium)
+		if err != nil {
+			return err
+		}
+		taskID = task.ID
+		_, err = linker.Link(ctx, "todoist", "42", task.ID, linking.RemoteVersion{})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to run transaction: %v", err)
+	}
+	if id, found, err := linker.TaskID(env.ctx, "todoist", "42"); err != nil || !found || id != taskID {
+		t.Errorf("expected the link to be committed, got %q found=%v err=%v", id, found, err)
+	}
+
+	// The importer links what it creates in the same transaction
+	items := []importer.Item{{RemoteID: "7", Title: "From Trello"}}
+	result, err := importer.NewImporter(svc, linker, env.Logger).Import(env.ctx, "trello", items, false)
+	if err != nil || len(result.Created) != 1 {
+		t.Fatalf("failed to import: %v", err)
+	}
+	if id, found, _ := linker.TaskID(env.ctx, "trello", "7"); !found || id != result.Created[0].ID {
+		t.Errorf("expected the imported task to be linked, got %q", id)
+	}
+}
+
+// TestDatabaseRelocate tests moving the database file to a new location
+func TestDatabaseRelocate(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	task, err := env.Service.CreateTask(env.ctx, "Relocated Task", "", domain.TaskPriorityHigh)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "sync", "tasks.db")
+	if err := env.Storage.Relocate(env.ctx, dst); err != nil {
+		t.Fatalf("failed to relocate database: %v", err)
+	}
+
+	if _, err := os.Stat(env.DBPath); !os.IsNotExist(err) {
+		t.Error("expected old database file to be removed")
+	}
+
+	store, err := storage.NewSQLiteStorage(env.ctx, dst, env.Logger)
+	if err != nil {
+		t.Fatalf("failed to open relocated database: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.IntegrityCheck(env.ctx); err != nil {
+		t.Errorf("relocated database failed integrity check: %v", err)
+	}
+
+	svc := service.NewTaskService(repository.NewSQLiteTaskRepository(store.DB(), env.Logger), env.Logger)
+	if _, err := svc.GetTask(env.ctx, task.ID); err != nil {
+		t.Errorf("expected task in relocated database: %v", err)
+	}
+
+	if err := store.Relocate(env.ctx, dst); err == nil {
+		t.Error("expected error relocating onto an existing file")
+	}
+}
+
+// TestDoctor tests the installation checks run by "task doctor"
+func TestDoctor(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	statuses := func(checks []doctor.Check) map[string]doctor.Status {
+		got := map[string]doctor.Status{}
+		for _, check := range checks {
+			got[check.Name] = check.Status
+		}
+		return got
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.Chmod(env.DBPath, 0o600); err != nil {
+		t.Fatalf("failed to chmod database: %v", err)
+	}
+	opts := doctor.Options{ConfigFile: configFile, Storage: env.Storage, Full: true}
+
+	got := statuses(doctor.Run(env.ctx, opts))
+	for _, name := range []string{"Config", "Database", "Schema", "Integrity", "Permissions", "Disk space"} {
+		if status, ok := got[name]; !ok || status == doctor.Fail {
+			t.Errorf("expected check %s to pass, got %v (ran: %v)", name, status, ok)
+		}
+	}
+
+	// An invalid config file, a database readable by others, and a schema
+	// from a newer version are reported
+	if err := os.WriteFile(configFile, []byte("database:\n  type: oracle\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.Chmod(env.DBPath, 0o644); err != nil {
+		t.Fatalf("failed to chmod database: %v", err)
+	}
+	if _, err := env.Storage.DB().ExecContext(env.ctx, "INSERT INTO migrations (version) VALUES ('999_from_the_future')"); err != nil {
+		t.Fatalf("failed to record migration: %v", err)
+	}
+
+	checks := doctor.Run(env.ctx, opts)
+	got = statuses(checks)
+	if got["Config"] != doctor.Fail || got["Permissions"] != doctor.Warn || got["Schema"] != doctor.Warn {
+		t.Errorf("expected config to fail and permissions and schema to warn, got %v", got)
+	}
+	for _, check := range checks {
+		if check.Status != doctor.OK && check.Fix == "" {
+			t.Errorf("expected a suggested fix for %s", check.Name)
+		}
+	}
+}
+
+// TestDatabaseMaintenance tests pruning old tombstones and compacting the
+// database
+func TestDatabaseMaintenance(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	repo := repository.NewSQLiteTaskRepository(env.Storage.DB(), env.Logger)
+	old := &domain.Tombstone{TaskID: "11111111-1111-1111-1111-111111111111", DeletedAt: time.Now().AddDate(0, -6, 0)}
+	if err := repo.ApplyTombstone(env.ctx, old); err != nil {
+		t.Fatalf("failed to record tombstone: %v", err)
+	}
+	task, err := env.Service.CreateTask(env.ctx, "Short-lived", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := env.Service.DeleteTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	cutoff := time.Now().AddDate(0, -3, 0)
+	if n, err := env.Storage.CountTombstones(env.ctx, cutoff); err != nil || n != 1 {
+		t.Fatalf("expected one tombstone to prune, got %d (%v)", n, err)
+	}
+
+	report, err := env.Storage.Maintain(env.ctx, cutoff)
+	if err != nil {
+		t.Fatalf("failed to maintain database: %v", err)
+	}
+	if report.TombstonesPruned != 1 || report.SizeBefore == 0 || report.SizeAfter == 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	tombstones, err := repo.ListTombstones(env.ctx)
+	if err != nil {
+		t.Fatalf("failed to list tombstones: %v", err)
+	}
+	if len(tombstones) != 1 || tombstones[0].TaskID != task.ID {
+		t.Errorf("expected only the recent tombstone to remain, got %v", tombstones)
+	}
+	if err := env.Storage.IntegrityCheck(env.ctx); err != nil {
+		t.Errorf("expected an intact database: %v", err)
+	}
+
+	// A zero cutoff keeps every tombstone
+	if report, err := env.Storage.Maintain(env.ctx, time.Time{}); err != nil || report.TombstonesPruned != 0 {
+		t.Errorf("expected nothing pruned, got %+v (%v)", report, err)
+	}
+}
+
+// TestDatabaseOptimize tests that optimize collects query planner statistics
+func TestDatabaseOptimize(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	if _, err := env.Service.CreateTask(env.ctx, "Task", "", domain.TaskPriorityMedium); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	if _, err := env.Storage.Optimize(env.ctx); err != nil {
+		t.Fatalf("failed to optimize: %v", err)
+	}
+
+	var n int
+	if err := env.Storage.DB().QueryRow("SELECT COUNT(*) FROM sqlite_stat1").Scan(&n); err != nil {
+		t.Fatalf("expected sqlite_stat1 after optimize: %v", err)
+	}
+	if n == 0 {
+		t.Error("expected statistics to be collected")
+	}
+}
+
+// TestDatabaseArchive tests moving old completed tasks into the archive database
+func TestDatabaseArchive(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	done, err := env.Service.CreateTask(env.ctx, "Done", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CompleteTask(env.ctx, done.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+	open, err := env.Service.CreateTask(env.ctx, "Open", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	archivePath := config.DatabaseConfig{Path: env.DBPath}.ArchivePath()
+
+	// Nothing was completed before the cutoff yet
+	n, err := env.Storage.Archive(env.ctx, archivePath, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("failed to archive: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("expected no tasks archived, got %d", n)
+	}
+
+	n, err = env.Storage.Archive(env.ctx, archivePath, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("failed to archive: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 task archived, got %d", n)
+	}
+
+	if _, err := env.Service.GetTask(env.ctx, done.ID); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("expected archived task to leave the main database, got %v", err)
+	}
+	if _, err := env.Service.GetTask(env.ctx, open.ID); err != nil {
+		t.Errorf("expected pending task to stay: %v", err)
+	}
+
+	archive, err := storage.NewSQLiteStorage(env.ctx, archivePath, env.Logger)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer archive.Close()
+
+	svc := service.NewTaskService(repository.NewSQLiteTaskRepository(archive.DB(), env.Logger), env.Logger)
+	archived, err := svc.GetTask(env.ctx, done.ID)
+	if err != nil {
+		t.Fatalf("expected task in archive: %v", err)
+	}
+	if archived.Status != domain.TaskStatusCompleted || archived.CompletedAt == nil {
+		t.Errorf("expected archived task to keep its completion, got %+v", archived)
+	}
+}
+
+// TestDatabaseSync tests two-way sync with last-edit-wins and tombstones
+func TestDatabaseSync(t *testing.T) {
+	laptop := setupTestEnvironment(t)
+	defer laptop.cleanup(t)
+	desktop := setupTestEnvironment(t)
+	defer desktop.cleanup(t)
+
+	syncer := dbsync.NewSyncer(
+		dbsync.NewSQLiteReplica(laptop.Storage.DB(), laptop.Logger),
+		dbsync.NewSQLiteReplica(desktop.Storage.DB(), desktop.Logger),
+		laptop.Logger,
+	)
+
+	shared, err := laptop.Service.CreateTask(laptop.ctx, "Shared", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	doomed, err := desktop.Service.CreateTask(desktop.ctx, "Doomed", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	plan, err := syncer.Sync(laptop.ctx)
+	if err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+
+	// Link the shared task to the one about to be deleted on the desktop
+	laptopLinks := repository.NewSQLiteLinkRepository(laptop.Storage.DB(), laptop.Logger)
+	if err := laptopLinks.Add(laptop.ctx, &domain.Link{TaskID: shared.ID, Kind: domain.LinkRelates, Target: doomed.ID, CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to link tasks: %v", err)
+	}
+	if plan.Count(dbsync.PushCreate) != 1 || plan.Count(dbsync.PullCreate) != 1 {
+		t.Errorf("expected one task each way, got %d pushed and %d pulled",
+			plan.Count(dbsync.PushCreate), plan.Count(dbsync.PullCreate))
+	}
+
+	// Edit on both sides; the later edit wins
+	if _, err := laptop.Service.UpdateTask(laptop.ctx, shared.ID, "Laptop edit", "", ""); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := desktop.Service.UpdateTask(desktop.ctx, shared.ID, "Desktop edit", "", ""); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	// A delete on one side must not be undone by the other
+	if err := desktop.Service.DeleteTask(desktop.ctx, doomed.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	if _, err := syncer.Sync(laptop.ctx); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+
+	for name, env := range map[string]*TestEnvironment{"laptop": laptop, "desktop": desktop} {
+		task, err := env.Service.GetTask(env.ctx, shared.ID)
+		if err != nil {
+			t.Fatalf("%s: failed to get task: %v", name, err)
+		}
+		if task.Title != "Desktop edit" {
+			t.Errorf("%s: expected later edit to win, got '%s'", name, task.Title)
+		}
+		if _, err := env.Service.GetTask(env.ctx, doomed.ID); err != domain.ErrTaskNotFound {
+			t.Errorf("%s: expected deleted task to stay deleted, got %v", name, err)
+		}
+	}
+
+	// The synced delete took the link to the task with it
+	if links, err := laptopLinks.ListByTask(laptop.ctx, shared.ID); err != nil || len(links) != 0 {
+		t.Errorf("expected no links to the deleted task, got %d (%v)", len(links), err)
+	}
+
+	plan, err = syncer.Plan(laptop.ctx)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if !plan.Empty() {
+		t.Errorf("expected databases to be in sync, %d action(s) pending", len(plan.Actions))
+	}
+}
+
+// failingCreates fails to create the task with the given ID
+type failingCreates struct {
+	domain.TaskRepository
+	id string
+}
+
+// Create fails for the task with the ID, and creates the others
+func (r *failingCreates) Create(ctx context.Context, task *domain.Task) error {
+	if task.ID == r.id {
+		return errors.New("disk full")
+	}
+	return r.TaskRepository.Create(ctx, task)
+}
+
+// TestSyncTransactions tests that a sync changes each database in one
+// transaction, kept apart from the other database's
+func TestSyncTransactions(t *testing.T) {
+	laptop := setupTestEnvironment(t)
+	defer laptop.cleanup(t)
+	desktop := setupTestEnvironment(t)
+	defer desktop.cleanup(t)
+
+	first, err := desktop.Service.CreateTask(desktop.ctx, "First", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	second, err := desktop.Service.CreateTask(desktop.ctx, "Second", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	// A failed pull leaves the local database as it was
+	local := dbsync.NewSQLiteReplica(laptop.Storage.DB(), laptop.Logger)
+	local.Tasks = &failingCreates{TaskRepository: local.Tasks, id: max(first.ID, second.ID)}
+	syncer := dbsync.NewSyncer(local, dbsync.NewSQLiteReplica(desktop.Storage.DB(), desktop.Logger), laptop.Logger)
+	if _, err := syncer.Sync(laptop.ctx); err == nil {
+		t.Fatal("expected the sync to fail")
+	}
+	if n, _ := laptop.Service.CountTasks(laptop.ctx, domain.TaskFilter{}); n != 0 {
+		t.Errorf("expected the failed sync to be rolled back, got %d task(s)", n)
+	}
+
+	// Transactions on two databases do not mix
+	laptopTx := repository.NewTxManager(laptop.Storage.DB(), laptop.Logger)
+	desktopTx := repository.NewTxManager(desktop.Storage.DB(), desktop.Logger)
+	errAbort := errors.New("abort")
+	err = laptopTx.WithTx(laptop.ctx, func(ctx context.Context) error {
+		if err := desktopTx.WithTx(ctx, func(ctx context.Context) error {
+			return desktop.Repo.Delete(ctx, first.ID)
+		}); err != nil {
+			return err
+		}
+		if err := laptop.Repo.Create(ctx, &domain.Task{ID: uuid.New().String(), Title: "Rolled back",
+			Status: domain.TaskStatusPending, Priority: domain.TaskPriorityLow, CreatedAt: time.Now(), UpdatedAt: time.Now()}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected the outer transaction to abort, got %v", err)
+	}
+	if _, err := desktop.Service.GetTask(desktop.ctx, first.ID); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("expected the desktop transaction to be committed on its own, got %v", err)
+	}
+	if n, _ := laptop.Service.CountTasks(laptop.ctx, domain.TaskFilter{}); n != 0 {
+		t.Errorf("expected the laptop transaction to be rolled back, got %d task(s)", n)
+	}
+}
+
+// TestSyncEncryptedDatabases tests that a sync only copies encrypted rows
+// between databases sharing a key
+func TestSyncEncryptedDatabases(t *testing.T) {
+	laptop := setupTestEnvironment(t)
+	defer laptop.cleanup(t)
+	desktop := setupTestEnvironment(t)
+	defer desktop.cleanup(t)
+
+	if _, err := laptop.Service.CreateTask(laptop.ctx, "Client: Acme merger", "", domain.TaskPriorityHigh); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := encryption.EncryptDatabase(laptop.ctx, laptop.Storage.DB(), "correct horse"); err != nil {
+		t.Fatalf("failed to encrypt database: %v", err)
+	}
+
+	// A plain database does not take encrypted rows
+	if err := encryption.CheckSameKey(laptop.ctx, laptop.Storage.DB(), desktop.Storage.DB()); !errors.Is(err, encryption.ErrKeyMismatch) {
+		t.Errorf("expected a key mismatch with a plain database, got %v", err)
+	}
+
+	// A new remote takes the key, and reads the synced tasks with the passphrase
+	if err := encryption.CopyKey(laptop.ctx, laptop.Storage.DB(), desktop.Storage.DB()); err != nil {
+		t.Fatalf("failed to copy key: %v", err)
+	}
+	if err := encryption.CheckSameKey(laptop.ctx, laptop.Storage.DB(), desktop.Storage.DB()); err != nil {
+		t.Fatalf("expected the same key after copying it, got %v", err)
+	}
+	syncer := dbsync.NewSyncer(
+		dbsync.NewSQLiteReplica(laptop.Storage.DB(), laptop.Logger),
+		dbsync.NewSQLiteReplica(desktop.Storage.DB(), desktop.Logger),
+		laptop.Logger,
+	)
+	if _, err := syncer.Sync(laptop.ctx); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	cipher, err := encryption.Open(desktop.ctx, desktop.Storage.DB(), "correct horse")
+	if err != nil || cipher == nil {
+		t.Fatalf("failed to open the remote with the passphrase: %v", err)
+	}
+	tasks, err := repository.NewEncryptedTaskRepository(desktop.Repo, cipher).List(desktop.ctx, domain.TaskFilter{})
+	if err != nil || len(tasks) != 1 || tasks[0].Title != "Client: Acme merger" {
+		t.Errorf("expected the synced task to be readable on the remote, got %v (%v)", tasks, err)
+	}
+
+	// A database encrypted on its own has another salt, even with the same passphrase
+	other := setupTestEnvironment(t)
+	defer other.cleanup(t)
+	if _, err := encryption.EncryptDatabase(other.ctx, other.Storage.DB(), "correct horse"); err != nil {
+		t.Fatalf("failed to encrypt database: %v", err)
+	}
+	if err := encryption.CheckSameKey(laptop.ctx, laptop.Storage.DB(), other.Storage.DB()); !errors.Is(err, encryption.ErrKeyMismatch) {
+		t.Errorf("expected a key mismatch between separately encrypted databases, got %v", err)
+	}
+	if err := encryption.CopyKey(laptop.ctx, laptop.Storage.DB(), other.Storage.DB()); !errors.Is(err, encryption.ErrAlreadyEncrypted) {
+		t.Errorf("expected copying a key into an encrypted database to fail, got %v", err)
+	}
+}
+
+// TestTracingSpans tests that service and repository calls are traced
+func TestTracingSpans(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	svc := service.NewTaskService(repository.NewTracingTaskRepository(env.Repo), env.Logger)
+
+	if _, err := svc.CreateTask(env.ctx, "Traced", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := svc.GetTask(env.ctx, "nonexistent-id"); err == nil {
+		t.Fatal("expected error for nonexistent task")
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	parent, ok := spans["TaskService.CreateTask"]
+	if !ok {
+		t.Fatal("expected a TaskService.CreateTask span")
+	}
+	child, ok := spans["TaskRepository.Create"]
+	if !ok {
+		t.Fatal("expected a TaskRepository.Create span")
+	}
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected repository span to be a child of the service span")
+	}
+
+	if get, ok := spans["TaskRepository.GetByID"]; !ok || get.Status().Code != codes.Error {
+		t.Error("expected failed GetByID to be recorded as an error span")
+	}
+}
+
+// TestTaskHooks tests Go and config-declared hooks on create and update
+func TestTaskHooks(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	declared, err := rules.Compile([]config.RuleConfig{{
+		Name:    "office-needs-description",
+		When:    map[string]string{"context": "@Office"},
+		Require: []string{"description"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to compile rules: %v", err)
+	}
+
+	trimTitle := func(ctx context.Context, task *domain.Task) error {
+		task.Title = strings.TrimSpace(task.Title)
+		return nil
+	}
+
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithHooks(trimTitle), service.WithHooks(declared...))
+
+	_, err = svc.CreateTask(env.ctx, "Report", "", domain.TaskPriorityLow, domain.WithTaskContext("office"))
+	var violation *domain.RuleViolation
+	if !errors.As(err, &violation) || violation.Rule != "office-needs-description" {
+		t.Fatalf("expected rule violation, got %v", err)
+	}
+
+	task, err := svc.CreateTask(env.ctx, "  Groceries  ", "", domain.TaskPriorityLow, domain.WithTaskContext("errands"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if task.Title != "Groceries" {
+		t.Errorf("expected hook to trim title, got '%s'", task.Title)
+	}
+
+	if _, err := svc.UpdateTask(env.ctx, task.ID, "", "", "", domain.WithTaskContext("office")); !errors.As(err, &violation) {
+		t.Errorf("expected rule violation on update, got %v", err)
+	}
+
+	if _, err := rules.Compile([]config.RuleConfig{{Name: "bad", Require: []string{"due_date"}}}); err == nil {
+		t.Error("expected error for unknown rule field")
+	}
+}
+
+// TestUpdateTasksBulk tests filtered bulk updates
+func TestUpdateTasksBulk(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	for _, title := range []string{"One", "Two"} {
+		if _, err := env.Service.CreateTask(env.ctx, title, "", domain.TaskPriorityHigh, domain.WithTaskContext("someday")); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	other, err := env.Service.CreateTask(env.ctx, "Other", "", domain.TaskPriorityHigh)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	someday := "someday"
+	low := domain.TaskPriorityLow
+	completed := domain.TaskStatusCompleted
+	n, err := env.Service.UpdateTasks(env.ctx, domain.TaskFilter{Context: &someday},
+		domain.TaskPatch{Priority: &low, Status: &completed})
+	if err != nil {
+		t.Fatalf("failed to update tasks: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 tasks updated, got %d", n)
+	}
+
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{Context: &someday})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	for _, task := range tasks {
+		if task.Priority != domain.TaskPriorityLow || task.Status != domain.TaskStatusCompleted || task.CompletedAt == nil {
+			t.Errorf("expected task %s to be low priority and completed, got %s/%s", task.ID, task.Priority, task.Status)
+		}
+	}
+
+	unchanged, err := env.Service.GetTask(env.ctx, other.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if unchanged.Priority != domain.TaskPriorityHigh {
+		t.Error("expected task outside the filter to be unchanged")
+	}
+
+	// An invalid change is rejected for every task
+	bad := "two words"
+	if _, err := env.Service.UpdateTasks(env.ctx, domain.TaskFilter{}, domain.TaskPatch{Context: &bad}); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+// TestCompletionCache tests that cached completion data goes stale on writes
+func TestCompletionCache(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	if _, err := env.Service.CreateTask(env.ctx, "Cached", "", domain.TaskPriorityLow,
+		domain.WithTaskContext("home"), domain.WithAssignee("")); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	fingerprint, err := suggest.Fingerprint(env.DBPath)
+	if err != nil {
+		t.Fatalf("failed to fingerprint database: %v", err)
+	}
+
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+
+	store := suggest.NewStore(filepath.Join(t.TempDir(), "completion.json"))
+	if err := store.Save(suggest.Build(fingerprint, tasks)); err != nil {
+		t.Fatalf("failed to save cache: %v", err)
+	}
+
+	cache, err := store.Load()
+	if err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+	if cache.Fingerprint != fingerprint || len(cache.Tasks) != 1 || cache.Tasks[0].Title != "Cached" {
+		t.Errorf("unexpected cache contents: %+v", cache)
+	}
+	if len(cache.Contexts) != 1 || cache.Contexts[0] != "home" {
+		t.Errorf("expected contexts [home], got %v", cache.Contexts)
+	}
+
+	if _, err := env.Service.CreateTask(env.ctx, "Another", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	after, err := suggest.Fingerprint(env.DBPath)
+	if err != nil {
+		t.Fatalf("failed to fingerprint database: %v", err)
+	}
+	if after == cache.Fingerprint {
+		t.Error("expected fingerprint to change after a write")
+	}
+}
+
+// TestQueryFiltering tests filtering with query expressions
+func TestQueryFiltering(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	fixtures := []struct {
+		title    string
+		priority domain.TaskPriority
+		context  string
+		due      string // metadata key and day, "todoist.due=2026-03-01"
+	}{
+		{"Buy milk", domain.TaskPriorityHigh, "errands", "due=2026-01-10"},
+		{"Write report", domain.TaskPriorityMedium, "office", "due=2026-02-01"},
+		{"Water plants", domain.TaskPriorityLow, "home", "todoist.due=2026-03-01"},
+		{"Call 100% support", domain.TaskPriorityLow, "office", ""},
+	}
+	for _, f := range fixtures {
+		task, err := env.Service.CreateTask(env.ctx, f.title, "", f.priority, domain.WithTaskContext(f.context))
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		if key, day, ok := strings.Cut(f.due, "="); ok {
+			if _, err := env.Service.SetTaskMetadata(env.ctx, task.ID, key, day); err != nil {
+				t.Fatalf("failed to set due date: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"priority:high OR context:office", 3},
+		{"context:office AND NOT priority:low", 1},
+		{"context:office priority:low", 1},
+		{"priority>=medium", 2},
+		{"priority<high AND (context:home OR context:errands)", 1},
+		{"title:water", 1},
+		{`title:"100%"`, 1},
+		{"title:_", 0},
+		{"created:today", 4},
+		{"created<2000-01-01", 0},
+		{"completed>=2000-01-01", 0},
+		{"status:pending AND context!=office", 2},
+		{"due<2026-02-01", 1},
+		{"due<=2026-02-01", 2},
+		{"due:2026-03-01", 1},
+		{"due>2026-01-10", 2},
+		{"due!=2026-01-10", 3},
+		{"status:pending AND due<2025-01-01", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := query.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{Query: expr})
+			if err != nil {
+				t.Fatalf("failed to list tasks: %v", err)
+			}
+			if len(tasks) != tt.want {
+				t.Errorf("expected %d tasks, got %d", tt.want, len(tasks))
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "tag:urgent", "status:done", "title<a", "(priority:high", "priority:high)", "status"} {
+		if _, err := query.Parse(bad); err == nil {
+			t.Errorf("expected parse error for %q", bad)
+		}
+	}
+}
+
+// TestTaskCounts tests counting tasks in the database
+func TestTaskCounts(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	for _, priority := range []domain.TaskPriority{domain.TaskPriorityHigh, domain.TaskPriorityHigh, domain.TaskPriorityLow} {
+		if _, err := env.Service.CreateTask(env.ctx, "Task", "", priority); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	done, err := env.Service.CreateTask(env.ctx, "Done", "", domain.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.CompleteTask(env.ctx, done.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+
+	n, err := env.Service.CountTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to count tasks: %v", err)
+	}
+	if n != 4 {
+		t.Errorf("expected 4 tasks, got %d", n)
+	}
+
+	high := domain.TaskPriorityHigh
+	n, err = env.Service.CountTasks(env.ctx, domain.TaskFilter{Priority: &high})
+	if err != nil {
+		t.Fatalf("failed to count tasks: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 high priority tasks, got %d", n)
+	}
+
+	byStatus, err := env.Service.CountTasksByStatus(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to count tasks by status: %v", err)
+	}
+	if byStatus[domain.TaskStatusPending] != 3 || byStatus[domain.TaskStatusCompleted] != 1 {
+		t.Errorf("unexpected status counts: %v", byStatus)
+	}
+
+	pending := domain.TaskStatusPending
+	byPriority, err := env.Service.CountTasksByPriority(env.ctx, domain.TaskFilter{Status: &pending})
+	if err != nil {
+		t.Fatalf("failed to count tasks by priority: %v", err)
+	}
+	if byPriority[domain.TaskPriorityHigh] != 2 || byPriority[domain.TaskPriorityLow] != 1 || byPriority[domain.TaskPriorityMedium] != 0 {
+		t.Errorf("unexpected priority counts: %v", byPriority)
+	}
+}
+
+// TestStreamTasks tests streaming tasks without loading them into a slice
+func TestStreamTasks(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	for i := 0; i < 5; i++ {
+		if _, err := env.Service.CreateTask(env.ctx, fmt.Sprintf("Task %d", i), "", domain.TaskPriorityMedium); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	var want bytes.Buffer
+	if err := export.WriteCanonical(&want, tasks); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	// Streaming in ID order produces the canonical export directly
+	var got bytes.Buffer
+	err = env.Service.StreamTasks(env.ctx, domain.TaskFilter{}, func(task *domain.Task) error {
+		return export.WriteCanonicalTask(&got, task)
+	})
+	if err != nil {
+		t.Fatalf("failed to stream tasks: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("streamed export differs from canonical export:\n%s\nvs\n%s", got.String(), want.String())
+	}
+
+	// An error from the callback stops the stream
+	stop := errors.New("stop")
+	seen := 0
+	err = env.Service.StreamTasks(env.ctx, domain.TaskFilter{}, func(task *domain.Task) error {
+		seen++
+		if seen == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("expected callback error, got %v", err)
+	}
+	if seen != 2 {
+		t.Errorf("expected stream to stop after 2 tasks, saw %d", seen)
+	}
+}
+
+// TestRetryBusyWrites tests that writes blocked by another connection are retried
+func TestRetryBusyWrites(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	// A second connection to the same file that gives up on locks almost immediately
+	other, err := storage.NewSQLiteStorage(env.ctx, env.DBPath, env.Logger, storage.WithBusyTimeout(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to open second connection: %v", err)
+	}
+	defer other.Close()
+	inner := repository.NewSQLiteTaskRepository(other.DB(), env.Logger)
+
+	// Hold the write lock from the first connection
+	conn, err := env.Storage.DB().Conn(env.ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	if _, err := conn.ExecContext(env.ctx, "BEGIN IMMEDIATE"); err != nil {
+		conn.Close()
+		t.Fatalf("failed to take write lock: %v", err)
+	}
+
+	now := time.Now()
+	task := &domain.Task{
+		ID:        "6f1c2d9e-4b7a-4c3e-9a51-0d2e8f7b3c14",
+		Title:     "Blocked",
+		Status:    domain.TaskStatusPending,
+		Priority:  domain.TaskPriorityLow,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	noRetry := repository.NewRetryTaskRepository(inner, repository.RetryPolicy{Attempts: 1}, env.Logger)
+	if err := noRetry.Create(env.ctx, task); !storage.IsBusy(err) {
+		t.Fatalf("expected busy error without retries, got %v", err)
+	}
+
+	// Release the lock while the retrying write is backing off
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		conn.ExecContext(context.Background(), "ROLLBACK")
+		conn.Close()
+	}()
+
+	retrying := repository.NewRetryTaskRepository(inner, repository.RetryPolicy{Attempts: 10, Backoff: 20 * time.Millisecond}, env.Logger)
+	if err := retrying.Create(env.ctx, task); err != nil {
+		t.Fatalf("expected write to succeed after retrying, got %v", err)
+	}
+
+	if _, err := env.Service.GetTask(env.ctx, task.ID); err != nil {
+		t.Errorf("expected retried task to be stored: %v", err)
+	}
+}
+
+// TestConcurrentWriters tests two writers on one database, each running its
+// writes in transactions that read before they write, so that SQLite fails
+// one of them at once rather than deadlock
+func TestConcurrentWriters(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	policy := repository.RetryPolicy{Attempts: 12, Backoff: time.Millisecond}
+	writer := func() *service.TaskService {
+		t.Helper()
+		st, err := storage.NewSQLiteStorage(env.ctx, env.DBPath, env.Logger, storage.WithBusyTimeout(time.Millisecond))
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		t.Cleanup(func() { st.Close() })
+		repo := repository.NewRetryTaskRepository(repository.NewSQLiteTaskRepository(st.DB(), env.Logger), policy, env.Logger)
+		return service.NewTaskService(repo, env.Logger,
+			service.WithTransactions(repository.NewTxManager(st.DB(), env.Logger).WithRetry(policy)))
+	}
+
+	const writes = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*writes)
+	for _, svc := range []*service.TaskService{writer(), writer()} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range writes {
+				task, err := svc.CreateTask(env.ctx, "Concurrent", "", domain.TaskPriorityLow)
+				if err == nil {
+					_, err = svc.UpdateTask(env.ctx, task.ID, "", "", domain.TaskPriorityHigh)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("write failed: %v", err)
+	}
+
+	high := domain.TaskPriorityHigh
+	if n, err := env.Service.CountTasks(env.ctx, domain.TaskFilter{Priority: &high}); err != nil || n != 2*writes {
+		t.Errorf("expected %d updated tasks, got %d (%v)", 2*writes, n, err)
+	}
+}
+
+// TestCachedRepository tests that cached reads are served from memory and
+// invalidated by writes
+func TestCachedRepository(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	// Write calls read in their transaction, past the cache, so they never
+	// write back stale fields
+	cached := repository.NewCachedTaskRepository(env.Repo, repository.CachePolicy{Size: 10, TTL: time.Hour})
+	svc := service.NewTaskService(cached, env.Logger, service.WithTransactions(repository.NewTxManager(env.Storage.DB(), env.Logger)))
+
+	task, err := svc.CreateTask(env.ctx, "Cached", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	low := domain.TaskPriorityLow
+	filter := domain.TaskFilter{Priority: &low}
+	if _, err := svc.GetTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if _, err := svc.ListTasks(env.ctx, filter); err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+
+	// A write bypassing the cache goes unnoticed...
+	if _, err := env.Service.UpdateTask(env.ctx, task.ID, "Changed elsewhere", "", ""); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	got, err := svc.GetTask(env.ctx, task.ID)
+	if err != nil || got.Title != "Cached" {
+		t.Errorf("expected the cached title, got %v (%v)", got, err)
+	}
+
+	// ...and callers changing what they got do not change the cache
+	got.Title = "Mutated"
+	tasks, err := svc.ListTasks(env.ctx, filter)
+	if err != nil || len(tasks) != 1 || tasks[0].Title != "Cached" {
+		t.Errorf("expected the cached listing, got %v (%v)", tasks, err)
+	}
+	if again, _ := svc.GetTask(env.ctx, task.ID); again.Title != "Cached" {
+		t.Errorf("expected the cache to be unaffected by callers, got %q", again.Title)
+	}
+
+	// A write through the cache invalidates the task and the listings
+	if _, err := svc.CreateTask(env.ctx, "Another", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if tasks, _ := svc.ListTasks(env.ctx, filter); len(tasks) != 2 {
+		t.Errorf("expected the listing to be read again, got %d tasks", len(tasks))
+	}
+	if _, err := svc.CompleteTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+	got, err = svc.GetTask(env.ctx, task.ID)
+	if err != nil || got.Title != "Changed elsewhere" || got.Status != domain.TaskStatusCompleted {
+		t.Errorf("expected the task to be read again, got %v (%v)", got, err)
+	}
+
+	// Entries expire
+	short := service.NewTaskService(repository.NewCachedTaskRepository(env.Repo, repository.CachePolicy{Size: 10, TTL: time.Millisecond}), env.Logger)
+	if _, err := short.GetTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if _, err := env.Service.UpdateTask(env.ctx, task.ID, "Expired", "", ""); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if got, _ := short.GetTask(env.ctx, task.ID); got.Title != "Expired" {
+		t.Errorf("expected the expired entry to be read again, got %q", got.Title)
+	}
+}
+
+// TestDatabaseEncryption tests encrypting and decrypting task text at rest
+func TestDatabaseEncryption(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	existing, err := env.Service.CreateTask(env.ctx, "Client: Acme merger", "Call the lawyers", domain.TaskPriorityHigh)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	n, err := encryption.EncryptDatabase(env.ctx, env.Storage.DB(), "correct horse")
+	if err != nil {
+		t.Fatalf("failed to encrypt database: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 task encrypted, got %d", n)
+	}
+
+	if _, err := encryption.EncryptDatabase(env.ctx, env.Storage.DB(), "correct horse"); !errors.Is(err, encryption.ErrAlreadyEncrypted) {
+		t.Errorf("expected already encrypted error, got %v", err)
+	}
+
+	if _, err := encryption.Open(env.ctx, env.Storage.DB(), ""); !errors.Is(err, encryption.ErrPassphraseRequired) {
+		t.Errorf("expected passphrase required error, got %v", err)
+	}
+	if _, err := encryption.Open(env.ctx, env.Storage.DB(), "wrong"); !errors.Is(err, encryption.ErrWrongPassphrase) {
+		t.Errorf("expected wrong passphrase error, got %v", err)
+	}
+
+	cipher, err := encryption.Open(env.ctx, env.Storage.DB(), "correct horse")
+	if err != nil || cipher == nil {
+		t.Fatalf("failed to open encrypted database: %v", err)
+	}
+	svc := service.NewTaskService(repository.NewEncryptedTaskRepository(env.Repo, cipher), env.Logger)
+
+	created, err := svc.CreateTask(env.ctx, "Client: Globex audit", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create encrypted task: %v", err)
+	}
+	if created.Title != "Client: Globex audit" {
+		t.Errorf("expected caller's task to stay in plain text, got %q", created.Title)
+	}
+
+	// Text that looks encrypted is sealed like any other
+	lookalike, err := svc.CreateTask(env.ctx, "enc:v1:not really", "enc:v1:", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	var raw string
+	if err := env.Storage.DB().QueryRowContext(env.ctx, "SELECT title FROM tasks WHERE id = ?", lookalike.ID).Scan(&raw); err != nil {
+		t.Fatalf("failed to query raw task: %v", err)
+	}
+	if raw == lookalike.Title {
+		t.Errorf("expected a title with the encryption prefix to be sealed, got %q", raw)
+	}
+	if got, err := svc.GetTask(env.ctx, lookalike.ID); err != nil || got.Title != "enc:v1:not really" || got.Description != "enc:v1:" {
+		t.Errorf("expected the title and description back as typed, got %v (%v)", got, err)
+	}
+	if err := svc.DeleteTask(env.ctx, lookalike.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	// Nothing readable is left in the table
+	var plain int
+	if err := env.Storage.DB().QueryRowContext(env.ctx,
+		"SELECT COUNT(*) FROM tasks WHERE title LIKE '%Client%' OR description LIKE '%lawyers%'").Scan(&plain); err != nil {
+		t.Fatalf("failed to query raw tasks: %v", err)
+	}
+	if plain != 0 {
+		t.Errorf("expected no plain-text titles or descriptions, found %d", plain)
+	}
+
+	got, err := svc.GetTask(env.ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("failed to get encrypted task: %v", err)
+	}
+	if got.Title != existing.Title || got.Description != existing.Description {
+		t.Errorf("expected decrypted %q/%q, got %q/%q", existing.Title, existing.Description, got.Title, got.Description)
+	}
+
+	description := "Send the report"
+	if _, err := svc.UpdateTasks(env.ctx, domain.TaskFilter{}, domain.TaskPatch{Description: &description}); err != nil {
+		t.Fatalf("failed to update tasks: %v", err)
+	}
+
+	if n, err := encryption.DecryptDatabase(env.ctx, env.Storage.DB(), "correct horse"); err != nil || n != 2 {
+		t.Fatalf("expected 2 tasks decrypted, got %d: %v", n, err)
+	}
+	if cipher, err := encryption.Open(env.ctx, env.Storage.DB(), ""); err != nil || cipher != nil {
+		t.Errorf("expected plain database after decrypt, got %v, %v", cipher, err)
+	}
+
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	for _, task := range tasks {
+		if !strings.HasPrefix(task.Title, "Client: ") || task.Description != description {
+			t.Errorf("expected plain-text task after decrypt, got %q/%q", task.Title, task.Description)
+		}
+	}
+}
+
+// TestCreateTasksBatch tests atomic batch creation
+func TestCreateTasksBatch(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	tasks, err := env.Service.CreateTasks(env.ctx, []service.NewTaskDraft{
+		{Title: "Batch 1", Priority: domain.TaskPriorityLow},
+		{Title: "Batch 2", Priority: domain.TaskPriorityHigh, Options: []domain.TaskOption{domain.WithTaskContext("home")}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+	if len(tasks) != 2 || tasks[1].Context != "home" {
+		t.Fatalf("expected 2 tasks with options applied, got %d", len(tasks))
+	}
+
+	// An invalid draft aborts the whole batch
+	_, err = env.Service.CreateTasks(env.ctx, []service.NewTaskDraft{
+		{Title: "Valid", Priority: domain.TaskPriorityLow},
+		{Title: "", Priority: domain.TaskPriorityLow},
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid draft")
+	}
+
+	all, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("expected failed batch to create nothing, got %d tasks total", len(all))
+	}
+}
+
+// TestDryRunService tests that a dry-run service never writes
+func TestDryRunService(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	task, err := env.Service.CreateTask(env.ctx, "Real Task", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	dry := env.Service.DryRun(env.readOnly)
+
+	if _, err := dry.CreateTask(env.ctx, "Ghost Task", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("dry-run create failed: %v", err)
+	}
+
+	completed, err := dry.CompleteTask(env.ctx, task.ID)
+	if err != nil {
+		t.Fatalf("dry-run complete failed: %v", err)
+	}
+	if completed.Status != domain.TaskStatusCompleted {
+		t.Error("expected dry-run result to show the would-be state")
+	}
+
+	if err := dry.DeleteTask(env.ctx, "nonexistent-id"); err != domain.ErrTaskNotFound {
+		t.Errorf("expected ErrTaskNotFound from dry-run delete, got %v", err)
+	}
+	if err := dry.DeleteTask(env.ctx, task.ID); err != nil {
+		t.Fatalf("dry-run delete failed: %v", err)
+	}
+
+	all, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(all) != 1 || all[0].Status != domain.TaskStatusPending {
+		t.Errorf("expected database to be unchanged by dry run, got %d task(s)", len(all))
+	}
+}
+
+// TestTaskAssignees tests assigning tasks to registered users
+func TestTaskAssignees(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	userRepo := repository.NewSQLiteUserRepository(env.Storage.DB(), env.Logger)
+	users := service.NewUserService(userRepo, env.Logger)
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithUserRepository(userRepo))
+
+	if _, err := users.CreateUser(env.ctx, "Alice", "alice@example.com"); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if _, err := users.CreateUser(env.ctx, "alice", ""); err != domain.ErrDuplicateUser {
+		t.Errorf("expected ErrDuplicateUser, got %v", err)
+	}
+
+	task, err := svc.CreateTask(env.ctx, "Review PR", "", domain.TaskPriorityHigh, domain.WithAssignee("ALICE"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if task.Assignee != "alice" {
+		t.Errorf("expected normalized assignee 'alice', got '%s'", task.Assignee)
+	}
+
+	if _, err := svc.CreateTask(env.ctx, "Unowned", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	if _, err := svc.CreateTask(env.ctx, "Orphan", "", domain.TaskPriorityLow, domain.WithAssignee("bob")); err == nil {
+		t.Error("expected error for unknown assignee")
+	}
+
+	name := "alice"
+	results, err := svc.ListTasks(env.ctx, domain.TaskFilter{Assignee: &name})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != task.ID {
+		t.Errorf("expected only alice's task, got %d task(s)", len(results))
+	}
+
+	// Tasks delegated by a creator
+	if _, err := users.CreateUser(env.ctx, "bob", ""); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	delegated, err := svc.CreateTask(env.ctx, "Write docs", "", domain.TaskPriorityLow,
+		domain.WithCreatedBy("Bob"), domain.WithAssignee("alice"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	creator := "bob"
+	results, err = svc.ListTasks(env.ctx, domain.TaskFilter{CreatedBy: &creator})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != delegated.ID {
+		t.Errorf("expected only bob's task, got %d task(s)", len(results))
+	}
+
+	// Unassigning through an update
+	updated, err := svc.UpdateTask(env.ctx, task.ID, "", "", "", domain.WithAssignee(""))
+	if err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if updated.Assignee != "" {
+		t.Errorf("expected assignee to be cleared, got '%s'", updated.Assignee)
+	}
+}
+
+// TestDelegateTask tests handing a task over and the event it publishes
+func TestDelegateTask(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	userRepo := repository.NewSQLiteUserRepository(env.Storage.DB(), env.Logger)
+	users := service.NewUserService(userRepo, env.Logger)
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := users.CreateUser(env.ctx, name, ""); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+
+	var got []events.Event
+	bus := events.NewBus(env.Logger)
+	bus.Subscribe("recorder", func(ctx context.Context, event events.Event) error {
+		got = append(got, event)
+		return nil
+	}, events.TaskDelegated)
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithUserRepository(userRepo), service.WithEvents(bus))
+
+	task, err := svc.CreateTask(env.ctx, "Write report", "", domain.TaskPriorityMedium, domain.WithAssignee("alice"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	delegated, err := svc.DelegateTask(env.ctx, task.ID, "Bob", "alice")
+	if err != nil {
+		t.Fatalf("failed to delegate task: %v", err)
+	}
+	if delegated.Assignee != "bob" {
+		t.Errorf("expected the task to be assigned to bob, got %q", delegated.Assignee)
+	}
+	if len(got) != 1 || got[0].By != "alice" || got[0].Task.Assignee != "bob" {
+		t.Errorf("expected one task.delegated event by alice, got %+v", got)
+	}
+
+	if _, err := svc.DelegateTask(env.ctx, task.ID, "bob", "alice"); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected delegating to the assignee to be rejected, got %v", err)
+	}
+	if _, err := svc.DelegateTask(env.ctx, task.ID, "carol", "alice"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("expected an unknown user to be rejected, got %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("expected rejected delegations to publish nothing, got %d events", len(got))
+	}
+}
+
+// TestUserRoles tests role checks once a user is an admin
+func TestUserRoles(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	userRepo := repository.NewSQLiteUserRepository(env.Storage.DB(), env.Logger)
+	users := service.NewUserService(userRepo, env.Logger)
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithUserRepository(userRepo))
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if _, err := users.CreateUser(env.ctx, name, ""); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	if enforced, _ := users.RolesEnforced(env.ctx); enforced {
+		t.Error("expected no role checks before there is an admin")
+	}
+
+	// Anyone may make the first admin
+	alice, err := users.SetRole(env.ctx, "Alice", domain.RoleAdmin)
+	if err != nil || alice.Role != domain.RoleAdmin {
+		t.Fatalf("failed to make alice an admin: %v", err)
+	}
+	if enforced, _ := users.RolesEnforced(env.ctx); !enforced {
+		t.Error("expected role checks once there is an admin")
+	}
+	bob, _ := users.GetUser(env.ctx, "bob")
+	if _, err := users.As(bob).SetRole(env.ctx, "bob", domain.RoleAdmin); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected members not to change roles, got %v", err)
+	}
+	carol, err := users.As(alice).SetRole(env.ctx, "carol", domain.RoleViewer)
+	if err != nil {
+		t.Fatalf("failed to make carol a viewer: %v", err)
+	}
+
+	asAlice, asBob, asCarol := svc.As(alice), svc.As(bob), svc.As(carol)
+
+	theirs, err := asAlice.CreateTask(env.ctx, "Alice's", "", domain.TaskPriorityLow, domain.WithCreatedBy("alice"))
+	if err != nil {
+		t.Fatalf("failed to create task as admin: %v", err)
+	}
+	mine, err := asBob.CreateTask(env.ctx, "Bob's", "", domain.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task as member: %v", err)
+	}
+	if mine.CreatedBy != "bob" {
+		t.Errorf("expected the member's task to be recorded as theirs, got created_by %q", mine.CreatedBy)
+	}
+
+	if _, err := asBob.CompleteTask(env.ctx, mine.ID); err != nil {
+		t.Errorf("expected members to change their own tasks, got %v", err)
+	}
+	if _, err := asBob.CompleteTask(env.ctx, theirs.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected members not to change others' tasks, got %v", err)
+	}
+	if err := asBob.DeleteTask(env.ctx, theirs.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected members not to delete others' tasks, got %v", err)
+	}
+	high := domain.TaskPriorityHigh
+	if _, err := asBob.UpdateTasks(env.ctx, domain.TaskFilter{}, domain.TaskPatch{Priority: &high}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected a bulk update touching others' tasks to be rejected, got %v", err)
+	}
+
+	if _, err := asCarol.CreateTask(env.ctx, "Carol's", "", domain.TaskPriorityLow); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected viewers not to create tasks, got %v", err)
+	}
+	if tasks, err := asCarol.ListTasks(env.ctx, domain.TaskFilter{}); err != nil || len(tasks) != 2 {
+		t.Errorf("expected viewers to read every task, got %d, %v", len(tasks), err)
+	}
+
+	if _, err := asAlice.UpdateTasks(env.ctx, domain.TaskFilter{}, domain.TaskPatch{Priority: &high}); err != nil {
+		t.Errorf("expected admins to change any task, got %v", err)
+	}
+	stored, _ := svc.GetTask(env.ctx, theirs.ID)
+	if stored.Status != domain.TaskStatusPending || stored.Priority != domain.TaskPriorityHigh {
+		t.Errorf("expected only the admin's change to alice's task, got %s/%s", stored.Status, stored.Priority)
+	}
+}
+
+// TestLinkRoles tests that changing links is subject to the user's role
+func TestLinkRoles(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	links := repository.NewSQLiteLinkRepository(env.Storage.DB(), env.Logger)
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithLinks(links))
+	alice := &domain.User{Name: "alice", Role: domain.RoleAdmin}
+	bob := &domain.User{Name: "bob", Role: domain.RoleMember}
+	carol := &domain.User{Name: "carol", Role: domain.RoleViewer}
+
+	theirs, err := svc.CreateTask(env.ctx, "Alice's", "", domain.TaskPriorityLow, domain.WithCreatedBy("alice"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	mine, err := svc.CreateTask(env.ctx, "Bob's", "", domain.TaskPriorityLow, domain.WithCreatedBy("bob"))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	link := func(from, to string) *domain.Link {
+		return &domain.Link{TaskID: from, Kind: domain.LinkRelates, Target: to, CreatedAt: time.Now()}
+	}
+
+	if err := svc.As(carol).LinkTask(env.ctx, link(mine.ID, theirs.ID)); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected viewers not to link tasks, got %v", err)
+	}
+	if err := svc.As(bob).LinkTask(env.ctx, link(theirs.ID, mine.ID)); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected members not to link others' tasks, got %v", err)
+	}
+	if err := svc.As(bob).DryRun(env.readOnly).LinkTask(env.ctx, link(mine.ID, theirs.ID)); err != nil {
+		t.Errorf("expected a dry run to succeed, got %v", err)
+	}
+	if got, _ := links.ListByTask(env.ctx, mine.ID); len(got) != 0 {
+		t.Errorf("expected a dry run and rejected links to write nothing, got %+v", got)
+	}
+	if err := svc.As(bob).LinkTask(env.ctx, link(mine.ID, theirs.ID)); err != nil {
+		t.Errorf("expected members to link their own tasks, got %v", err)
+	}
+	if err := svc.As(carol).UnlinkTask(env.ctx, mine.ID, theirs.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected viewers not to unlink tasks, got %v", err)
+	}
+	if err := svc.As(bob).UnlinkTask(env.ctx, theirs.ID, mine.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("expected members not to unlink others' tasks, got %v", err)
+	}
+	if err := svc.As(alice).UnlinkTask(env.ctx, theirs.ID, mine.ID); err != nil {
+		t.Errorf("expected admins to unlink any task, got %v", err)
+	}
+	if err := service.NewTaskService(env.Repo, env.Logger).LinkTask(env.ctx, link(mine.ID, theirs.ID)); err == nil {
+		t.Error("expected linking to fail without a link repository")
+	}
+}
+
+// TestOwnerScope tests limiting the task service to the tasks a user owns
+func TestOwnerScope(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	userRepo := repository.NewSQLiteUserRepository(env.Storage.DB(), env.Logger)
+	users := service.NewUserService(userRepo, env.Logger)
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := users.CreateUser(env.ctx, name, ""); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	svc := service.NewTaskService(env.Repo, env.Logger, service.WithUserRepository(userRepo))
+
+	created, _ := svc.CreateTask(env.ctx, "Created by bob", "", domain.TaskPriorityLow, domain.WithCreatedBy("bob"))
+	assigned, _ := svc.CreateTask(env.ctx, "Assigned to bob", "", domain.TaskPriorityLow,
+		domain.WithCreatedBy("alice"), domain.WithAssignee("bob"))
+	other, _ := svc.CreateTask(env.ctx, "Alice's", "", domain.TaskPriorityHigh, domain.WithCreatedBy("alice"))
+	if _, err := svc.CreateTask(env.ctx, "Nobody's", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	bob := svc.ScopedTo(&domain.User{Name: "bob", Role: domain.RoleMember})
+	tasks, err := bob.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	var ids []string
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	if len(ids) != 2 || !slices.Contains(ids, created.ID) || !slices.Contains(ids, assigned.ID) {
+		t.Errorf("expected the tasks bob created or is assigned, got %v", ids)
+	}
+	if n, _ := bob.CountTasks(env.ctx, domain.TaskFilter{}); n != 2 {
+		t.Errorf("expected counts to be scoped too, got %d", n)
+	}
+	if _, err := bob.GetTask(env.ctx, other.ID); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Errorf("expected others' tasks to be not found, got %v", err)
+	}
+
+	high := domain.TaskPriorityHigh
+	if n, err := bob.UpdateTasks(env.ctx, domain.TaskFilter{}, domain.TaskPatch{Priority: &high}); err != nil || n != 2 {
+		t.Errorf("expected bulk updates to touch only bob's 2 tasks, got %d, %v", n, err)
+	}
+
+	nobody := svc.ScopedTo(&domain.User{Role: domain.RoleViewer})
+	if n, _ := nobody.CountTasks(env.ctx, domain.TaskFilter{}); n != 0 {
+		t.Errorf("expected an unnamed user to see no tasks, got %d", n)
+	}
+	if n, _ := svc.CountTasks(env.ctx, domain.TaskFilter{}); n != 4 {
+		t.Errorf("expected the unscoped service to see all 4 tasks, got %d", n)
+	}
+}
+
+// TestCanonicalExport tests that canonical exports are deterministic
+func TestCanonicalExport(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	if _, err := env.Service.CreateTask(env.ctx, "First", "", domain.TaskPriorityLow); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	second, err := env.Service.CreateTask(env.ctx, "Second", "", domain.TaskPriorityHigh)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.SetTaskMetadata(env.ctx, second.ID, "github", json.RawMessage(`{"repo":"x","number":7}`)); err != nil {
+		t.Fatalf("failed to set metadata: %v", err)
+	}
+
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+
+	var first bytes.Buffer
+	if err := export.WriteCanonical(&first, tasks); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	// Same data in a different order, local time zone, and metadata key order
+	reordered := make([]*domain.Task, 0, len(tasks))
+	for i := len(tasks) - 1; i >= 0; i-- {
+		task := *tasks[i]
+		task.CreatedAt = task.CreatedAt.In(time.FixedZone("UTC+2", 2*60*60))
+		if task.ID == second.ID {
+			task.Metadata = domain.Metadata{"github": json.RawMessage(`{ "number": 7, "repo": "x" }`)}
+		}
+		reordered = append(reordered, &task)
+	}
+
+	var again bytes.Buffer
+	if err := export.WriteCanonical(&again, reordered); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	if first.String() != again.String() {
+		t.Errorf("expected identical exports, got:\n%s\nvs:\n%s", first.String(), again.String())
+	}
+	if lines := strings.Count(first.String(), "\n"); lines != 2 {
+		t.Errorf("expected 2 lines, got %d", lines)
+	}
+
+	d1, err := export.Digest(tasks)
+	if err != nil {
+		t.Fatalf("failed to hash export: %v", err)
+	}
+	d2, _ := export.Digest(reordered)
+	if d1 != d2 {
+		t.Error("expected identical digests for identical data")
+	}
+}
+
+// TestCanonicalSchema tests that canonical exports follow their schema and read back
+func TestCanonicalSchema(t *testing.T) {
+	env := setupTestEnvironment(t)
+	defer env.cleanup(t)
+
+	task, err := env.Service.CreateTask(env.ctx, "Renew passport", "Before June", domain.TaskPriorityHigh,
+		domain.WithTaskContext("home"), domain.WithEstimate(90*time.Minute), domain.WithPinned(true))
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := env.Service.SetTaskMetadata(env.ctx, task.ID, "todoist.due", json.RawMessage(`"2026-06-01"`)); err != nil {
+		t.Fatalf("failed to set metadata: %v", err)
+	}
+	tasks, err := env.Service.ListTasks(env.ctx, domain.TaskFilter{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+
+	var exported bytes.Buffer
+	if err := export.WriteCanonical(&exported, tasks); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if !strings.Contains(exported.String(), `"schema_version":1`) {
+		t.Errorf("expected the schema version in the export, got %s", exported.String())
+	}
+
+	read, err := export.ReadCanonical(bytes.NewReader(exported.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to read export back: %v", err)
+	}
+	want, _ := export.Digest(tasks)
+	if got, _ := export.Digest(read); got != want {
+		t.Error("expected the export to read back unchanged")
+	}
+
+	line := strings.TrimSpace(exported.String())
+	tests := []struct {
+		name string
+		line string
+		path string
+		msg  string
+	}{
+		{"enum", strings.Replace(line, `"priority":"high"`, `"priority":"urgent"`, 1), "/priority", "must be one of low, medium, high"},
+		{"type", strings.Replace(line, `"estimate":5400`, `"estimate":"90m"`, 1), "/estimate", "must be integer"},
+		{"nested", strings.Replace(line, `"fields":{}`, `"fields":{"sprint":12}`, 1), "/fields/sprint", "must be string"},
+		{"unknown", strings.Replace(line, `"title"`, `"titel"`, 1), "", `missing required property "title"`},
+		{"format", strings.Replace(line, `"created_at":"`, `"created_at":"yesterday`, 1), "/created_at", "RFC 3339"},
+		{"newer", strings.Replace(line, `"schema_version":1`, `"schema_version":2`, 1), "/schema_version", "upgrade task"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := export.ReadCanonical(strings.NewReader("\n" + tt.line + "\n"))
+			var schemaErr *export.SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected a schema error, got %v", err)
+			}
+			if schemaErr.Path != tt.path || !strings.Contains(schemaErr.Message, tt.msg) {
+				t.Errorf("expected %q at %q, got %q at %q", tt.msg, tt.path, schemaErr.Message, schemaErr.Path)
+			}
+			if !strings.HasPrefix(err.Error(), "line 2: ") {
+				t.Errorf("expected the line number in %q", err)
+			}
+		})
+	}
+}
+
 // BenchmarkTaskCreation benchmarks task creation performance
 func BenchmarkTaskCreation(b *testing.B) {
 	env := setupTestEnvironment(&testing.T{})